@@ -1,33 +1,52 @@
 package main
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"database/sql"
-	"log"
+	"log/slog"
+	"os"
+	"time"
 
 	"github.com/hanifsyahsn/go_boilerplate/internal/config"
 	"github.com/hanifsyahsn/go_boilerplate/internal/db"
 	"github.com/hanifsyahsn/go_boilerplate/internal/server"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/logger"
 	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/tracing"
 )
 
 func main() {
 	conf, err := config.LoadConfig(".")
 	if err != nil {
-		log.Fatal("Error loading config: ", err)
+		logger.Fatal("Error loading config", slog.Any("err", err))
 	}
 
 	if err = conf.Validate(); err != nil {
-		log.Fatal("Invalid configuration:", err)
+		logger.Fatal("Invalid configuration", slog.Any("err", err))
 	}
 
+	slog.SetDefault(logger.New(os.Stderr, conf))
+
+	shutdownTracing, err := tracing.Setup(context.Background(), conf)
+	if err != nil {
+		logger.Fatal("Cannot set up tracing", slog.Any("err", err))
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Failed to flush traces", slog.Any("err", err))
+		}
+	}()
+
 	conn, err := sql.Open(conf.DBDriver, conf.DBSource)
 	if err != nil {
-		log.Fatal("Cannot open DB driver:", err)
+		logger.Fatal("Cannot open DB driver", slog.Any("err", err))
 	}
 
 	if err = conn.Ping(); err != nil {
-		log.Fatal("Cannot connect to database:", err)
+		logger.Fatal("Cannot connect to database", slog.Any("err", err))
 	}
 
 	var tokenMaker token.Maker
@@ -38,24 +57,24 @@ func main() {
 		var privateKey *ecdsa.PrivateKey
 		privateKey, err = token.LoadECPrivateKey(conf.ECPrivateKeyPath)
 		if err != nil {
-			log.Fatal("Error loading private key")
+			logger.Fatal("Error loading private key", slog.Any("err", err))
 		}
 
 		var publicKey *ecdsa.PublicKey
 		publicKey, err = token.LoadECPublicKey(conf.ECPublicKeyPath)
 		if err != nil {
-			log.Fatal("Error loading public key")
+			logger.Fatal("Error loading public key", slog.Any("err", err))
 		}
 
 		tokenMaker = token.NewTokenMakerES256(privateKey, publicKey, conf.TokenIssuer)
 	} else {
-		log.Fatal("Unsupported JWT")
+		logger.Fatal("Unsupported JWT")
 	}
 
 	store := db.NewSQLStore(conf, conn, tokenMaker)
-	srv := server.NewServer(store, conf.ServerAddress, tokenMaker, conf)
+	srv := server.NewServer(store, conn, conf.ServerAddress, tokenMaker, conf)
 
 	if err = srv.Run(); err != nil {
-		log.Fatal("Failed to start server: ", err)
+		logger.Fatal("Failed to start server", slog.Any("err", err))
 	}
 }
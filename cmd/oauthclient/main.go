@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/config"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	oauthService "github.com/hanifsyahsn/go_boilerplate/internal/service/oauthservice"
+)
+
+// Registers an OAuth / OpenID Connect client, e.g.
+// go run ./cmd/oauthclient -name "Wiki" -redirect-uris "https://wiki.internal/callback"
+func main() {
+	name := flag.String("name", "", "human readable client name shown on the consent page")
+	redirectURIs := flag.String("redirect-uris", "", "comma separated list of allowed redirect URIs")
+	public := flag.Bool("public", false, "register a public client (no secret, PKCE only)")
+	flag.Parse()
+
+	if *name == "" || *redirectURIs == "" {
+		flag.Usage()
+		log.Fatal("name and redirect-uris are required")
+	}
+
+	conf, err := config.LoadConfig(".")
+	if err != nil {
+		log.Fatal("Error loading config: ", err)
+	}
+
+	conn, err := sql.Open(conf.DBDriver, conf.DBSource)
+	if err != nil {
+		log.Fatal("Cannot open DB driver:", err)
+	}
+	defer conn.Close()
+
+	// Client registration never signs tokens nor logs users in, so neither a token maker nor an authenticator is needed
+	store := db.NewSQLStore(conf, conn, nil)
+	svc := oauthService.NewService(store, nil, nil, conf)
+
+	request := oauthService.RegisterClientRequest{
+		Name:         *name,
+		RedirectURIs: strings.Split(*redirectURIs, ","),
+		Public:       *public,
+	}
+
+	client, clientSecret, err := svc.RegisterClient(context.Background(), request)
+	if err != nil {
+		log.Fatal("Failed to register client: ", err)
+	}
+
+	fmt.Printf("client_id:     %s\n", client.ClientID)
+	if clientSecret != "" {
+		fmt.Printf("client_secret: %s (store it now, it cannot be shown again)\n", clientSecret)
+	}
+}
@@ -3,28 +3,112 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	ENV                  string        `mapstructure:"ENV"`
-	DBDriver             string        `mapstructure:"DB_DRIVER"`
-	DBSource             string        `mapstructure:"DB_SOURCE"`
-	ServerAddress        string        `mapstructure:"SERVER_ADDRESS"`
-	JWTSecretKey         string        `mapstructure:"JWT_SECRET_KEY"`
-	JWTHS256             bool          `mapstructure:"JWT_HS256"`
-	JWTES256             bool          `mapstructure:"JWT_ES256"`
-	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
-	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
-	ECPrivateKeyPath     string        `mapstructure:"EC_PRIVATE_KEY_PATH"`
-	ECPublicKeyPath      string        `mapstructure:"EC_PUBLIC_KEY_PATH"`
-	GinMode              string        `mapstructure:"GIN_MODE"`
-	TokenIssuer          string        `mapstructure:"TOKEN_ISSUER"`
-	RedisAddress         string        `mapstructure:"REDIS_ADDRESS"`
-	RedisPassword        string        `mapstructure:"REDIS_PASSWORD"`
+	ENV                    string        `mapstructure:"ENV"`
+	DBDriver               string        `mapstructure:"DB_DRIVER"`
+	DBSource               string        `mapstructure:"DB_SOURCE"`
+	ServerAddress          string        `mapstructure:"SERVER_ADDRESS"`
+	JWTSecretKey           string        `mapstructure:"JWT_SECRET_KEY"`
+	JWTHS256               bool          `mapstructure:"JWT_HS256"`
+	JWTES256               bool          `mapstructure:"JWT_ES256"`
+	AccessTokenDuration    time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration   time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	ECPrivateKeyPath       string        `mapstructure:"EC_PRIVATE_KEY_PATH"`
+	ECPublicKeyPath        string        `mapstructure:"EC_PUBLIC_KEY_PATH"`
+	GinMode                string        `mapstructure:"GIN_MODE"`
+	TokenIssuer            string        `mapstructure:"TOKEN_ISSUER"`
+	RedisAddress           string        `mapstructure:"REDIS_ADDRESS"`
+	RedisPassword          string        `mapstructure:"REDIS_PASSWORD"`
+	PublicURL              string        `mapstructure:"PUBLIC_URL"`
+	OAuthCodeDuration      time.Duration `mapstructure:"OAUTH_CODE_DURATION"`
+	IDTokenDuration        time.Duration `mapstructure:"ID_TOKEN_DURATION"`
+	GoogleClientID         string        `mapstructure:"GOOGLE_CLIENT_ID"`
+	GoogleClientSecret     string        `mapstructure:"GOOGLE_CLIENT_SECRET"`
+	GitHubClientID         string        `mapstructure:"GITHUB_CLIENT_ID"`
+	GitHubClientSecret     string        `mapstructure:"GITHUB_CLIENT_SECRET"`
+	MicrosoftClientID      string        `mapstructure:"MICROSOFT_CLIENT_ID"`
+	MicrosoftClientSecret  string        `mapstructure:"MICROSOFT_CLIENT_SECRET"`
+	MicrosoftTenant        string        `mapstructure:"MICROSOFT_TENANT"`
+	SMTPHost               string        `mapstructure:"SMTP_HOST"`
+	SMTPPort               int           `mapstructure:"SMTP_PORT"`
+	SMTPUsername           string        `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword           string        `mapstructure:"SMTP_PASSWORD"`
+	MailFrom               string        `mapstructure:"MAIL_FROM"`
+	MagicLinkURL           string        `mapstructure:"MAGIC_LINK_URL"`
+	MagicLinkDuration      time.Duration `mapstructure:"MAGIC_LINK_DURATION"`
+	WebAuthnRPID           string        `mapstructure:"WEBAUTHN_RP_ID"`
+	WebAuthnRPName         string        `mapstructure:"WEBAUTHN_RP_NAME"`
+	WebAuthnRPOrigins      string        `mapstructure:"WEBAUTHN_RP_ORIGINS"`
+	WebAuthnTimeout        time.Duration `mapstructure:"WEBAUTHN_TIMEOUT"`
+	AuthBackends           string        `mapstructure:"AUTH_BACKENDS"`
+	LDAPURL                string        `mapstructure:"LDAP_URL"`
+	LDAPBindDN             string        `mapstructure:"LDAP_BIND_DN"`
+	LDAPBindPassword       string        `mapstructure:"LDAP_BIND_PASSWORD"`
+	LDAPBaseDN             string        `mapstructure:"LDAP_BASE_DN"`
+	LDAPUserFilter         string        `mapstructure:"LDAP_USER_FILTER"`
+	LDAPEmailAttribute     string        `mapstructure:"LDAP_EMAIL_ATTRIBUTE"`
+	LDAPNameAttribute      string        `mapstructure:"LDAP_NAME_ATTRIBUTE"`
+	LDAPGroupAttribute     string        `mapstructure:"LDAP_GROUP_ATTRIBUTE"`
+	LDAPGroupRoles         string        `mapstructure:"LDAP_GROUP_ROLES"`
+	LDAPDefaultRole        string        `mapstructure:"LDAP_DEFAULT_ROLE"`
+	LDAPStartTLS           bool          `mapstructure:"LDAP_START_TLS"`
+	LDAPTimeout            time.Duration `mapstructure:"LDAP_TIMEOUT"`
+	SCIMAPIToken           string        `mapstructure:"SCIM_API_TOKEN"`
+	OrgInvitationURL       string        `mapstructure:"ORG_INVITATION_URL"`
+	OrgInvitationDuration  time.Duration `mapstructure:"ORG_INVITATION_DURATION"`
+	RegistrationMode       string        `mapstructure:"REGISTRATION_MODE"`
+	UserInvitationURL      string        `mapstructure:"USER_INVITATION_URL"`
+	UserInvitationDuration time.Duration `mapstructure:"USER_INVITATION_DURATION"`
+
+	ImpersonationTokenDuration time.Duration `mapstructure:"IMPERSONATION_TOKEN_DURATION"`
+	ReauthMaxAge               time.Duration `mapstructure:"REAUTH_MAX_AGE"`
+	DPoPProofMaxAge            time.Duration `mapstructure:"DPOP_PROOF_MAX_AGE"`
+	TLSCertFile                string        `mapstructure:"TLS_CERT_FILE"`
+	TLSKeyFile                 string        `mapstructure:"TLS_KEY_FILE"`
+	TLSClientCAFile            string        `mapstructure:"TLS_CLIENT_CA_FILE"`
+	TLSClientAuth              string        `mapstructure:"TLS_CLIENT_AUTH"`
+	TLSReloadInterval          time.Duration `mapstructure:"TLS_RELOAD_INTERVAL"`
+	SCIMClientCertNames        string        `mapstructure:"SCIM_CLIENT_CERT_NAMES"`
+	RateLimitBackend           string        `mapstructure:"RATE_LIMIT_BACKEND"`
+	RateLimitMemoryCapacity    int           `mapstructure:"RATE_LIMIT_MEMORY_CAPACITY"`
+	RateLimitJanitorInterval   time.Duration `mapstructure:"RATE_LIMIT_JANITOR_INTERVAL"`
+	RateLimitPolicies          string        `mapstructure:"RATE_LIMIT_POLICIES"`
+	RateLimitAllowlist         string        `mapstructure:"RATE_LIMIT_ALLOWLIST"`
+	TrustedProxies             string        `mapstructure:"TRUSTED_PROXIES"`
+	RedisMode                  string        `mapstructure:"REDIS_MODE"`
+	RedisUsername              string        `mapstructure:"REDIS_USERNAME"`
+	RedisDB                    int           `mapstructure:"REDIS_DB"`
+	RedisSentinelMaster        string        `mapstructure:"REDIS_SENTINEL_MASTER"`
+	RedisSentinelPassword      string        `mapstructure:"REDIS_SENTINEL_PASSWORD"`
+	RedisTLS                   bool          `mapstructure:"REDIS_TLS"`
+	RedisTLSCAFile             string        `mapstructure:"REDIS_TLS_CA_FILE"`
+	RedisTLSServerName         string        `mapstructure:"REDIS_TLS_SERVER_NAME"`
+	RedisPoolSize              int           `mapstructure:"REDIS_POOL_SIZE"`
+	RedisDialTimeout           time.Duration `mapstructure:"REDIS_DIAL_TIMEOUT"`
+	RedisReadTimeout           time.Duration `mapstructure:"REDIS_READ_TIMEOUT"`
+	RedisWriteTimeout          time.Duration `mapstructure:"REDIS_WRITE_TIMEOUT"`
+	RedisFailureMode           string        `mapstructure:"REDIS_FAILURE_MODE"`
+	RedisFailOpenCacheTTL      time.Duration `mapstructure:"REDIS_FAIL_OPEN_CACHE_TTL"`
+	RedisBreakerFailures       int           `mapstructure:"REDIS_BREAKER_FAILURES"`
+	RedisBreakerCooldown       time.Duration `mapstructure:"REDIS_BREAKER_COOLDOWN"`
+	JTICacheTTL                time.Duration `mapstructure:"JTI_CACHE_TTL"`
+	JTICacheCapacity           int           `mapstructure:"JTI_CACHE_CAPACITY"`
+	LogLevel                   string        `mapstructure:"LOG_LEVEL"`
+	LogFormat                  string        `mapstructure:"LOG_FORMAT"`
+	OTelEndpoint               string        `mapstructure:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	OTelServiceName            string        `mapstructure:"OTEL_SERVICE_NAME"`
+	OTelSampleRatio            float64       `mapstructure:"OTEL_TRACES_SAMPLER_RATIO"`
+	AdminServerAddress         string        `mapstructure:"ADMIN_SERVER_ADDRESS"`
+	HealthCheckTimeout         time.Duration `mapstructure:"HEALTH_CHECK_TIMEOUT"`
+	ShutdownDrainDelay         time.Duration `mapstructure:"SHUTDOWN_DRAIN_DELAY"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -112,5 +196,248 @@ func (c Config) Validate() error {
 		return errors.New("REDIS_PASSWORD is required")
 	}
 
+	if c.PublicURL == "" {
+		return errors.New("PUBLIC_URL is required")
+	}
+	if c.OAuthCodeDuration <= 0 {
+		return fmt.Errorf("OAUTH_CODE_DURATION must be greater than 0, got %v", c.OAuthCodeDuration)
+	}
+	if c.IDTokenDuration <= 0 {
+		return fmt.Errorf("ID_TOKEN_DURATION must be greater than 0, got %v", c.IDTokenDuration)
+	}
+
+	if c.GoogleClientID != "" && c.GoogleClientSecret == "" {
+		return errors.New("GOOGLE_CLIENT_SECRET is required when GOOGLE_CLIENT_ID is set")
+	}
+	if c.GitHubClientID != "" && c.GitHubClientSecret == "" {
+		return errors.New("GITHUB_CLIENT_SECRET is required when GITHUB_CLIENT_ID is set")
+	}
+	if c.MicrosoftClientID != "" && (c.MicrosoftClientSecret == "" || c.MicrosoftTenant == "") {
+		return errors.New("MICROSOFT_CLIENT_SECRET and MICROSOFT_TENANT are required when MICROSOFT_CLIENT_ID is set")
+	}
+
+	if c.SMTPHost != "" && (c.SMTPPort <= 0 || c.MailFrom == "") {
+		return errors.New("SMTP_PORT and MAIL_FROM are required when SMTP_HOST is set")
+	}
+	if c.MagicLinkURL == "" {
+		return errors.New("MAGIC_LINK_URL is required")
+	}
+	if c.MagicLinkDuration <= 0 {
+		return fmt.Errorf("MAGIC_LINK_DURATION must be greater than 0, got %v", c.MagicLinkDuration)
+	}
+
+	if c.WebAuthnRPID == "" || c.WebAuthnRPName == "" || c.WebAuthnRPOrigins == "" {
+		return errors.New("WEBAUTHN_RP_ID, WEBAUTHN_RP_NAME and WEBAUTHN_RP_ORIGINS are required")
+	}
+	if c.WebAuthnTimeout <= 0 {
+		return fmt.Errorf("WEBAUTHN_TIMEOUT must be greater than 0, got %v", c.WebAuthnTimeout)
+	}
+
+	if c.AuthBackends == "" {
+		return errors.New("AUTH_BACKENDS is required")
+	}
+	if strings.Contains(strings.ToLower(c.AuthBackends), "ldap") {
+		if c.LDAPURL == "" || c.LDAPBaseDN == "" {
+			return errors.New("LDAP_URL and LDAP_BASE_DN are required when AUTH_BACKENDS contains ldap")
+		}
+		if !strings.Contains(c.LDAPUserFilter, "{username}") {
+			return errors.New("LDAP_USER_FILTER must contain the {username} placeholder")
+		}
+		if c.LDAPEmailAttribute == "" || c.LDAPDefaultRole == "" {
+			return errors.New("LDAP_EMAIL_ATTRIBUTE and LDAP_DEFAULT_ROLE are required when AUTH_BACKENDS contains ldap")
+		}
+		if c.LDAPTimeout <= 0 {
+			return fmt.Errorf("LDAP_TIMEOUT must be greater than 0, got %v", c.LDAPTimeout)
+		}
+	}
+
+	if c.SCIMAPIToken != "" && len(c.SCIMAPIToken) < 32 {
+		return errors.New("SCIM_API_TOKEN must be at least 32 characters long")
+	}
+
+	if c.OrgInvitationURL == "" {
+		return errors.New("ORG_INVITATION_URL is required")
+	}
+	if c.OrgInvitationDuration <= 0 {
+		return fmt.Errorf("ORG_INVITATION_DURATION must be greater than 0, got %v", c.OrgInvitationDuration)
+	}
+
+	switch c.RegistrationMode {
+	case "open", "invite":
+	default:
+		return fmt.Errorf("invalid REGISTRATION_MODE value '%s' (expected: open or invite)", c.RegistrationMode)
+	}
+	if c.UserInvitationURL == "" {
+		return errors.New("USER_INVITATION_URL is required")
+	}
+	if c.UserInvitationDuration <= 0 {
+		return fmt.Errorf("USER_INVITATION_DURATION must be greater than 0, got %v", c.UserInvitationDuration)
+	}
+
+	if c.ImpersonationTokenDuration <= 0 {
+		return fmt.Errorf("IMPERSONATION_TOKEN_DURATION must be greater than 0, got %v", c.ImpersonationTokenDuration)
+	}
+	if c.ReauthMaxAge <= 0 {
+		return fmt.Errorf("REAUTH_MAX_AGE must be greater than 0, got %v", c.ReauthMaxAge)
+	}
+	if c.DPoPProofMaxAge <= 0 {
+		return fmt.Errorf("DPOP_PROOF_MAX_AGE must be greater than 0, got %v", c.DPoPProofMaxAge)
+	}
+
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	switch c.TLSClientAuth {
+	case "none":
+	case "optional", "require":
+		if c.TLSCertFile == "" || c.TLSClientCAFile == "" {
+			return fmt.Errorf("TLS_CERT_FILE, TLS_KEY_FILE and TLS_CLIENT_CA_FILE are required when TLS_CLIENT_AUTH is '%s'", c.TLSClientAuth)
+		}
+	default:
+		return fmt.Errorf("invalid TLS_CLIENT_AUTH value '%s' (expected: none, optional or require)", c.TLSClientAuth)
+	}
+	if c.TLSCertFile != "" && c.TLSReloadInterval <= 0 {
+		return fmt.Errorf("TLS_RELOAD_INTERVAL must be greater than 0, got %v", c.TLSReloadInterval)
+	}
+	if c.SCIMClientCertNames != "" && c.TLSClientAuth == "none" {
+		return errors.New("TLS_CLIENT_AUTH must be optional or require when SCIM_CLIENT_CERT_NAMES is set")
+	}
+
+	switch c.RateLimitBackend {
+	case "redis", "memory":
+	default:
+		return fmt.Errorf("invalid RATE_LIMIT_BACKEND value '%s' (expected: redis or memory)", c.RateLimitBackend)
+	}
+	if c.RateLimitBackend == "memory" {
+		if c.RateLimitMemoryCapacity <= 0 {
+			return fmt.Errorf("RATE_LIMIT_MEMORY_CAPACITY must be greater than 0, got %v", c.RateLimitMemoryCapacity)
+		}
+		if c.RateLimitJanitorInterval <= 0 {
+			return fmt.Errorf("RATE_LIMIT_JANITOR_INTERVAL must be greater than 0, got %v", c.RateLimitJanitorInterval)
+		}
+	}
+	if c.RateLimitPolicies == "" {
+		return errors.New("RATE_LIMIT_POLICIES is required")
+	}
+
+	switch c.RedisMode {
+	case "standalone":
+		if strings.Contains(c.RedisAddress, ",") {
+			return errors.New("REDIS_ADDRESS must be a single address when REDIS_MODE is standalone")
+		}
+	case "sentinel":
+		if c.RedisSentinelMaster == "" {
+			return errors.New("REDIS_SENTINEL_MASTER is required when REDIS_MODE is sentinel")
+		}
+	case "cluster":
+		if c.RedisDB != 0 {
+			return errors.New("REDIS_DB must be 0 when REDIS_MODE is cluster")
+		}
+	default:
+		return fmt.Errorf("invalid REDIS_MODE value '%s' (expected: standalone, sentinel or cluster)", c.RedisMode)
+	}
+	if c.RedisDB < 0 {
+		return fmt.Errorf("REDIS_DB must not be negative, got %v", c.RedisDB)
+	}
+	if c.RedisPoolSize < 0 {
+		return fmt.Errorf("REDIS_POOL_SIZE must not be negative, got %v", c.RedisPoolSize)
+	}
+	if c.RedisDialTimeout <= 0 || c.RedisReadTimeout <= 0 || c.RedisWriteTimeout <= 0 {
+		return errors.New("REDIS_DIAL_TIMEOUT, REDIS_READ_TIMEOUT and REDIS_WRITE_TIMEOUT must be greater than 0")
+	}
+	if !c.RedisTLS && (c.RedisTLSCAFile != "" || c.RedisTLSServerName != "") {
+		return errors.New("REDIS_TLS must be true when REDIS_TLS_CA_FILE or REDIS_TLS_SERVER_NAME is set")
+	}
+	switch c.RedisFailureMode {
+	case "closed":
+	case "open":
+		if c.RedisFailOpenCacheTTL <= 0 {
+			return fmt.Errorf("REDIS_FAIL_OPEN_CACHE_TTL must be greater than 0 when REDIS_FAILURE_MODE is open, got %v", c.RedisFailOpenCacheTTL)
+		}
+	default:
+		return fmt.Errorf("invalid REDIS_FAILURE_MODE value '%s' (expected: closed or open)", c.RedisFailureMode)
+	}
+	if c.RedisBreakerFailures <= 0 {
+		return fmt.Errorf("REDIS_BREAKER_FAILURES must be greater than 0, got %v", c.RedisBreakerFailures)
+	}
+	if c.RedisBreakerCooldown <= 0 {
+		return fmt.Errorf("REDIS_BREAKER_COOLDOWN must be greater than 0, got %v", c.RedisBreakerCooldown)
+	}
+	if c.JTICacheTTL < 0 {
+		return fmt.Errorf("JTI_CACHE_TTL must not be negative, got %v", c.JTICacheTTL)
+	}
+	if c.JTICacheCapacity <= 0 {
+		return fmt.Errorf("JTI_CACHE_CAPACITY must be greater than 0, got %v", c.JTICacheCapacity)
+	}
+	switch strings.ToLower(c.LogLevel) {
+	case "", "debug", "info", "warn", "error":
+	default:
+		return fmt.Errorf("invalid LOG_LEVEL value '%s' (expected: debug, info, warn or error)", c.LogLevel)
+	}
+	switch c.LogFormat {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("invalid LOG_FORMAT value '%s' (expected: text or json)", c.LogFormat)
+	}
+	if c.TracingEnabled() {
+		endpoint, err := url.Parse(c.OTelEndpoint)
+		if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+			return fmt.Errorf("OTEL_EXPORTER_OTLP_ENDPOINT must be an http(s) URL, got '%s'", c.OTelEndpoint)
+		}
+		if c.OTelServiceName == "" {
+			return errors.New("OTEL_SERVICE_NAME is required when OTEL_EXPORTER_OTLP_ENDPOINT is set")
+		}
+		if c.OTelSampleRatio < 0 || c.OTelSampleRatio > 1 {
+			return fmt.Errorf("OTEL_TRACES_SAMPLER_RATIO must be between 0 and 1, got %v", c.OTelSampleRatio)
+		}
+	}
+	if c.AdminServerEnabled() && c.AdminServerAddress == c.ServerAddress {
+		return errors.New("ADMIN_SERVER_ADDRESS must differ from SERVER_ADDRESS")
+	}
+	if c.HealthCheckTimeout <= 0 {
+		return fmt.Errorf("HEALTH_CHECK_TIMEOUT must be greater than 0, got %v", c.HealthCheckTimeout)
+	}
+	if c.ShutdownDrainDelay < 0 {
+		return fmt.Errorf("SHUTDOWN_DRAIN_DELAY must not be negative, got %v", c.ShutdownDrainDelay)
+	}
+
 	return nil
 }
+
+// TLSEnabled reports whether the server listens with HTTPS instead of plain HTTP
+func (c Config) TLSEnabled() bool {
+	return c.TLSCertFile != ""
+}
+
+// SCIMEnabled reports whether the SCIM API has a way to authenticate its clients: the API token, client
+// certificates or both
+func (c Config) SCIMEnabled() bool {
+	return c.SCIMAPIToken != "" || c.SCIMClientCertNames != ""
+}
+
+// OIDCProviderEnabled reports whether the OAuth / OpenID Connect provider is served. ID tokens are signed by the token
+// maker, so relying parties could only verify HS256 ones with JWT_SECRET_KEY, which also signs every access token.
+func (c Config) OIDCProviderEnabled() bool {
+	return c.JWTES256
+}
+
+// RedisFailOpen reports whether access tokens and DPoP proofs are still accepted while Redis cannot be reached
+func (c Config) RedisFailOpen() bool {
+	return c.RedisFailureMode == "open"
+}
+
+// TracingEnabled reports whether spans are exported, see tracing.Setup
+func (c Config) TracingEnabled() bool {
+	return c.OTelEndpoint != ""
+}
+
+// AdminServerEnabled reports whether the metrics are served on a separate listener, see server.NewServer
+func (c Config) AdminServerEnabled() bool {
+	return c.AdminServerAddress != ""
+}
+
+// SelfRegistrationEnabled reports whether anyone may sign up. In invite mode accounts are only created from
+// invitations, LDAP and SCIM.
+func (c Config) SelfRegistrationEnabled() bool {
+	return c.RegistrationMode != "invite"
+}
@@ -2,14 +2,24 @@ package db
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/device"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/dpop"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/mtls"
 	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
 )
 
-func (store *SQLStore) RegisterTx(ctx context.Context, arg sqlc.CreateUserParams) (user sqlc.User, accessToken, refreshToken string, accessClaims, refreshClaims jwt.MapClaims, err error) {
+// RegisterTxHook runs inside RegisterTx right after the user is created, before the tokens are issued. It returns
+// the user as it should be seen by the caller, e.g. with an updated role.
+type RegisterTxHook func(ctx context.Context, q *sqlc.Queries, user sqlc.User) (sqlc.User, error)
+
+func (store *SQLStore) RegisterTx(ctx context.Context, arg sqlc.CreateUserParams, hooks ...RegisterTxHook) (user sqlc.User, accessToken, refreshToken string, accessClaims, refreshClaims jwt.MapClaims, err error) {
 	err = store.execTx(ctx, func(q *sqlc.Queries) error {
 		var txErr error
 		user, txErr = q.CreateUser(ctx, arg)
@@ -17,16 +27,33 @@ func (store *SQLStore) RegisterTx(ctx context.Context, arg sqlc.CreateUserParams
 			return txErr
 		}
 
-		var refreshTokenExp time.Time
-		accessToken, refreshToken, accessClaims, refreshClaims, txErr = store.tokenMaker.CreateToken(user, store.config.AccessTokenDuration, store.config.RefreshTokenDuration)
+		for _, hook := range hooks {
+			user, txErr = hook(ctx, q, user)
+			if txErr != nil {
+				return txErr
+			}
+		}
+
+		session := mtls.Bind(ctx, dpop.Bind(ctx, token.Session{SessionId: uuid.New().String()}))
+		accessToken, refreshToken, accessClaims, refreshClaims, txErr = store.tokenMaker.CreateToken(ctx, user, session, store.config.AccessTokenDuration, store.config.RefreshTokenDuration)
 		if txErr != nil {
 			return txErr
 		}
 
+		refreshTokenExp, ok := refreshClaims[constant.ExpirationKey].(int64)
+		if !ok {
+			return fmt.Errorf("token expiration is not an integer")
+		}
+
+		client := device.FromContext(ctx)
 		_, txErr = q.CreateRefreshToken(ctx, sqlc.CreateRefreshTokenParams{
 			UserID:       user.ID,
 			RefreshToken: token.HashToken(refreshToken),
-			ExpiredAt:    refreshTokenExp,
+			ExpiredAt:    time.Unix(refreshTokenExp, 0),
+			SessionID:    session.SessionId,
+			DeviceLabel:  client.Label,
+			IpAddress:    client.IPAddress,
+			UserAgent:    client.UserAgent,
 		})
 
 		return txErr
@@ -10,6 +10,8 @@ import (
 	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
 	"github.com/hanifsyahsn/go_boilerplate/internal/util"
 	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/device"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
 	"github.com/stretchr/testify/require"
 )
 
@@ -22,7 +24,9 @@ func TestRegisterTx(t *testing.T) {
 		Password: util.RandomString(10),
 	}
 
-	auth, accessToken, refreshToken, accessClaims, refreshClaims, err := store.RegisterTx(context.Background(), createUserParams)
+	client := device.NewInfo("127.0.0.1", "curl/8.7.1")
+
+	auth, accessToken, refreshToken, accessClaims, refreshClaims, err := store.RegisterTx(device.WithInfo(context.Background(), client), createUserParams)
 	require.NoError(t, err)
 	require.NotEmpty(t, auth)
 	require.NotEmpty(t, accessToken)
@@ -40,6 +44,14 @@ func TestRegisterTx(t *testing.T) {
 
 	require.Equal(t, accessClaims[constant.EmailKey], auth.Email)
 	require.Equal(t, refreshClaims[constant.EmailKey], auth.Email)
+
+	sessions, err := store.ListRefreshTokensByUserId(context.Background(), auth.ID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, token.SessionFromClaims(accessClaims).SessionId, sessions[0].SessionID)
+	require.Equal(t, client.Label, sessions[0].DeviceLabel)
+	require.Equal(t, client.IPAddress, sessions[0].IpAddress)
+	require.WithinDuration(t, time.Now().Add(conf.RefreshTokenDuration), sessions[0].ExpiredAt, time.Minute)
 }
 
 func TestRegisterTx_CreateUserError(t *testing.T) {
@@ -76,12 +88,26 @@ func (f *failingTokenMaker) VerifyToken(tokenString string) (*jwt.Token, jwt.Map
 	return &jwt.Token{}, jwt.MapClaims{}, nil
 }
 
-func (f *failingTokenMaker) RefreshToken(email string, userId int64, accessTokenDuration time.Duration, jti string) (accessToken string, err error) {
+func (f *failingTokenMaker) RefreshToken(ctx context.Context, email string, userId int64, session token.Session, accessTokenDuration time.Duration, jti string) (accessToken string, err error) {
 	return "", nil
 }
 
+func (f *failingTokenMaker) SignClaims(ctx context.Context, claims jwt.MapClaims) (string, error) {
+	return "", errors.New("token signing failed")
+}
+
+func (f *failingTokenMaker) Algorithm() string {
+	return ""
+}
+
+func (f *failingTokenMaker) JWKS() []map[string]interface{} {
+	return nil
+}
+
 func (f *failingTokenMaker) CreateToken(
+	ctx context.Context,
 	user sqlc.User,
+	session token.Session,
 	accessDuration time.Duration,
 	refreshDuration time.Duration,
 ) (string, string, jwt.MapClaims, jwt.MapClaims, error) {
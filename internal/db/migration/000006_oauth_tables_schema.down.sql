@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS oauth_refresh_tokens CASCADE;
+DROP TABLE IF EXISTS oauth_authorization_codes CASCADE;
+DROP TABLE IF EXISTS oauth_clients CASCADE;
\ No newline at end of file
@@ -0,0 +1,55 @@
+CREATE TABLE "oauth_clients" (
+                                "id" bigserial PRIMARY KEY,
+                                "client_id" varchar NOT NULL,
+                                "client_secret" varchar NOT NULL DEFAULT '',
+                                "name" varchar NOT NULL,
+                                "redirect_uris" varchar[] NOT NULL,
+                                "created_at" timestamptz NOT NULL DEFAULT (now()),
+                                "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE oauth_clients
+    ADD CONSTRAINT oauth_clients_client_id_unique UNIQUE (client_id);
+
+CREATE TRIGGER oauth_clients_updated_at
+    BEFORE UPDATE ON oauth_clients
+    FOR EACH ROW
+    EXECUTE PROCEDURE update_updated_at_column();
+
+CREATE TABLE "oauth_authorization_codes" (
+                                            "id" bigserial PRIMARY KEY,
+                                            "code" varchar NOT NULL,
+                                            "client_id" varchar NOT NULL,
+                                            "user_id" bigint NOT NULL,
+                                            "redirect_uri" varchar NOT NULL,
+                                            "scope" varchar NOT NULL,
+                                            "nonce" varchar NOT NULL DEFAULT '',
+                                            "code_challenge" varchar NOT NULL,
+                                            "code_challenge_method" varchar NOT NULL,
+                                            "auth_time" timestamptz NOT NULL,
+                                            "expired_at" timestamptz NOT NULL,
+                                            "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "oauth_authorization_codes" ADD FOREIGN KEY ("client_id") REFERENCES "oauth_clients" ("client_id") ON DELETE CASCADE;
+ALTER TABLE "oauth_authorization_codes" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE oauth_authorization_codes
+    ADD CONSTRAINT oauth_authorization_codes_code_unique UNIQUE (code);
+
+CREATE TABLE "oauth_refresh_tokens" (
+                                       "id" bigserial PRIMARY KEY,
+                                       "client_id" varchar NOT NULL,
+                                       "user_id" bigint NOT NULL,
+                                       "refresh_token" varchar NOT NULL,
+                                       "scope" varchar NOT NULL,
+                                       "auth_time" timestamptz NOT NULL,
+                                       "expired_at" timestamptz NOT NULL,
+                                       "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "oauth_refresh_tokens" ADD FOREIGN KEY ("client_id") REFERENCES "oauth_clients" ("client_id") ON DELETE CASCADE;
+ALTER TABLE "oauth_refresh_tokens" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE oauth_refresh_tokens
+    ADD CONSTRAINT oauth_refresh_tokens_refresh_token_unique UNIQUE (refresh_token);
@@ -0,0 +1,15 @@
+CREATE TABLE "user_identities" (
+                                  "id" bigserial PRIMARY KEY,
+                                  "user_id" bigint NOT NULL,
+                                  "provider" varchar NOT NULL,
+                                  "subject" varchar NOT NULL,
+                                  "email" varchar NOT NULL DEFAULT '',
+                                  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "user_identities" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE user_identities
+    ADD CONSTRAINT user_identities_provider_subject_unique UNIQUE (provider, subject);
+
+CREATE INDEX idx_user_identities_user_id ON user_identities (user_id);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS magic_link_tokens CASCADE;
\ No newline at end of file
@@ -0,0 +1,15 @@
+CREATE TABLE "magic_link_tokens" (
+                                    "id" bigserial PRIMARY KEY,
+                                    "user_id" bigint NOT NULL,
+                                    "token" varchar NOT NULL,
+                                    "nonce" varchar NOT NULL,
+                                    "expired_at" timestamptz NOT NULL,
+                                    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "magic_link_tokens" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE magic_link_tokens
+    ADD CONSTRAINT magic_link_tokens_token_unique UNIQUE (token);
+
+CREATE INDEX idx_magic_link_tokens_user_id ON magic_link_tokens (user_id);
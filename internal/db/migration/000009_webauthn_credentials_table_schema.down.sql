@@ -0,0 +1 @@
+DROP TABLE IF EXISTS webauthn_credentials CASCADE;
\ No newline at end of file
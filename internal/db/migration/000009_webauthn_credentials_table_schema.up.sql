@@ -0,0 +1,26 @@
+CREATE TABLE "webauthn_credentials" (
+                                       "id" bigserial PRIMARY KEY,
+                                       "user_id" bigint NOT NULL,
+                                       "credential_id" bytea NOT NULL,
+                                       "public_key" bytea NOT NULL,
+                                       "attestation_type" varchar NOT NULL DEFAULT '',
+                                       "transports" varchar[] NOT NULL DEFAULT '{}',
+                                       "aaguid" bytea NOT NULL,
+                                       "sign_count" bigint NOT NULL DEFAULT 0,
+                                       "backup_eligible" boolean NOT NULL DEFAULT false,
+                                       "backup_state" boolean NOT NULL DEFAULT false,
+                                       "created_at" timestamptz NOT NULL DEFAULT (now()),
+                                       "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "webauthn_credentials" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE webauthn_credentials
+    ADD CONSTRAINT webauthn_credentials_credential_id_unique UNIQUE (credential_id);
+
+CREATE INDEX idx_webauthn_credentials_user_id ON webauthn_credentials (user_id);
+
+CREATE TRIGGER webauthn_credentials_updated_at
+    BEFORE UPDATE ON webauthn_credentials
+    FOR EACH ROW
+    EXECUTE PROCEDURE update_updated_at_column();
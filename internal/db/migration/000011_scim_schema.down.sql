@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS roles CASCADE;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS "external_id",
+    DROP COLUMN IF EXISTS "active";
@@ -0,0 +1,24 @@
+ALTER TABLE users
+    ADD COLUMN "active" boolean NOT NULL DEFAULT true,
+    ADD COLUMN "external_id" varchar;
+
+CREATE TABLE "roles" (
+                         "id" bigserial PRIMARY KEY,
+                         "name" varchar NOT NULL,
+                         "external_id" varchar,
+                         "created_at" timestamptz NOT NULL DEFAULT (now()),
+                         "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE roles
+    ADD CONSTRAINT roles_name_unique UNIQUE (name);
+
+INSERT INTO roles (name)
+SELECT 'user'
+UNION
+SELECT DISTINCT role FROM users;
+
+CREATE TRIGGER roles_updated_at
+    BEFORE UPDATE ON roles
+    FOR EACH ROW
+    EXECUTE PROCEDURE update_updated_at_column();
@@ -0,0 +1,52 @@
+CREATE TABLE "organizations" (
+                                 "id" bigserial PRIMARY KEY,
+                                 "name" varchar NOT NULL,
+                                 "created_at" timestamptz NOT NULL DEFAULT (now()),
+                                 "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "memberships" (
+                               "id" bigserial PRIMARY KEY,
+                               "organization_id" bigint NOT NULL,
+                               "user_id" bigint NOT NULL,
+                               "role" varchar NOT NULL DEFAULT 'member',
+                               "created_at" timestamptz NOT NULL DEFAULT (now()),
+                               "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "memberships" ADD FOREIGN KEY ("organization_id") REFERENCES "organizations" ("id") ON DELETE CASCADE;
+ALTER TABLE "memberships" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE memberships
+    ADD CONSTRAINT memberships_organization_user_unique UNIQUE (organization_id, user_id);
+
+CREATE INDEX idx_memberships_user_id ON memberships (user_id);
+
+CREATE TABLE "organization_invitations" (
+                                            "id" bigserial PRIMARY KEY,
+                                            "organization_id" bigint NOT NULL,
+                                            "email" varchar NOT NULL,
+                                            "role" varchar NOT NULL,
+                                            "token" varchar NOT NULL,
+                                            "invited_by" bigint NOT NULL,
+                                            "expired_at" timestamptz NOT NULL,
+                                            "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "organization_invitations" ADD FOREIGN KEY ("organization_id") REFERENCES "organizations" ("id") ON DELETE CASCADE;
+ALTER TABLE "organization_invitations" ADD FOREIGN KEY ("invited_by") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE organization_invitations
+    ADD CONSTRAINT organization_invitations_token_unique UNIQUE (token);
+ALTER TABLE organization_invitations
+    ADD CONSTRAINT organization_invitations_organization_email_unique UNIQUE (organization_id, email);
+
+CREATE TRIGGER organizations_updated_at
+    BEFORE UPDATE ON organizations
+    FOR EACH ROW
+    EXECUTE PROCEDURE update_updated_at_column();
+
+CREATE TRIGGER memberships_updated_at
+    BEFORE UPDATE ON memberships
+    FOR EACH ROW
+    EXECUTE PROCEDURE update_updated_at_column();
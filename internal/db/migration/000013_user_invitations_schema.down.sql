@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS user_invitations CASCADE;
+
+DELETE FROM roles
+WHERE name = 'admin' AND NOT EXISTS (SELECT 1 FROM users WHERE role = 'admin');
@@ -0,0 +1,27 @@
+CREATE TABLE "user_invitations" (
+                                    "id" bigserial PRIMARY KEY,
+                                    "email" varchar NOT NULL,
+                                    "role" varchar NOT NULL,
+                                    "token" varchar NOT NULL,
+                                    "invited_by" bigint NOT NULL,
+                                    "expired_at" timestamptz NOT NULL,
+                                    "created_at" timestamptz NOT NULL DEFAULT (now()),
+                                    "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "user_invitations" ADD FOREIGN KEY ("invited_by") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE user_invitations
+    ADD CONSTRAINT user_invitations_token_unique UNIQUE (token);
+ALTER TABLE user_invitations
+    ADD CONSTRAINT user_invitations_email_unique UNIQUE (email);
+
+CREATE TRIGGER user_invitations_updated_at
+    BEFORE UPDATE ON user_invitations
+    FOR EACH ROW
+    EXECUTE PROCEDURE update_updated_at_column();
+
+-- Admins manage invitations
+INSERT INTO roles (name)
+VALUES ('admin')
+ON CONFLICT (name) DO NOTHING;
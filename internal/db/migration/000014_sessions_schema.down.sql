@@ -0,0 +1,16 @@
+DELETE FROM refresh_tokens;
+
+DROP INDEX IF EXISTS idx_refresh_tokens_user_id;
+
+ALTER TABLE refresh_tokens
+    DROP CONSTRAINT IF EXISTS refresh_tokens_session_id_unique;
+
+ALTER TABLE refresh_tokens
+    DROP COLUMN IF EXISTS "session_id",
+    DROP COLUMN IF EXISTS "device_label",
+    DROP COLUMN IF EXISTS "ip_address",
+    DROP COLUMN IF EXISTS "user_agent",
+    DROP COLUMN IF EXISTS "last_used_at";
+
+ALTER TABLE refresh_tokens
+    ADD CONSTRAINT refresh_tokens_user_id_unique UNIQUE (user_id);
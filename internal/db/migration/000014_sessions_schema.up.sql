@@ -0,0 +1,18 @@
+-- Refresh tokens become per-device sessions. Tokens issued before this migration carry no session id, so
+-- their sessions are dropped and users sign in again.
+DELETE FROM refresh_tokens;
+
+ALTER TABLE refresh_tokens
+    DROP CONSTRAINT refresh_tokens_user_id_unique;
+
+ALTER TABLE refresh_tokens
+    ADD COLUMN "session_id" varchar NOT NULL,
+    ADD COLUMN "device_label" varchar NOT NULL DEFAULT '',
+    ADD COLUMN "ip_address" varchar NOT NULL DEFAULT '',
+    ADD COLUMN "user_agent" varchar NOT NULL DEFAULT '',
+    ADD COLUMN "last_used_at" timestamptz NOT NULL DEFAULT (now());
+
+ALTER TABLE refresh_tokens
+    ADD CONSTRAINT refresh_tokens_session_id_unique UNIQUE (session_id);
+
+CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens (user_id);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS audit_logs;
+DROP TABLE IF EXISTS role_permissions;
+
+DELETE FROM roles
+WHERE name = 'support'
+  AND NOT EXISTS (SELECT 1 FROM users WHERE users.role = 'support');
@@ -0,0 +1,34 @@
+CREATE TABLE "role_permissions" (
+                                    "id" bigserial PRIMARY KEY,
+                                    "role_id" bigint NOT NULL,
+                                    "permission" varchar NOT NULL,
+                                    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "role_permissions" ADD FOREIGN KEY ("role_id") REFERENCES "roles" ("id") ON DELETE CASCADE;
+
+ALTER TABLE role_permissions
+    ADD CONSTRAINT role_permissions_role_id_permission_unique UNIQUE (role_id, permission);
+
+-- Audit entries outlive the users they mention, so the user columns are deliberately not foreign keys
+CREATE TABLE "audit_logs" (
+                              "id" bigserial PRIMARY KEY,
+                              "user_id" bigint NOT NULL,
+                              "impersonator_id" bigint,
+                              "action" varchar NOT NULL,
+                              "ip_address" varchar NOT NULL DEFAULT '',
+                              "user_agent" varchar NOT NULL DEFAULT '',
+                              "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX idx_audit_logs_user_id ON audit_logs (user_id);
+CREATE INDEX idx_audit_logs_impersonator_id ON audit_logs (impersonator_id);
+
+-- Support staff and admins may impersonate users
+INSERT INTO roles (name)
+VALUES ('support'), ('admin')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT id, 'users:impersonate' FROM roles WHERE name IN ('support', 'admin')
+ON CONFLICT (role_id, permission) DO NOTHING;
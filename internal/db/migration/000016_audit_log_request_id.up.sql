@@ -0,0 +1,3 @@
+-- Audit entries name the request they were recorded for, so they can be matched with the logs of that request
+ALTER TABLE audit_logs
+    ADD COLUMN "request_id" varchar NOT NULL DEFAULT '';
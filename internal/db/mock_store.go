@@ -8,7 +8,7 @@ import (
 	context "context"
 	reflect "reflect"
 
-	jwt "github.com/golang-jwt/jwt/v5"
+	v5 "github.com/golang-jwt/jwt/v5"
 	gomock "github.com/golang/mock/gomock"
 	sqlc "github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
 )
@@ -36,6 +36,262 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// AcceptOrganizationInvitationTx mocks base method.
+func (m *MockStore) AcceptOrganizationInvitationTx(ctx context.Context, invitation sqlc.OrganizationInvitation, userId int64) (sqlc.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptOrganizationInvitationTx", ctx, invitation, userId)
+	ret0, _ := ret[0].(sqlc.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptOrganizationInvitationTx indicates an expected call of AcceptOrganizationInvitationTx.
+func (mr *MockStoreMockRecorder) AcceptOrganizationInvitationTx(ctx, invitation, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptOrganizationInvitationTx", reflect.TypeOf((*MockStore)(nil).AcceptOrganizationInvitationTx), ctx, invitation, userId)
+}
+
+// ConsumeMagicLinkToken mocks base method.
+func (m *MockStore) ConsumeMagicLinkToken(ctx context.Context, token string) (sqlc.MagicLinkToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeMagicLinkToken", ctx, token)
+	ret0, _ := ret[0].(sqlc.MagicLinkToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeMagicLinkToken indicates an expected call of ConsumeMagicLinkToken.
+func (mr *MockStoreMockRecorder) ConsumeMagicLinkToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeMagicLinkToken", reflect.TypeOf((*MockStore)(nil).ConsumeMagicLinkToken), ctx, token)
+}
+
+// ConsumeOAuthAuthorizationCode mocks base method.
+func (m *MockStore) ConsumeOAuthAuthorizationCode(ctx context.Context, code string) (sqlc.OauthAuthorizationCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeOAuthAuthorizationCode", ctx, code)
+	ret0, _ := ret[0].(sqlc.OauthAuthorizationCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeOAuthAuthorizationCode indicates an expected call of ConsumeOAuthAuthorizationCode.
+func (mr *MockStoreMockRecorder) ConsumeOAuthAuthorizationCode(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeOAuthAuthorizationCode", reflect.TypeOf((*MockStore)(nil).ConsumeOAuthAuthorizationCode), ctx, code)
+}
+
+// ConsumeOAuthRefreshToken mocks base method.
+func (m *MockStore) ConsumeOAuthRefreshToken(ctx context.Context, refreshToken string) (sqlc.OauthRefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeOAuthRefreshToken", ctx, refreshToken)
+	ret0, _ := ret[0].(sqlc.OauthRefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeOAuthRefreshToken indicates an expected call of ConsumeOAuthRefreshToken.
+func (mr *MockStoreMockRecorder) ConsumeOAuthRefreshToken(ctx, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeOAuthRefreshToken", reflect.TypeOf((*MockStore)(nil).ConsumeOAuthRefreshToken), ctx, refreshToken)
+}
+
+// ConsumeUserInvitation mocks base method.
+func (m *MockStore) ConsumeUserInvitation(ctx context.Context, id int64) (sqlc.UserInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeUserInvitation", ctx, id)
+	ret0, _ := ret[0].(sqlc.UserInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeUserInvitation indicates an expected call of ConsumeUserInvitation.
+func (mr *MockStoreMockRecorder) ConsumeUserInvitation(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeUserInvitation", reflect.TypeOf((*MockStore)(nil).ConsumeUserInvitation), ctx, id)
+}
+
+// CountRoles mocks base method.
+func (m *MockStore) CountRoles(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountRoles", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountRoles indicates an expected call of CountRoles.
+func (mr *MockStoreMockRecorder) CountRoles(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountRoles", reflect.TypeOf((*MockStore)(nil).CountRoles), ctx)
+}
+
+// CountUserInvitations mocks base method.
+func (m *MockStore) CountUserInvitations(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUserInvitations", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUserInvitations indicates an expected call of CountUserInvitations.
+func (mr *MockStoreMockRecorder) CountUserInvitations(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUserInvitations", reflect.TypeOf((*MockStore)(nil).CountUserInvitations), ctx)
+}
+
+// CountUsers mocks base method.
+func (m *MockStore) CountUsers(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUsers", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUsers indicates an expected call of CountUsers.
+func (mr *MockStoreMockRecorder) CountUsers(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUsers", reflect.TypeOf((*MockStore)(nil).CountUsers), ctx)
+}
+
+// CreateAuditLog mocks base method.
+func (m *MockStore) CreateAuditLog(ctx context.Context, arg sqlc.CreateAuditLogParams) (sqlc.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditLog", ctx, arg)
+	ret0, _ := ret[0].(sqlc.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAuditLog indicates an expected call of CreateAuditLog.
+func (mr *MockStoreMockRecorder) CreateAuditLog(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditLog", reflect.TypeOf((*MockStore)(nil).CreateAuditLog), ctx, arg)
+}
+
+// CreateMagicLinkToken mocks base method.
+func (m *MockStore) CreateMagicLinkToken(ctx context.Context, arg sqlc.CreateMagicLinkTokenParams) (sqlc.MagicLinkToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMagicLinkToken", ctx, arg)
+	ret0, _ := ret[0].(sqlc.MagicLinkToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMagicLinkToken indicates an expected call of CreateMagicLinkToken.
+func (mr *MockStoreMockRecorder) CreateMagicLinkToken(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMagicLinkToken", reflect.TypeOf((*MockStore)(nil).CreateMagicLinkToken), ctx, arg)
+}
+
+// CreateMembership mocks base method.
+func (m *MockStore) CreateMembership(ctx context.Context, arg sqlc.CreateMembershipParams) (sqlc.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMembership", ctx, arg)
+	ret0, _ := ret[0].(sqlc.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMembership indicates an expected call of CreateMembership.
+func (mr *MockStoreMockRecorder) CreateMembership(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMembership", reflect.TypeOf((*MockStore)(nil).CreateMembership), ctx, arg)
+}
+
+// CreateOAuthAuthorizationCode mocks base method.
+func (m *MockStore) CreateOAuthAuthorizationCode(ctx context.Context, arg sqlc.CreateOAuthAuthorizationCodeParams) (sqlc.OauthAuthorizationCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOAuthAuthorizationCode", ctx, arg)
+	ret0, _ := ret[0].(sqlc.OauthAuthorizationCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOAuthAuthorizationCode indicates an expected call of CreateOAuthAuthorizationCode.
+func (mr *MockStoreMockRecorder) CreateOAuthAuthorizationCode(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOAuthAuthorizationCode", reflect.TypeOf((*MockStore)(nil).CreateOAuthAuthorizationCode), ctx, arg)
+}
+
+// CreateOAuthClient mocks base method.
+func (m *MockStore) CreateOAuthClient(ctx context.Context, arg sqlc.CreateOAuthClientParams) (sqlc.OauthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOAuthClient", ctx, arg)
+	ret0, _ := ret[0].(sqlc.OauthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOAuthClient indicates an expected call of CreateOAuthClient.
+func (mr *MockStoreMockRecorder) CreateOAuthClient(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOAuthClient", reflect.TypeOf((*MockStore)(nil).CreateOAuthClient), ctx, arg)
+}
+
+// CreateOAuthRefreshToken mocks base method.
+func (m *MockStore) CreateOAuthRefreshToken(ctx context.Context, arg sqlc.CreateOAuthRefreshTokenParams) (sqlc.OauthRefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOAuthRefreshToken", ctx, arg)
+	ret0, _ := ret[0].(sqlc.OauthRefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOAuthRefreshToken indicates an expected call of CreateOAuthRefreshToken.
+func (mr *MockStoreMockRecorder) CreateOAuthRefreshToken(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOAuthRefreshToken", reflect.TypeOf((*MockStore)(nil).CreateOAuthRefreshToken), ctx, arg)
+}
+
+// CreateOrganization mocks base method.
+func (m *MockStore) CreateOrganization(ctx context.Context, name string) (sqlc.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrganization", ctx, name)
+	ret0, _ := ret[0].(sqlc.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOrganization indicates an expected call of CreateOrganization.
+func (mr *MockStoreMockRecorder) CreateOrganization(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrganization", reflect.TypeOf((*MockStore)(nil).CreateOrganization), ctx, name)
+}
+
+// CreateOrganizationTx mocks base method.
+func (m *MockStore) CreateOrganizationTx(ctx context.Context, name string, ownerId int64) (sqlc.Organization, sqlc.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrganizationTx", ctx, name, ownerId)
+	ret0, _ := ret[0].(sqlc.Organization)
+	ret1, _ := ret[1].(sqlc.Membership)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateOrganizationTx indicates an expected call of CreateOrganizationTx.
+func (mr *MockStoreMockRecorder) CreateOrganizationTx(ctx, name, ownerId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrganizationTx", reflect.TypeOf((*MockStore)(nil).CreateOrganizationTx), ctx, name, ownerId)
+}
+
+// CreateProvisionedUser mocks base method.
+func (m *MockStore) CreateProvisionedUser(ctx context.Context, arg sqlc.CreateProvisionedUserParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProvisionedUser", ctx, arg)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateProvisionedUser indicates an expected call of CreateProvisionedUser.
+func (mr *MockStoreMockRecorder) CreateProvisionedUser(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProvisionedUser", reflect.TypeOf((*MockStore)(nil).CreateProvisionedUser), ctx, arg)
+}
+
 // CreateRefreshToken mocks base method.
 func (m *MockStore) CreateRefreshToken(ctx context.Context, arg sqlc.CreateRefreshTokenParams) (sqlc.RefreshToken, error) {
 	m.ctrl.T.Helper()
@@ -51,6 +307,51 @@ func (mr *MockStoreMockRecorder) CreateRefreshToken(ctx, arg interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshToken", reflect.TypeOf((*MockStore)(nil).CreateRefreshToken), ctx, arg)
 }
 
+// CreateRole mocks base method.
+func (m *MockStore) CreateRole(ctx context.Context, arg sqlc.CreateRoleParams) (sqlc.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRole", ctx, arg)
+	ret0, _ := ret[0].(sqlc.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRole indicates an expected call of CreateRole.
+func (mr *MockStoreMockRecorder) CreateRole(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRole", reflect.TypeOf((*MockStore)(nil).CreateRole), ctx, arg)
+}
+
+// CreateRolePermission mocks base method.
+func (m *MockStore) CreateRolePermission(ctx context.Context, arg sqlc.CreateRolePermissionParams) (sqlc.RolePermission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRolePermission", ctx, arg)
+	ret0, _ := ret[0].(sqlc.RolePermission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRolePermission indicates an expected call of CreateRolePermission.
+func (mr *MockStoreMockRecorder) CreateRolePermission(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRolePermission", reflect.TypeOf((*MockStore)(nil).CreateRolePermission), ctx, arg)
+}
+
+// CreateRoleTx mocks base method.
+func (m *MockStore) CreateRoleTx(ctx context.Context, arg sqlc.CreateRoleParams, members []int64) (sqlc.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRoleTx", ctx, arg, members)
+	ret0, _ := ret[0].(sqlc.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRoleTx indicates an expected call of CreateRoleTx.
+func (mr *MockStoreMockRecorder) CreateRoleTx(ctx, arg, members interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRoleTx", reflect.TypeOf((*MockStore)(nil).CreateRoleTx), ctx, arg, members)
+}
+
 // CreateUser mocks base method.
 func (m *MockStore) CreateUser(ctx context.Context, arg sqlc.CreateUserParams) (sqlc.User, error) {
 	m.ctrl.T.Helper()
@@ -66,6 +367,94 @@ func (mr *MockStoreMockRecorder) CreateUser(ctx, arg interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), ctx, arg)
 }
 
+// CreateUserIdentity mocks base method.
+func (m *MockStore) CreateUserIdentity(ctx context.Context, arg sqlc.CreateUserIdentityParams) (sqlc.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserIdentity", ctx, arg)
+	ret0, _ := ret[0].(sqlc.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUserIdentity indicates an expected call of CreateUserIdentity.
+func (mr *MockStoreMockRecorder) CreateUserIdentity(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserIdentity", reflect.TypeOf((*MockStore)(nil).CreateUserIdentity), ctx, arg)
+}
+
+// CreateUserInvitation mocks base method.
+func (m *MockStore) CreateUserInvitation(ctx context.Context, arg sqlc.CreateUserInvitationParams) (sqlc.UserInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserInvitation", ctx, arg)
+	ret0, _ := ret[0].(sqlc.UserInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUserInvitation indicates an expected call of CreateUserInvitation.
+func (mr *MockStoreMockRecorder) CreateUserInvitation(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserInvitation", reflect.TypeOf((*MockStore)(nil).CreateUserInvitation), ctx, arg)
+}
+
+// CreateUserWithRole mocks base method.
+func (m *MockStore) CreateUserWithRole(ctx context.Context, arg sqlc.CreateUserWithRoleParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserWithRole", ctx, arg)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUserWithRole indicates an expected call of CreateUserWithRole.
+func (mr *MockStoreMockRecorder) CreateUserWithRole(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserWithRole", reflect.TypeOf((*MockStore)(nil).CreateUserWithRole), ctx, arg)
+}
+
+// CreateWebauthnCredential mocks base method.
+func (m *MockStore) CreateWebauthnCredential(ctx context.Context, arg sqlc.CreateWebauthnCredentialParams) (sqlc.WebauthnCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebauthnCredential", ctx, arg)
+	ret0, _ := ret[0].(sqlc.WebauthnCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebauthnCredential indicates an expected call of CreateWebauthnCredential.
+func (mr *MockStoreMockRecorder) CreateWebauthnCredential(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebauthnCredential", reflect.TypeOf((*MockStore)(nil).CreateWebauthnCredential), ctx, arg)
+}
+
+// DeleteOAuthRefreshTokensByUserId mocks base method.
+func (m *MockStore) DeleteOAuthRefreshTokensByUserId(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOAuthRefreshTokensByUserId", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOAuthRefreshTokensByUserId indicates an expected call of DeleteOAuthRefreshTokensByUserId.
+func (mr *MockStoreMockRecorder) DeleteOAuthRefreshTokensByUserId(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOAuthRefreshTokensByUserId", reflect.TypeOf((*MockStore)(nil).DeleteOAuthRefreshTokensByUserId), ctx, userID)
+}
+
+// DeleteOrganizationInvitation mocks base method.
+func (m *MockStore) DeleteOrganizationInvitation(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOrganizationInvitation", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOrganizationInvitation indicates an expected call of DeleteOrganizationInvitation.
+func (mr *MockStoreMockRecorder) DeleteOrganizationInvitation(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrganizationInvitation", reflect.TypeOf((*MockStore)(nil).DeleteOrganizationInvitation), ctx, id)
+}
+
 // DeleteRefreshToken mocks base method.
 func (m *MockStore) DeleteRefreshToken(ctx context.Context, refreshToken string) error {
 	m.ctrl.T.Helper()
@@ -80,6 +469,151 @@ func (mr *MockStoreMockRecorder) DeleteRefreshToken(ctx, refreshToken interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRefreshToken", reflect.TypeOf((*MockStore)(nil).DeleteRefreshToken), ctx, refreshToken)
 }
 
+// DeleteRefreshTokenBySessionId mocks base method.
+func (m *MockStore) DeleteRefreshTokenBySessionId(ctx context.Context, arg sqlc.DeleteRefreshTokenBySessionIdParams) (sqlc.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRefreshTokenBySessionId", ctx, arg)
+	ret0, _ := ret[0].(sqlc.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteRefreshTokenBySessionId indicates an expected call of DeleteRefreshTokenBySessionId.
+func (mr *MockStoreMockRecorder) DeleteRefreshTokenBySessionId(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRefreshTokenBySessionId", reflect.TypeOf((*MockStore)(nil).DeleteRefreshTokenBySessionId), ctx, arg)
+}
+
+// DeleteRefreshTokensByUserId mocks base method.
+func (m *MockStore) DeleteRefreshTokensByUserId(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRefreshTokensByUserId", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRefreshTokensByUserId indicates an expected call of DeleteRefreshTokensByUserId.
+func (mr *MockStoreMockRecorder) DeleteRefreshTokensByUserId(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRefreshTokensByUserId", reflect.TypeOf((*MockStore)(nil).DeleteRefreshTokensByUserId), ctx, userID)
+}
+
+// DeleteRole mocks base method.
+func (m *MockStore) DeleteRole(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRole", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRole indicates an expected call of DeleteRole.
+func (mr *MockStoreMockRecorder) DeleteRole(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRole", reflect.TypeOf((*MockStore)(nil).DeleteRole), ctx, id)
+}
+
+// DeleteRoleTx mocks base method.
+func (m *MockStore) DeleteRoleTx(ctx context.Context, id int64, defaultRole string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRoleTx", ctx, id, defaultRole)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRoleTx indicates an expected call of DeleteRoleTx.
+func (mr *MockStoreMockRecorder) DeleteRoleTx(ctx, id, defaultRole interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRoleTx", reflect.TypeOf((*MockStore)(nil).DeleteRoleTx), ctx, id, defaultRole)
+}
+
+// DeleteUser mocks base method.
+func (m *MockStore) DeleteUser(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockStoreMockRecorder) DeleteUser(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockStore)(nil).DeleteUser), ctx, id)
+}
+
+// DeleteUserInvitation mocks base method.
+func (m *MockStore) DeleteUserInvitation(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserInvitation", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserInvitation indicates an expected call of DeleteUserInvitation.
+func (mr *MockStoreMockRecorder) DeleteUserInvitation(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserInvitation", reflect.TypeOf((*MockStore)(nil).DeleteUserInvitation), ctx, id)
+}
+
+// GetMembership mocks base method.
+func (m *MockStore) GetMembership(ctx context.Context, arg sqlc.GetMembershipParams) (sqlc.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMembership", ctx, arg)
+	ret0, _ := ret[0].(sqlc.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMembership indicates an expected call of GetMembership.
+func (mr *MockStoreMockRecorder) GetMembership(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMembership", reflect.TypeOf((*MockStore)(nil).GetMembership), ctx, arg)
+}
+
+// GetOAuthClient mocks base method.
+func (m *MockStore) GetOAuthClient(ctx context.Context, clientID string) (sqlc.OauthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuthClient", ctx, clientID)
+	ret0, _ := ret[0].(sqlc.OauthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuthClient indicates an expected call of GetOAuthClient.
+func (mr *MockStoreMockRecorder) GetOAuthClient(ctx, clientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuthClient", reflect.TypeOf((*MockStore)(nil).GetOAuthClient), ctx, clientID)
+}
+
+// GetOrganization mocks base method.
+func (m *MockStore) GetOrganization(ctx context.Context, id int64) (sqlc.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganization", ctx, id)
+	ret0, _ := ret[0].(sqlc.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganization indicates an expected call of GetOrganization.
+func (mr *MockStoreMockRecorder) GetOrganization(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganization", reflect.TypeOf((*MockStore)(nil).GetOrganization), ctx, id)
+}
+
+// GetOrganizationInvitationByToken mocks base method.
+func (m *MockStore) GetOrganizationInvitationByToken(ctx context.Context, token string) (sqlc.OrganizationInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganizationInvitationByToken", ctx, token)
+	ret0, _ := ret[0].(sqlc.OrganizationInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganizationInvitationByToken indicates an expected call of GetOrganizationInvitationByToken.
+func (mr *MockStoreMockRecorder) GetOrganizationInvitationByToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationInvitationByToken", reflect.TypeOf((*MockStore)(nil).GetOrganizationInvitationByToken), ctx, token)
+}
+
 // GetRefreshTokenByUserId mocks base method.
 func (m *MockStore) GetRefreshTokenByUserId(ctx context.Context, arg sqlc.GetRefreshTokenByUserIdParams) (sqlc.RefreshToken, error) {
 	m.ctrl.T.Helper()
@@ -95,6 +629,36 @@ func (mr *MockStoreMockRecorder) GetRefreshTokenByUserId(ctx, arg interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshTokenByUserId", reflect.TypeOf((*MockStore)(nil).GetRefreshTokenByUserId), ctx, arg)
 }
 
+// GetRole mocks base method.
+func (m *MockStore) GetRole(ctx context.Context, id int64) (sqlc.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRole", ctx, id)
+	ret0, _ := ret[0].(sqlc.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRole indicates an expected call of GetRole.
+func (mr *MockStoreMockRecorder) GetRole(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRole", reflect.TypeOf((*MockStore)(nil).GetRole), ctx, id)
+}
+
+// GetRoleByName mocks base method.
+func (m *MockStore) GetRoleByName(ctx context.Context, name string) (sqlc.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoleByName", ctx, name)
+	ret0, _ := ret[0].(sqlc.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoleByName indicates an expected call of GetRoleByName.
+func (mr *MockStoreMockRecorder) GetRoleByName(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoleByName", reflect.TypeOf((*MockStore)(nil).GetRoleByName), ctx, name)
+}
+
 // GetUser mocks base method.
 func (m *MockStore) GetUser(ctx context.Context, email string) (sqlc.User, error) {
 	m.ctrl.T.Helper()
@@ -110,36 +674,411 @@ func (mr *MockStoreMockRecorder) GetUser(ctx, email interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStore)(nil).GetUser), ctx, email)
 }
 
+// GetUserByID mocks base method.
+func (m *MockStore) GetUserByID(ctx context.Context, id int64) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", ctx, id)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockStoreMockRecorder) GetUserByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockStore)(nil).GetUserByID), ctx, id)
+}
+
+// GetUserIdentity mocks base method.
+func (m *MockStore) GetUserIdentity(ctx context.Context, arg sqlc.GetUserIdentityParams) (sqlc.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserIdentity", ctx, arg)
+	ret0, _ := ret[0].(sqlc.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserIdentity indicates an expected call of GetUserIdentity.
+func (mr *MockStoreMockRecorder) GetUserIdentity(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserIdentity", reflect.TypeOf((*MockStore)(nil).GetUserIdentity), ctx, arg)
+}
+
+// GetUserInvitation mocks base method.
+func (m *MockStore) GetUserInvitation(ctx context.Context, id int64) (sqlc.UserInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserInvitation", ctx, id)
+	ret0, _ := ret[0].(sqlc.UserInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserInvitation indicates an expected call of GetUserInvitation.
+func (mr *MockStoreMockRecorder) GetUserInvitation(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserInvitation", reflect.TypeOf((*MockStore)(nil).GetUserInvitation), ctx, id)
+}
+
+// GetUserInvitationByToken mocks base method.
+func (m *MockStore) GetUserInvitationByToken(ctx context.Context, token string) (sqlc.UserInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserInvitationByToken", ctx, token)
+	ret0, _ := ret[0].(sqlc.UserInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserInvitationByToken indicates an expected call of GetUserInvitationByToken.
+func (mr *MockStoreMockRecorder) GetUserInvitationByToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserInvitationByToken", reflect.TypeOf((*MockStore)(nil).GetUserInvitationByToken), ctx, token)
+}
+
+// HasRolePermission mocks base method.
+func (m *MockStore) HasRolePermission(ctx context.Context, arg sqlc.HasRolePermissionParams) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasRolePermission", ctx, arg)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasRolePermission indicates an expected call of HasRolePermission.
+func (mr *MockStoreMockRecorder) HasRolePermission(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasRolePermission", reflect.TypeOf((*MockStore)(nil).HasRolePermission), ctx, arg)
+}
+
+// ListAuditLogsByUserId mocks base method.
+func (m *MockStore) ListAuditLogsByUserId(ctx context.Context, arg sqlc.ListAuditLogsByUserIdParams) ([]sqlc.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditLogsByUserId", ctx, arg)
+	ret0, _ := ret[0].([]sqlc.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditLogsByUserId indicates an expected call of ListAuditLogsByUserId.
+func (mr *MockStoreMockRecorder) ListAuditLogsByUserId(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditLogsByUserId", reflect.TypeOf((*MockStore)(nil).ListAuditLogsByUserId), ctx, arg)
+}
+
+// ListMembersByOrganizationId mocks base method.
+func (m *MockStore) ListMembersByOrganizationId(ctx context.Context, organizationID int64) ([]sqlc.ListMembersByOrganizationIdRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMembersByOrganizationId", ctx, organizationID)
+	ret0, _ := ret[0].([]sqlc.ListMembersByOrganizationIdRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMembersByOrganizationId indicates an expected call of ListMembersByOrganizationId.
+func (mr *MockStoreMockRecorder) ListMembersByOrganizationId(ctx, organizationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMembersByOrganizationId", reflect.TypeOf((*MockStore)(nil).ListMembersByOrganizationId), ctx, organizationID)
+}
+
+// ListMembershipsByUserId mocks base method.
+func (m *MockStore) ListMembershipsByUserId(ctx context.Context, userID int64) ([]sqlc.ListMembershipsByUserIdRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMembershipsByUserId", ctx, userID)
+	ret0, _ := ret[0].([]sqlc.ListMembershipsByUserIdRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMembershipsByUserId indicates an expected call of ListMembershipsByUserId.
+func (mr *MockStoreMockRecorder) ListMembershipsByUserId(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMembershipsByUserId", reflect.TypeOf((*MockStore)(nil).ListMembershipsByUserId), ctx, userID)
+}
+
+// ListRefreshTokensByUserId mocks base method.
+func (m *MockStore) ListRefreshTokensByUserId(ctx context.Context, userID int64) ([]sqlc.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRefreshTokensByUserId", ctx, userID)
+	ret0, _ := ret[0].([]sqlc.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRefreshTokensByUserId indicates an expected call of ListRefreshTokensByUserId.
+func (mr *MockStoreMockRecorder) ListRefreshTokensByUserId(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRefreshTokensByUserId", reflect.TypeOf((*MockStore)(nil).ListRefreshTokensByUserId), ctx, userID)
+}
+
+// ListRoles mocks base method.
+func (m *MockStore) ListRoles(ctx context.Context, arg sqlc.ListRolesParams) ([]sqlc.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRoles", ctx, arg)
+	ret0, _ := ret[0].([]sqlc.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRoles indicates an expected call of ListRoles.
+func (mr *MockStoreMockRecorder) ListRoles(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRoles", reflect.TypeOf((*MockStore)(nil).ListRoles), ctx, arg)
+}
+
+// ListUserInvitations mocks base method.
+func (m *MockStore) ListUserInvitations(ctx context.Context, arg sqlc.ListUserInvitationsParams) ([]sqlc.UserInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserInvitations", ctx, arg)
+	ret0, _ := ret[0].([]sqlc.UserInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserInvitations indicates an expected call of ListUserInvitations.
+func (mr *MockStoreMockRecorder) ListUserInvitations(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserInvitations", reflect.TypeOf((*MockStore)(nil).ListUserInvitations), ctx, arg)
+}
+
+// ListUsers mocks base method.
+func (m *MockStore) ListUsers(ctx context.Context, arg sqlc.ListUsersParams) ([]sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", ctx, arg)
+	ret0, _ := ret[0].([]sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockStoreMockRecorder) ListUsers(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockStore)(nil).ListUsers), ctx, arg)
+}
+
+// ListUsersByRole mocks base method.
+func (m *MockStore) ListUsersByRole(ctx context.Context, role string) ([]sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsersByRole", ctx, role)
+	ret0, _ := ret[0].([]sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsersByRole indicates an expected call of ListUsersByRole.
+func (mr *MockStoreMockRecorder) ListUsersByRole(ctx, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsersByRole", reflect.TypeOf((*MockStore)(nil).ListUsersByRole), ctx, role)
+}
+
+// ListWebauthnCredentialsByUserId mocks base method.
+func (m *MockStore) ListWebauthnCredentialsByUserId(ctx context.Context, userID int64) ([]sqlc.WebauthnCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebauthnCredentialsByUserId", ctx, userID)
+	ret0, _ := ret[0].([]sqlc.WebauthnCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebauthnCredentialsByUserId indicates an expected call of ListWebauthnCredentialsByUserId.
+func (mr *MockStoreMockRecorder) ListWebauthnCredentialsByUserId(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebauthnCredentialsByUserId", reflect.TypeOf((*MockStore)(nil).ListWebauthnCredentialsByUserId), ctx, userID)
+}
+
 // RegisterTx mocks base method.
-func (m *MockStore) RegisterTx(ctx context.Context, arg sqlc.CreateUserParams) (sqlc.User, string, string, jwt.MapClaims, jwt.MapClaims, error) {
+func (m *MockStore) RegisterTx(ctx context.Context, arg sqlc.CreateUserParams, hooks ...RegisterTxHook) (sqlc.User, string, string, v5.MapClaims, v5.MapClaims, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RegisterTx", ctx, arg)
+	varargs := []interface{}{ctx, arg}
+	for _, a := range hooks {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RegisterTx", varargs...)
 	ret0, _ := ret[0].(sqlc.User)
 	ret1, _ := ret[1].(string)
 	ret2, _ := ret[2].(string)
-	ret3, _ := ret[3].(jwt.MapClaims)
-	ret4, _ := ret[4].(jwt.MapClaims)
+	ret3, _ := ret[3].(v5.MapClaims)
+	ret4, _ := ret[4].(v5.MapClaims)
 	ret5, _ := ret[5].(error)
 	return ret0, ret1, ret2, ret3, ret4, ret5
 }
 
 // RegisterTx indicates an expected call of RegisterTx.
-func (mr *MockStoreMockRecorder) RegisterTx(ctx, arg interface{}) *gomock.Call {
+func (mr *MockStoreMockRecorder) RegisterTx(ctx, arg interface{}, hooks ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterTx", reflect.TypeOf((*MockStore)(nil).RegisterTx), ctx, arg)
+	varargs := append([]interface{}{ctx, arg}, hooks...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterTx", reflect.TypeOf((*MockStore)(nil).RegisterTx), varargs...)
 }
 
-// UpsertRefreshToken mocks base method.
-func (m *MockStore) UpsertRefreshToken(ctx context.Context, arg sqlc.UpsertRefreshTokenParams) (sqlc.RefreshToken, error) {
+// RenameUsersRole mocks base method.
+func (m *MockStore) RenameUsersRole(ctx context.Context, arg sqlc.RenameUsersRoleParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpsertRefreshToken", ctx, arg)
-	ret0, _ := ret[0].(sqlc.RefreshToken)
+	ret := m.ctrl.Call(m, "RenameUsersRole", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameUsersRole indicates an expected call of RenameUsersRole.
+func (mr *MockStoreMockRecorder) RenameUsersRole(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameUsersRole", reflect.TypeOf((*MockStore)(nil).RenameUsersRole), ctx, arg)
+}
+
+// ResetUserRole mocks base method.
+func (m *MockStore) ResetUserRole(ctx context.Context, arg sqlc.ResetUserRoleParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetUserRole", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetUserRole indicates an expected call of ResetUserRole.
+func (mr *MockStoreMockRecorder) ResetUserRole(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetUserRole", reflect.TypeOf((*MockStore)(nil).ResetUserRole), ctx, arg)
+}
+
+// SocialRegisterTx mocks base method.
+func (m *MockStore) SocialRegisterTx(ctx context.Context, arg sqlc.CreateUserParams, identity sqlc.CreateUserIdentityParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SocialRegisterTx", ctx, arg, identity)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SocialRegisterTx indicates an expected call of SocialRegisterTx.
+func (mr *MockStoreMockRecorder) SocialRegisterTx(ctx, arg, identity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SocialRegisterTx", reflect.TypeOf((*MockStore)(nil).SocialRegisterTx), ctx, arg, identity)
+}
+
+// TouchRefreshToken mocks base method.
+func (m *MockStore) TouchRefreshToken(ctx context.Context, arg sqlc.TouchRefreshTokenParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchRefreshToken", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchRefreshToken indicates an expected call of TouchRefreshToken.
+func (mr *MockStoreMockRecorder) TouchRefreshToken(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchRefreshToken", reflect.TypeOf((*MockStore)(nil).TouchRefreshToken), ctx, arg)
+}
+
+// UpdateProvisionedUser mocks base method.
+func (m *MockStore) UpdateProvisionedUser(ctx context.Context, arg sqlc.UpdateProvisionedUserParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProvisionedUser", ctx, arg)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProvisionedUser indicates an expected call of UpdateProvisionedUser.
+func (mr *MockStoreMockRecorder) UpdateProvisionedUser(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProvisionedUser", reflect.TypeOf((*MockStore)(nil).UpdateProvisionedUser), ctx, arg)
+}
+
+// UpdateRole mocks base method.
+func (m *MockStore) UpdateRole(ctx context.Context, arg sqlc.UpdateRoleParams) (sqlc.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRole", ctx, arg)
+	ret0, _ := ret[0].(sqlc.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRole indicates an expected call of UpdateRole.
+func (mr *MockStoreMockRecorder) UpdateRole(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRole", reflect.TypeOf((*MockStore)(nil).UpdateRole), ctx, arg)
+}
+
+// UpdateRoleTx mocks base method.
+func (m *MockStore) UpdateRoleTx(ctx context.Context, arg UpdateRoleTxParams) (sqlc.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRoleTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRoleTx indicates an expected call of UpdateRoleTx.
+func (mr *MockStoreMockRecorder) UpdateRoleTx(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRoleTx", reflect.TypeOf((*MockStore)(nil).UpdateRoleTx), ctx, arg)
+}
+
+// UpdateUserInvitationToken mocks base method.
+func (m *MockStore) UpdateUserInvitationToken(ctx context.Context, arg sqlc.UpdateUserInvitationTokenParams) (sqlc.UserInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserInvitationToken", ctx, arg)
+	ret0, _ := ret[0].(sqlc.UserInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserInvitationToken indicates an expected call of UpdateUserInvitationToken.
+func (mr *MockStoreMockRecorder) UpdateUserInvitationToken(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserInvitationToken", reflect.TypeOf((*MockStore)(nil).UpdateUserInvitationToken), ctx, arg)
+}
+
+// UpdateUserNameAndRole mocks base method.
+func (m *MockStore) UpdateUserNameAndRole(ctx context.Context, arg sqlc.UpdateUserNameAndRoleParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserNameAndRole", ctx, arg)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserNameAndRole indicates an expected call of UpdateUserNameAndRole.
+func (mr *MockStoreMockRecorder) UpdateUserNameAndRole(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserNameAndRole", reflect.TypeOf((*MockStore)(nil).UpdateUserNameAndRole), ctx, arg)
+}
+
+// UpdateUserRole mocks base method.
+func (m *MockStore) UpdateUserRole(ctx context.Context, arg sqlc.UpdateUserRoleParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserRole", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserRole indicates an expected call of UpdateUserRole.
+func (mr *MockStoreMockRecorder) UpdateUserRole(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserRole", reflect.TypeOf((*MockStore)(nil).UpdateUserRole), ctx, arg)
+}
+
+// UpdateWebauthnCredentialSignCount mocks base method.
+func (m *MockStore) UpdateWebauthnCredentialSignCount(ctx context.Context, arg sqlc.UpdateWebauthnCredentialSignCountParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebauthnCredentialSignCount", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebauthnCredentialSignCount indicates an expected call of UpdateWebauthnCredentialSignCount.
+func (mr *MockStoreMockRecorder) UpdateWebauthnCredentialSignCount(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebauthnCredentialSignCount", reflect.TypeOf((*MockStore)(nil).UpdateWebauthnCredentialSignCount), ctx, arg)
+}
+
+// UpsertOrganizationInvitation mocks base method.
+func (m *MockStore) UpsertOrganizationInvitation(ctx context.Context, arg sqlc.UpsertOrganizationInvitationParams) (sqlc.OrganizationInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertOrganizationInvitation", ctx, arg)
+	ret0, _ := ret[0].(sqlc.OrganizationInvitation)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpsertRefreshToken indicates an expected call of UpsertRefreshToken.
-func (mr *MockStoreMockRecorder) UpsertRefreshToken(ctx, arg interface{}) *gomock.Call {
+// UpsertOrganizationInvitation indicates an expected call of UpsertOrganizationInvitation.
+func (mr *MockStoreMockRecorder) UpsertOrganizationInvitation(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertRefreshToken", reflect.TypeOf((*MockStore)(nil).UpsertRefreshToken), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertOrganizationInvitation", reflect.TypeOf((*MockStore)(nil).UpsertOrganizationInvitation), ctx, arg)
 }
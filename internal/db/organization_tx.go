@@ -0,0 +1,47 @@
+package db
+
+import (
+	"context"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+)
+
+// CreateOrganizationTx creates an organization with ownerId as its first member and owner
+func (store *SQLStore) CreateOrganizationTx(ctx context.Context, name string, ownerId int64) (organization sqlc.Organization, membership sqlc.Membership, err error) {
+	err = store.execTx(ctx, func(q *sqlc.Queries) error {
+		var txErr error
+		organization, txErr = q.CreateOrganization(ctx, name)
+		if txErr != nil {
+			return txErr
+		}
+
+		membership, txErr = q.CreateMembership(ctx, sqlc.CreateMembershipParams{
+			OrganizationID: organization.ID,
+			UserID:         ownerId,
+			Role:           constant.OrgRoleOwner,
+		})
+		return txErr
+	})
+
+	return
+}
+
+// AcceptOrganizationInvitationTx turns an invitation into a membership for userId and consumes the invitation
+func (store *SQLStore) AcceptOrganizationInvitationTx(ctx context.Context, invitation sqlc.OrganizationInvitation, userId int64) (membership sqlc.Membership, err error) {
+	err = store.execTx(ctx, func(q *sqlc.Queries) error {
+		var txErr error
+		membership, txErr = q.CreateMembership(ctx, sqlc.CreateMembershipParams{
+			OrganizationID: invitation.OrganizationID,
+			UserID:         userId,
+			Role:           invitation.Role,
+		})
+		if txErr != nil {
+			return txErr
+		}
+
+		return q.DeleteOrganizationInvitation(ctx, invitation.ID)
+	})
+
+	return
+}
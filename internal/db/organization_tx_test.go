@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrganizationTx(t *testing.T) {
+	store := NewSQLStore(conf, testDB, tokenMaker)
+	owner := createTxUser(t, store)
+	invitee := createTxUser(t, store)
+
+	organization, membership, err := store.CreateOrganizationTx(context.Background(), util.RandomString(10), owner.ID)
+	require.NoError(t, err)
+	require.Equal(t, organization.ID, membership.OrganizationID)
+	require.Equal(t, constant.OrgRoleOwner, membership.Role)
+
+	invitation, err := store.UpsertOrganizationInvitation(context.Background(), sqlc.UpsertOrganizationInvitationParams{
+		OrganizationID: organization.ID,
+		Email:          invitee.Email,
+		Role:           constant.OrgRoleMember,
+		Token:          util.RandomString(32),
+		InvitedBy:      owner.ID,
+		ExpiredAt:      time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	membership, err = store.AcceptOrganizationInvitationTx(context.Background(), invitation, invitee.ID)
+	require.NoError(t, err)
+	require.Equal(t, constant.OrgRoleMember, membership.Role)
+
+	_, err = store.GetOrganizationInvitationByToken(context.Background(), invitation.Token)
+	require.Error(t, err)
+
+	members, err := store.ListMembersByOrganizationId(context.Background(), organization.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+}
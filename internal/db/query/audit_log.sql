@@ -0,0 +1,13 @@
+-- name: CreateAuditLog :one
+INSERT INTO audit_logs (
+    user_id, impersonator_id, action, ip_address, user_agent, request_id
+) VALUES (
+             $1, $2, $3, $4, $5, $6
+         ) RETURNING *;
+
+-- name: ListAuditLogsByUserId :many
+SELECT * FROM audit_logs
+WHERE user_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
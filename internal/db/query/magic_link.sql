@@ -0,0 +1,11 @@
+-- name: CreateMagicLinkToken :one
+INSERT INTO magic_link_tokens (
+    user_id, token, nonce, expired_at
+) VALUES (
+             $1, $2, $3, $4
+         ) RETURNING *;
+
+-- name: ConsumeMagicLinkToken :one
+DELETE FROM magic_link_tokens
+WHERE token = $1
+RETURNING *;
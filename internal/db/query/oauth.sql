@@ -0,0 +1,39 @@
+-- name: CreateOAuthClient :one
+INSERT INTO oauth_clients (
+    client_id, client_secret, name, redirect_uris
+) VALUES (
+             $1, $2, $3, $4
+         ) RETURNING *;
+
+-- name: GetOAuthClient :one
+SELECT * FROM oauth_clients
+WHERE client_id = $1
+LIMIT 1;
+
+-- name: CreateOAuthAuthorizationCode :one
+INSERT INTO oauth_authorization_codes (
+    code, client_id, user_id, redirect_uri, scope, nonce, code_challenge, code_challenge_method, auth_time, expired_at
+) VALUES (
+             $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+         ) RETURNING *;
+
+-- name: ConsumeOAuthAuthorizationCode :one
+DELETE FROM oauth_authorization_codes
+WHERE code = $1
+RETURNING *;
+
+-- name: CreateOAuthRefreshToken :one
+INSERT INTO oauth_refresh_tokens (
+    client_id, user_id, refresh_token, scope, auth_time, expired_at
+) VALUES (
+             $1, $2, $3, $4, $5, $6
+         ) RETURNING *;
+
+-- name: ConsumeOAuthRefreshToken :one
+DELETE FROM oauth_refresh_tokens
+WHERE refresh_token = $1
+RETURNING *;
+
+-- name: DeleteOAuthRefreshTokensByUserId :exec
+DELETE FROM oauth_refresh_tokens
+WHERE user_id = $1;
@@ -0,0 +1,57 @@
+-- name: CreateOrganization :one
+INSERT INTO organizations (
+    name
+) VALUES (
+             $1
+         ) RETURNING *;
+
+-- name: GetOrganization :one
+SELECT * FROM organizations
+WHERE id = $1
+LIMIT 1;
+
+-- name: CreateMembership :one
+INSERT INTO memberships (
+    organization_id, user_id, role
+) VALUES (
+             $1, $2, $3
+         ) RETURNING *;
+
+-- name: GetMembership :one
+SELECT * FROM memberships
+WHERE organization_id = $1 AND user_id = $2
+LIMIT 1;
+
+-- name: ListMembershipsByUserId :many
+SELECT m.organization_id, o.name, m.role, m.created_at
+FROM memberships m
+JOIN organizations o ON o.id = m.organization_id
+WHERE m.user_id = $1
+ORDER BY m.organization_id;
+
+-- name: ListMembersByOrganizationId :many
+SELECT m.user_id, u.name, u.email, m.role, m.created_at
+FROM memberships m
+JOIN users u ON u.id = m.user_id
+WHERE m.organization_id = $1
+ORDER BY m.user_id;
+
+-- name: UpsertOrganizationInvitation :one
+INSERT INTO organization_invitations (organization_id, email, role, token, invited_by, expired_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+    ON CONFLICT (organization_id, email)
+DO UPDATE SET
+    role = EXCLUDED.role,
+           token = EXCLUDED.token,
+           invited_by = EXCLUDED.invited_by,
+           expired_at = EXCLUDED.expired_at
+RETURNING *;
+
+-- name: GetOrganizationInvitationByToken :one
+SELECT * FROM organization_invitations
+WHERE token = $1
+LIMIT 1;
+
+-- name: DeleteOrganizationInvitation :exec
+DELETE FROM organization_invitations
+WHERE id = $1;
@@ -1,19 +1,10 @@
 -- name: CreateRefreshToken :one
 INSERT INTO refresh_tokens (
-    user_id, refresh_token, expired_at
+    user_id, refresh_token, expired_at, session_id, device_label, ip_address, user_agent
 ) VALUES (
-             $1, $2, $3
+             $1, $2, $3, $4, $5, $6, $7
          ) RETURNING *;
 
--- name: UpsertRefreshToken :one
-INSERT INTO refresh_tokens (user_id, refresh_token, expired_at)
-VALUES ($1, $2, $3)
-    ON CONFLICT (user_id)
-DO UPDATE SET
-    refresh_token = EXCLUDED.refresh_token,
-           expired_at = EXCLUDED.expired_at
-RETURNING *;
-
 -- name: DeleteRefreshToken :exec
 DELETE FROM refresh_tokens
 WHERE refresh_token = $1;
@@ -21,4 +12,23 @@ WHERE refresh_token = $1;
 -- name: GetRefreshTokenByUserId :one
 SELECT * FROM refresh_tokens
 WHERE refresh_token = $1 and user_id = $2
-LIMIT 1;
\ No newline at end of file
+LIMIT 1;
+
+-- name: ListRefreshTokensByUserId :many
+SELECT * FROM refresh_tokens
+WHERE user_id = $1 AND expired_at > now()
+ORDER BY last_used_at DESC;
+
+-- name: TouchRefreshToken :exec
+UPDATE refresh_tokens
+SET last_used_at = now(), ip_address = $2
+WHERE id = $1;
+
+-- name: DeleteRefreshTokenBySessionId :one
+DELETE FROM refresh_tokens
+WHERE user_id = $1 AND session_id = $2
+RETURNING *;
+
+-- name: DeleteRefreshTokensByUserId :exec
+DELETE FROM refresh_tokens
+WHERE user_id = $1;
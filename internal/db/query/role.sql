@@ -0,0 +1,49 @@
+-- name: CreateRole :one
+INSERT INTO roles (
+    name, external_id
+) VALUES (
+             $1, $2
+         ) RETURNING *;
+
+-- name: GetRole :one
+SELECT * FROM roles
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetRoleByName :one
+SELECT * FROM roles
+WHERE name = $1
+LIMIT 1;
+
+-- name: ListRoles :many
+SELECT * FROM roles
+ORDER BY id
+LIMIT $1
+OFFSET $2;
+
+-- name: CountRoles :one
+SELECT count(*) FROM roles;
+
+-- name: UpdateRole :one
+UPDATE roles
+SET name = $2, external_id = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteRole :exec
+DELETE FROM roles
+WHERE id = $1;
+
+-- name: CreateRolePermission :one
+INSERT INTO role_permissions (
+    role_id, permission
+) VALUES (
+             $1, $2
+         ) RETURNING *;
+
+-- name: HasRolePermission :one
+SELECT EXISTS (
+    SELECT 1 FROM role_permissions
+    JOIN roles ON roles.id = role_permissions.role_id
+    WHERE roles.name = $1 AND role_permissions.permission = $2
+);
@@ -8,4 +8,68 @@ INSERT INTO users (
 -- name: GetUser :one
 SELECT * FROM users
 WHERE email = $1
-LIMIT 1;
\ No newline at end of file
+LIMIT 1;
+
+-- name: GetUserByID :one
+SELECT * FROM users
+WHERE id = $1
+LIMIT 1;
+
+-- name: CreateUserWithRole :one
+INSERT INTO users (
+    name, email, password, role
+) VALUES (
+             $1, $2, $3, $4
+         ) RETURNING *;
+
+-- name: UpdateUserNameAndRole :one
+UPDATE users
+SET name = $2, role = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateProvisionedUser :one
+INSERT INTO users (
+    name, email, password, active, external_id
+) VALUES (
+             $1, $2, $3, $4, $5
+         ) RETURNING *;
+
+-- name: UpdateProvisionedUser :one
+UPDATE users
+SET name = $2, email = $3, active = $4, external_id = $5
+WHERE id = $1
+RETURNING *;
+
+-- name: ListUsers :many
+SELECT * FROM users
+ORDER BY id
+LIMIT $1
+OFFSET $2;
+
+-- name: CountUsers :one
+SELECT count(*) FROM users;
+
+-- name: ListUsersByRole :many
+SELECT * FROM users
+WHERE role = $1
+ORDER BY id;
+
+-- name: UpdateUserRole :exec
+UPDATE users
+SET role = $2
+WHERE id = $1;
+
+-- name: ResetUserRole :exec
+UPDATE users
+SET role = sqlc.arg(default_role)
+WHERE id = sqlc.arg(id) AND role = sqlc.arg(role);
+
+-- name: RenameUsersRole :exec
+UPDATE users
+SET role = sqlc.arg(new_role)
+WHERE role = sqlc.arg(old_role);
+
+-- name: DeleteUser :exec
+DELETE FROM users
+WHERE id = $1;
@@ -0,0 +1,40 @@
+-- name: CreateUserInvitation :one
+INSERT INTO user_invitations (
+    email, role, token, invited_by, expired_at
+) VALUES (
+             $1, $2, $3, $4, $5
+         ) RETURNING *;
+
+-- name: GetUserInvitation :one
+SELECT * FROM user_invitations
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetUserInvitationByToken :one
+SELECT * FROM user_invitations
+WHERE token = $1
+LIMIT 1;
+
+-- name: ListUserInvitations :many
+SELECT * FROM user_invitations
+ORDER BY id
+LIMIT $1
+OFFSET $2;
+
+-- name: CountUserInvitations :one
+SELECT count(*) FROM user_invitations;
+
+-- name: UpdateUserInvitationToken :one
+UPDATE user_invitations
+SET token = $2, expired_at = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: ConsumeUserInvitation :one
+DELETE FROM user_invitations
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteUserInvitation :exec
+DELETE FROM user_invitations
+WHERE id = $1;
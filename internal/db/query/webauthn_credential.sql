@@ -0,0 +1,16 @@
+-- name: CreateWebauthnCredential :one
+INSERT INTO webauthn_credentials (
+    user_id, credential_id, public_key, attestation_type, transports, aaguid, sign_count, backup_eligible, backup_state
+) VALUES (
+             $1, $2, $3, $4, $5, $6, $7, $8, $9
+         ) RETURNING *;
+
+-- name: ListWebauthnCredentialsByUserId :many
+SELECT * FROM webauthn_credentials
+WHERE user_id = $1
+ORDER BY id;
+
+-- name: UpdateWebauthnCredentialSignCount :exec
+UPDATE webauthn_credentials
+SET sign_count = $2, backup_state = $3
+WHERE credential_id = $1;
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+)
+
+// UpdateRoleTxParams describes a change to a role and the users holding it. Users removed from the role fall
+// back to DefaultRole. With ReplaceMembers every current member is removed before AddMembers are applied.
+type UpdateRoleTxParams struct {
+	Role           sqlc.UpdateRoleParams
+	DefaultRole    string
+	ReplaceMembers bool
+	AddMembers     []int64
+	RemoveMembers  []int64
+}
+
+// CreateRoleTx creates a role and moves the given users into it
+func (store *SQLStore) CreateRoleTx(ctx context.Context, arg sqlc.CreateRoleParams, members []int64) (role sqlc.Role, err error) {
+	err = store.execTx(ctx, func(q *sqlc.Queries) error {
+		var txErr error
+		role, txErr = q.CreateRole(ctx, arg)
+		if txErr != nil {
+			return txErr
+		}
+
+		for _, userId := range members {
+			if txErr = q.UpdateUserRole(ctx, sqlc.UpdateUserRoleParams{ID: userId, Role: role.Name}); txErr != nil {
+				return txErr
+			}
+		}
+		return nil
+	})
+
+	return
+}
+
+// UpdateRoleTx renames a role, carrying its members over, and applies membership changes
+func (store *SQLStore) UpdateRoleTx(ctx context.Context, arg UpdateRoleTxParams) (role sqlc.Role, err error) {
+	err = store.execTx(ctx, func(q *sqlc.Queries) error {
+		previous, txErr := q.GetRole(ctx, arg.Role.ID)
+		if txErr != nil {
+			return txErr
+		}
+
+		role, txErr = q.UpdateRole(ctx, arg.Role)
+		if txErr != nil {
+			return txErr
+		}
+
+		if previous.Name != role.Name {
+			txErr = q.RenameUsersRole(ctx, sqlc.RenameUsersRoleParams{NewRole: role.Name, OldRole: previous.Name})
+			if txErr != nil {
+				return txErr
+			}
+		}
+
+		if arg.ReplaceMembers {
+			txErr = q.RenameUsersRole(ctx, sqlc.RenameUsersRoleParams{NewRole: arg.DefaultRole, OldRole: role.Name})
+			if txErr != nil {
+				return txErr
+			}
+		}
+
+		for _, userId := range arg.AddMembers {
+			if txErr = q.UpdateUserRole(ctx, sqlc.UpdateUserRoleParams{ID: userId, Role: role.Name}); txErr != nil {
+				return txErr
+			}
+		}
+
+		for _, userId := range arg.RemoveMembers {
+			txErr = q.ResetUserRole(ctx, sqlc.ResetUserRoleParams{DefaultRole: arg.DefaultRole, ID: userId, Role: role.Name})
+			if txErr != nil {
+				return txErr
+			}
+		}
+		return nil
+	})
+
+	return
+}
+
+// DeleteRoleTx deletes a role after moving its members to defaultRole
+func (store *SQLStore) DeleteRoleTx(ctx context.Context, id int64, defaultRole string) error {
+	return store.execTx(ctx, func(q *sqlc.Queries) error {
+		role, txErr := q.GetRole(ctx, id)
+		if txErr != nil {
+			return txErr
+		}
+
+		txErr = q.RenameUsersRole(ctx, sqlc.RenameUsersRoleParams{NewRole: defaultRole, OldRole: role.Name})
+		if txErr != nil {
+			return txErr
+		}
+
+		return q.DeleteRole(ctx, id)
+	})
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createTxUser(t *testing.T, store Store) sqlc.User {
+	user, err := store.CreateUser(context.Background(), sqlc.CreateUserParams{Name: util.RandomString(10), Email: util.RandomString(10)})
+	require.NoError(t, err)
+	return user
+}
+
+func TestRoleTx(t *testing.T) {
+	store := NewSQLStore(conf, testDB, tokenMaker)
+	first := createTxUser(t, store)
+	second := createTxUser(t, store)
+
+	role, err := store.CreateRoleTx(context.Background(), sqlc.CreateRoleParams{Name: util.RandomString(10)}, []int64{first.ID})
+	require.NoError(t, err)
+
+	members, err := store.ListUsersByRole(context.Background(), role.Name)
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+
+	// Renaming carries the members over, replacing moves the previous ones back to the default role
+	renamed, err := store.UpdateRoleTx(context.Background(), UpdateRoleTxParams{
+		Role:           sqlc.UpdateRoleParams{ID: role.ID, Name: util.RandomString(10)},
+		DefaultRole:    "user",
+		ReplaceMembers: true,
+		AddMembers:     []int64{second.ID},
+	})
+	require.NoError(t, err)
+
+	members, err = store.ListUsersByRole(context.Background(), renamed.Name)
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	require.Equal(t, second.ID, members[0].ID)
+
+	user, err := store.GetUserByID(context.Background(), first.ID)
+	require.NoError(t, err)
+	require.Equal(t, "user", user.Role)
+
+	require.NoError(t, store.DeleteRoleTx(context.Background(), renamed.ID, "user"))
+
+	user, err = store.GetUserByID(context.Background(), second.ID)
+	require.NoError(t, err)
+	require.Equal(t, "user", user.Role)
+}
@@ -0,0 +1,24 @@
+package db
+
+import (
+	"context"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+)
+
+// SocialRegisterTx creates a password-less user together with the external identity it signed up with
+func (store *SQLStore) SocialRegisterTx(ctx context.Context, arg sqlc.CreateUserParams, identity sqlc.CreateUserIdentityParams) (user sqlc.User, err error) {
+	err = store.execTx(ctx, func(q *sqlc.Queries) error {
+		var txErr error
+		user, txErr = q.CreateUser(ctx, arg)
+		if txErr != nil {
+			return txErr
+		}
+
+		identity.UserID = user.ID
+		_, txErr = q.CreateUserIdentity(ctx, identity)
+		return txErr
+	})
+
+	return
+}
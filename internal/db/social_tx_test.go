@@ -0,0 +1,49 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSocialRegisterTx(t *testing.T) {
+	store := NewSQLStore(conf, testDB, tokenMaker)
+
+	arg := sqlc.CreateUserParams{
+		Name:  util.RandomString(10),
+		Email: util.RandomString(10),
+	}
+	identity := sqlc.CreateUserIdentityParams{
+		Provider: "google",
+		Subject:  util.RandomString(20),
+		Email:    arg.Email,
+	}
+
+	user, err := store.SocialRegisterTx(context.Background(), arg, identity)
+	require.NoError(t, err)
+	require.Equal(t, arg.Email, user.Email)
+
+	linked, err := store.GetUserIdentity(context.Background(), sqlc.GetUserIdentityParams{Provider: identity.Provider, Subject: identity.Subject})
+	require.NoError(t, err)
+	require.Equal(t, user.ID, linked.UserID)
+}
+
+func TestSocialRegisterTx_DuplicateIdentity(t *testing.T) {
+	store := NewSQLStore(conf, testDB, tokenMaker)
+
+	identity := sqlc.CreateUserIdentityParams{Provider: "github", Subject: util.RandomString(20)}
+
+	_, err := store.SocialRegisterTx(context.Background(), sqlc.CreateUserParams{Name: util.RandomString(10), Email: util.RandomString(10)}, identity)
+	require.NoError(t, err)
+
+	email := util.RandomString(10)
+	_, err = store.SocialRegisterTx(context.Background(), sqlc.CreateUserParams{Name: util.RandomString(10), Email: email}, identity)
+	require.Error(t, err)
+
+	// The user row must be rolled back together with the identity
+	user, _ := store.GetUser(context.Background(), email)
+	require.Empty(t, user)
+}
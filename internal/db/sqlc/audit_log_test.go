@@ -0,0 +1,36 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAuditLog(t *testing.T) {
+	user := createAUser(t)
+	admin := createAUser(t)
+
+	arg := CreateAuditLogParams{
+		UserID:         user.ID,
+		ImpersonatorID: sql.NullInt64{Int64: admin.ID, Valid: true},
+		Action:         "POST /orgs",
+		IpAddress:      "127.0.0.1",
+		UserAgent:      "curl/8.7.1",
+		RequestID:      "5f0c6e0e-7d3c-4d43-9a7b-3b4b7f8e2a11",
+	}
+	entry, err := testQueries.CreateAuditLog(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, entry.ID)
+	require.Equal(t, arg.UserID, entry.UserID)
+	require.Equal(t, arg.ImpersonatorID, entry.ImpersonatorID)
+	require.Equal(t, arg.Action, entry.Action)
+	require.Equal(t, arg.RequestID, entry.RequestID)
+	require.NotZero(t, entry.CreatedAt)
+
+	entries, err := testQueries.ListAuditLogsByUserId(context.Background(), ListAuditLogsByUserIdParams{UserID: user.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, entry.ID, entries[0].ID)
+}
@@ -0,0 +1,35 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumeMagicLinkToken(t *testing.T) {
+	user := createAUser(t)
+
+	arg := CreateMagicLinkTokenParams{
+		UserID:    user.ID,
+		Token:     util.RandomString(64),
+		Nonce:     util.RandomString(64),
+		ExpiredAt: time.Now().Add(15 * time.Minute),
+	}
+	created, err := testQueries.CreateMagicLinkToken(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Token, created.Token)
+	require.Equal(t, arg.Nonce, created.Nonce)
+	require.NotZero(t, created.CreatedAt)
+
+	consumed, err := testQueries.ConsumeMagicLinkToken(context.Background(), arg.Token)
+	require.NoError(t, err)
+	require.Equal(t, user.ID, consumed.UserID)
+
+	// A link can only be used once
+	_, err = testQueries.ConsumeMagicLinkToken(context.Background(), arg.Token)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
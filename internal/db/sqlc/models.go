@@ -5,9 +5,93 @@
 package sqlc
 
 import (
+	"database/sql"
 	"time"
 )
 
+type AuditLog struct {
+	ID             int64         `json:"id"`
+	UserID         int64         `json:"user_id"`
+	ImpersonatorID sql.NullInt64 `json:"impersonator_id"`
+	Action         string        `json:"action"`
+	IpAddress      string        `json:"ip_address"`
+	UserAgent      string        `json:"user_agent"`
+	CreatedAt      time.Time     `json:"created_at"`
+	RequestID      string        `json:"request_id"`
+}
+
+type MagicLinkToken struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Token     string    `json:"token"`
+	Nonce     string    `json:"nonce"`
+	ExpiredAt time.Time `json:"expired_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Membership struct {
+	ID             int64     `json:"id"`
+	OrganizationID int64     `json:"organization_id"`
+	UserID         int64     `json:"user_id"`
+	Role           string    `json:"role"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type OauthAuthorizationCode struct {
+	ID                  int64     `json:"id"`
+	Code                string    `json:"code"`
+	ClientID            string    `json:"client_id"`
+	UserID              int64     `json:"user_id"`
+	RedirectUri         string    `json:"redirect_uri"`
+	Scope               string    `json:"scope"`
+	Nonce               string    `json:"nonce"`
+	CodeChallenge       string    `json:"code_challenge"`
+	CodeChallengeMethod string    `json:"code_challenge_method"`
+	AuthTime            time.Time `json:"auth_time"`
+	ExpiredAt           time.Time `json:"expired_at"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+type OauthClient struct {
+	ID           int64     `json:"id"`
+	ClientID     string    `json:"client_id"`
+	ClientSecret string    `json:"client_secret"`
+	Name         string    `json:"name"`
+	RedirectUris []string  `json:"redirect_uris"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type OauthRefreshToken struct {
+	ID           int64     `json:"id"`
+	ClientID     string    `json:"client_id"`
+	UserID       int64     `json:"user_id"`
+	RefreshToken string    `json:"refresh_token"`
+	Scope        string    `json:"scope"`
+	AuthTime     time.Time `json:"auth_time"`
+	ExpiredAt    time.Time `json:"expired_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type Organization struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type OrganizationInvitation struct {
+	ID             int64     `json:"id"`
+	OrganizationID int64     `json:"organization_id"`
+	Email          string    `json:"email"`
+	Role           string    `json:"role"`
+	Token          string    `json:"token"`
+	InvitedBy      int64     `json:"invited_by"`
+	ExpiredAt      time.Time `json:"expired_at"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
 type RefreshToken struct {
 	ID           int64     `json:"id"`
 	UserID       int64     `json:"user_id"`
@@ -15,13 +99,71 @@ type RefreshToken struct {
 	ExpiredAt    time.Time `json:"expired_at"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
+	SessionID    string    `json:"session_id"`
+	DeviceLabel  string    `json:"device_label"`
+	IpAddress    string    `json:"ip_address"`
+	UserAgent    string    `json:"user_agent"`
+	LastUsedAt   time.Time `json:"last_used_at"`
+}
+
+type Role struct {
+	ID         int64          `json:"id"`
+	Name       string         `json:"name"`
+	ExternalID sql.NullString `json:"external_id"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
+
+type RolePermission struct {
+	ID         int64     `json:"id"`
+	RoleID     int64     `json:"role_id"`
+	Permission string    `json:"permission"`
+	CreatedAt  time.Time `json:"created_at"`
 }
 
 type User struct {
+	ID         int64          `json:"id"`
+	Name       string         `json:"name"`
+	Email      string         `json:"email"`
+	Password   string         `json:"password"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	Role       string         `json:"role"`
+	Active     bool           `json:"active"`
+	ExternalID sql.NullString `json:"external_id"`
+}
+
+type UserIdentity struct {
 	ID        int64     `json:"id"`
-	Name      string    `json:"name"`
+	UserID    int64     `json:"user_id"`
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
 	Email     string    `json:"email"`
-	Password  string    `json:"password"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type UserInvitation struct {
+	ID        int64     `json:"id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	Token     string    `json:"token"`
+	InvitedBy int64     `json:"invited_by"`
+	ExpiredAt time.Time `json:"expired_at"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+type WebauthnCredential struct {
+	ID              int64     `json:"id"`
+	UserID          int64     `json:"user_id"`
+	CredentialID    []byte    `json:"credential_id"`
+	PublicKey       []byte    `json:"public_key"`
+	AttestationType string    `json:"attestation_type"`
+	Transports      []string  `json:"transports"`
+	Aaguid          []byte    `json:"aaguid"`
+	SignCount       int64     `json:"sign_count"`
+	BackupEligible  bool      `json:"backup_eligible"`
+	BackupState     bool      `json:"backup_state"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
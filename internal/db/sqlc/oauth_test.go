@@ -0,0 +1,108 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createAOAuthClient(t *testing.T) OauthClient {
+	arg := CreateOAuthClientParams{
+		ClientID:     util.RandomString(20),
+		ClientSecret: util.RandomString(64),
+		Name:         util.RandomString(10),
+		RedirectUris: []string{"https://app.example.com/callback", "http://localhost:3000/callback"},
+	}
+
+	client, err := testQueries.CreateOAuthClient(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.ClientID, client.ClientID)
+	require.Equal(t, arg.RedirectUris, client.RedirectUris)
+	require.NotZero(t, client.CreatedAt)
+	return client
+}
+
+func TestGetOAuthClient(t *testing.T) {
+	client := createAOAuthClient(t)
+
+	got, err := testQueries.GetOAuthClient(context.Background(), client.ClientID)
+	require.NoError(t, err)
+	require.Equal(t, client.ID, got.ID)
+	require.Equal(t, client.RedirectUris, got.RedirectUris)
+}
+
+func TestConsumeOAuthAuthorizationCode(t *testing.T) {
+	client := createAOAuthClient(t)
+	user := createAUser(t)
+
+	arg := CreateOAuthAuthorizationCodeParams{
+		Code:                util.RandomString(64),
+		ClientID:            client.ClientID,
+		UserID:              user.ID,
+		RedirectUri:         client.RedirectUris[0],
+		Scope:               "openid",
+		CodeChallenge:       util.RandomString(43),
+		CodeChallengeMethod: "S256",
+		AuthTime:            time.Now(),
+		ExpiredAt:           time.Now().Add(time.Minute),
+	}
+	_, err := testQueries.CreateOAuthAuthorizationCode(context.Background(), arg)
+	require.NoError(t, err)
+
+	consumed, err := testQueries.ConsumeOAuthAuthorizationCode(context.Background(), arg.Code)
+	require.NoError(t, err)
+	require.Equal(t, user.ID, consumed.UserID)
+
+	// A code can only be redeemed once
+	_, err = testQueries.ConsumeOAuthAuthorizationCode(context.Background(), arg.Code)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestConsumeOAuthRefreshToken(t *testing.T) {
+	client := createAOAuthClient(t)
+	user := createAUser(t)
+
+	arg := CreateOAuthRefreshTokenParams{
+		ClientID:     client.ClientID,
+		UserID:       user.ID,
+		RefreshToken: util.RandomString(64),
+		Scope:        "openid",
+		AuthTime:     time.Now(),
+		ExpiredAt:    time.Now().Add(time.Hour),
+	}
+	_, err := testQueries.CreateOAuthRefreshToken(context.Background(), arg)
+	require.NoError(t, err)
+
+	consumed, err := testQueries.ConsumeOAuthRefreshToken(context.Background(), arg.RefreshToken)
+	require.NoError(t, err)
+	require.Equal(t, client.ClientID, consumed.ClientID)
+
+	_, err = testQueries.ConsumeOAuthRefreshToken(context.Background(), arg.RefreshToken)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestDeleteOAuthRefreshTokensByUserId(t *testing.T) {
+	client := createAOAuthClient(t)
+	user := createAUser(t)
+
+	arg := CreateOAuthRefreshTokenParams{
+		ClientID:     client.ClientID,
+		UserID:       user.ID,
+		RefreshToken: util.RandomString(64),
+		Scope:        "openid",
+		AuthTime:     time.Now(),
+		ExpiredAt:    time.Now().Add(time.Hour),
+	}
+	_, err := testQueries.CreateOAuthRefreshToken(context.Background(), arg)
+	require.NoError(t, err)
+
+	err = testQueries.DeleteOAuthRefreshTokensByUserId(context.Background(), user.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.ConsumeOAuthRefreshToken(context.Background(), arg.RefreshToken)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,96 @@
+package sqlc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createAnOrganization(t *testing.T) Organization {
+	name := util.RandomString(10)
+
+	organization, err := testQueries.CreateOrganization(context.Background(), name)
+	require.NoError(t, err)
+	require.Equal(t, name, organization.Name)
+	require.NotZero(t, organization.ID)
+	require.NotZero(t, organization.CreatedAt)
+	return organization
+}
+
+func TestGetOrganization(t *testing.T) {
+	organization := createAnOrganization(t)
+
+	found, err := testQueries.GetOrganization(context.Background(), organization.ID)
+	require.NoError(t, err)
+	require.Equal(t, organization.Name, found.Name)
+}
+
+func TestMemberships(t *testing.T) {
+	organization := createAnOrganization(t)
+	user := createAUser(t)
+
+	membership, err := testQueries.CreateMembership(context.Background(), CreateMembershipParams{
+		OrganizationID: organization.ID,
+		UserID:         user.ID,
+		Role:           "admin",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "admin", membership.Role)
+
+	found, err := testQueries.GetMembership(context.Background(), GetMembershipParams{OrganizationID: organization.ID, UserID: user.ID})
+	require.NoError(t, err)
+	require.Equal(t, membership.ID, found.ID)
+
+	_, err = testQueries.CreateMembership(context.Background(), CreateMembershipParams{
+		OrganizationID: organization.ID,
+		UserID:         user.ID,
+		Role:           "member",
+	})
+	require.Error(t, err)
+
+	memberships, err := testQueries.ListMembershipsByUserId(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, memberships, 1)
+	require.Equal(t, organization.Name, memberships[0].Name)
+
+	members, err := testQueries.ListMembersByOrganizationId(context.Background(), organization.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	require.Equal(t, user.Email, members[0].Email)
+}
+
+func TestOrganizationInvitation(t *testing.T) {
+	organization := createAnOrganization(t)
+	inviter := createAUser(t)
+	arg := UpsertOrganizationInvitationParams{
+		OrganizationID: organization.ID,
+		Email:          util.RandomString(10),
+		Role:           "member",
+		Token:          util.RandomString(32),
+		InvitedBy:      inviter.ID,
+		ExpiredAt:      time.Now().Add(time.Hour),
+	}
+
+	invitation, err := testQueries.UpsertOrganizationInvitation(context.Background(), arg)
+	require.NoError(t, err)
+
+	// Inviting the same email again replaces the pending invitation
+	arg.Role = "admin"
+	arg.Token = util.RandomString(32)
+	reinvited, err := testQueries.UpsertOrganizationInvitation(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, invitation.ID, reinvited.ID)
+	require.Equal(t, "admin", reinvited.Role)
+
+	_, err = testQueries.GetOrganizationInvitationByToken(context.Background(), invitation.Token)
+	require.Error(t, err)
+
+	found, err := testQueries.GetOrganizationInvitationByToken(context.Background(), arg.Token)
+	require.NoError(t, err)
+	require.Equal(t, arg.Email, found.Email)
+
+	require.NoError(t, testQueries.DeleteOrganizationInvitation(context.Background(), found.ID))
+}
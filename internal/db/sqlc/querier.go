@@ -9,12 +9,69 @@ import (
 )
 
 type Querier interface {
+	ConsumeMagicLinkToken(ctx context.Context, token string) (MagicLinkToken, error)
+	ConsumeOAuthAuthorizationCode(ctx context.Context, code string) (OauthAuthorizationCode, error)
+	ConsumeOAuthRefreshToken(ctx context.Context, refreshToken string) (OauthRefreshToken, error)
+	ConsumeUserInvitation(ctx context.Context, id int64) (UserInvitation, error)
+	CountRoles(ctx context.Context) (int64, error)
+	CountUserInvitations(ctx context.Context) (int64, error)
+	CountUsers(ctx context.Context) (int64, error)
+	CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error)
+	CreateMagicLinkToken(ctx context.Context, arg CreateMagicLinkTokenParams) (MagicLinkToken, error)
+	CreateMembership(ctx context.Context, arg CreateMembershipParams) (Membership, error)
+	CreateOAuthAuthorizationCode(ctx context.Context, arg CreateOAuthAuthorizationCodeParams) (OauthAuthorizationCode, error)
+	CreateOAuthClient(ctx context.Context, arg CreateOAuthClientParams) (OauthClient, error)
+	CreateOAuthRefreshToken(ctx context.Context, arg CreateOAuthRefreshTokenParams) (OauthRefreshToken, error)
+	CreateOrganization(ctx context.Context, name string) (Organization, error)
+	CreateProvisionedUser(ctx context.Context, arg CreateProvisionedUserParams) (User, error)
 	CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) (RefreshToken, error)
+	CreateRole(ctx context.Context, arg CreateRoleParams) (Role, error)
+	CreateRolePermission(ctx context.Context, arg CreateRolePermissionParams) (RolePermission, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) (UserIdentity, error)
+	CreateUserInvitation(ctx context.Context, arg CreateUserInvitationParams) (UserInvitation, error)
+	CreateUserWithRole(ctx context.Context, arg CreateUserWithRoleParams) (User, error)
+	CreateWebauthnCredential(ctx context.Context, arg CreateWebauthnCredentialParams) (WebauthnCredential, error)
+	DeleteOAuthRefreshTokensByUserId(ctx context.Context, userID int64) error
+	DeleteOrganizationInvitation(ctx context.Context, id int64) error
 	DeleteRefreshToken(ctx context.Context, refreshToken string) error
+	DeleteRefreshTokenBySessionId(ctx context.Context, arg DeleteRefreshTokenBySessionIdParams) (RefreshToken, error)
+	DeleteRefreshTokensByUserId(ctx context.Context, userID int64) error
+	DeleteRole(ctx context.Context, id int64) error
+	DeleteUser(ctx context.Context, id int64) error
+	DeleteUserInvitation(ctx context.Context, id int64) error
+	GetMembership(ctx context.Context, arg GetMembershipParams) (Membership, error)
+	GetOAuthClient(ctx context.Context, clientID string) (OauthClient, error)
+	GetOrganization(ctx context.Context, id int64) (Organization, error)
+	GetOrganizationInvitationByToken(ctx context.Context, token string) (OrganizationInvitation, error)
 	GetRefreshTokenByUserId(ctx context.Context, arg GetRefreshTokenByUserIdParams) (RefreshToken, error)
+	GetRole(ctx context.Context, id int64) (Role, error)
+	GetRoleByName(ctx context.Context, name string) (Role, error)
 	GetUser(ctx context.Context, email string) (User, error)
-	UpsertRefreshToken(ctx context.Context, arg UpsertRefreshTokenParams) (RefreshToken, error)
+	GetUserByID(ctx context.Context, id int64) (User, error)
+	GetUserIdentity(ctx context.Context, arg GetUserIdentityParams) (UserIdentity, error)
+	GetUserInvitation(ctx context.Context, id int64) (UserInvitation, error)
+	GetUserInvitationByToken(ctx context.Context, token string) (UserInvitation, error)
+	HasRolePermission(ctx context.Context, arg HasRolePermissionParams) (bool, error)
+	ListAuditLogsByUserId(ctx context.Context, arg ListAuditLogsByUserIdParams) ([]AuditLog, error)
+	ListMembersByOrganizationId(ctx context.Context, organizationID int64) ([]ListMembersByOrganizationIdRow, error)
+	ListMembershipsByUserId(ctx context.Context, userID int64) ([]ListMembershipsByUserIdRow, error)
+	ListRefreshTokensByUserId(ctx context.Context, userID int64) ([]RefreshToken, error)
+	ListRoles(ctx context.Context, arg ListRolesParams) ([]Role, error)
+	ListUserInvitations(ctx context.Context, arg ListUserInvitationsParams) ([]UserInvitation, error)
+	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	ListUsersByRole(ctx context.Context, role string) ([]User, error)
+	ListWebauthnCredentialsByUserId(ctx context.Context, userID int64) ([]WebauthnCredential, error)
+	RenameUsersRole(ctx context.Context, arg RenameUsersRoleParams) error
+	ResetUserRole(ctx context.Context, arg ResetUserRoleParams) error
+	TouchRefreshToken(ctx context.Context, arg TouchRefreshTokenParams) error
+	UpdateProvisionedUser(ctx context.Context, arg UpdateProvisionedUserParams) (User, error)
+	UpdateRole(ctx context.Context, arg UpdateRoleParams) (Role, error)
+	UpdateUserInvitationToken(ctx context.Context, arg UpdateUserInvitationTokenParams) (UserInvitation, error)
+	UpdateUserNameAndRole(ctx context.Context, arg UpdateUserNameAndRoleParams) (User, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) error
+	UpdateWebauthnCredentialSignCount(ctx context.Context, arg UpdateWebauthnCredentialSignCountParams) error
+	UpsertOrganizationInvitation(ctx context.Context, arg UpsertOrganizationInvitationParams) (OrganizationInvitation, error)
 }
 
 var _ Querier = (*Queries)(nil)
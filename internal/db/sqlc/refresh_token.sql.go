@@ -12,20 +12,32 @@ import (
 
 const createRefreshToken = `-- name: CreateRefreshToken :one
 INSERT INTO refresh_tokens (
-    user_id, refresh_token, expired_at
+    user_id, refresh_token, expired_at, session_id, device_label, ip_address, user_agent
 ) VALUES (
-             $1, $2, $3
-         ) RETURNING id, user_id, refresh_token, expired_at, created_at, updated_at
+             $1, $2, $3, $4, $5, $6, $7
+         ) RETURNING id, user_id, refresh_token, expired_at, created_at, updated_at, session_id, device_label, ip_address, user_agent, last_used_at
 `
 
 type CreateRefreshTokenParams struct {
 	UserID       int64     `json:"user_id"`
 	RefreshToken string    `json:"refresh_token"`
 	ExpiredAt    time.Time `json:"expired_at"`
+	SessionID    string    `json:"session_id"`
+	DeviceLabel  string    `json:"device_label"`
+	IpAddress    string    `json:"ip_address"`
+	UserAgent    string    `json:"user_agent"`
 }
 
 func (q *Queries) CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) (RefreshToken, error) {
-	row := q.db.QueryRowContext(ctx, createRefreshToken, arg.UserID, arg.RefreshToken, arg.ExpiredAt)
+	row := q.db.QueryRowContext(ctx, createRefreshToken,
+		arg.UserID,
+		arg.RefreshToken,
+		arg.ExpiredAt,
+		arg.SessionID,
+		arg.DeviceLabel,
+		arg.IpAddress,
+		arg.UserAgent,
+	)
 	var i RefreshToken
 	err := row.Scan(
 		&i.ID,
@@ -34,6 +46,11 @@ func (q *Queries) CreateRefreshToken(ctx context.Context, arg CreateRefreshToken
 		&i.ExpiredAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SessionID,
+		&i.DeviceLabel,
+		&i.IpAddress,
+		&i.UserAgent,
+		&i.LastUsedAt,
 	)
 	return i, err
 }
@@ -48,19 +65,19 @@ func (q *Queries) DeleteRefreshToken(ctx context.Context, refreshToken string) e
 	return err
 }
 
-const getRefreshTokenByUserId = `-- name: GetRefreshTokenByUserId :one
-SELECT id, user_id, refresh_token, expired_at, created_at, updated_at FROM refresh_tokens
-WHERE refresh_token = $1 and user_id = $2
-LIMIT 1
+const deleteRefreshTokenBySessionId = `-- name: DeleteRefreshTokenBySessionId :one
+DELETE FROM refresh_tokens
+WHERE user_id = $1 AND session_id = $2
+RETURNING id, user_id, refresh_token, expired_at, created_at, updated_at, session_id, device_label, ip_address, user_agent, last_used_at
 `
 
-type GetRefreshTokenByUserIdParams struct {
-	RefreshToken string `json:"refresh_token"`
-	UserID       int64  `json:"user_id"`
+type DeleteRefreshTokenBySessionIdParams struct {
+	UserID    int64  `json:"user_id"`
+	SessionID string `json:"session_id"`
 }
 
-func (q *Queries) GetRefreshTokenByUserId(ctx context.Context, arg GetRefreshTokenByUserIdParams) (RefreshToken, error) {
-	row := q.db.QueryRowContext(ctx, getRefreshTokenByUserId, arg.RefreshToken, arg.UserID)
+func (q *Queries) DeleteRefreshTokenBySessionId(ctx context.Context, arg DeleteRefreshTokenBySessionIdParams) (RefreshToken, error) {
+	row := q.db.QueryRowContext(ctx, deleteRefreshTokenBySessionId, arg.UserID, arg.SessionID)
 	var i RefreshToken
 	err := row.Scan(
 		&i.ID,
@@ -69,28 +86,38 @@ func (q *Queries) GetRefreshTokenByUserId(ctx context.Context, arg GetRefreshTok
 		&i.ExpiredAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SessionID,
+		&i.DeviceLabel,
+		&i.IpAddress,
+		&i.UserAgent,
+		&i.LastUsedAt,
 	)
 	return i, err
 }
 
-const upsertRefreshToken = `-- name: UpsertRefreshToken :one
-INSERT INTO refresh_tokens (user_id, refresh_token, expired_at)
-VALUES ($1, $2, $3)
-    ON CONFLICT (user_id)
-DO UPDATE SET
-    refresh_token = EXCLUDED.refresh_token,
-           expired_at = EXCLUDED.expired_at
-RETURNING id, user_id, refresh_token, expired_at, created_at, updated_at
+const deleteRefreshTokensByUserId = `-- name: DeleteRefreshTokensByUserId :exec
+DELETE FROM refresh_tokens
+WHERE user_id = $1
 `
 
-type UpsertRefreshTokenParams struct {
-	UserID       int64     `json:"user_id"`
-	RefreshToken string    `json:"refresh_token"`
-	ExpiredAt    time.Time `json:"expired_at"`
+func (q *Queries) DeleteRefreshTokensByUserId(ctx context.Context, userID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteRefreshTokensByUserId, userID)
+	return err
+}
+
+const getRefreshTokenByUserId = `-- name: GetRefreshTokenByUserId :one
+SELECT id, user_id, refresh_token, expired_at, created_at, updated_at, session_id, device_label, ip_address, user_agent, last_used_at FROM refresh_tokens
+WHERE refresh_token = $1 and user_id = $2
+LIMIT 1
+`
+
+type GetRefreshTokenByUserIdParams struct {
+	RefreshToken string `json:"refresh_token"`
+	UserID       int64  `json:"user_id"`
 }
 
-func (q *Queries) UpsertRefreshToken(ctx context.Context, arg UpsertRefreshTokenParams) (RefreshToken, error) {
-	row := q.db.QueryRowContext(ctx, upsertRefreshToken, arg.UserID, arg.RefreshToken, arg.ExpiredAt)
+func (q *Queries) GetRefreshTokenByUserId(ctx context.Context, arg GetRefreshTokenByUserIdParams) (RefreshToken, error) {
+	row := q.db.QueryRowContext(ctx, getRefreshTokenByUserId, arg.RefreshToken, arg.UserID)
 	var i RefreshToken
 	err := row.Scan(
 		&i.ID,
@@ -99,6 +126,68 @@ func (q *Queries) UpsertRefreshToken(ctx context.Context, arg UpsertRefreshToken
 		&i.ExpiredAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SessionID,
+		&i.DeviceLabel,
+		&i.IpAddress,
+		&i.UserAgent,
+		&i.LastUsedAt,
 	)
 	return i, err
 }
+
+const listRefreshTokensByUserId = `-- name: ListRefreshTokensByUserId :many
+SELECT id, user_id, refresh_token, expired_at, created_at, updated_at, session_id, device_label, ip_address, user_agent, last_used_at FROM refresh_tokens
+WHERE user_id = $1 AND expired_at > now()
+ORDER BY last_used_at DESC
+`
+
+func (q *Queries) ListRefreshTokensByUserId(ctx context.Context, userID int64) ([]RefreshToken, error) {
+	rows, err := q.db.QueryContext(ctx, listRefreshTokensByUserId, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []RefreshToken{}
+	for rows.Next() {
+		var i RefreshToken
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.RefreshToken,
+			&i.ExpiredAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.SessionID,
+			&i.DeviceLabel,
+			&i.IpAddress,
+			&i.UserAgent,
+			&i.LastUsedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const touchRefreshToken = `-- name: TouchRefreshToken :exec
+UPDATE refresh_tokens
+SET last_used_at = now(), ip_address = $2
+WHERE id = $1
+`
+
+type TouchRefreshTokenParams struct {
+	ID        int64  `json:"id"`
+	IpAddress string `json:"ip_address"`
+}
+
+func (q *Queries) TouchRefreshToken(ctx context.Context, arg TouchRefreshTokenParams) error {
+	_, err := q.db.ExecContext(ctx, touchRefreshToken, arg.ID, arg.IpAddress)
+	return err
+}
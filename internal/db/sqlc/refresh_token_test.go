@@ -2,18 +2,28 @@ package sqlc
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
 	"github.com/stretchr/testify/require"
 )
 
 func generateRefreshToken(t *testing.T) RefreshToken {
 	user := createAUser(t)
+	return generateUserRefreshToken(t, user.ID)
+}
+
+func generateUserRefreshToken(t *testing.T, userId int64) RefreshToken {
 	arg := CreateRefreshTokenParams{
-		UserID:       user.ID,
+		UserID:       userId,
 		RefreshToken: "refresh_token",
-		ExpiredAt:    time.Now(),
+		ExpiredAt:    time.Now().Add(time.Hour),
+		SessionID:    util.RandomString(16),
+		DeviceLabel:  "Chrome on macOS",
+		IpAddress:    "127.0.0.1",
+		UserAgent:    "Mozilla/5.0",
 	}
 
 	refreshToken, err := testQueries.CreateRefreshToken(context.Background(), arg)
@@ -23,10 +33,15 @@ func generateRefreshToken(t *testing.T) RefreshToken {
 	require.Equal(t, arg.UserID, refreshToken.UserID)
 	require.Equal(t, arg.RefreshToken, refreshToken.RefreshToken)
 	require.WithinDuration(t, arg.ExpiredAt, refreshToken.ExpiredAt, time.Second)
+	require.Equal(t, arg.SessionID, refreshToken.SessionID)
+	require.Equal(t, arg.DeviceLabel, refreshToken.DeviceLabel)
+	require.Equal(t, arg.IpAddress, refreshToken.IpAddress)
+	require.Equal(t, arg.UserAgent, refreshToken.UserAgent)
 
 	require.NotZero(t, refreshToken.ID)
 	require.NotZero(t, refreshToken.CreatedAt)
 	require.NotZero(t, refreshToken.UpdatedAt)
+	require.NotZero(t, refreshToken.LastUsedAt)
 	return refreshToken
 }
 
@@ -41,6 +56,16 @@ func TestDeleteRefreshToken(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestDeleteRefreshTokensByUserId(t *testing.T) {
+	refreshToken := generateRefreshToken(t)
+
+	err := testQueries.DeleteRefreshTokensByUserId(context.Background(), refreshToken.UserID)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetRefreshTokenByUserId(context.Background(), GetRefreshTokenByUserIdParams{RefreshToken: refreshToken.RefreshToken, UserID: refreshToken.UserID})
+	require.Error(t, err)
+}
+
 func TestGetRefreshTokenByUserId(t *testing.T) {
 	refreshToken := generateRefreshToken(t)
 	arg := GetRefreshTokenByUserIdParams{
@@ -52,14 +77,28 @@ func TestGetRefreshTokenByUserId(t *testing.T) {
 	require.Equal(t, refreshToken.UserID, refreshTokenUser.UserID)
 }
 
-func TestUpsertRefreshToken(t *testing.T) {
+func TestDeleteRefreshTokenBySessionId(t *testing.T) {
 	refreshToken := generateRefreshToken(t)
-	arg := UpsertRefreshTokenParams{
-		RefreshToken: "refresh_token",
-		UserID:       refreshToken.UserID,
-		ExpiredAt:    time.Now(),
-	}
-	upsertedRefreshToken, err := testQueries.UpsertRefreshToken(context.Background(), arg)
+
+	_, err := testQueries.DeleteRefreshTokenBySessionId(context.Background(), DeleteRefreshTokenBySessionIdParams{UserID: refreshToken.UserID + 1, SessionID: refreshToken.SessionID})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	deleted, err := testQueries.DeleteRefreshTokenBySessionId(context.Background(), DeleteRefreshTokenBySessionIdParams{UserID: refreshToken.UserID, SessionID: refreshToken.SessionID})
+	require.NoError(t, err)
+	require.Equal(t, refreshToken.ID, deleted.ID)
+}
+
+func TestListRefreshTokensByUserId(t *testing.T) {
+	refreshToken := generateRefreshToken(t)
+	other := generateUserRefreshToken(t, refreshToken.UserID)
+
+	err := testQueries.TouchRefreshToken(context.Background(), TouchRefreshTokenParams{ID: refreshToken.ID, IpAddress: "10.0.0.1"})
+	require.NoError(t, err)
+
+	sessions, err := testQueries.ListRefreshTokensByUserId(context.Background(), refreshToken.UserID)
 	require.NoError(t, err)
-	require.Equal(t, arg.RefreshToken, upsertedRefreshToken.RefreshToken)
+	require.Len(t, sessions, 2)
+	require.Equal(t, refreshToken.ID, sessions[0].ID)
+	require.Equal(t, "10.0.0.1", sessions[0].IpAddress)
+	require.Equal(t, other.ID, sessions[1].ID)
 }
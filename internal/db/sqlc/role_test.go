@@ -0,0 +1,96 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createARole(t *testing.T) Role {
+	arg := CreateRoleParams{
+		Name:       util.RandomString(10),
+		ExternalID: sql.NullString{String: util.RandomString(10), Valid: true},
+	}
+
+	role, err := testQueries.CreateRole(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Name, role.Name)
+	require.Equal(t, arg.ExternalID, role.ExternalID)
+	require.NotZero(t, role.ID)
+	require.NotZero(t, role.CreatedAt)
+	return role
+}
+
+func TestGetRole(t *testing.T) {
+	role := createARole(t)
+
+	found, err := testQueries.GetRole(context.Background(), role.ID)
+	require.NoError(t, err)
+	require.Equal(t, role.Name, found.Name)
+
+	found, err = testQueries.GetRoleByName(context.Background(), role.Name)
+	require.NoError(t, err)
+	require.Equal(t, role.ID, found.ID)
+}
+
+func TestDefaultRoleExists(t *testing.T) {
+	_, err := testQueries.GetRoleByName(context.Background(), "user")
+	require.NoError(t, err)
+}
+
+func TestListRoles(t *testing.T) {
+	createARole(t)
+	createARole(t)
+
+	count, err := testQueries.CountRoles(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, count, int64(3))
+
+	roles, err := testQueries.ListRoles(context.Background(), ListRolesParams{Limit: 2, Offset: 1})
+	require.NoError(t, err)
+	require.Len(t, roles, 2)
+}
+
+func TestUpdateRole(t *testing.T) {
+	role := createARole(t)
+
+	arg := UpdateRoleParams{ID: role.ID, Name: util.RandomString(10)}
+	updated, err := testQueries.UpdateRole(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Name, updated.Name)
+	require.False(t, updated.ExternalID.Valid)
+}
+
+func TestDeleteRole(t *testing.T) {
+	role := createARole(t)
+
+	require.NoError(t, testQueries.DeleteRole(context.Background(), role.ID))
+
+	_, err := testQueries.GetRole(context.Background(), role.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestRolePermission(t *testing.T) {
+	role := createARole(t)
+
+	has, err := testQueries.HasRolePermission(context.Background(), HasRolePermissionParams{Name: role.Name, Permission: "users:impersonate"})
+	require.NoError(t, err)
+	require.False(t, has)
+
+	permission, err := testQueries.CreateRolePermission(context.Background(), CreateRolePermissionParams{RoleID: role.ID, Permission: "users:impersonate"})
+	require.NoError(t, err)
+	require.Equal(t, role.ID, permission.RoleID)
+
+	has, err = testQueries.HasRolePermission(context.Background(), HasRolePermissionParams{Name: role.Name, Permission: "users:impersonate"})
+	require.NoError(t, err)
+	require.True(t, has)
+}
+
+func TestAdminCanImpersonate(t *testing.T) {
+	has, err := testQueries.HasRolePermission(context.Background(), HasRolePermissionParams{Name: "admin", Permission: "users:impersonate"})
+	require.NoError(t, err)
+	require.True(t, has)
+}
@@ -7,14 +7,65 @@ package sqlc
 
 import (
 	"context"
+	"database/sql"
 )
 
+const countUsers = `-- name: CountUsers :one
+SELECT count(*) FROM users
+`
+
+func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUsers)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createProvisionedUser = `-- name: CreateProvisionedUser :one
+INSERT INTO users (
+    name, email, password, active, external_id
+) VALUES (
+             $1, $2, $3, $4, $5
+         ) RETURNING id, name, email, password, created_at, updated_at, role, active, external_id
+`
+
+type CreateProvisionedUserParams struct {
+	Name       string         `json:"name"`
+	Email      string         `json:"email"`
+	Password   string         `json:"password"`
+	Active     bool           `json:"active"`
+	ExternalID sql.NullString `json:"external_id"`
+}
+
+func (q *Queries) CreateProvisionedUser(ctx context.Context, arg CreateProvisionedUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, createProvisionedUser,
+		arg.Name,
+		arg.Email,
+		arg.Password,
+		arg.Active,
+		arg.ExternalID,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Role,
+		&i.Active,
+		&i.ExternalID,
+	)
+	return i, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (
     name, email, password
 ) VALUES (
              $1, $2, $3
-         ) RETURNING id, name, email, password, created_at, updated_at
+         ) RETURNING id, name, email, password, created_at, updated_at, role, active, external_id
 `
 
 type CreateUserParams struct {
@@ -33,12 +84,62 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Role,
+		&i.Active,
+		&i.ExternalID,
 	)
 	return i, err
 }
 
+const createUserWithRole = `-- name: CreateUserWithRole :one
+INSERT INTO users (
+    name, email, password, role
+) VALUES (
+             $1, $2, $3, $4
+         ) RETURNING id, name, email, password, created_at, updated_at, role, active, external_id
+`
+
+type CreateUserWithRoleParams struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+}
+
+func (q *Queries) CreateUserWithRole(ctx context.Context, arg CreateUserWithRoleParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, createUserWithRole,
+		arg.Name,
+		arg.Email,
+		arg.Password,
+		arg.Role,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Role,
+		&i.Active,
+		&i.ExternalID,
+	)
+	return i, err
+}
+
+const deleteUser = `-- name: DeleteUser :exec
+DELETE FROM users
+WHERE id = $1
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteUser, id)
+	return err
+}
+
 const getUser = `-- name: GetUser :one
-SELECT id, name, email, password, created_at, updated_at FROM users
+SELECT id, name, email, password, created_at, updated_at, role, active, external_id FROM users
 WHERE email = $1
 LIMIT 1
 `
@@ -53,6 +154,233 @@ func (q *Queries) GetUser(ctx context.Context, email string) (User, error) {
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Role,
+		&i.Active,
+		&i.ExternalID,
 	)
 	return i, err
 }
+
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, name, email, password, created_at, updated_at, role, active, external_id FROM users
+WHERE id = $1
+LIMIT 1
+`
+
+func (q *Queries) GetUserByID(ctx context.Context, id int64) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Role,
+		&i.Active,
+		&i.ExternalID,
+	)
+	return i, err
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT id, name, email, password, created_at, updated_at, role, active, external_id FROM users
+ORDER BY id
+LIMIT $1
+OFFSET $2
+`
+
+type ListUsersParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsers, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Email,
+			&i.Password,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Role,
+			&i.Active,
+			&i.ExternalID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsersByRole = `-- name: ListUsersByRole :many
+SELECT id, name, email, password, created_at, updated_at, role, active, external_id FROM users
+WHERE role = $1
+ORDER BY id
+`
+
+func (q *Queries) ListUsersByRole(ctx context.Context, role string) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsersByRole, role)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Email,
+			&i.Password,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Role,
+			&i.Active,
+			&i.ExternalID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const renameUsersRole = `-- name: RenameUsersRole :exec
+UPDATE users
+SET role = $1
+WHERE role = $2
+`
+
+type RenameUsersRoleParams struct {
+	NewRole string `json:"new_role"`
+	OldRole string `json:"old_role"`
+}
+
+func (q *Queries) RenameUsersRole(ctx context.Context, arg RenameUsersRoleParams) error {
+	_, err := q.db.ExecContext(ctx, renameUsersRole, arg.NewRole, arg.OldRole)
+	return err
+}
+
+const resetUserRole = `-- name: ResetUserRole :exec
+UPDATE users
+SET role = $1
+WHERE id = $2 AND role = $3
+`
+
+type ResetUserRoleParams struct {
+	DefaultRole string `json:"default_role"`
+	ID          int64  `json:"id"`
+	Role        string `json:"role"`
+}
+
+func (q *Queries) ResetUserRole(ctx context.Context, arg ResetUserRoleParams) error {
+	_, err := q.db.ExecContext(ctx, resetUserRole, arg.DefaultRole, arg.ID, arg.Role)
+	return err
+}
+
+const updateProvisionedUser = `-- name: UpdateProvisionedUser :one
+UPDATE users
+SET name = $2, email = $3, active = $4, external_id = $5
+WHERE id = $1
+RETURNING id, name, email, password, created_at, updated_at, role, active, external_id
+`
+
+type UpdateProvisionedUserParams struct {
+	ID         int64          `json:"id"`
+	Name       string         `json:"name"`
+	Email      string         `json:"email"`
+	Active     bool           `json:"active"`
+	ExternalID sql.NullString `json:"external_id"`
+}
+
+func (q *Queries) UpdateProvisionedUser(ctx context.Context, arg UpdateProvisionedUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateProvisionedUser,
+		arg.ID,
+		arg.Name,
+		arg.Email,
+		arg.Active,
+		arg.ExternalID,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Role,
+		&i.Active,
+		&i.ExternalID,
+	)
+	return i, err
+}
+
+const updateUserNameAndRole = `-- name: UpdateUserNameAndRole :one
+UPDATE users
+SET name = $2, role = $3
+WHERE id = $1
+RETURNING id, name, email, password, created_at, updated_at, role, active, external_id
+`
+
+type UpdateUserNameAndRoleParams struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+func (q *Queries) UpdateUserNameAndRole(ctx context.Context, arg UpdateUserNameAndRoleParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserNameAndRole, arg.ID, arg.Name, arg.Role)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Role,
+		&i.Active,
+		&i.ExternalID,
+	)
+	return i, err
+}
+
+const updateUserRole = `-- name: UpdateUserRole :exec
+UPDATE users
+SET role = $2
+WHERE id = $1
+`
+
+type UpdateUserRoleParams struct {
+	ID   int64  `json:"id"`
+	Role string `json:"role"`
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserRole, arg.ID, arg.Role)
+	return err
+}
@@ -0,0 +1,89 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createAUserInvitation(t *testing.T) UserInvitation {
+	inviter := createAUser(t)
+	arg := CreateUserInvitationParams{
+		Email:     util.RandomString(10),
+		Role:      "user",
+		Token:     util.RandomString(32),
+		InvitedBy: inviter.ID,
+		ExpiredAt: time.Now().Add(time.Hour),
+	}
+
+	invitation, err := testQueries.CreateUserInvitation(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Email, invitation.Email)
+	require.Equal(t, arg.Role, invitation.Role)
+	require.Equal(t, arg.Token, invitation.Token)
+	require.Equal(t, inviter.ID, invitation.InvitedBy)
+	require.NotZero(t, invitation.ID)
+	return invitation
+}
+
+func TestGetUserInvitation(t *testing.T) {
+	invitation := createAUserInvitation(t)
+
+	found, err := testQueries.GetUserInvitation(context.Background(), invitation.ID)
+	require.NoError(t, err)
+	require.Equal(t, invitation.Email, found.Email)
+
+	found, err = testQueries.GetUserInvitationByToken(context.Background(), invitation.Token)
+	require.NoError(t, err)
+	require.Equal(t, invitation.ID, found.ID)
+}
+
+func TestListUserInvitations(t *testing.T) {
+	createAUserInvitation(t)
+	createAUserInvitation(t)
+
+	count, err := testQueries.CountUserInvitations(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, count, int64(2))
+
+	invitations, err := testQueries.ListUserInvitations(context.Background(), ListUserInvitationsParams{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, invitations, 2)
+}
+
+func TestUpdateUserInvitationToken(t *testing.T) {
+	invitation := createAUserInvitation(t)
+
+	arg := UpdateUserInvitationTokenParams{ID: invitation.ID, Token: util.RandomString(32), ExpiredAt: time.Now().Add(2 * time.Hour)}
+	updated, err := testQueries.UpdateUserInvitationToken(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Token, updated.Token)
+	require.WithinDuration(t, arg.ExpiredAt, updated.ExpiredAt, time.Second)
+
+	_, err = testQueries.GetUserInvitationByToken(context.Background(), invitation.Token)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestConsumeUserInvitation(t *testing.T) {
+	invitation := createAUserInvitation(t)
+
+	consumed, err := testQueries.ConsumeUserInvitation(context.Background(), invitation.ID)
+	require.NoError(t, err)
+	require.Equal(t, invitation.ID, consumed.ID)
+
+	_, err = testQueries.ConsumeUserInvitation(context.Background(), invitation.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestDeleteUserInvitation(t *testing.T) {
+	invitation := createAUserInvitation(t)
+
+	require.NoError(t, testQueries.DeleteUserInvitation(context.Background(), invitation.ID))
+
+	_, err := testQueries.GetUserInvitation(context.Background(), invitation.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -2,6 +2,7 @@ package sqlc
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 
 	"github.com/hanifsyahsn/go_boilerplate/internal/util"
@@ -22,6 +23,10 @@ func createAUser(t *testing.T) User {
 	require.Equal(t, arg.Email, user.Email)
 	require.Equal(t, arg.Password, user.Password)
 
+	require.Equal(t, "user", user.Role)
+	require.True(t, user.Active)
+	require.False(t, user.ExternalID.Valid)
+
 	require.NotZero(t, user.ID)
 	require.NotZero(t, user.CreatedAt)
 	require.NotZero(t, user.UpdatedAt)
@@ -40,3 +45,122 @@ func TestGetUser(t *testing.T) {
 	require.Equal(t, user.Email, getUserByEmail.Email)
 	require.Equal(t, user.ID, getUserByEmail.ID)
 }
+
+func TestGetUserByID(t *testing.T) {
+	user := createAUser(t)
+	getUserByID, err := testQueries.GetUserByID(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Equal(t, user.Email, getUserByID.Email)
+	require.Equal(t, user.ID, getUserByID.ID)
+}
+
+func TestCreateUserWithRole(t *testing.T) {
+	arg := CreateUserWithRoleParams{
+		Name:  util.RandomString(10),
+		Email: util.RandomString(20),
+		Role:  "admin",
+	}
+	user, err := testQueries.CreateUserWithRole(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Email, user.Email)
+	require.Equal(t, arg.Role, user.Role)
+	require.Empty(t, user.Password)
+}
+
+func TestUpdateUserNameAndRole(t *testing.T) {
+	user := createAUser(t)
+
+	arg := UpdateUserNameAndRoleParams{
+		ID:   user.ID,
+		Name: util.RandomString(10),
+		Role: "staff",
+	}
+	updated, err := testQueries.UpdateUserNameAndRole(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, user.ID, updated.ID)
+	require.Equal(t, arg.Name, updated.Name)
+	require.Equal(t, arg.Role, updated.Role)
+	require.Equal(t, user.Email, updated.Email)
+}
+
+func TestCreateProvisionedUser(t *testing.T) {
+	arg := CreateProvisionedUserParams{
+		Name:       util.RandomString(10),
+		Email:      util.RandomString(10),
+		Active:     false,
+		ExternalID: sql.NullString{String: util.RandomString(10), Valid: true},
+	}
+
+	user, err := testQueries.CreateProvisionedUser(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Email, user.Email)
+	require.Empty(t, user.Password)
+	require.False(t, user.Active)
+	require.Equal(t, arg.ExternalID, user.ExternalID)
+	require.Equal(t, "user", user.Role)
+}
+
+func TestUpdateProvisionedUser(t *testing.T) {
+	user := createAUser(t)
+
+	arg := UpdateProvisionedUserParams{
+		ID:     user.ID,
+		Name:   util.RandomString(10),
+		Email:  util.RandomString(10),
+		Active: false,
+	}
+
+	updated, err := testQueries.UpdateProvisionedUser(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Name, updated.Name)
+	require.Equal(t, arg.Email, updated.Email)
+	require.False(t, updated.Active)
+	require.Equal(t, user.Password, updated.Password)
+}
+
+func TestListUsers(t *testing.T) {
+	createAUser(t)
+	createAUser(t)
+
+	count, err := testQueries.CountUsers(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, count, int64(2))
+
+	users, err := testQueries.ListUsers(context.Background(), ListUsersParams{Limit: 2, Offset: 0})
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	require.Less(t, users[0].ID, users[1].ID)
+}
+
+func TestUserRoleMembership(t *testing.T) {
+	role := util.RandomString(10)
+	renamed := util.RandomString(10)
+	first := createAUser(t)
+	second := createAUser(t)
+
+	require.NoError(t, testQueries.UpdateUserRole(context.Background(), UpdateUserRoleParams{ID: first.ID, Role: role}))
+	require.NoError(t, testQueries.UpdateUserRole(context.Background(), UpdateUserRoleParams{ID: second.ID, Role: role}))
+
+	members, err := testQueries.ListUsersByRole(context.Background(), role)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+
+	// Resetting only applies while the user still holds the role
+	require.NoError(t, testQueries.ResetUserRole(context.Background(), ResetUserRoleParams{DefaultRole: "user", ID: first.ID, Role: renamed}))
+	require.NoError(t, testQueries.RenameUsersRole(context.Background(), RenameUsersRoleParams{NewRole: renamed, OldRole: role}))
+	require.NoError(t, testQueries.ResetUserRole(context.Background(), ResetUserRoleParams{DefaultRole: "user", ID: first.ID, Role: renamed}))
+
+	members, err = testQueries.ListUsersByRole(context.Background(), renamed)
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	require.Equal(t, second.ID, members[0].ID)
+}
+
+func TestDeleteUser(t *testing.T) {
+	user := createAUser(t)
+
+	require.NoError(t, testQueries.DeleteUser(context.Background(), user.ID))
+
+	_, err := testQueries.GetUserByID(context.Background(), user.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,60 @@
+package sqlc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createAWebauthnCredential(t *testing.T, userId int64) WebauthnCredential {
+	arg := CreateWebauthnCredentialParams{
+		UserID:          userId,
+		CredentialID:    []byte(util.RandomString(32)),
+		PublicKey:       []byte(util.RandomString(77)),
+		AttestationType: "none",
+		Transports:      []string{"internal", "hybrid"},
+		Aaguid:          make([]byte, 16),
+		SignCount:       1,
+		BackupEligible:  true,
+	}
+
+	credential, err := testQueries.CreateWebauthnCredential(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.CredentialID, credential.CredentialID)
+	require.Equal(t, arg.Transports, credential.Transports)
+	require.Equal(t, arg.SignCount, credential.SignCount)
+	require.NotZero(t, credential.CreatedAt)
+	return credential
+}
+
+func TestListWebauthnCredentialsByUserId(t *testing.T) {
+	user := createAUser(t)
+	first := createAWebauthnCredential(t, user.ID)
+	second := createAWebauthnCredential(t, user.ID)
+
+	credentials, err := testQueries.ListWebauthnCredentialsByUserId(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, credentials, 2)
+	require.Equal(t, first.ID, credentials[0].ID)
+	require.Equal(t, second.ID, credentials[1].ID)
+}
+
+func TestUpdateWebauthnCredentialSignCount(t *testing.T) {
+	user := createAUser(t)
+	credential := createAWebauthnCredential(t, user.ID)
+
+	err := testQueries.UpdateWebauthnCredentialSignCount(context.Background(), UpdateWebauthnCredentialSignCountParams{
+		CredentialID: credential.CredentialID,
+		SignCount:    5,
+		BackupState:  true,
+	})
+	require.NoError(t, err)
+
+	credentials, err := testQueries.ListWebauthnCredentialsByUserId(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, credentials, 1)
+	require.EqualValues(t, 5, credentials[0].SignCount)
+	require.True(t, credentials[0].BackupState)
+}
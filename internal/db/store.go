@@ -14,7 +14,13 @@ import (
 
 type Store interface {
 	sqlc.Querier
-	RegisterTx(ctx context.Context, arg sqlc.CreateUserParams) (user sqlc.User, accessToken, refreshToken string, accessClaims, refreshClaims jwt.MapClaims, err error)
+	RegisterTx(ctx context.Context, arg sqlc.CreateUserParams, hooks ...RegisterTxHook) (user sqlc.User, accessToken, refreshToken string, accessClaims, refreshClaims jwt.MapClaims, err error)
+	SocialRegisterTx(ctx context.Context, arg sqlc.CreateUserParams, identity sqlc.CreateUserIdentityParams) (user sqlc.User, err error)
+	CreateRoleTx(ctx context.Context, arg sqlc.CreateRoleParams, members []int64) (role sqlc.Role, err error)
+	UpdateRoleTx(ctx context.Context, arg UpdateRoleTxParams) (role sqlc.Role, err error)
+	DeleteRoleTx(ctx context.Context, id int64, defaultRole string) error
+	CreateOrganizationTx(ctx context.Context, name string, ownerId int64) (organization sqlc.Organization, membership sqlc.Membership, err error)
+	AcceptOrganizationInvitationTx(ctx context.Context, invitation sqlc.OrganizationInvitation, userId int64) (membership sqlc.Membership, err error)
 }
 
 type SQLStore struct {
@@ -26,7 +32,7 @@ type SQLStore struct {
 
 func NewSQLStore(config config.Config, conn *sql.DB, maker token.Maker) Store {
 	return &SQLStore{
-		Queries:    sqlc.New(conn),
+		Queries:    sqlc.New(traceDB(conn)),
 		db:         conn,
 		config:     config,
 		tokenMaker: maker,
@@ -39,7 +45,7 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(q *sqlc.Queries) erro
 		return err
 	}
 
-	q := sqlc.New(tx)
+	q := sqlc.New(traceDB(tx))
 	if err := fn(q); err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
 			return fmt.Errorf("tx err: %v, rollback err: %v", err, rbErr)
@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/tracing"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// queryNamePattern finds the name sqlc puts in front of every query, e.g. "-- name: GetUserByEmail :one"
+var queryNamePattern = regexp.MustCompile(`^-- name: (\w+)`)
+
+// tracedDB starts a span named after the sqlc query for every statement. The span of QueryContext covers running
+// the query, not reading its rows.
+type tracedDB struct {
+	db sqlc.DBTX
+}
+
+func traceDB(db sqlc.DBTX) sqlc.DBTX {
+	return &tracedDB{db: db}
+}
+
+func (traced *tracedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := startQuery(ctx, query)
+	result, err := traced.db.ExecContext(ctx, query, args...)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (traced *tracedDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	ctx, span := startQuery(ctx, query)
+	stmt, err := traced.db.PrepareContext(ctx, query)
+	tracing.End(span, err)
+	return stmt, err
+}
+
+func (traced *tracedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := startQuery(ctx, query)
+	rows, err := traced.db.QueryContext(ctx, query, args...)
+	tracing.End(span, err)
+	return rows, err
+}
+
+func (traced *tracedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := startQuery(ctx, query)
+	row := traced.db.QueryRowContext(ctx, query, args...)
+	// sql.ErrNoRows only surfaces on Scan, it is not a failure of the query
+	tracing.End(span, row.Err())
+	return row
+}
+
+func startQuery(ctx context.Context, query string) (context.Context, trace.Span) {
+	name := "query"
+	if matches := queryNamePattern.FindStringSubmatch(query); matches != nil {
+		name = matches[1]
+	}
+	return tracing.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBOperationName(name),
+			semconv.DBQueryText(query),
+		),
+	)
+}
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/tracing/tracingtest"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// failingDB fails every statement without a database
+type failingDB struct {
+	sqlc.DBTX
+}
+
+var errStatement = errors.New("statement failed")
+
+func (db *failingDB) ExecContext(context.Context, string, ...interface{}) (sql.Result, error) {
+	return nil, errStatement
+}
+
+func (db *failingDB) PrepareContext(context.Context, string) (*sql.Stmt, error) {
+	return nil, errStatement
+}
+
+func (db *failingDB) QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error) {
+	return nil, errStatement
+}
+
+func TestTracedDB(t *testing.T) {
+	exporter := tracingtest.NewExporter(t)
+	traced := traceDB(&failingDB{})
+
+	_, err := traced.ExecContext(context.Background(), "-- name: DeleteUser :exec\nDELETE FROM users WHERE id = $1", 1)
+	require.ErrorIs(t, err, errStatement)
+	_, err = traced.QueryContext(context.Background(), "SELECT 1")
+	require.ErrorIs(t, err, errStatement)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	require.Equal(t, "DeleteUser", spans[0].Name)
+	require.Contains(t, spans[0].Attributes, semconv.DBSystemNamePostgreSQL)
+	require.Contains(t, spans[0].Attributes, semconv.DBOperationName("DeleteUser"))
+	require.Equal(t, codes.Error, spans[0].Status.Code)
+	require.Equal(t, "query", spans[1].Name)
+}
@@ -0,0 +1,25 @@
+package db
+
+import (
+	"context"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+)
+
+// AcceptUserInvitation is a RegisterTxHook that gives the new user the invited role and consumes the invitation.
+// The invitation is deleted in the same transaction, so it fails with sql.ErrNoRows when it was used or revoked
+// concurrently and the registration is rolled back.
+func AcceptUserInvitation(invitation sqlc.UserInvitation) RegisterTxHook {
+	return func(ctx context.Context, q *sqlc.Queries, user sqlc.User) (sqlc.User, error) {
+		if _, err := q.ConsumeUserInvitation(ctx, invitation.ID); err != nil {
+			return user, err
+		}
+
+		if err := q.UpdateUserRole(ctx, sqlc.UpdateUserRoleParams{ID: user.ID, Role: invitation.Role}); err != nil {
+			return user, err
+		}
+
+		user.Role = invitation.Role
+		return user, nil
+	}
+}
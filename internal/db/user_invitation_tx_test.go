@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/stretchr/testify/require"
+)
+
+func createTxUserInvitation(t *testing.T, store Store) sqlc.UserInvitation {
+	inviter := createTxUser(t, store)
+	invitation, err := store.CreateUserInvitation(context.Background(), sqlc.CreateUserInvitationParams{
+		Email:     util.RandomString(10),
+		Role:      constant.AdminRole,
+		Token:     util.RandomString(32),
+		InvitedBy: inviter.ID,
+		ExpiredAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	return invitation
+}
+
+func TestRegisterTxAcceptUserInvitation(t *testing.T) {
+	store := NewSQLStore(conf, testDB, tokenMaker)
+	invitation := createTxUserInvitation(t, store)
+
+	arg := sqlc.CreateUserParams{Name: util.RandomString(10), Email: invitation.Email, Password: util.RandomString(10)}
+	user, _, _, _, _, err := store.RegisterTx(context.Background(), arg, AcceptUserInvitation(invitation))
+	require.NoError(t, err)
+	require.Equal(t, constant.AdminRole, user.Role)
+
+	stored, err := store.GetUserByID(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Equal(t, constant.AdminRole, stored.Role)
+
+	_, err = store.GetUserInvitation(context.Background(), invitation.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestRegisterTxRevokedUserInvitation(t *testing.T) {
+	store := NewSQLStore(conf, testDB, tokenMaker)
+	invitation := createTxUserInvitation(t, store)
+	require.NoError(t, store.DeleteUserInvitation(context.Background(), invitation.ID))
+
+	// The registration is rolled back when the invitation is gone by the time it commits
+	arg := sqlc.CreateUserParams{Name: util.RandomString(10), Email: invitation.Email, Password: util.RandomString(10)}
+	_, _, _, _, _, err := store.RegisterTx(context.Background(), arg, AcceptUserInvitation(invitation))
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = store.GetUser(context.Background(), invitation.Email)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
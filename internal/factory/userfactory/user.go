@@ -13,6 +13,8 @@ type Options struct {
 	Email     string
 	Name      string
 	Password  string
+	Role      string
+	Inactive  bool
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
@@ -27,6 +29,8 @@ func NewOptions(opts *Options) sqlc.User {
 		Email:     opts.Email,
 		Name:      opts.Name,
 		Password:  opts.Password,
+		Role:      opts.Role,
+		Active:    !opts.Inactive,
 		CreatedAt: opts.CreatedAt,
 		UpdatedAt: opts.UpdatedAt,
 	}
@@ -43,6 +47,9 @@ func NewOptions(opts *Options) sqlc.User {
 	if user.Password == "" {
 		user.Password = util.RandomString(6)
 	}
+	if user.Role == "" {
+		user.Role = "user"
+	}
 	if user.CreatedAt.IsZero() {
 		user.CreatedAt = time.Now()
 	}
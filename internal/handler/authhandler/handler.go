@@ -12,6 +12,7 @@ import (
 	"github.com/hanifsyahsn/go_boilerplate/internal/util"
 	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
 	"github.com/hanifsyahsn/go_boilerplate/internal/util/cookie"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
 )
 
 type Handler struct {
@@ -77,6 +78,39 @@ func (handler *Handler) Login(c *gin.Context) {
 	c.JSON(http.StatusOK, res)
 }
 
+func (handler *Handler) Reauthenticate(c *gin.Context) {
+	var req service.ReauthenticateRequest
+	var err error
+	if err = c.ShouldBindJSON(&req); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			uve := util.ValidatorError(ve)
+			h.HandleError(c, errors.New(uve))
+			return
+		}
+		h.HandleError(c, err)
+		return
+	}
+
+	session := token.Session{
+		OrgId:     c.GetInt64(constant.OrgIdKey),
+		SessionId: c.GetString(constant.SessionIdKey),
+		ActorId:   c.GetInt64(constant.ImpersonatorIdKey),
+	}
+
+	user, accessToken, refreshToken, err := handler.userService.ReauthenticateService(c.Request.Context(), c.GetString(constant.EmailKey), req.Password, session)
+	if err != nil {
+		h.HandleError(c, err)
+		return
+	}
+
+	cookie.ParseTokens(c, accessToken, refreshToken)
+
+	res := service.ToLoginResponse(user, accessToken, refreshToken)
+
+	c.JSON(http.StatusOK, res)
+}
+
 func (handler *Handler) Logout(c *gin.Context) {
 	userId := c.GetInt64(constant.UserIdKey)
 	refreshToken := c.GetString(constant.RefreshTokenKey)
@@ -96,9 +130,9 @@ func (handler *Handler) RefreshAccessToken(c *gin.Context) {
 	email := c.GetString(constant.EmailKey)
 	userId := c.GetInt64(constant.UserIdKey)
 	refreshToken := c.GetString(constant.RefreshTokenKey)
-	jti := c.GetString(constant.JsonWebTokenIdKey)
+	session := token.Session{OrgId: c.GetInt64(constant.OrgIdKey), AuthTime: c.GetInt64(constant.AuthTimeKey)}
 
-	accessToken, _, err := handler.userService.RefreshAccessTokenService(c.Request.Context(), refreshToken, email, userId, jti)
+	accessToken, _, err := handler.userService.RefreshAccessTokenService(c.Request.Context(), refreshToken, email, userId, session)
 	if err != nil {
 		h.HandleError(c, err)
 		return
@@ -114,13 +148,91 @@ func (handler *Handler) RefreshAccessToken(c *gin.Context) {
 func (handler *Handler) Me(c *gin.Context) {
 	email := c.GetString(constant.EmailKey)
 
-	user, err := handler.userService.MeService(c, email)
+	user, memberships, impersonator, err := handler.userService.MeService(c, email, c.GetInt64(constant.ImpersonatorIdKey))
 	if err != nil {
 		h.HandleError(c, err)
 		return
 	}
 
-	res := service.ToMeResponse(user)
+	res := service.ToMeResponse(user, memberships, c.GetInt64(constant.OrgIdKey), impersonator)
 
 	c.JSON(http.StatusOK, res)
 }
+
+func (handler *Handler) ListSessions(c *gin.Context) {
+	sessions, err := handler.userService.ListSessionsService(c.Request.Context(), c.GetInt64(constant.UserIdKey))
+	if err != nil {
+		h.HandleError(c, err)
+		return
+	}
+
+	res := service.ToSessionResponses(sessions, c.GetString(constant.SessionIdKey))
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (handler *Handler) RevokeSession(c *gin.Context) {
+	var uri service.SessionUri
+	var err error
+	if err = c.ShouldBindUri(&uri); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			uve := util.ValidatorError(ve)
+			h.HandleError(c, errors.New(uve))
+			return
+		}
+		h.HandleError(c, err)
+		return
+	}
+
+	err = handler.userService.RevokeSessionService(c.Request.Context(), c.GetInt64(constant.UserIdKey), uri.ID)
+	if err != nil {
+		h.HandleError(c, err)
+		return
+	}
+
+	if uri.ID == c.GetString(constant.SessionIdKey) {
+		cookie.RemoveTokens(c)
+	}
+
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func (handler *Handler) LogoutAll(c *gin.Context) {
+	err := handler.userService.LogoutAllService(c.Request.Context(), c.GetInt64(constant.UserIdKey))
+	if err != nil {
+		h.HandleError(c, err)
+		return
+	}
+
+	cookie.RemoveTokens(c)
+
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+// Impersonate hands the admin an access token of another user. The admin's own cookies are left untouched, the
+// token is only returned in the body.
+func (handler *Handler) Impersonate(c *gin.Context) {
+	var uri service.ImpersonateUri
+	var err error
+	if err = c.ShouldBindUri(&uri); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			uve := util.ValidatorError(ve)
+			h.HandleError(c, errors.New(uve))
+			return
+		}
+		h.HandleError(c, err)
+		return
+	}
+
+	user, accessToken, err := handler.userService.ImpersonateService(c.Request.Context(), c.GetInt64(constant.UserIdKey), uri.ID)
+	if err != nil {
+		h.HandleError(c, err)
+		return
+	}
+
+	res := service.ToImpersonationResponse(user, accessToken, int64(handler.userService.ImpersonationTokenDuration().Seconds()))
+
+	c.JSON(http.StatusCreated, res)
+}
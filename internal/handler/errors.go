@@ -2,11 +2,12 @@ package handler
 
 import (
 	stderrors "errors"
-	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
 	appErrors "github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/logger"
 )
 
 func HandleError(c *gin.Context, err error) {
@@ -17,32 +18,23 @@ func HandleError(c *gin.Context, err error) {
 
 	var appErr *appErrors.AppError
 	if stderrors.As(err, &appErr) {
+		status := appErrors.HTTPStatus(appErr.Code)
 
 		if appErr.Err != nil {
-			log.Printf(
-				"[%s] %s | path=%s | err=%v",
-				appErr.Code,
-				appErr.Message,
-				c.Request.URL.Path,
-				appErr.Err,
-			)
+			logger.ResponseError(c, status, string(appErr.Code), appErr.Message, appErr.Err)
 		}
 
 		c.JSON(
-			appErrors.HTTPStatus(appErr.Code),
-			gin.H{"message": appErr.Message},
+			status,
+			util.MessageResponse(c, appErr.Message),
 		)
 		return
 	}
 
-	log.Printf(
-		"[INTERNAL_SERVER_ERROR] path=%s | err=%v",
-		c.Request.URL.Path,
-		err,
-	)
+	logger.ResponseError(c, http.StatusInternalServerError, string(appErrors.CodeInternal), "Unexpected error", err)
 
 	c.JSON(
 		http.StatusInternalServerError,
-		gin.H{"message": "Unexpected error"},
+		util.MessageResponse(c, "Unexpected error"),
 	)
 }
@@ -0,0 +1,32 @@
+package healthhandler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/health"
+)
+
+type Handler struct {
+	checker *health.Checker
+}
+
+func NewHandler(checker *health.Checker) *Handler {
+	return &Handler{checker: checker}
+}
+
+// Liveness answers as long as the process serves requests, it checks no dependency so an outage of one does not get
+// the server restarted
+func (handler *Handler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+}
+
+// Readiness answers 503 while a required dependency is failing or the server is shutting down
+func (handler *Handler) Readiness(c *gin.Context) {
+	report, ready := handler.checker.Check(c.Request.Context())
+	status := http.StatusOK
+	if !ready {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
@@ -0,0 +1,148 @@
+package invitationhandler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	h "github.com/hanifsyahsn/go_boilerplate/internal/handler"
+	"github.com/hanifsyahsn/go_boilerplate/internal/service/authservice"
+	service "github.com/hanifsyahsn/go_boilerplate/internal/service/invitationservice"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/cookie"
+)
+
+type Handler struct {
+	invitationService *service.Service
+}
+
+func NewHandler(service *service.Service) *Handler {
+	return &Handler{invitationService: service}
+}
+
+func (handler *Handler) Create(c *gin.Context) {
+	var req service.CreateInvitationRequest
+	var err error
+	if err = c.ShouldBindJSON(&req); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			uve := util.ValidatorError(ve)
+			h.HandleError(c, errors.New(uve))
+			return
+		}
+		h.HandleError(c, err)
+		return
+	}
+
+	invitation, err := handler.invitationService.CreateService(c.Request.Context(), c.GetInt64(constant.UserIdKey), req)
+	if err != nil {
+		h.HandleError(c, err)
+		return
+	}
+
+	res := service.ToInvitationResponse(invitation)
+
+	c.JSON(http.StatusCreated, res)
+}
+
+func (handler *Handler) List(c *gin.Context) {
+	var req service.ListInvitationsRequest
+	var err error
+	if err = c.ShouldBindQuery(&req); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			uve := util.ValidatorError(ve)
+			h.HandleError(c, errors.New(uve))
+			return
+		}
+		h.HandleError(c, err)
+		return
+	}
+
+	invitations, total, err := handler.invitationService.ListService(c.Request.Context(), req)
+	if err != nil {
+		h.HandleError(c, err)
+		return
+	}
+
+	res := service.ToListInvitationsResponse(invitations, total)
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (handler *Handler) Resend(c *gin.Context) {
+	var uri service.InvitationUri
+	var err error
+	if err = c.ShouldBindUri(&uri); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			uve := util.ValidatorError(ve)
+			h.HandleError(c, errors.New(uve))
+			return
+		}
+		h.HandleError(c, err)
+		return
+	}
+
+	invitation, err := handler.invitationService.ResendService(c.Request.Context(), uri.ID)
+	if err != nil {
+		h.HandleError(c, err)
+		return
+	}
+
+	res := service.ToInvitationResponse(invitation)
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (handler *Handler) Revoke(c *gin.Context) {
+	var uri service.InvitationUri
+	var err error
+	if err = c.ShouldBindUri(&uri); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			uve := util.ValidatorError(ve)
+			h.HandleError(c, errors.New(uve))
+			return
+		}
+		h.HandleError(c, err)
+		return
+	}
+
+	err = handler.invitationService.RevokeService(c.Request.Context(), uri.ID)
+	if err != nil {
+		h.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func (handler *Handler) Complete(c *gin.Context) {
+	var req service.CompleteInvitationRequest
+	var err error
+	if err = c.ShouldBindJSON(&req); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			uve := util.ValidatorError(ve)
+			h.HandleError(c, errors.New(uve))
+			return
+		}
+		h.HandleError(c, err)
+		return
+	}
+
+	user, accessToken, refreshToken, err := handler.invitationService.CompleteService(c.Request.Context(), req)
+	if err != nil {
+		h.HandleError(c, err)
+		return
+	}
+
+	cookie.ParseTokens(c, accessToken, refreshToken)
+
+	res := authservice.ToRegisterResponse(user, accessToken, refreshToken)
+
+	c.JSON(http.StatusCreated, res)
+}
@@ -0,0 +1,71 @@
+package invitationhandler
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/service/authservice"
+	service "github.com/hanifsyahsn/go_boilerplate/internal/service/invitationservice"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/mailer"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/redis"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComplete(t *testing.T) {
+	testCases := []struct {
+		name      string
+		buildStub func(store *db.MockStore)
+		status    int
+	}{
+		{
+			name: "expired invitation",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetUserInvitationByToken(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.UserInvitation{
+					ID:        1,
+					Email:     util.RandomString(8) + "@example.com",
+					ExpiredAt: time.Now().Add(-time.Second),
+				}, nil)
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "unknown invitation",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetUserInvitationByToken(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.UserInvitation{}, sql.ErrNoRows)
+			},
+			status: http.StatusNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := db.NewMockStore(ctrl)
+			testCase.buildStub(store)
+			authSvc := authservice.NewService(store, util.HashPassword, util.CheckPasswordHash, tokenMaker, conf, redis.NewMockClient(ctrl))
+			handler := NewHandler(service.NewService(store, mailer.NewMockMailer(ctrl), authSvc, conf))
+
+			r := gin.New()
+			r.POST("/auth/register/invitation", handler.Complete)
+
+			body := `{"token": "invitation-token", "name": "Invitee", "password": "secret123"}`
+			request := httptest.NewRequest(http.MethodPost, "/auth/register/invitation", strings.NewReader(body))
+			request.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			r.ServeHTTP(recorder, request)
+
+			require.Equal(t, testCase.status, recorder.Code)
+		})
+	}
+}
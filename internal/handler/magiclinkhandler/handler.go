@@ -0,0 +1,81 @@
+package magiclinkhandler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	h "github.com/hanifsyahsn/go_boilerplate/internal/handler"
+	"github.com/hanifsyahsn/go_boilerplate/internal/service/authservice"
+	service "github.com/hanifsyahsn/go_boilerplate/internal/service/magiclinkservice"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/cookie"
+)
+
+type Handler struct {
+	magicLinkService *service.Service
+	nonceMaxAge      int
+}
+
+func NewHandler(service *service.Service, nonceMaxAge int) *Handler {
+	return &Handler{magicLinkService: service, nonceMaxAge: nonceMaxAge}
+}
+
+func (handler *Handler) Request(c *gin.Context) {
+	var req service.MagicLinkRequest
+	var err error
+	if err = c.ShouldBindJSON(&req); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			uve := util.ValidatorError(ve)
+			h.HandleError(c, errors.New(uve))
+			return
+		}
+		h.HandleError(c, err)
+		return
+	}
+
+	currentNonce, _ := c.Cookie(constant.MagicLinkNonceKey)
+
+	nonce, err := handler.magicLinkService.RequestService(c.Request.Context(), req, currentNonce)
+	if err != nil {
+		h.HandleError(c, err)
+		return
+	}
+
+	cookie.ParseMagicLinkNonce(c, nonce, handler.nonceMaxAge)
+
+	c.JSON(http.StatusAccepted, gin.H{})
+}
+
+func (handler *Handler) Consume(c *gin.Context) {
+	var req service.ConsumeRequest
+	var err error
+	if err = c.ShouldBindJSON(&req); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			uve := util.ValidatorError(ve)
+			h.HandleError(c, errors.New(uve))
+			return
+		}
+		h.HandleError(c, err)
+		return
+	}
+
+	nonce, _ := c.Cookie(constant.MagicLinkNonceKey)
+
+	user, accessToken, refreshToken, err := handler.magicLinkService.ConsumeService(c.Request.Context(), req, nonce)
+	if err != nil {
+		h.HandleError(c, err)
+		return
+	}
+
+	cookie.RemoveMagicLinkNonce(c)
+	cookie.ParseTokens(c, accessToken, refreshToken)
+
+	res := authservice.ToLoginResponse(user, accessToken, refreshToken)
+
+	c.JSON(http.StatusOK, res)
+}
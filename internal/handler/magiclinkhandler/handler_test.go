@@ -0,0 +1,82 @@
+package magiclinkhandler
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/service/authservice"
+	service "github.com/hanifsyahsn/go_boilerplate/internal/service/magiclinkservice"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/mailer"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/redis"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsume(t *testing.T) {
+	nonce := util.RandomString(32)
+
+	testCases := []struct {
+		name      string
+		buildStub func(store *db.MockStore)
+		status    int
+	}{
+		{
+			name: "expired link",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().ConsumeMagicLinkToken(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.MagicLinkToken{
+					UserID:    1,
+					Nonce:     token.HashToken(nonce),
+					ExpiredAt: time.Now().Add(-time.Second),
+				}, nil)
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "used link",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().ConsumeMagicLinkToken(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.MagicLinkToken{}, sql.ErrNoRows)
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "database error",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().ConsumeMagicLinkToken(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.MagicLinkToken{}, sql.ErrConnDone)
+			},
+			status: http.StatusInternalServerError,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := db.NewMockStore(ctrl)
+			testCase.buildStub(store)
+			authSvc := authservice.NewService(store, util.HashPassword, util.CheckPasswordHash, tokenMaker, conf, redis.NewMockClient(ctrl))
+			handler := NewHandler(service.NewService(store, mailer.NewMockMailer(ctrl), authSvc, conf), 60)
+
+			r := gin.New()
+			r.POST("/auth/magic-link/consume", handler.Consume)
+
+			request := httptest.NewRequest(http.MethodPost, "/auth/magic-link/consume", strings.NewReader(`{"token": "link-token"}`))
+			request.Header.Set("Content-Type", "application/json")
+			request.AddCookie(&http.Cookie{Name: constant.MagicLinkNonceKey, Value: nonce})
+			recorder := httptest.NewRecorder()
+			r.ServeHTTP(recorder, request)
+
+			require.Equal(t, testCase.status, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package oauthhandler
+
+import (
+	"embed"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	h "github.com/hanifsyahsn/go_boilerplate/internal/handler"
+	service "github.com/hanifsyahsn/go_boilerplate/internal/service/oauthservice"
+	appErrors "github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/logger"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var templates = template.Must(template.ParseFS(templateFS, "templates/*.html"))
+
+type Handler struct {
+	oauthService *service.Service
+}
+
+func NewHandler(service *service.Service) *Handler {
+	return &Handler{oauthService: service}
+}
+
+type authorizePage struct {
+	ClientName string
+	Request    service.AuthorizeRequest
+	Error      string
+}
+
+type errorPage struct {
+	Error string
+}
+
+func (handler *Handler) Authorize(c *gin.Context) {
+	var req service.AuthorizeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		render(c, http.StatusBadRequest, "error.html", errorPage{Error: "Invalid authorization request"})
+		return
+	}
+
+	client, err := handler.oauthService.ValidateAuthorizeRequest(c.Request.Context(), req)
+	if err != nil {
+		handleAuthorizeError(c, req, err)
+		return
+	}
+
+	render(c, http.StatusOK, "authorize.html", authorizePage{ClientName: client.Name, Request: req})
+}
+
+func (handler *Handler) Consent(c *gin.Context) {
+	var req service.ConsentRequest
+	if err := c.ShouldBind(&req); err != nil {
+		render(c, http.StatusBadRequest, "error.html", errorPage{Error: "Invalid authorization request"})
+		return
+	}
+
+	client, redirectURL, err := handler.oauthService.Authorize(c.Request.Context(), req)
+	if err != nil {
+		var appErr *appErrors.AppError
+		if errors.As(err, &appErr) && appErr.Code == appErrors.CodeUnauthorized {
+			render(c, http.StatusUnauthorized, "authorize.html", authorizePage{ClientName: client.Name, Request: req.AuthorizeRequest, Error: appErr.Message})
+			return
+		}
+		handleAuthorizeError(c, req.AuthorizeRequest, err)
+		return
+	}
+
+	c.Redirect(http.StatusFound, redirectURL)
+}
+
+func (handler *Handler) Token(c *gin.Context) {
+	// Token responses must never be cached (RFC 6749 section 5.1)
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	var req service.TokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		handleProtocolError(c, &service.Error{Code: service.ErrInvalidRequest, Description: "Malformed token request", Err: err})
+		return
+	}
+
+	// client_secret_basic credentials are form-encoded before being placed in the header (RFC 6749 section 2.3.1)
+	if clientId, clientSecret, ok := c.Request.BasicAuth(); ok {
+		req.ClientID, _ = url.QueryUnescape(clientId)
+		req.ClientSecret, _ = url.QueryUnescape(clientSecret)
+	}
+
+	res, err := handler.oauthService.Token(c.Request.Context(), req)
+	if err != nil {
+		handleProtocolError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (handler *Handler) UserInfo(c *gin.Context) {
+	fields := strings.Fields(c.GetHeader("Authorization"))
+	if len(fields) != 2 || fields[0] != "Bearer" {
+		handleProtocolError(c, &service.Error{Code: service.ErrInvalidToken, Description: "Bearer access token is required"})
+		return
+	}
+
+	res, err := handler.oauthService.UserInfo(c.Request.Context(), fields[1])
+	if err != nil {
+		handleProtocolError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (handler *Handler) Discovery(c *gin.Context) {
+	c.JSON(http.StatusOK, handler.oauthService.Discovery())
+}
+
+func (handler *Handler) JWKS(c *gin.Context) {
+	c.JSON(http.StatusOK, handler.oauthService.JWKS())
+}
+
+func render(c *gin.Context, status int, name string, data interface{}) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	// The login page must not be framed by other origins (clickjacking)
+	c.Header("X-Frame-Options", "DENY")
+	c.Status(status)
+	if err := templates.ExecuteTemplate(c.Writer, name, data); err != nil {
+		logger.ResponseError(c, http.StatusInternalServerError, string(appErrors.CodeInternal), "Failed to render page", err)
+	}
+}
+
+func handleAuthorizeError(c *gin.Context, req service.AuthorizeRequest, err error) {
+	var oauthErr *service.Error
+	if errors.As(err, &oauthErr) {
+		redirectURL, rErr := service.ErrorRedirect(req.RedirectURI, req.State, oauthErr)
+		if rErr == nil {
+			c.Redirect(http.StatusFound, redirectURL)
+			return
+		}
+		err = rErr
+	}
+
+	var appErr *appErrors.AppError
+	if errors.As(err, &appErr) {
+		if appErr.Err != nil {
+			logger.ResponseError(c, appErrors.HTTPStatus(appErr.Code), string(appErr.Code), appErr.Message, appErr.Err)
+		}
+		render(c, appErrors.HTTPStatus(appErr.Code), "error.html", errorPage{Error: appErr.Message})
+		return
+	}
+
+	logger.ResponseError(c, http.StatusInternalServerError, string(appErrors.CodeInternal), "Unexpected error", err)
+	render(c, http.StatusInternalServerError, "error.html", errorPage{Error: "Unexpected error"})
+}
+
+func handleProtocolError(c *gin.Context, err error) {
+	var oauthErr *service.Error
+	if !errors.As(err, &oauthErr) {
+		h.HandleError(c, err)
+		return
+	}
+
+	status := oauthErr.HTTPStatus()
+	if oauthErr.Err != nil {
+		logger.ResponseError(c, status, oauthErr.Code, oauthErr.Description, oauthErr.Err)
+	}
+	if status == http.StatusUnauthorized {
+		if oauthErr.Code == service.ErrInvalidToken {
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		} else {
+			c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+	}
+
+	c.JSON(status, gin.H{"error": oauthErr.Code, "error_description": oauthErr.Description})
+}
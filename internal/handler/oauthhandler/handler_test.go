@@ -0,0 +1,218 @@
+package oauthhandler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/factory/userfactory"
+	"github.com/hanifsyahsn/go_boilerplate/internal/service/authservice"
+	service "github.com/hanifsyahsn/go_boilerplate/internal/service/oauthservice"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/pkce"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRouter(store db.Store) *gin.Engine {
+	handler := NewHandler(service.NewService(store, authservice.NewPasswordAuthenticator(store, util.CheckPasswordHash), tokenMaker, conf))
+
+	r := gin.New()
+	r.GET("/.well-known/openid-configuration", handler.Discovery)
+	r.GET("/oauth/authorize", handler.Authorize)
+	r.POST("/oauth/authorize", handler.Consent)
+	r.POST("/oauth/token", handler.Token)
+	r.GET("/oauth/userinfo", handler.UserInfo)
+	r.GET("/oauth/jwks", handler.JWKS)
+	return r
+}
+
+// TestAuthorizationCodeFlow walks through the whole authorization code + PKCE flow the way a relying party would
+func TestAuthorizationCodeFlow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	password := util.RandomString(10)
+	hashed, err := util.HashPassword(context.Background(), password)
+	require.NoError(t, err)
+	user := userfactory.NewOptions(&userfactory.Options{Password: hashed})
+
+	clientSecret := util.RandomString(20)
+	redirectURI := "https://app.example.com/callback"
+	client := sqlc.OauthClient{
+		ClientID:     "wiki",
+		ClientSecret: token.HashToken(clientSecret),
+		Name:         "Wiki",
+		RedirectUris: []string{redirectURI},
+	}
+	codeVerifier := util.RandomString(64)
+
+	// In-memory stand-ins for the rows the service writes and later consumes
+	var storedCode sqlc.OauthAuthorizationCode
+	store := db.NewMockStore(ctrl)
+	store.EXPECT().GetOAuthClient(gomock.Any(), client.ClientID).AnyTimes().Return(client, nil)
+	store.EXPECT().GetUser(gomock.Any(), user.Email).AnyTimes().Return(user, nil)
+	store.EXPECT().GetUserByID(gomock.Any(), user.ID).AnyTimes().Return(user, nil)
+	store.EXPECT().CreateOAuthAuthorizationCode(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg sqlc.CreateOAuthAuthorizationCodeParams) (sqlc.OauthAuthorizationCode, error) {
+			storedCode = sqlc.OauthAuthorizationCode{
+				Code:                arg.Code,
+				ClientID:            arg.ClientID,
+				UserID:              arg.UserID,
+				RedirectUri:         arg.RedirectUri,
+				Scope:               arg.Scope,
+				Nonce:               arg.Nonce,
+				CodeChallenge:       arg.CodeChallenge,
+				CodeChallengeMethod: arg.CodeChallengeMethod,
+				AuthTime:            arg.AuthTime,
+				ExpiredAt:           arg.ExpiredAt,
+			}
+			return storedCode, nil
+		})
+	store.EXPECT().ConsumeOAuthAuthorizationCode(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, code string) (sqlc.OauthAuthorizationCode, error) {
+			require.Equal(t, storedCode.Code, code)
+			return storedCode, nil
+		})
+	store.EXPECT().CreateOAuthRefreshToken(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.OauthRefreshToken{}, nil)
+
+	router := newTestRouter(store)
+
+	// Discovery
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/.well-known/openid-configuration", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var discovery service.DiscoveryResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &discovery))
+	require.Equal(t, conf.TokenIssuer, discovery.Issuer)
+	require.Contains(t, discovery.CodeChallengeMethodsSupported, "S256")
+
+	authorizeParams := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {client.ClientID},
+		"redirect_uri":          {redirectURI},
+		"scope":                 {"openid email profile"},
+		"state":                 {"xyz"},
+		"nonce":                 {"abc"},
+		"code_challenge":        {pkce.S256CodeChallenge(codeVerifier)},
+		"code_challenge_method": {"S256"},
+	}
+
+	// Login page
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/oauth/authorize?"+authorizeParams.Encode(), nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Body.String(), "Sign in to Wiki")
+
+	// Login + consent
+	form := url.Values{}
+	for key, values := range authorizeParams {
+		form[key] = values
+	}
+	form.Set("email", user.Email)
+	form.Set("password", password)
+	form.Set("decision", service.DecisionApprove)
+
+	request := httptest.NewRequest(http.MethodPost, "/oauth/authorize", strings.NewReader(form.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusFound, recorder.Code)
+
+	location, err := url.Parse(recorder.Header().Get("Location"))
+	require.NoError(t, err)
+	require.Equal(t, "xyz", location.Query().Get("state"))
+	code := location.Query().Get("code")
+	require.NotEmpty(t, code)
+
+	// Code exchange with client_secret_basic
+	tokenForm := url.Values{
+		"grant_type":    {service.GrantTypeAuthorizationCode},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {codeVerifier},
+	}
+	request = httptest.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(tokenForm.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.SetBasicAuth(client.ClientID, clientSecret)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "no-store", recorder.Header().Get("Cache-Control"))
+
+	var tokens service.TokenResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &tokens))
+	require.NotEmpty(t, tokens.IDToken)
+
+	// UserInfo
+	request = httptest.NewRequest(http.MethodGet, "/oauth/userinfo", nil)
+	request.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var userInfo service.UserInfoResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &userInfo))
+	require.Equal(t, strconv.FormatInt(user.ID, 10), userInfo.Sub)
+	require.Equal(t, user.Email, userInfo.Email)
+	require.Equal(t, user.Name, userInfo.Name)
+}
+
+func TestAuthorizeRejectsUnregisteredRedirectURI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := db.NewMockStore(ctrl)
+	store.EXPECT().GetOAuthClient(gomock.Any(), "wiki").Times(1).Return(sqlc.OauthClient{
+		ClientID:     "wiki",
+		RedirectUris: []string{"https://app.example.com/callback"},
+	}, nil)
+
+	params := url.Values{
+		"response_type": {"code"},
+		"client_id":     {"wiki"},
+		"redirect_uri":  {"https://evil.example.com/callback"},
+		"scope":         {"openid"},
+	}
+
+	recorder := httptest.NewRecorder()
+	newTestRouter(store).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/oauth/authorize?"+params.Encode(), nil))
+
+	// Must render an error instead of redirecting to an untrusted URI
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	require.Empty(t, recorder.Header().Get("Location"))
+}
+
+func TestTokenErrorFormat(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := db.NewMockStore(ctrl)
+	store.EXPECT().GetOAuthClient(gomock.Any(), "unknown").Times(1).Return(sqlc.OauthClient{}, context.Canceled)
+
+	form := url.Values{"grant_type": {service.GrantTypeAuthorizationCode}, "client_id": {"unknown"}}
+	request := httptest.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(form.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	newTestRouter(store).ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+	request = httptest.NewRequest(http.MethodGet, "/oauth/userinfo", nil)
+	recorder = httptest.NewRecorder()
+	newTestRouter(store).ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+	require.Contains(t, recorder.Header().Get("WWW-Authenticate"), "invalid_token")
+
+	var body map[string]string
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	require.Equal(t, service.ErrInvalidToken, body["error"])
+}
@@ -0,0 +1,34 @@
+package oauthhandler
+
+import (
+	"log"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hanifsyahsn/go_boilerplate/internal/config"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
+)
+
+var conf config.Config
+var tokenMaker token.Maker
+
+func TestMain(m *testing.M) {
+	var err error
+	conf, err = config.LoadConfig("../../..")
+	if err != nil {
+		log.Fatal("Cannot load config: ", err)
+	}
+
+	if err = conf.Validate(); err != nil {
+		log.Fatal("Invalid configuration:", err)
+	}
+
+	// Only the HS256 maker can be built without key files on disk
+	tokenMaker = token.NewTokenMakerHS256(conf.JWTSecretKey, conf.TokenIssuer)
+
+	gin.SetMode(gin.TestMode)
+
+	code := m.Run()
+	os.Exit(code)
+}
@@ -0,0 +1,162 @@
+package orghandler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	h "github.com/hanifsyahsn/go_boilerplate/internal/handler"
+	"github.com/hanifsyahsn/go_boilerplate/internal/service/authservice"
+	service "github.com/hanifsyahsn/go_boilerplate/internal/service/orgservice"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/cookie"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
+)
+
+type Handler struct {
+	orgService *service.Service
+}
+
+func NewHandler(service *service.Service) *Handler {
+	return &Handler{orgService: service}
+}
+
+func (handler *Handler) Create(c *gin.Context) {
+	var req service.CreateOrganizationRequest
+	var err error
+	if err = c.ShouldBindJSON(&req); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			uve := util.ValidatorError(ve)
+			h.HandleError(c, errors.New(uve))
+			return
+		}
+		h.HandleError(c, err)
+		return
+	}
+
+	organization, membership, err := handler.orgService.CreateOrganizationService(c.Request.Context(), c.GetInt64(constant.UserIdKey), req)
+	if err != nil {
+		h.HandleError(c, err)
+		return
+	}
+
+	res := service.ToOrganizationResponse(organization, membership)
+
+	c.JSON(http.StatusCreated, res)
+}
+
+func (handler *Handler) ListMemberships(c *gin.Context) {
+	memberships, err := handler.orgService.ListMembershipsService(c.Request.Context(), c.GetInt64(constant.UserIdKey))
+	if err != nil {
+		h.HandleError(c, err)
+		return
+	}
+
+	res := service.ToMembershipsResponse(memberships)
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (handler *Handler) ListMembers(c *gin.Context) {
+	members, err := handler.orgService.ListMembersService(c.Request.Context(), c.GetInt64(constant.OrgIdKey))
+	if err != nil {
+		h.HandleError(c, err)
+		return
+	}
+
+	res := service.ToMembersResponse(members)
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (handler *Handler) Invite(c *gin.Context) {
+	var req service.InviteRequest
+	var err error
+	if err = c.ShouldBindJSON(&req); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			uve := util.ValidatorError(ve)
+			h.HandleError(c, errors.New(uve))
+			return
+		}
+		h.HandleError(c, err)
+		return
+	}
+
+	invitation, err := handler.orgService.InviteService(c.Request.Context(), c.GetInt64(constant.OrgIdKey), c.GetInt64(constant.UserIdKey), req)
+	if err != nil {
+		h.HandleError(c, err)
+		return
+	}
+
+	res := service.ToInvitationResponse(invitation)
+
+	c.JSON(http.StatusCreated, res)
+}
+
+func (handler *Handler) AcceptInvitation(c *gin.Context) {
+	var req service.AcceptInvitationRequest
+	var err error
+	if err = c.ShouldBindJSON(&req); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			uve := util.ValidatorError(ve)
+			h.HandleError(c, errors.New(uve))
+			return
+		}
+		h.HandleError(c, err)
+		return
+	}
+
+	_, err = handler.orgService.AcceptInvitationService(c.Request.Context(), c.GetInt64(constant.UserIdKey), c.GetString(constant.EmailKey), req)
+	if err != nil {
+		h.HandleError(c, err)
+		return
+	}
+
+	memberships, err := handler.orgService.ListMembershipsService(c.Request.Context(), c.GetInt64(constant.UserIdKey))
+	if err != nil {
+		h.HandleError(c, err)
+		return
+	}
+
+	res := service.ToMembershipsResponse(memberships)
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (handler *Handler) Switch(c *gin.Context) {
+	var req service.SwitchOrganizationRequest
+	var err error
+	if err = c.ShouldBindJSON(&req); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			uve := util.ValidatorError(ve)
+			h.HandleError(c, errors.New(uve))
+			return
+		}
+		h.HandleError(c, err)
+		return
+	}
+
+	session := token.Session{
+		SessionId: c.GetString(constant.SessionIdKey),
+		ActorId:   c.GetInt64(constant.ImpersonatorIdKey),
+		AuthTime:  c.GetInt64(constant.AuthTimeKey),
+	}
+
+	user, accessToken, refreshToken, err := handler.orgService.SwitchOrganizationService(c.Request.Context(), c.GetInt64(constant.UserIdKey), session, req)
+	if err != nil {
+		h.HandleError(c, err)
+		return
+	}
+
+	cookie.ParseTokens(c, accessToken, refreshToken)
+
+	res := authservice.ToLoginResponse(user, accessToken, refreshToken)
+
+	c.JSON(http.StatusOK, res)
+}
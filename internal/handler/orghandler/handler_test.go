@@ -0,0 +1,77 @@
+package orghandler
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/service/authservice"
+	service "github.com/hanifsyahsn/go_boilerplate/internal/service/orgservice"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/mailer"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/redis"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptInvitation(t *testing.T) {
+	email := util.RandomString(8) + "@example.com"
+
+	testCases := []struct {
+		name      string
+		buildStub func(store *db.MockStore)
+		status    int
+	}{
+		{
+			name: "expired invitation",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetOrganizationInvitationByToken(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.OrganizationInvitation{
+					ID:        1,
+					Email:     email,
+					ExpiredAt: time.Now().Add(-time.Second),
+				}, nil)
+				store.EXPECT().AcceptOrganizationInvitationTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "unknown invitation",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetOrganizationInvitationByToken(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.OrganizationInvitation{}, sql.ErrNoRows)
+			},
+			status: http.StatusNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := db.NewMockStore(ctrl)
+			testCase.buildStub(store)
+			authSvc := authservice.NewService(store, util.HashPassword, util.CheckPasswordHash, tokenMaker, conf, redis.NewMockClient(ctrl))
+			handler := NewHandler(service.NewService(store, mailer.NewMockMailer(ctrl), authSvc, conf))
+
+			r := gin.New()
+			r.POST("/orgs/invitations/accept", func(c *gin.Context) {
+				c.Set(constant.UserIdKey, int64(1))
+				c.Set(constant.EmailKey, email)
+			}, handler.AcceptInvitation)
+
+			request := httptest.NewRequest(http.MethodPost, "/orgs/invitations/accept", strings.NewReader(`{"token": "invitation-token"}`))
+			request.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			r.ServeHTTP(recorder, request)
+
+			require.Equal(t, testCase.status, recorder.Code)
+		})
+	}
+}
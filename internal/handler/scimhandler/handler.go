@@ -0,0 +1,242 @@
+package scimhandler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	service "github.com/hanifsyahsn/go_boilerplate/internal/service/scimservice"
+	appErrors "github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/logger"
+)
+
+const contentType = "application/scim+json"
+
+type Handler struct {
+	scimService *service.Service
+}
+
+func NewHandler(service *service.Service) *Handler {
+	return &Handler{scimService: service}
+}
+
+func (handler *Handler) ServiceProviderConfig(c *gin.Context) {
+	respond(c, http.StatusOK, handler.scimService.ServiceProviderConfigService())
+}
+
+func (handler *Handler) ListUsers(c *gin.Context) {
+	var req service.ListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		handleError(c, appErrors.New(appErrors.CodeBadRequest, "Invalid query parameters", err))
+		return
+	}
+
+	res, err := handler.scimService.ListUsersService(c.Request.Context(), req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, res)
+}
+
+func (handler *Handler) GetUser(c *gin.Context) {
+	res, err := handler.scimService.GetUserService(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, res)
+}
+
+func (handler *Handler) CreateUser(c *gin.Context) {
+	var req service.UserResource
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleError(c, appErrors.New(appErrors.CodeBadRequest, "Invalid request body", err))
+		return
+	}
+
+	res, err := handler.scimService.CreateUserService(c.Request.Context(), req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.Header("Location", res.Meta.Location)
+	respond(c, http.StatusCreated, res)
+}
+
+func (handler *Handler) ReplaceUser(c *gin.Context) {
+	var req service.UserResource
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleError(c, appErrors.New(appErrors.CodeBadRequest, "Invalid request body", err))
+		return
+	}
+
+	res, err := handler.scimService.ReplaceUserService(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, res)
+}
+
+func (handler *Handler) PatchUser(c *gin.Context) {
+	var req service.PatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleError(c, appErrors.New(appErrors.CodeBadRequest, "Invalid request body", err))
+		return
+	}
+
+	res, err := handler.scimService.PatchUserService(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, res)
+}
+
+func (handler *Handler) DeleteUser(c *gin.Context) {
+	err := handler.scimService.DeleteUserService(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (handler *Handler) ListGroups(c *gin.Context) {
+	var req service.ListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		handleError(c, appErrors.New(appErrors.CodeBadRequest, "Invalid query parameters", err))
+		return
+	}
+
+	res, err := handler.scimService.ListGroupsService(c.Request.Context(), req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, res)
+}
+
+func (handler *Handler) GetGroup(c *gin.Context) {
+	res, err := handler.scimService.GetGroupService(c.Request.Context(), c.Param("id"), c.Query("excludedAttributes"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, res)
+}
+
+func (handler *Handler) CreateGroup(c *gin.Context) {
+	var req service.GroupResource
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleError(c, appErrors.New(appErrors.CodeBadRequest, "Invalid request body", err))
+		return
+	}
+
+	res, err := handler.scimService.CreateGroupService(c.Request.Context(), req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.Header("Location", res.Meta.Location)
+	respond(c, http.StatusCreated, res)
+}
+
+func (handler *Handler) ReplaceGroup(c *gin.Context) {
+	var req service.GroupResource
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleError(c, appErrors.New(appErrors.CodeBadRequest, "Invalid request body", err))
+		return
+	}
+
+	res, err := handler.scimService.ReplaceGroupService(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, res)
+}
+
+func (handler *Handler) PatchGroup(c *gin.Context) {
+	var req service.PatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleError(c, appErrors.New(appErrors.CodeBadRequest, "Invalid request body", err))
+		return
+	}
+
+	res, err := handler.scimService.PatchGroupService(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, res)
+}
+
+func (handler *Handler) DeleteGroup(c *gin.Context) {
+	err := handler.scimService.DeleteGroupService(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func respond(c *gin.Context, status int, body any) {
+	c.Header("Content-Type", contentType)
+	c.JSON(status, body)
+}
+
+// handleError reports every failure in the SCIM error format, including the generic application errors
+func handleError(c *gin.Context, err error) {
+	if c.Writer.Written() {
+		return
+	}
+
+	status := http.StatusInternalServerError
+	detail := "Unexpected error"
+	var scimType string
+
+	var scimErr *service.Error
+	var appErr *appErrors.AppError
+	switch {
+	case errors.As(err, &scimErr):
+		status, scimType, detail = scimErr.Status, scimErr.ScimType, scimErr.Detail
+		if scimErr.Err != nil {
+			logger.ResponseError(c, status, scimType, detail, scimErr.Err)
+		}
+	case errors.As(err, &appErr):
+		status, detail = appErrors.HTTPStatus(appErr.Code), appErr.Message
+		if appErr.Code == appErrors.CodeBadRequest {
+			scimType = service.ErrInvalidSyntax
+		}
+		if appErr.Err != nil {
+			logger.ResponseError(c, status, string(appErr.Code), appErr.Message, appErr.Err)
+		}
+	default:
+		logger.ResponseError(c, status, string(appErrors.CodeInternal), detail, err)
+	}
+
+	body := gin.H{
+		"schemas": []string{service.ErrorSchema},
+		"status":  strconv.Itoa(status),
+		"detail":  detail,
+	}
+	if scimType != "" {
+		body["scimType"] = scimType
+	}
+	respond(c, status, body)
+}
@@ -0,0 +1,63 @@
+package socialhandler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	h "github.com/hanifsyahsn/go_boilerplate/internal/handler"
+	"github.com/hanifsyahsn/go_boilerplate/internal/service/authservice"
+	service "github.com/hanifsyahsn/go_boilerplate/internal/service/socialservice"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/cookie"
+	appErrors "github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+)
+
+type Handler struct {
+	socialService *service.Service
+}
+
+func NewHandler(service *service.Service) *Handler {
+	return &Handler{socialService: service}
+}
+
+func (handler *Handler) Login(c *gin.Context) {
+	redirectURL, state, err := handler.socialService.BeginService(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		h.HandleError(c, err)
+		return
+	}
+
+	cookie.ParseSocialState(c, state, int(service.StateDuration.Seconds()))
+
+	c.Redirect(http.StatusFound, redirectURL)
+}
+
+func (handler *Handler) Callback(c *gin.Context) {
+	if providerErr := c.Query("error"); providerErr != "" {
+		h.HandleError(c, appErrors.New(appErrors.CodeUnauthorized, "Login was cancelled at the provider", fmt.Errorf("provider error: %s", providerErr)))
+		return
+	}
+
+	state := c.Query("state")
+	stateCookie, err := c.Cookie(constant.SocialStateKey)
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(stateCookie)) != 1 {
+		h.HandleError(c, appErrors.New(appErrors.CodeUnauthorized, "Login session does not belong to this browser", errors.New("state cookie mismatch")))
+		return
+	}
+
+	user, accessToken, refreshToken, err := handler.socialService.CallbackService(c.Request.Context(), c.Param("provider"), state, c.Query("code"))
+	if err != nil {
+		h.HandleError(c, err)
+		return
+	}
+
+	cookie.RemoveSocialState(c)
+	cookie.ParseTokens(c, accessToken, refreshToken)
+
+	res := authservice.ToLoginResponse(user, accessToken, refreshToken)
+
+	c.JSON(http.StatusOK, res)
+}
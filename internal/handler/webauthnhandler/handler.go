@@ -0,0 +1,83 @@
+package webauthnhandler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	h "github.com/hanifsyahsn/go_boilerplate/internal/handler"
+	"github.com/hanifsyahsn/go_boilerplate/internal/service/authservice"
+	service "github.com/hanifsyahsn/go_boilerplate/internal/service/webauthnservice"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/cookie"
+	appErrors "github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+)
+
+type Handler struct {
+	webAuthnService *service.Service
+}
+
+func NewHandler(service *service.Service) *Handler {
+	return &Handler{webAuthnService: service}
+}
+
+func (handler *Handler) BeginRegistration(c *gin.Context) {
+	userId := c.GetInt64(constant.UserIdKey)
+
+	creation, err := handler.webAuthnService.BeginRegistrationService(c.Request.Context(), userId)
+	if err != nil {
+		h.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, creation)
+}
+
+func (handler *Handler) FinishRegistration(c *gin.Context) {
+	userId := c.GetInt64(constant.UserIdKey)
+
+	body, err := c.GetRawData()
+	if err != nil {
+		h.HandleError(c, appErrors.New(appErrors.CodeBadRequest, "Invalid request body", err))
+		return
+	}
+
+	credential, err := handler.webAuthnService.FinishRegistrationService(c.Request.Context(), userId, body)
+	if err != nil {
+		h.HandleError(c, err)
+		return
+	}
+
+	res := service.ToCredentialResponse(credential)
+
+	c.JSON(http.StatusCreated, res)
+}
+
+func (handler *Handler) BeginLogin(c *gin.Context) {
+	assertion, err := handler.webAuthnService.BeginLoginService(c.Request.Context())
+	if err != nil {
+		h.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, assertion)
+}
+
+func (handler *Handler) FinishLogin(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		h.HandleError(c, appErrors.New(appErrors.CodeBadRequest, "Invalid request body", err))
+		return
+	}
+
+	user, accessToken, refreshToken, err := handler.webAuthnService.FinishLoginService(c.Request.Context(), body)
+	if err != nil {
+		h.HandleError(c, err)
+		return
+	}
+
+	cookie.ParseTokens(c, accessToken, refreshToken)
+
+	res := authservice.ToLoginResponse(user, accessToken, refreshToken)
+
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"database/sql"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/device"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/logger"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/requestid"
+)
+
+// AuditMiddleware records every successful state changing request of an authenticated user in audit_logs, naming
+// the impersonating admin when the request was made with an impersonation token. It must run after
+// AccessAuthMiddleware. A failed write is logged and does not fail the already handled request.
+func AuditMiddleware(store db.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return
+		}
+		if c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+
+		userId := c.GetInt64(constant.UserIdKey)
+		if userId == 0 {
+			return
+		}
+
+		client := device.FromContext(c.Request.Context())
+		impersonatorId := c.GetInt64(constant.ImpersonatorIdKey)
+
+		_, err := store.CreateAuditLog(c.Request.Context(), sqlc.CreateAuditLogParams{
+			UserID:         userId,
+			ImpersonatorID: sql.NullInt64{Int64: impersonatorId, Valid: impersonatorId != 0},
+			Action:         c.Request.Method + " " + c.FullPath(),
+			IpAddress:      client.IPAddress,
+			UserAgent:      client.UserAgent,
+			RequestID:      requestid.FromContext(c.Request.Context()).RequestID,
+		})
+		if err != nil {
+			logger.FromGin(c).Error("failed to write audit log", slog.Any("err", err))
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package audit
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	requestIdMiddleware "github.com/hanifsyahsn/go_boilerplate/internal/middleware/requestid"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/requestid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditMiddleware(t *testing.T) {
+	testCases := []struct {
+		name           string
+		method         string
+		status         int
+		impersonatorId int64
+		buildStub      func(store *db.MockStore)
+	}{
+		{
+			name:   "records state changing request",
+			method: http.MethodPost,
+			status: http.StatusCreated,
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().CreateAuditLog(gomock.Any(), sqlc.CreateAuditLogParams{UserID: 1, Action: "POST /orgs/:orgId", RequestID: "req-1"}).Times(1).Return(sqlc.AuditLog{}, nil)
+			},
+		},
+		{
+			name:           "records impersonator",
+			method:         http.MethodDelete,
+			status:         http.StatusOK,
+			impersonatorId: 2,
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().CreateAuditLog(gomock.Any(), sqlc.CreateAuditLogParams{
+					UserID:         1,
+					ImpersonatorID: sql.NullInt64{Int64: 2, Valid: true},
+					Action:         "DELETE /orgs/:orgId",
+					RequestID:      "req-1",
+				}).Times(1).Return(sqlc.AuditLog{}, nil)
+			},
+		},
+		{
+			name:   "skips reads",
+			method: http.MethodGet,
+			status: http.StatusOK,
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:   "skips failed requests",
+			method: http.MethodPost,
+			status: http.StatusForbidden,
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:   "write failure does not change the response",
+			method: http.MethodPost,
+			status: http.StatusCreated,
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.AuditLog{}, sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := db.NewMockStore(ctrl)
+			testCase.buildStub(store)
+
+			router := gin.New()
+			router.Use(requestIdMiddleware.RequestIDMiddleware())
+			router.Handle(testCase.method, "/orgs/:orgId", func(c *gin.Context) {
+				c.Set(constant.UserIdKey, int64(1))
+				c.Set(constant.ImpersonatorIdKey, testCase.impersonatorId)
+				c.Next()
+			}, AuditMiddleware(store), func(c *gin.Context) {
+				c.Status(testCase.status)
+			})
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(testCase.method, "/orgs/1", nil)
+			require.NoError(t, err)
+			request.Header.Set(requestid.Header, "req-1")
+
+			router.ServeHTTP(recorder, request)
+			require.Equal(t, testCase.status, recorder.Code)
+		})
+	}
+}
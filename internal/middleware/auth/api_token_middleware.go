@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hanifsyahsn/go_boilerplate/internal/middleware"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
+)
+
+// APITokenMiddleware authenticates machine clients (e.g. a SCIM provisioning connector) with a static bearer token
+func APITokenMiddleware(apiToken string) gin.HandlerFunc {
+	expected := token.HashToken(apiToken)
+
+	return func(c *gin.Context) {
+		fields := strings.Fields(c.GetHeader(authorizationHeaderKey))
+		if len(fields) != 2 || !strings.EqualFold(fields[0], authorizationTypeBearer) {
+			c.Header("WWW-Authenticate", authorizationTypeBearer)
+			middleware.HandleError(c, errors.CodeUnauthorized, "Unauthorized", fmt.Errorf("invalid authorization header format"))
+			return
+		}
+
+		// Comparing hashes keeps the comparison constant time regardless of the presented token's length
+		if subtle.ConstantTimeCompare([]byte(token.HashToken(fields[1])), []byte(expected)) != 1 {
+			c.Header("WWW-Authenticate", authorizationTypeBearer+` error="invalid_token"`)
+			middleware.HandleError(c, errors.CodeUnauthorized, "Unauthorized", fmt.Errorf("invalid api token"))
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -2,8 +2,6 @@ package auth
 
 import (
 	"fmt"
-	"log"
-	"strconv"
 	"strings"
 
 	stderrors "errors"
@@ -12,9 +10,14 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/hanifsyahsn/go_boilerplate/internal/middleware"
 	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/dpop"
 	"github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/jticache"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/metrics"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/mtls"
 	"github.com/hanifsyahsn/go_boilerplate/internal/util/redis"
 	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
+	goRedis "github.com/redis/go-redis/v9"
 )
 
 const (
@@ -22,12 +25,13 @@ const (
 	authorizationTypeBearer = "Bearer"
 )
 
-func AccessAuthMiddleware(tokenMaker token.Maker, redis redis.Client) gin.HandlerFunc {
+// AccessAuthMiddleware accepts an access token only while it is the current token of its session in Redis. Recent
+// answers of Redis are kept in jtis, which also judges tokens while Redis is unavailable; without it every token is
+// checked against Redis and rejected during an outage.
+func AccessAuthMiddleware(tokenMaker token.Maker, redisClient redis.Client, jtis *jticache.Cache) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenString, err := c.Cookie(constant.AccessTokenKey)
 		if err != nil {
-			log.Printf("access token cookie warning: %v", err)
-
 			authHeader := c.GetHeader(authorizationHeaderKey)
 			if authHeader == "" {
 				middleware.HandleError(c, errors.CodeUnauthorized, "Unauthorized", fmt.Errorf("authorization is missing from the header"))
@@ -35,7 +39,7 @@ func AccessAuthMiddleware(tokenMaker token.Maker, redis redis.Client) gin.Handle
 			}
 
 			fields := strings.Fields(authHeader)
-			if len(fields) != 2 || fields[0] != authorizationTypeBearer {
+			if len(fields) != 2 || (fields[0] != authorizationTypeBearer && fields[0] != dpop.HeaderName) {
 				middleware.HandleError(c, errors.CodeUnauthorized, "Unauthorized", fmt.Errorf("invalid authorization header format"))
 				return
 			}
@@ -46,61 +50,93 @@ func AccessAuthMiddleware(tokenMaker token.Maker, redis redis.Client) gin.Handle
 		_, claims, err := tokenMaker.VerifyToken(tokenString)
 		if err != nil {
 			if stderrors.Is(err, jwt.ErrTokenExpired) {
+				metrics.TokenVerificationFailures.WithLabelValues(metrics.TokenExpired).Inc()
 				middleware.HandleError(c, errors.CodeTokenExpired, "Unauthorized", err)
 				return
 			}
-			middleware.HandleError(c, errors.CodeUnauthorized, "Unauthorized", err)
+			reject(c, verificationFailure(err), err)
 			return
 		}
 
 		jtiVal, ok := claims[constant.JsonWebTokenIdKey]
 		if !ok {
-			middleware.HandleError(c, errors.CodeUnauthorized, "Unauthorized", fmt.Errorf("%v is not found in payload", constant.JsonWebTokenIdKey))
+			reject(c, metrics.TokenMalformed, fmt.Errorf("%v is not found in payload", constant.JsonWebTokenIdKey))
 			return
 		}
 		jti, ok := jtiVal.(string)
 		if !ok || jti == "" {
-			middleware.HandleError(c, errors.CodeUnauthorized, "Unauthorized", fmt.Errorf("%v in payload is not a string", constant.JsonWebTokenIdKey))
+			reject(c, metrics.TokenMalformed, fmt.Errorf("%v in payload is not a string", constant.JsonWebTokenIdKey))
 			return
 		}
 
 		subVal, ok := claims[constant.SubKey]
 		if !ok {
-			middleware.HandleError(c, errors.CodeUnauthorized, "Unauthorized", fmt.Errorf("%v is not found in payload", constant.SubKey))
+			reject(c, metrics.TokenMalformed, fmt.Errorf("%v is not found in payload", constant.SubKey))
 			return
 		}
 		sub, ok := subVal.(float64)
 		if !ok || sub == 0 {
-			middleware.HandleError(c, errors.CodeUnauthorized, "Unauthorized", fmt.Errorf("%v in payload is not a float64", constant.SubKey))
+			reject(c, metrics.TokenMalformed, fmt.Errorf("%v in payload is not a float64", constant.SubKey))
 			return
 		}
 
 		emailVal, ok := claims[constant.EmailKey]
 		if !ok {
-			middleware.HandleError(c, errors.CodeUnauthorized, "Unauthorized", fmt.Errorf("%v is not found in payload", constant.EmailKey))
+			reject(c, metrics.TokenMalformed, fmt.Errorf("%v is not found in payload", constant.EmailKey))
 			return
 		}
 		email, ok := emailVal.(string)
 		if !ok || email == "" {
-			middleware.HandleError(c, errors.CodeUnauthorized, "Unauthorized", fmt.Errorf("%v in payload is not a string", constant.EmailKey))
+			reject(c, metrics.TokenMalformed, fmt.Errorf("%v in payload is not a string", constant.EmailKey))
 			return
 		}
 
-		userAccessKey := "user:access:" + strconv.Itoa(int(sub))
-		userJti, err := redis.Get(userAccessKey)
-		if err != nil {
-			middleware.HandleError(c, errors.CodeUnauthorized, "Unauthorized", err)
+		session := token.SessionFromClaims(claims)
+		if session.SessionId == "" {
+			reject(c, metrics.TokenMalformed, fmt.Errorf("%v is not found in payload", constant.SessionIdKey))
 			return
 		}
-		if userJti != jti {
-			middleware.HandleError(c, errors.CodeUnauthorized, "Unauthorized", fmt.Errorf("JSON web token ID in payload does not match with the stored one"))
+		if err := requireBoundProof(c, session.Jkt, tokenString); err != nil {
+			reject(c, metrics.TokenUnbound, err)
+			return
+		}
+		if err := requireBoundCertificate(c, session.X5t); err != nil {
+			reject(c, metrics.TokenUnbound, err)
 			return
 		}
 
+		accessKey := redis.UserAccessKey(int64(sub), session.SessionId)
+		if jtis == nil || !jtis.Lookup(accessKey, jti) {
+			userJti, err := redisClient.Get(c.Request.Context(), accessKey)
+			switch {
+			case err == nil:
+				if userJti != jti {
+					reject(c, metrics.TokenJTIMismatch, fmt.Errorf("JSON web token ID in payload does not match with the stored one"))
+					return
+				}
+				if jtis != nil {
+					if expiresAt, _ := claims.GetExpirationTime(); expiresAt != nil {
+						jtis.Remember(accessKey, jti, expiresAt.Time)
+					}
+				}
+			case stderrors.Is(err, goRedis.Nil):
+				// The session has ended, e.g. by a logout
+				reject(c, metrics.TokenJTIMismatch, err)
+				return
+			case jtis == nil || !jtis.Degraded(accessKey, jti):
+				reject(c, metrics.TokenUnverifiable, fmt.Errorf("JSON web token ID cannot be checked while redis is unavailable: %w", err))
+				return
+			}
+		}
+
 		c.Set(constant.UserIdKey, int64(sub))
 		c.Set(constant.JsonWebTokenIdKey, jti)
 		c.Set(constant.EmailKey, email)
 		c.Set(constant.AccessTokenKey, tokenString)
+		c.Set(constant.OrgIdKey, session.OrgId)
+		c.Set(constant.SessionIdKey, session.SessionId)
+		c.Set(constant.ImpersonatorIdKey, session.ActorId)
+		c.Set(constant.AuthTimeKey, session.AuthTime)
 
 		c.Next()
 	}
@@ -111,8 +147,6 @@ func RefreshAuthMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 		tokenString, err := c.Cookie(constant.RefreshTokenKey)
 		if err != nil {
 			if err != nil {
-				log.Printf("refresh token cookie warning: %v", err)
-
 				authHeader := c.GetHeader(authorizationHeaderKey)
 				if authHeader == "" {
 					middleware.HandleError(c, errors.CodeUnauthorized, "Unauthorized", fmt.Errorf("authorization is missing from the header"))
@@ -120,7 +154,7 @@ func RefreshAuthMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 				}
 
 				fields := strings.Fields(authHeader)
-				if len(fields) != 2 || fields[0] != authorizationTypeBearer {
+				if len(fields) != 2 || (fields[0] != authorizationTypeBearer && fields[0] != dpop.HeaderName) {
 					middleware.HandleError(c, errors.CodeUnauthorized, "Unauthorized", fmt.Errorf("invalid authorization header format"))
 					return
 				}
@@ -131,36 +165,109 @@ func RefreshAuthMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 
 		_, claims, err := tokenMaker.VerifyToken(tokenString)
 		if err != nil {
-			middleware.HandleError(c, errors.CodeUnauthorized, "Unauthorized", err)
+			reject(c, verificationFailure(err), err)
 			return
 		}
 
 		emailVal, ok := claims[constant.EmailKey]
 		if !ok {
-			middleware.HandleError(c, errors.CodeUnauthorized, "Unauthorized", fmt.Errorf("%v is not found in payload", constant.EmailKey))
+			reject(c, metrics.TokenMalformed, fmt.Errorf("%v is not found in payload", constant.EmailKey))
 			return
 		}
 		email, ok := emailVal.(string)
 		if !ok || email == "" {
-			middleware.HandleError(c, errors.CodeUnauthorized, "Unauthorized", fmt.Errorf("%v in payload is not a string", constant.EmailKey))
+			reject(c, metrics.TokenMalformed, fmt.Errorf("%v in payload is not a string", constant.EmailKey))
 			return
 		}
 
 		subVal, ok := claims[constant.SubKey]
 		if !ok {
-			middleware.HandleError(c, errors.CodeUnauthorized, "Unauthorized", fmt.Errorf("%v is not found in payload", constant.SubKey))
+			reject(c, metrics.TokenMalformed, fmt.Errorf("%v is not found in payload", constant.SubKey))
 			return
 		}
 		sub, ok := subVal.(float64)
 		if !ok || sub == 0 {
-			middleware.HandleError(c, errors.CodeUnauthorized, "Unauthorized", fmt.Errorf("%v in payload is not a float64", constant.SubKey))
+			reject(c, metrics.TokenMalformed, fmt.Errorf("%v in payload is not a float64", constant.SubKey))
 			return
 		}
 
 		c.Set(constant.UserIdKey, int64(sub))
 		c.Set(constant.EmailKey, email)
+		session := token.SessionFromClaims(claims)
+		if session.ActorId != 0 {
+			middleware.HandleError(c, errors.CodeUnauthorized, "Unauthorized", fmt.Errorf("impersonation tokens cannot be refreshed"))
+			return
+		}
+		if err := requireBoundProof(c, session.Jkt, ""); err != nil {
+			reject(c, metrics.TokenUnbound, err)
+			return
+		}
+		if err := requireBoundCertificate(c, session.X5t); err != nil {
+			reject(c, metrics.TokenUnbound, err)
+			return
+		}
+
 		c.Set(constant.RefreshTokenKey, tokenString)
+		c.Set(constant.OrgIdKey, session.OrgId)
+		c.Set(constant.SessionIdKey, session.SessionId)
+		c.Set(constant.AuthTimeKey, session.AuthTime)
 
 		c.Next()
 	}
 }
+
+// reject aborts an unauthorized request and counts its token in metrics.TokenVerificationFailures
+func reject(c *gin.Context, reason string, err error) {
+	metrics.TokenVerificationFailures.WithLabelValues(reason).Inc()
+	middleware.HandleError(c, errors.CodeUnauthorized, "Unauthorized", err)
+}
+
+// verificationFailure names the reason token.Maker.VerifyToken rejected a token
+func verificationFailure(err error) string {
+	switch {
+	case stderrors.Is(err, jwt.ErrTokenExpired):
+		return metrics.TokenExpired
+	case stderrors.Is(err, jwt.ErrTokenSignatureInvalid), stderrors.Is(err, jwt.ErrTokenUnverifiable):
+		return metrics.TokenBadSignature
+	default:
+		return metrics.TokenMalformed
+	}
+}
+
+// requireBoundProof checks that a token bound to a DPoP key (cnf.jkt) came with a proof signed by that key, verified
+// earlier by the DPoP middleware. Access tokens must also be hashed into the proof's ath claim, refresh tokens pass
+// an empty accessToken. Unbound tokens are plain bearer tokens and need no proof.
+func requireBoundProof(c *gin.Context, jkt, accessToken string) error {
+	if jkt == "" {
+		return nil
+	}
+
+	proof, ok := dpop.FromContext(c.Request.Context())
+	if !ok {
+		return fmt.Errorf("token is bound to a DPoP key but no proof was sent")
+	}
+	if proof.Thumbprint != jkt {
+		return fmt.Errorf("DPoP proof key does not match the token binding")
+	}
+	if accessToken != "" && proof.AccessTokenHash != dpop.AccessTokenHash(accessToken) {
+		return fmt.Errorf("DPoP proof ath does not match the access token")
+	}
+	return nil
+}
+
+// requireBoundCertificate checks that a token bound to a client certificate (cnf.x5t#S256) is presented over a
+// connection authenticated with that certificate, see RFC 8705
+func requireBoundCertificate(c *gin.Context, x5t string) error {
+	if x5t == "" {
+		return nil
+	}
+
+	cert, ok := mtls.FromContext(c.Request.Context())
+	if !ok {
+		return fmt.Errorf("token is bound to a client certificate but none was presented")
+	}
+	if mtls.Thumbprint(cert) != x5t {
+		return fmt.Errorf("client certificate does not match the token binding")
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"context"
+	"crypto/x509"
 	"fmt"
 	"math"
 	"net/http"
@@ -8,18 +10,31 @@ import (
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/golang/mock/gomock"
 	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
 	"github.com/hanifsyahsn/go_boilerplate/internal/factory/userfactory"
 	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/dpop"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/jticache"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/metrics"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/mtls"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/mtls/mtlstest"
 	"github.com/hanifsyahsn/go_boilerplate/internal/util/redis"
 	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
 	mockmaker "github.com/hanifsyahsn/go_boilerplate/internal/util/token/mock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	goRedis "github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/require"
 )
 
+const (
+	testSessionId  = "8b0b3c44-5c4f-4c43-9a0e-8a3a1f0d6a51"
+	testThumbprint = "0ZcOCORZNYy-DWpqq30jZyJGHTN0d2HglBV3uiguA4I"
+)
+
 func addAccessAuthorizationCookie(
 	t *testing.T,
 	request *http.Request,
@@ -33,7 +48,7 @@ func addAccessAuthorizationCookie(
 	} else {
 		dur = conf.AccessTokenDuration
 	}
-	accessToken, refreshToken, accessClaims, refreshClaims, err := tokenMaker.CreateToken(user, dur, conf.RefreshTokenDuration)
+	accessToken, refreshToken, accessClaims, refreshClaims, err := tokenMaker.CreateToken(context.Background(), user, token.Session{SessionId: testSessionId}, dur, conf.RefreshTokenDuration)
 	require.NoError(t, err)
 	require.NotEmpty(t, accessToken)
 	require.NotEmpty(t, refreshToken)
@@ -68,7 +83,7 @@ func addAccessAuthorizationHeader(
 	} else {
 		dur = conf.AccessTokenDuration
 	}
-	accessToken, refreshToken, accessClaims, refreshClaims, err := tokenMaker.CreateToken(user, dur, conf.RefreshTokenDuration)
+	accessToken, refreshToken, accessClaims, refreshClaims, err := tokenMaker.CreateToken(context.Background(), user, token.Session{SessionId: testSessionId}, dur, conf.RefreshTokenDuration)
 	require.NoError(t, err)
 	require.NotEmpty(t, accessToken)
 	require.NotEmpty(t, refreshToken)
@@ -81,6 +96,52 @@ func addAccessAuthorizationHeader(
 	return accessClaims
 }
 
+// addDPoPAuthorization sends an access token bound to testThumbprint with the DPoP scheme. Unless proofThumbprint
+// is empty, it also stores the proof the DPoP middleware would have verified, hashing ath over athToken.
+func addDPoPAuthorization(
+	t *testing.T,
+	request *http.Request,
+	tokenMaker token.Maker,
+	user sqlc.User,
+	proofThumbprint string,
+	athToken func(accessToken string) string,
+) jwt.MapClaims {
+	accessToken, _, accessClaims, _, err := tokenMaker.CreateToken(context.Background(), user, token.Session{SessionId: testSessionId, Jkt: testThumbprint}, conf.AccessTokenDuration, conf.RefreshTokenDuration)
+	require.NoError(t, err)
+
+	request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", dpop.HeaderName, accessToken))
+	if proofThumbprint != "" {
+		proof := dpop.Proof{Thumbprint: proofThumbprint, JTI: "proof-jti", AccessTokenHash: dpop.AccessTokenHash(athToken(accessToken))}
+		*request = *request.WithContext(dpop.WithProof(request.Context(), proof))
+	}
+
+	return accessClaims
+}
+
+// addCertificateBoundAuthorization sends an access token bound to the bound client certificate over a connection
+// authenticated with presented, or without a client certificate when it is nil
+func addCertificateBoundAuthorization(
+	t *testing.T,
+	request *http.Request,
+	tokenMaker token.Maker,
+	user sqlc.User,
+	bound, presented *x509.Certificate,
+) jwt.MapClaims {
+	accessToken, _, accessClaims, _, err := tokenMaker.CreateToken(context.Background(), user, token.Session{SessionId: testSessionId, X5t: mtls.Thumbprint(bound)}, conf.AccessTokenDuration, conf.RefreshTokenDuration)
+	require.NoError(t, err)
+
+	request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+	if presented != nil {
+		*request = *request.WithContext(mtls.WithCertificate(request.Context(), presented))
+	}
+
+	return accessClaims
+}
+
+func sameToken(accessToken string) string {
+	return accessToken
+}
+
 func TestAccessAuthMiddleware(t *testing.T) {
 	testCases := []struct {
 		name          string
@@ -96,15 +157,137 @@ func TestAccessAuthMiddleware(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker, user sqlc.User) jwt.MapClaims {
 				return addAccessAuthorizationCookie(t, request, tokenMaker, user, time.Duration(0))
 			},
-			buildStub: func(redis *redis.MockClient, accessClaims jwt.MapClaims) {
+			buildStub: func(mockRedis *redis.MockClient, accessClaims jwt.MapClaims) {
 				jti := accessClaims[constant.JsonWebTokenIdKey].(string)
-				redis.EXPECT().Get(gomock.Any()).Times(1).Return(jti, nil)
+				mockRedis.EXPECT().Get(gomock.Any(), redis.UserAccessKey(1, testSessionId)).Times(1).Return(jti, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 			useMockToken: false,
 		},
+		{
+			name: "Be able to accept a DPoP bound token with a matching proof",
+			user: userfactory.NewOptions(nil),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker, user sqlc.User) jwt.MapClaims {
+				return addDPoPAuthorization(t, request, tokenMaker, user, testThumbprint, sameToken)
+			},
+			buildStub: func(mockRedis *redis.MockClient, accessClaims jwt.MapClaims) {
+				jti := accessClaims[constant.JsonWebTokenIdKey].(string)
+				mockRedis.EXPECT().Get(gomock.Any(), redis.UserAccessKey(1, testSessionId)).Times(1).Return(jti, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+			useMockToken: false,
+		},
+		{
+			name: "Be able to throw an error when a DPoP bound token comes without a proof",
+			user: userfactory.NewOptions(nil),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker, user sqlc.User) jwt.MapClaims {
+				return addDPoPAuthorization(t, request, tokenMaker, user, "", sameToken)
+			},
+			buildStub: func(mockRedis *redis.MockClient, accessClaims jwt.MapClaims) {
+				mockRedis.EXPECT().Get(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+			useMockToken: false,
+		},
+		{
+			name: "Be able to throw an error when the DPoP proof is signed by another key",
+			user: userfactory.NewOptions(nil),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker, user sqlc.User) jwt.MapClaims {
+				return addDPoPAuthorization(t, request, tokenMaker, user, "another-thumbprint", sameToken)
+			},
+			buildStub: func(mockRedis *redis.MockClient, accessClaims jwt.MapClaims) {
+				mockRedis.EXPECT().Get(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+			useMockToken: false,
+		},
+		{
+			name: "Be able to throw an error when the DPoP proof ath does not match the token",
+			user: userfactory.NewOptions(nil),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker, user sqlc.User) jwt.MapClaims {
+				return addDPoPAuthorization(t, request, tokenMaker, user, testThumbprint, func(string) string { return "another-token" })
+			},
+			buildStub: func(mockRedis *redis.MockClient, accessClaims jwt.MapClaims) {
+				mockRedis.EXPECT().Get(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+			useMockToken: false,
+		},
+		{
+			name: "Be able to accept a certificate bound token over the bound mTLS connection",
+			user: userfactory.NewOptions(nil),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker, user sqlc.User) jwt.MapClaims {
+				cert := mtlstest.NewLeaf(t, mtlstest.NewCA(t, "test-ca"), "mobile-gateway").Cert
+				return addCertificateBoundAuthorization(t, request, tokenMaker, user, cert, cert)
+			},
+			buildStub: func(mockRedis *redis.MockClient, accessClaims jwt.MapClaims) {
+				jti := accessClaims[constant.JsonWebTokenIdKey].(string)
+				mockRedis.EXPECT().Get(gomock.Any(), redis.UserAccessKey(1, testSessionId)).Times(1).Return(jti, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+			useMockToken: false,
+		},
+		{
+			name: "Be able to throw an error when a certificate bound token comes without a client certificate",
+			user: userfactory.NewOptions(nil),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker, user sqlc.User) jwt.MapClaims {
+				cert := mtlstest.NewLeaf(t, mtlstest.NewCA(t, "test-ca"), "mobile-gateway").Cert
+				return addCertificateBoundAuthorization(t, request, tokenMaker, user, cert, nil)
+			},
+			buildStub: func(mockRedis *redis.MockClient, accessClaims jwt.MapClaims) {
+				mockRedis.EXPECT().Get(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+			useMockToken: false,
+		},
+		{
+			name: "Be able to throw an error when a certificate bound token comes with another client certificate",
+			user: userfactory.NewOptions(nil),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker, user sqlc.User) jwt.MapClaims {
+				ca := mtlstest.NewCA(t, "test-ca")
+				bound := mtlstest.NewLeaf(t, ca, "mobile-gateway").Cert
+				other := mtlstest.NewLeaf(t, ca, "mobile-gateway").Cert
+				return addCertificateBoundAuthorization(t, request, tokenMaker, user, bound, other)
+			},
+			buildStub: func(mockRedis *redis.MockClient, accessClaims jwt.MapClaims) {
+				mockRedis.EXPECT().Get(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+			useMockToken: false,
+		},
+		{
+			name: "Be able to throw an error when the session id is not found inside the token",
+			user: userfactory.NewOptions(nil),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker, user sqlc.User) jwt.MapClaims {
+				accessToken, _, accessClaims, _, err := tokenMaker.CreateToken(context.Background(), user, token.Session{}, conf.AccessTokenDuration, conf.RefreshTokenDuration)
+				require.NoError(t, err)
+				request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+				return accessClaims
+			},
+			buildStub: func(redis *redis.MockClient, accessClaims jwt.MapClaims) {
+				redis.EXPECT().Get(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+			useMockToken: false,
+		},
 		{
 			name: "No Token",
 			user: userfactory.NewOptions(nil),
@@ -139,7 +322,7 @@ func TestAccessAuthMiddleware(t *testing.T) {
 			},
 			buildStub: func(redis *redis.MockClient, accessClaims jwt.MapClaims) {
 				jti := accessClaims[constant.JsonWebTokenIdKey].(string)
-				redis.EXPECT().Get(gomock.Any()).Times(1).Return(jti, nil)
+				redis.EXPECT().Get(gomock.Any(), gomock.Any()).Times(1).Return(jti, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -350,7 +533,7 @@ func TestAccessAuthMiddleware(t *testing.T) {
 				return addAccessAuthorizationCookie(t, request, tokenMaker, user, time.Duration(0))
 			},
 			buildStub: func(redis *redis.MockClient, accessClaims jwt.MapClaims) {
-				redis.EXPECT().Get(gomock.Any()).Times(1).Return("", fmt.Errorf("failed to get data from redis"))
+				redis.EXPECT().Get(gomock.Any(), gomock.Any()).Times(1).Return("", fmt.Errorf("failed to get data from redis"))
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -364,7 +547,7 @@ func TestAccessAuthMiddleware(t *testing.T) {
 				return addAccessAuthorizationCookie(t, request, tokenMaker, user, time.Duration(0))
 			},
 			buildStub: func(redis *redis.MockClient, accessClaims jwt.MapClaims) {
-				redis.EXPECT().Get(gomock.Any()).Times(1).Return("jti1", nil)
+				redis.EXPECT().Get(gomock.Any(), gomock.Any()).Times(1).Return("jti1", nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -390,7 +573,7 @@ func TestAccessAuthMiddleware(t *testing.T) {
 
 			mockRedis := redis.NewMockClient(ctrl)
 
-			router.GET("/auth", AccessAuthMiddleware(tm, mockRedis), func(c *gin.Context) {
+			router.GET("/auth", AccessAuthMiddleware(tm, mockRedis, nil), func(c *gin.Context) {
 				c.JSON(http.StatusOK, gin.H{})
 			})
 
@@ -423,7 +606,7 @@ func addRefreshAuthorization(
 	} else {
 		dur = conf.RefreshTokenDuration
 	}
-	accessToken, refreshToken, accessClaims, refreshClaims, err := tokenMaker.CreateToken(user, conf.AccessTokenDuration, dur)
+	accessToken, refreshToken, accessClaims, refreshClaims, err := tokenMaker.CreateToken(context.Background(), user, token.Session{SessionId: testSessionId}, conf.AccessTokenDuration, dur)
 	require.NoError(t, err)
 	require.NotEmpty(t, accessToken)
 	require.NotEmpty(t, refreshToken)
@@ -442,6 +625,57 @@ func addRefreshAuthorization(
 	})
 }
 
+func TestAccessAuthMiddlewareFailureMetrics(t *testing.T) {
+	testCases := []struct {
+		reason    string
+		maker     token.Maker
+		duration  time.Duration
+		buildStub func(redis *redis.MockClient)
+	}{
+		{
+			reason:    metrics.TokenExpired,
+			maker:     tokenMaker,
+			duration:  -time.Minute,
+			buildStub: func(redis *redis.MockClient) {},
+		},
+		{
+			reason:    metrics.TokenBadSignature,
+			maker:     token.NewTokenMakerHS256("anotherSecretKey", conf.TokenIssuer),
+			buildStub: func(redis *redis.MockClient) {},
+		},
+		{
+			reason: metrics.TokenJTIMismatch,
+			maker:  tokenMaker,
+			buildStub: func(redis *redis.MockClient) {
+				redis.EXPECT().Get(gomock.Any(), gomock.Any()).Times(1).Return("jti1", nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.reason, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockRedis := redis.NewMockClient(ctrl)
+			tc.buildStub(mockRedis)
+
+			router := gin.New()
+			router.GET("/auth", AccessAuthMiddleware(tokenMaker, mockRedis, nil), func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{})
+			})
+
+			request, err := http.NewRequest(http.MethodGet, "/auth", nil)
+			require.NoError(t, err)
+			addAccessAuthorizationCookie(t, request, tc.maker, userfactory.NewOptions(nil), tc.duration)
+
+			failures := testutil.ToFloat64(metrics.TokenVerificationFailures.WithLabelValues(tc.reason))
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			require.Equal(t, failures+1, testutil.ToFloat64(metrics.TokenVerificationFailures.WithLabelValues(tc.reason)))
+		})
+	}
+}
+
 func TestRefreshAuthMiddleware(t *testing.T) {
 	testCases := []struct {
 		name          string
@@ -461,6 +695,46 @@ func TestRefreshAuthMiddleware(t *testing.T) {
 			},
 			useMockToken: false,
 		},
+		{
+			name: "Be able to throw an error when the token is an impersonation token",
+			user: userfactory.NewOptions(nil),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker, user sqlc.User) {
+				_, refreshToken, _, _, err := tokenMaker.CreateToken(context.Background(), user, token.Session{SessionId: testSessionId, ActorId: 2}, conf.AccessTokenDuration, conf.RefreshTokenDuration)
+				require.NoError(t, err)
+				request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, refreshToken))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+			useMockToken: false,
+		},
+		{
+			name: "Be able to throw an error when a DPoP bound token comes without a proof",
+			user: userfactory.NewOptions(nil),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker, user sqlc.User) {
+				_, refreshToken, _, _, err := tokenMaker.CreateToken(context.Background(), user, token.Session{SessionId: testSessionId, Jkt: testThumbprint}, conf.AccessTokenDuration, conf.RefreshTokenDuration)
+				require.NoError(t, err)
+				request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", dpop.HeaderName, refreshToken))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+			useMockToken: false,
+		},
+		{
+			name: "Be able to refresh a DPoP bound token with a matching proof",
+			user: userfactory.NewOptions(nil),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker, user sqlc.User) {
+				_, refreshToken, _, _, err := tokenMaker.CreateToken(context.Background(), user, token.Session{SessionId: testSessionId, Jkt: testThumbprint}, conf.AccessTokenDuration, conf.RefreshTokenDuration)
+				require.NoError(t, err)
+				request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", dpop.HeaderName, refreshToken))
+				*request = *request.WithContext(dpop.WithProof(request.Context(), dpop.Proof{Thumbprint: testThumbprint, JTI: "proof-jti"}))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+			useMockToken: false,
+		},
 		{
 			name: "No Token",
 			user: userfactory.NewOptions(nil),
@@ -623,3 +897,132 @@ func TestRefreshAuthMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestAccessAuthMiddlewareRedisOutage(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewCircuitBreaker(redis.NewRedisClient(goRedis.NewClient(&goRedis.Options{Addr: server.Addr(), MaxRetries: -1})), 2, time.Minute)
+	t.Cleanup(func() { client.Close() })
+
+	degraded := jticache.New(jticache.Options{Capacity: 100, FailOpenTTL: time.Minute})
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	subscribed := make(chan struct{}, 1)
+	go redis.ListenAccessInvalidations(ctx, client, degraded.Forget, func() {
+		degraded.Clear()
+		subscribed <- struct{}{}
+	})
+
+	failOpen := gin.New()
+	failOpen.GET("/auth", AccessAuthMiddleware(tokenMaker, client, degraded), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	failClosed := gin.New()
+	failClosed.GET("/auth", AccessAuthMiddleware(tokenMaker, client, nil), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	user := userfactory.NewOptions(nil)
+	newToken := func(sessionId string) (string, string) {
+		accessToken, _, accessClaims, _, err := tokenMaker.CreateToken(context.Background(), user, token.Session{SessionId: sessionId}, conf.AccessTokenDuration, conf.RefreshTokenDuration)
+		require.NoError(t, err)
+		return accessToken, accessClaims[constant.JsonWebTokenIdKey].(string)
+	}
+	serve := func(router *gin.Engine, accessToken string) int {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "/auth", nil)
+		require.NoError(t, err)
+		request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+		router.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	select {
+	case <-subscribed:
+	case <-time.After(time.Second):
+		t.Fatal("the instance did not subscribe to access invalidations")
+	}
+
+	current, jti := newToken(testSessionId)
+	require.NoError(t, server.Set(redis.UserAccessKey(user.ID, testSessionId), jti))
+	require.Equal(t, http.StatusOK, serve(failOpen, current))
+
+	// Another session is logged out just before Redis goes away
+	loggedOut, loggedOutJti := newToken("logged-out-session")
+	require.NoError(t, server.Set(redis.UserAccessKey(user.ID, "logged-out-session"), loggedOutJti))
+	require.Equal(t, http.StatusOK, serve(failOpen, loggedOut))
+	require.NoError(t, redis.DeleteUserAccess(ctx, client, user.ID, "logged-out-session"))
+	require.Eventually(t, func() bool { return degraded.Stats().Invalidations == 1 }, time.Second, 5*time.Millisecond)
+
+	// The session is refreshed just before Redis goes away
+	refreshed, refreshedJti := newToken(testSessionId)
+	require.NoError(t, server.Set(redis.UserAccessKey(user.ID, testSessionId), refreshedJti))
+	require.Equal(t, http.StatusOK, serve(failOpen, refreshed))
+	other, _ := newToken("other-session")
+
+	server.Close()
+
+	require.Equal(t, http.StatusUnauthorized, serve(failClosed, refreshed))
+	require.Equal(t, http.StatusOK, serve(failOpen, refreshed))
+	// The cache knows the previous token was replaced
+	require.Equal(t, http.StatusUnauthorized, serve(failOpen, current))
+	// The logged out token is not accepted again
+	require.Equal(t, http.StatusUnauthorized, serve(failOpen, loggedOut))
+	// Nor are the tokens this instance has not confirmed itself
+	require.Equal(t, http.StatusUnauthorized, serve(failOpen, other))
+
+	require.Equal(t, redis.BreakerOpen, client.Stats().State)
+	require.Equal(t, jticache.Stats{Entries: 2, Invalidations: 1, Accepted: 1, Rejected: 3}, degraded.Stats())
+}
+
+func TestAccessAuthMiddlewareJtiCache(t *testing.T) {
+	server := miniredis.RunT(t)
+	newClient := func() redis.Client {
+		client := redis.NewRedisClient(goRedis.NewClient(&goRedis.Options{Addr: server.Addr()}))
+		t.Cleanup(func() { client.Close() })
+		return client
+	}
+	client, otherInstance := newClient(), newClient()
+
+	jtis := jticache.New(jticache.Options{Capacity: 100, TTL: time.Minute})
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	subscribed := make(chan struct{}, 1)
+	go redis.ListenAccessInvalidations(ctx, client, jtis.Forget, func() {
+		jtis.Clear()
+		subscribed <- struct{}{}
+	})
+
+	router := gin.New()
+	router.GET("/auth", AccessAuthMiddleware(tokenMaker, client, jtis), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	user := userfactory.NewOptions(nil)
+	accessToken, _, accessClaims, _, err := tokenMaker.CreateToken(context.Background(), user, token.Session{SessionId: testSessionId}, conf.AccessTokenDuration, conf.RefreshTokenDuration)
+	require.NoError(t, err)
+	serve := func() int {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "/auth", nil)
+		require.NoError(t, err)
+		request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+		router.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	// Invalidations published before the instance subscribed are lost
+	select {
+	case <-subscribed:
+	case <-time.After(time.Second):
+		t.Fatal("the instance did not subscribe to access invalidations")
+	}
+
+	require.NoError(t, redis.SetUserAccess(ctx, client, user.ID, testSessionId, accessClaims[constant.JsonWebTokenIdKey].(string), time.Minute))
+	require.Equal(t, http.StatusOK, serve())
+	require.Equal(t, http.StatusOK, serve())
+	require.Equal(t, uint64(1), jtis.Stats().Hits)
+
+	// Another instance signs the session out
+	require.NoError(t, redis.DeleteUserAccess(ctx, otherInstance, user.ID, testSessionId))
+	require.Eventually(t, func() bool { return jtis.Stats().Invalidations == 1 }, time.Second, 5*time.Millisecond)
+	require.Equal(t, http.StatusUnauthorized, serve())
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/middleware"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+)
+
+// RequirePermissionMiddleware only lets active users whose role grants permission through, see the
+// role_permissions table. Like RequireRoleMiddleware it loads the user on every request and must run after
+// AccessAuthMiddleware.
+func RequirePermissionMiddleware(store db.Store, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := store.GetUserByID(c.Request.Context(), c.GetInt64(constant.UserIdKey))
+		if err != nil {
+			if stderrors.Is(err, sql.ErrNoRows) {
+				middleware.HandleError(c, errors.CodeUnauthorized, "Unauthorized", err)
+				return
+			}
+			middleware.HandleError(c, errors.CodeInternal, "Failed to get user", err)
+			return
+		}
+
+		if !user.Active {
+			middleware.HandleError(c, errors.CodeForbidden, "Insufficient permission", fmt.Errorf("user %d is deactivated", user.ID))
+			return
+		}
+
+		granted, err := store.HasRolePermission(c.Request.Context(), sqlc.HasRolePermissionParams{Name: user.Role, Permission: permission})
+		if err != nil {
+			middleware.HandleError(c, errors.CodeInternal, "Failed to get permissions", err)
+			return
+		}
+		if !granted {
+			middleware.HandleError(c, errors.CodeForbidden, "Insufficient permission", fmt.Errorf("role %q does not grant %q", user.Role, permission))
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/factory/userfactory"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequirePermissionMiddleware(t *testing.T) {
+	support := userfactory.NewOptions(&userfactory.Options{Role: "support"})
+	granted := sqlc.HasRolePermissionParams{Name: "support", Permission: constant.PermissionImpersonate}
+
+	testCases := []struct {
+		name       string
+		buildStub  func(store *db.MockStore)
+		wantStatus int
+	}{
+		{
+			name: "granted",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), int64(1)).Times(1).Return(support, nil)
+				store.EXPECT().HasRolePermission(gomock.Any(), granted).Times(1).Return(true, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "not granted",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), int64(1)).Times(1).Return(userfactory.NewOptions(nil), nil)
+				store.EXPECT().HasRolePermission(gomock.Any(), gomock.Any()).Times(1).Return(false, nil)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "deactivated user",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), int64(1)).Times(1).Return(userfactory.NewOptions(&userfactory.Options{Role: "support", Inactive: true}), nil)
+				store.EXPECT().HasRolePermission(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "deleted user",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), int64(1)).Times(1).Return(sqlc.User{}, sql.ErrNoRows)
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "database error loading the user",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), int64(1)).Times(1).Return(sqlc.User{}, sql.ErrConnDone)
+				store.EXPECT().HasRolePermission(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name: "database error loading the permissions",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), int64(1)).Times(1).Return(support, nil)
+				store.EXPECT().HasRolePermission(gomock.Any(), granted).Times(1).Return(false, sql.ErrConnDone)
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := db.NewMockStore(ctrl)
+			testCase.buildStub(store)
+
+			router := gin.New()
+			router.POST("/impersonate", func(c *gin.Context) {
+				c.Set(constant.UserIdKey, int64(1))
+				c.Next()
+			}, RequirePermissionMiddleware(store, constant.PermissionImpersonate), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, "/impersonate", nil)
+			require.NoError(t, err)
+
+			router.ServeHTTP(recorder, request)
+			require.Equal(t, testCase.wantStatus, recorder.Code)
+		})
+	}
+}
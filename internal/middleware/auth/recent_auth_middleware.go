@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hanifsyahsn/go_boilerplate/internal/middleware"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+)
+
+// RequireRecentAuth guards sensitive operations: the user must have entered their credentials within maxAge,
+// regardless of how long the access token itself lives. Otherwise it answers with CodeReauthenticationRequired so the
+// client can prompt for the password and call POST /auth/reauthenticate. Impersonation sessions are always
+// rejected. It must run after AccessAuthMiddleware.
+func RequireRecentAuth(maxAge time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetInt64(constant.ImpersonatorIdKey) != 0 {
+			middleware.HandleError(c, errors.CodeForbidden, "Not allowed while impersonating", fmt.Errorf("sensitive operation in an impersonation session"))
+			return
+		}
+
+		authTime := c.GetInt64(constant.AuthTimeKey)
+		if authTime == 0 {
+			middleware.HandleError(c, errors.CodeReauthenticationRequired, "Reauthentication required", fmt.Errorf("%v is not found in payload", constant.AuthTimeKey))
+			return
+		}
+
+		if age := time.Since(time.Unix(authTime, 0)); age > maxAge {
+			middleware.HandleError(c, errors.CodeReauthenticationRequired, "Reauthentication required", fmt.Errorf("last authentication was %v ago, max age is %v", age.Round(time.Second), maxAge))
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireRecentAuth(t *testing.T) {
+	testCases := []struct {
+		name           string
+		authTime       int64
+		impersonatorId int64
+		wantStatus     int
+		wantCode       errors.Code
+	}{
+		{
+			name:       "recent",
+			authTime:   time.Now().Add(-time.Minute).Unix(),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "stale",
+			authTime:   time.Now().Add(-time.Hour).Unix(),
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   errors.CodeReauthenticationRequired,
+		},
+		{
+			name:       "missing auth_time",
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   errors.CodeReauthenticationRequired,
+		},
+		{
+			name:           "impersonation",
+			authTime:       time.Now().Unix(),
+			impersonatorId: 2,
+			wantStatus:     http.StatusForbidden,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			router := gin.New()
+			router.POST("/sensitive", func(c *gin.Context) {
+				c.Set(constant.AuthTimeKey, testCase.authTime)
+				c.Set(constant.ImpersonatorIdKey, testCase.impersonatorId)
+				c.Next()
+			}, RequireRecentAuth(5*time.Minute), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, "/sensitive", nil)
+			require.NoError(t, err)
+
+			router.ServeHTTP(recorder, request)
+			require.Equal(t, testCase.wantStatus, recorder.Code)
+
+			if testCase.wantCode != "" {
+				var body struct {
+					Code errors.Code `json:"code"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				require.Equal(t, testCase.wantCode, body.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"fmt"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/middleware"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+)
+
+// RequireRoleMiddleware only lets active users holding one of roles through. Roles are not part of the access
+// token, so the user is loaded on every request and a demotion takes effect immediately. It must run after
+// AccessAuthMiddleware.
+func RequireRoleMiddleware(store db.Store, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := store.GetUserByID(c.Request.Context(), c.GetInt64(constant.UserIdKey))
+		if err != nil {
+			if stderrors.Is(err, sql.ErrNoRows) {
+				middleware.HandleError(c, errors.CodeUnauthorized, "Unauthorized", err)
+				return
+			}
+			middleware.HandleError(c, errors.CodeInternal, "Failed to get user", err)
+			return
+		}
+
+		if !user.Active || !slices.Contains(roles, user.Role) {
+			middleware.HandleError(c, errors.CodeForbidden, "Insufficient role", fmt.Errorf("role %q is not one of %v", user.Role, roles))
+			return
+		}
+
+		c.Next()
+	}
+}
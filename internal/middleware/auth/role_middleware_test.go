@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/factory/userfactory"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireRoleMiddleware(t *testing.T) {
+	testCases := []struct {
+		name       string
+		buildStub  func(store *db.MockStore)
+		wantStatus int
+	}{
+		{
+			name: "admin",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), int64(1)).Times(1).Return(userfactory.NewOptions(&userfactory.Options{Role: constant.AdminRole}), nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "regular user",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), int64(1)).Times(1).Return(userfactory.NewOptions(nil), nil)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "deactivated admin",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), int64(1)).Times(1).Return(userfactory.NewOptions(&userfactory.Options{Role: constant.AdminRole, Inactive: true}), nil)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "deleted user",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), int64(1)).Times(1).Return(sqlc.User{}, sql.ErrNoRows)
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "database error",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), int64(1)).Times(1).Return(sqlc.User{}, sql.ErrConnDone)
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := db.NewMockStore(ctrl)
+			testCase.buildStub(store)
+
+			router := gin.New()
+			router.GET("/admin", func(c *gin.Context) {
+				c.Set(constant.UserIdKey, int64(1))
+				c.Next()
+			}, RequireRoleMiddleware(store, constant.AdminRole), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/admin", nil)
+			require.NoError(t, err)
+
+			router.ServeHTTP(recorder, request)
+			require.Equal(t, testCase.wantStatus, recorder.Code)
+		})
+	}
+}
@@ -5,14 +5,15 @@ import (
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/requestid"
 )
 
 func CORSMiddleware() gin.HandlerFunc {
 	config := cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "DPoP", requestid.Header, requestid.TraceparentHeader},
+		ExposeHeaders:    []string{"Content-Length", "WWW-Authenticate", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After", requestid.Header, requestid.TraceparentHeader},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}
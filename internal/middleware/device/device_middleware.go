@@ -0,0 +1,17 @@
+package device
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/device"
+)
+
+// DeviceMiddleware stores the client IP and User-Agent in the request context so the services can record them
+// on the sessions they start, see device.FromContext
+func DeviceMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		info := device.NewInfo(c.ClientIP(), c.Request.UserAgent())
+		c.Request = c.Request.WithContext(device.WithInfo(c.Request.Context(), info))
+
+		c.Next()
+	}
+}
@@ -0,0 +1,67 @@
+package dpop
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hanifsyahsn/go_boilerplate/internal/middleware"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/dpop"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/logger"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/redis"
+)
+
+const authenticateHeaderKey = "WWW-Authenticate"
+
+// DPoPMiddleware verifies the DPoP proof of requests that carry one and stores it in the request context, see
+// dpop.FromContext. The token endpoints bind the tokens they issue to the proof key and the auth middlewares
+// require a matching proof for bound tokens. Requests without the header pass through untouched so plain bearer
+// clients keep working. With failOpen, see REDIS_FAILURE_MODE, proofs are accepted without the replay check while
+// Redis is unavailable; they are still bound to the method, URL and a maxAge window.
+func DPoPMiddleware(redisClient redis.Client, publicURL string, maxAge time.Duration, failOpen bool) gin.HandlerFunc {
+	baseURL := strings.TrimSuffix(publicURL, "/")
+	return func(c *gin.Context) {
+		values := c.Request.Header.Values(dpop.HeaderName)
+		if len(values) == 0 {
+			c.Next()
+			return
+		}
+		if len(values) > 1 {
+			abortInvalidProof(c, fmt.Errorf("multiple DPoP headers"))
+			return
+		}
+
+		proof, err := dpop.Verify(values[0], c.Request.Method, baseURL+c.Request.URL.Path, time.Now(), maxAge)
+		if err != nil {
+			abortInvalidProof(c, err)
+			return
+		}
+
+		// A proof is accepted until its iat falls out of the window on either side, so remember the jti that long
+		fresh, err := redisClient.SetNX(c.Request.Context(), redis.DPoPProofKey(proof.Thumbprint, proof.JTI), 1, 2*maxAge)
+		if err != nil {
+			if !failOpen {
+				abortInvalidProof(c, err)
+				return
+			}
+			logger.FromGin(c).Warn("DPoP proof accepted without replay check, redis is unavailable", slog.Any("err", err))
+			fresh = true
+		}
+		if !fresh {
+			abortInvalidProof(c, fmt.Errorf("DPoP proof %v has already been used", proof.JTI))
+			return
+		}
+
+		c.Request = c.Request.WithContext(dpop.WithProof(c.Request.Context(), proof))
+
+		c.Next()
+	}
+}
+
+func abortInvalidProof(c *gin.Context, err error) {
+	c.Header(authenticateHeaderKey, `DPoP error="invalid_dpop_proof", algs="ES256"`)
+	middleware.HandleError(c, errors.CodeUnauthorized, "Invalid DPoP proof", err)
+}
@@ -0,0 +1,169 @@
+package dpop
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/dpop"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/redis"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
+	"github.com/stretchr/testify/require"
+)
+
+const testPublicURL = "https://api.example.com/"
+
+func signProof(t *testing.T, key *ecdsa.PrivateKey, method, uri string) (string, string) {
+	jti := uuid.NewString()
+	proof := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{"htm": method, "htu": uri, "iat": time.Now().Unix(), "jti": jti})
+	proof.Header["typ"] = dpop.ProofType
+	proof.Header["jwk"] = token.ECPublicJWK(&key.PublicKey)
+	signed, err := proof.SignedString(key)
+	require.NoError(t, err)
+	return signed, jti
+}
+
+func TestDPoPMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	thumbprint := token.ECThumbprint(&key.PublicKey)
+
+	testCases := []struct {
+		name          string
+		failOpen      bool
+		setupProof    func(t *testing.T, request *http.Request, mockRedis *redis.MockClient)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupProof: func(t *testing.T, request *http.Request, mockRedis *redis.MockClient) {
+				proof, jti := signProof(t, key, http.MethodPost, "https://api.example.com/auth/login")
+				request.Header.Set(dpop.HeaderName, proof)
+				mockRedis.EXPECT().SetNX(gomock.Any(), redis.DPoPProofKey(thumbprint, jti), 1, 2*time.Minute).Times(1).Return(true, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), thumbprint)
+			},
+		},
+		{
+			name: "Be able to pass requests without a proof",
+			setupProof: func(t *testing.T, request *http.Request, mockRedis *redis.MockClient) {
+				mockRedis.EXPECT().SetNX(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.JSONEq(t, `{"jkt":""}`, recorder.Body.String())
+			},
+		},
+		{
+			name: "Be able to throw an error when the proof was made for another endpoint",
+			setupProof: func(t *testing.T, request *http.Request, mockRedis *redis.MockClient) {
+				proof, _ := signProof(t, key, http.MethodPost, "https://api.example.com/auth/register")
+				request.Header.Set(dpop.HeaderName, proof)
+				mockRedis.EXPECT().SetNX(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Contains(t, recorder.Header().Get(authenticateHeaderKey), "invalid_dpop_proof")
+			},
+		},
+		{
+			name: "Be able to throw an error when the proof is replayed",
+			setupProof: func(t *testing.T, request *http.Request, mockRedis *redis.MockClient) {
+				proof, jti := signProof(t, key, http.MethodPost, "https://api.example.com/auth/login")
+				request.Header.Set(dpop.HeaderName, proof)
+				mockRedis.EXPECT().SetNX(gomock.Any(), redis.DPoPProofKey(thumbprint, jti), 1, 2*time.Minute).Times(1).Return(false, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Contains(t, recorder.Header().Get(authenticateHeaderKey), "invalid_dpop_proof")
+			},
+		},
+		{
+			name: "Be able to throw an error when the replay cache is unavailable",
+			setupProof: func(t *testing.T, request *http.Request, mockRedis *redis.MockClient) {
+				proof, _ := signProof(t, key, http.MethodPost, "https://api.example.com/auth/login")
+				request.Header.Set(dpop.HeaderName, proof)
+				mockRedis.EXPECT().SetNX(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(false, fmt.Errorf("connection refused"))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "Be able to accept the proof when the replay cache is unavailable in fail-open mode",
+			failOpen: true,
+			setupProof: func(t *testing.T, request *http.Request, mockRedis *redis.MockClient) {
+				proof, _ := signProof(t, key, http.MethodPost, "https://api.example.com/auth/login")
+				request.Header.Set(dpop.HeaderName, proof)
+				mockRedis.EXPECT().SetNX(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(false, fmt.Errorf("connection refused"))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), thumbprint)
+			},
+		},
+		{
+			name:     "Be able to throw an error when the proof is replayed in fail-open mode",
+			failOpen: true,
+			setupProof: func(t *testing.T, request *http.Request, mockRedis *redis.MockClient) {
+				proof, _ := signProof(t, key, http.MethodPost, "https://api.example.com/auth/login")
+				request.Header.Set(dpop.HeaderName, proof)
+				mockRedis.EXPECT().SetNX(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(false, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Be able to throw an error when several proofs are sent",
+			setupProof: func(t *testing.T, request *http.Request, mockRedis *redis.MockClient) {
+				first, _ := signProof(t, key, http.MethodPost, "https://api.example.com/auth/login")
+				second, _ := signProof(t, key, http.MethodPost, "https://api.example.com/auth/login")
+				request.Header.Add(dpop.HeaderName, first)
+				request.Header.Add(dpop.HeaderName, second)
+				mockRedis.EXPECT().SetNX(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRedis := redis.NewMockClient(ctrl)
+
+			router := gin.New()
+			router.POST("/auth/login", DPoPMiddleware(mockRedis, testPublicURL, time.Minute, tc.failOpen), func(c *gin.Context) {
+				proof, _ := dpop.FromContext(c.Request.Context())
+				c.JSON(http.StatusOK, gin.H{"jkt": proof.Thumbprint})
+			})
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, "/auth/login", nil)
+			require.NoError(t, err)
+
+			tc.setupProof(t, request, mockRedis)
+
+			router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -1,11 +1,12 @@
 package middleware
 
 import (
-	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
 	"github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/logger"
 )
 
 func HandleError(c *gin.Context, code errors.Code, message string, err error) {
@@ -13,19 +14,27 @@ func HandleError(c *gin.Context, code errors.Code, message string, err error) {
 		return
 	}
 
-	log.Printf(
-		"[%s] %s | path=%s | err=%v",
-		code,
-		message,
-		c.Request.URL.Path,
-		err,
-	)
-
-	if code == errors.CodeTokenExpired {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": message, "code": code})
+	if code == errors.CodeTokenExpired || code == errors.CodeReauthenticationRequired {
+		logger.ResponseError(c, http.StatusUnauthorized, string(code), message, err)
+		body := util.MessageResponse(c, message)
+		body["code"] = code
+		c.AbortWithStatusJSON(http.StatusUnauthorized, body)
+		return
+	} else if code == errors.CodeForbidden {
+		logger.ResponseError(c, http.StatusForbidden, string(code), message, err)
+		c.AbortWithStatusJSON(http.StatusForbidden, util.MessageResponse(c, message))
+		return
+	} else if code == errors.CodeTooManyRequests {
+		logger.ResponseError(c, http.StatusTooManyRequests, string(code), message, err)
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, util.MessageResponse(c, message))
+		return
+	} else if code == errors.CodeInternal {
+		logger.ResponseError(c, http.StatusInternalServerError, string(code), message, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, util.MessageResponse(c, message))
 		return
 	} else {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": message})
+		logger.ResponseError(c, http.StatusUnauthorized, string(code), message, err)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, util.MessageResponse(c, message))
 		return
 	}
 
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		code       errors.Code
+		wantStatus int
+	}{
+		{code: errors.CodeUnauthorized, wantStatus: http.StatusUnauthorized},
+		{code: errors.CodeTokenExpired, wantStatus: http.StatusUnauthorized},
+		{code: errors.CodeReauthenticationRequired, wantStatus: http.StatusUnauthorized},
+		{code: errors.CodeForbidden, wantStatus: http.StatusForbidden},
+		{code: errors.CodeTooManyRequests, wantStatus: http.StatusTooManyRequests},
+		{code: errors.CodeInternal, wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(string(testCase.code), func(t *testing.T) {
+			router := gin.New()
+			router.GET("/", func(c *gin.Context) {
+				HandleError(c, testCase.code, "Message", fmt.Errorf("cause"))
+			})
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/", nil)
+			require.NoError(t, err)
+
+			router.ServeHTTP(recorder, request)
+			require.Equal(t, testCase.wantStatus, recorder.Code)
+		})
+	}
+}
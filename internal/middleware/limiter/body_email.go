@@ -0,0 +1,31 @@
+package limiter
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Only this much of the body is inspected, the rest is passed through to the handler untouched
+const maxEmailBodySize = 1 << 16
+
+// bodyEmail reads the normalized email of a JSON body without consuming it, so endpoints acting on an address
+// (e.g. sending mail) can be limited per inbox rather than per IP. It is empty when the body has no email.
+func bodyEmail(c *gin.Context) (string, error) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxEmailBodySize))
+	if err != nil {
+		return "", err
+	}
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err = json.Unmarshal(body, &req); err != nil {
+		return "", nil
+	}
+	return strings.ToLower(strings.TrimSpace(req.Email)), nil
+}
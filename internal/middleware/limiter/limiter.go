@@ -0,0 +1,134 @@
+package limiter
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hanifsyahsn/go_boilerplate/internal/middleware"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/logger"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/metrics"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/ratelimit"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
+)
+
+// Limiter builds rate limit middlewares from the configured policies, see ratelimit.ParsePolicies
+type Limiter struct {
+	backend   ratelimit.Limiter
+	policies  map[string]ratelimit.Policy
+	allowlist []netip.Prefix
+}
+
+// New returns a Limiter counting requests in backend. Clients whose IP is in allowlist, e.g. internal services,
+// are never limited.
+func New(backend ratelimit.Limiter, policies map[string]ratelimit.Policy, allowlist []netip.Prefix) *Limiter {
+	return &Limiter{backend: backend, policies: policies, allowlist: allowlist}
+}
+
+// Middleware enforces the named policy. An unknown name is a configuration mistake and stops the server at
+// startup rather than leaving a route unprotected.
+func (limiter *Limiter) Middleware(policyName string) gin.HandlerFunc {
+	policy, ok := limiter.policies[policyName]
+	if !ok {
+		logger.Fatal("Rate limit policy is not configured in RATE_LIMIT_POLICIES", slog.String("policy", policyName))
+	}
+
+	return func(c *gin.Context) {
+		if !policy.Applies(c.Request.Method, c.FullPath()) || limiter.allowlisted(c.ClientIP()) {
+			c.Next()
+			return
+		}
+
+		key, ok := limiter.key(c, policy)
+		if !ok {
+			return
+		}
+		if key != "" && !limiter.allow(c, policy.Name+":"+key, policy.Limit) {
+			metrics.RateLimitRejections.WithLabelValues(policy.Name).Inc()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// key identifies the client the request is counted against. An empty key skips the limit; false means the request
+// has already been aborted.
+func (limiter *Limiter) key(c *gin.Context, policy ratelimit.Policy) (string, bool) {
+	switch policy.Key {
+	case ratelimit.KeyUser:
+		userId, exists := c.Get(constant.UserIdKey)
+		if !exists {
+			middleware.HandleError(c, errors.CodeUnauthorized, "Unauthorized", fmt.Errorf("user id is not found in context"))
+			return "", false
+		}
+		return fmt.Sprintf("user:%v", userId), true
+	case ratelimit.KeyAPIKey:
+		// Unauthenticated requests are counted per IP so guessing keys is limited as well
+		fields := strings.Fields(c.GetHeader("Authorization"))
+		if len(fields) != 2 {
+			return "ip:" + c.ClientIP(), true
+		}
+		return "key:" + token.HashToken(fields[1]), true
+	case ratelimit.KeyBodyEmail:
+		email, err := bodyEmail(c)
+		if err != nil {
+			middleware.HandleError(c, errors.CodeBadRequest, "Invalid request body", err)
+			return "", false
+		}
+		if email == "" {
+			return "", true
+		}
+		return "email:" + email, true
+	default:
+		return "ip:" + c.ClientIP(), true
+	}
+}
+
+func (limiter *Limiter) allowlisted(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range limiter.allowlist {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// allow counts the request against key and reports the outcome in the RateLimit-* headers
+// (draft-ietf-httpapi-ratelimit-headers). It aborts with 429 and Retry-After once the limit is exceeded.
+func (limiter *Limiter) allow(c *gin.Context, key string, limit ratelimit.Limit) bool {
+	result, err := limiter.backend.Allow(c.Request.Context(), key, limit)
+	if err != nil {
+		// Failing open keeps the service usable while the limiter backend is unavailable
+		logger.FromGin(c).Error("rate limiter unavailable", slog.Any("err", err))
+		return true
+	}
+
+	c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("RateLimit-Reset", seconds(result.ResetAfter))
+
+	if !result.Allowed {
+		c.Header("Retry-After", seconds(result.RetryAfter))
+		middleware.HandleError(c, errors.CodeTooManyRequests, "Too many requests", fmt.Errorf("rate limit exceeded for %v", key))
+		return false
+	}
+	return true
+}
+
+// seconds rounds up, so clients retrying after the advertised delay are never early
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
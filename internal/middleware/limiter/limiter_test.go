@@ -0,0 +1,181 @@
+package limiter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/metrics"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/ratelimit"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/redis"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	goRedis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicies = "auth=ip,5/1m,5;login=body_email,1/5m,3,POST /login;user=user,1/1s,2;scim=api_key,1/1m,2"
+
+func newRedisLimiter(t *testing.T) (ratelimit.Limiter, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	server.SetTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	client := goRedis.NewClient(&goRedis.Options{Addr: server.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+
+	return ratelimit.NewRedisLimiter(redis.NewRedisClient(client)), server
+}
+
+func newLimiter(t *testing.T, backend ratelimit.Limiter, allowlist string) *Limiter {
+	policies, err := ratelimit.ParsePolicies(testPolicies)
+	require.NoError(t, err)
+	prefixes, err := ratelimit.ParsePrefixes(allowlist)
+	require.NoError(t, err)
+	return New(backend, policies, prefixes)
+}
+
+func ok(c *gin.Context) {
+	c.Status(http.StatusOK)
+}
+
+func serve(router *gin.Engine, method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest(method, path, strings.NewReader(body))
+	request.RemoteAddr = "10.0.0.1:1234"
+	for i := 0; i+1 < len(headers); i += 2 {
+		request.Header.Set(headers[i], headers[i+1])
+	}
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestIPPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	backend, _ := newRedisLimiter(t)
+
+	router := gin.New()
+	router.GET("/", newLimiter(t, backend, "").Middleware("auth"), ok)
+
+	for i := 4; i >= 0; i-- {
+		recorder := serve(router, http.MethodGet, "/", "")
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.Equal(t, "5", recorder.Header().Get("RateLimit-Limit"))
+		require.Equal(t, strconv.Itoa(i), recorder.Header().Get("RateLimit-Remaining"))
+	}
+
+	rejections := testutil.ToFloat64(metrics.RateLimitRejections.WithLabelValues("auth"))
+	recorder := serve(router, http.MethodGet, "/", "")
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	require.Equal(t, rejections+1, testutil.ToFloat64(metrics.RateLimitRejections.WithLabelValues("auth")))
+	require.Equal(t, "0", recorder.Header().Get("RateLimit-Remaining"))
+	require.Equal(t, "12", recorder.Header().Get("Retry-After"))
+	require.Equal(t, "60", recorder.Header().Get("RateLimit-Reset"))
+}
+
+func TestBodyEmailPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	backend, _ := newRedisLimiter(t)
+
+	router := gin.New()
+	group := router.Group("/", newLimiter(t, backend, "").Middleware("login"))
+	group.POST("/login", func(c *gin.Context) {
+		var req struct {
+			Email string `json:"email"`
+		}
+		require.NoError(t, c.ShouldBindJSON(&req))
+		c.Status(http.StatusOK)
+	})
+	group.POST("/register", ok)
+
+	for i := 0; i < 3; i++ {
+		require.Equal(t, http.StatusOK, serve(router, http.MethodPost, "/login", `{"email":"User@example.com"}`).Code)
+	}
+	// The address is normalized, so changing its case does not reset the budget
+	recorder := serve(router, http.MethodPost, "/login", `{"email":" user@EXAMPLE.com"}`)
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	require.Equal(t, "300", recorder.Header().Get("Retry-After"))
+
+	require.Equal(t, http.StatusOK, serve(router, http.MethodPost, "/login", `{"email":"other@example.com"}`).Code)
+
+	// The policy is scoped to POST /login
+	recorder = serve(router, http.MethodPost, "/register", `{"email":"user@example.com"}`)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Empty(t, recorder.Header().Get("RateLimit-Limit"))
+
+	// Requests without an email are not limited by it
+	recorder = serve(router, http.MethodPost, "/login", `{}`)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Empty(t, recorder.Header().Get("RateLimit-Limit"))
+}
+
+func TestUserPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	backend, _ := newRedisLimiter(t)
+	limiter := newLimiter(t, backend, "")
+
+	router := gin.New()
+	router.GET("/anonymous", limiter.Middleware("user"), ok)
+	router.GET("/:id", func(c *gin.Context) {
+		id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+		c.Set(constant.UserIdKey, id)
+	}, limiter.Middleware("user"), ok)
+
+	require.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/1", "").Code)
+	require.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/1", "").Code)
+	require.Equal(t, http.StatusTooManyRequests, serve(router, http.MethodGet, "/1", "").Code)
+	// Users sharing an IP have their own budget
+	require.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/2", "").Code)
+
+	require.Equal(t, http.StatusUnauthorized, serve(router, http.MethodGet, "/anonymous", "").Code)
+}
+
+func TestAPIKeyPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	backend, _ := newRedisLimiter(t)
+
+	router := gin.New()
+	router.GET("/", newLimiter(t, backend, "").Middleware("scim"), ok)
+
+	for i := 0; i < 2; i++ {
+		require.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/", "", "Authorization", "Bearer first").Code)
+	}
+	require.Equal(t, http.StatusTooManyRequests, serve(router, http.MethodGet, "/", "", "Authorization", "Bearer first").Code)
+	require.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/", "", "Authorization", "Bearer second").Code)
+
+	// Requests without a key share the budget of their IP
+	require.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/", "").Code)
+	require.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/", "").Code)
+	require.Equal(t, http.StatusTooManyRequests, serve(router, http.MethodGet, "/", "").Code)
+}
+
+func TestAllowlist(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	backend, _ := newRedisLimiter(t)
+
+	router := gin.New()
+	router.GET("/", newLimiter(t, backend, "10.0.0.0/8").Middleware("auth"), ok)
+
+	for i := 0; i < 10; i++ {
+		recorder := serve(router, http.MethodGet, "/", "")
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.Empty(t, recorder.Header().Get("RateLimit-Limit"))
+	}
+}
+
+func TestRateLimitFailsOpen(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	backend, server := newRedisLimiter(t)
+	server.Close()
+
+	router := gin.New()
+	router.GET("/", newLimiter(t, backend, "").Middleware("auth"), ok)
+
+	recorder := serve(router, http.MethodGet, "/", "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Empty(t, recorder.Header().Get("RateLimit-Limit"))
+}
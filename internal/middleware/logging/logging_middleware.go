@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/logger"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/requestid"
+)
+
+// LoggingMiddleware stores a logger carrying the request and trace IDs, route and client IP in the request context,
+// see logger.FromGin, and logs every request once it has been handled. It replaces the logger of gin.Default and
+// must run after RequestIDMiddleware.
+func LoggingMiddleware(base *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		ids := requestid.FromContext(c.Request.Context())
+		requestLogger := base.With(
+			slog.String(constant.RequestIdKey, ids.RequestID),
+			slog.String("trace_id", ids.TraceID),
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+			slog.String("client_ip", c.ClientIP()),
+		)
+		c.Request = c.Request.WithContext(logger.WithLogger(c.Request.Context(), requestLogger))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		} else if status >= http.StatusBadRequest {
+			level = slog.LevelWarn
+		}
+
+		// The query string is left out, it may carry tokens such as magic links
+		logger.FromGin(c).LogAttrs(c.Request.Context(), level, "request",
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.Int("size", c.Writer.Size()),
+		)
+	}
+}
+
+// RecoveryMiddleware turns a panic into a 500 response and logs it with its stack. It must run after
+// LoggingMiddleware so the panic is logged with the request.
+func RecoveryMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logger.FromGin(c).Error("panic recovered",
+					slog.Any("panic", recovered),
+					slog.String("stack", string(debug.Stack())),
+				)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, util.MessageResponse(c, "Unexpected error"))
+			}
+		}()
+
+		c.Next()
+	}
+}
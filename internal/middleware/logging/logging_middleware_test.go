@@ -0,0 +1,79 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hanifsyahsn/go_boilerplate/internal/config"
+	requestIdMiddleware "github.com/hanifsyahsn/go_boilerplate/internal/middleware/requestid"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/logger"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/requestid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggingMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buffer bytes.Buffer
+	router := gin.New()
+	router.Use(requestIdMiddleware.RequestIDMiddleware(), LoggingMiddleware(logger.New(&buffer, config.Config{ENV: "production", LogLevel: "debug"})), RecoveryMiddleware())
+	router.GET("/users/:id", func(c *gin.Context) {
+		c.Set(constant.UserIdKey, int64(7))
+		logger.FromGin(c).Debug("handling")
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/users/7?token=secret", nil)
+	request.RemoteAddr = "10.0.0.1:1234"
+	request.Header.Set(requestid.Header, "req-1")
+	request.Header.Set(requestid.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+
+	entries := decode(t, &buffer)
+	require.Len(t, entries, 2)
+	require.Equal(t, "handling", entries[0]["msg"])
+	require.Equal(t, "request", entries[1]["msg"])
+	require.Equal(t, "INFO", entries[1]["level"])
+	require.Equal(t, "/users/:id", entries[1]["route"])
+	require.Equal(t, "/users/7", entries[1]["path"])
+	require.Equal(t, "10.0.0.1", entries[1]["client_ip"])
+	require.Equal(t, float64(7), entries[1][constant.UserIdKey])
+	require.Equal(t, float64(http.StatusNoContent), entries[1]["status"])
+	require.Equal(t, "req-1", entries[0]["request_id"])
+	require.Equal(t, "req-1", entries[1]["request_id"])
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", entries[1]["trace_id"])
+	require.NotContains(t, buffer.String(), "secret")
+
+	buffer.Reset()
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+	require.JSONEq(t, `{"message": "Unexpected error", "request_id": "`+recorder.Header().Get(requestid.Header)+`"}`, recorder.Body.String())
+
+	entries = decode(t, &buffer)
+	require.Len(t, entries, 2)
+	require.Equal(t, "panic recovered", entries[0]["msg"])
+	require.Equal(t, "boom", entries[0]["panic"])
+	require.Equal(t, "ERROR", entries[1]["level"])
+}
+
+func decode(t *testing.T, buffer *bytes.Buffer) []map[string]interface{} {
+	var entries []map[string]interface{}
+	decoder := json.NewDecoder(buffer)
+	for decoder.More() {
+		var entry map[string]interface{}
+		require.NoError(t, decoder.Decode(&entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
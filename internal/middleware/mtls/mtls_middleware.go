@@ -0,0 +1,42 @@
+package mtls
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hanifsyahsn/go_boilerplate/internal/middleware"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/mtls"
+)
+
+// ClientCertificateMiddleware stores the verified client certificate of mTLS connections in the request context,
+// see mtls.FromContext. The token endpoints bind the tokens they issue to it and the auth middlewares require it
+// for bound tokens.
+func ClientCertificateMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if cert, ok := mtls.VerifiedCertificate(c.Request.TLS); ok {
+			c.Request = c.Request.WithContext(mtls.WithCertificate(c.Request.Context(), cert))
+		}
+
+		c.Next()
+	}
+}
+
+// ClientCertAuthMiddleware authenticates machine clients by their verified client certificate, matched by subject
+// common name or DNS name. Requests without a matching certificate are handed to fallback, e.g. the API token
+// middleware, or rejected when fallback is nil.
+func ClientCertAuthMiddleware(names []string, fallback gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if cert, ok := mtls.FromContext(c.Request.Context()); ok && mtls.MatchesName(cert, names) {
+			c.Next()
+			return
+		}
+
+		if fallback != nil {
+			fallback(c)
+			return
+		}
+
+		middleware.HandleError(c, errors.CodeUnauthorized, "Unauthorized", fmt.Errorf("no trusted client certificate"))
+	}
+}
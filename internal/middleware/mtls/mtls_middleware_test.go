@@ -0,0 +1,116 @@
+package mtls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/mtls"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/mtls/mtlstest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientCertAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ca := mtlstest.NewCA(t, "test-ca")
+	connector := mtlstest.NewLeaf(t, ca, "scim-connector").Cert
+	stranger := mtlstest.NewLeaf(t, ca, "stranger").Cert
+
+	fallback := func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "Bearer token" {
+			c.Next()
+			return
+		}
+		c.AbortWithStatus(http.StatusForbidden)
+	}
+
+	testCases := []struct {
+		name          string
+		tlsState      *tls.ConnectionState
+		header        string
+		fallback      gin.HandlerFunc
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			tlsState: &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{connector, ca.Cert}}},
+			fallback: fallback,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "Be able to fall back when the certificate is not trusted",
+			tlsState: &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{stranger, ca.Cert}}},
+			header:   "Bearer token",
+			fallback: fallback,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "Be able to ignore certificates that were not verified",
+			tlsState: &tls.ConnectionState{PeerCertificates: []*x509.Certificate{connector}},
+			fallback: fallback,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "Be able to throw an error without a certificate and fallback",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(ClientCertificateMiddleware())
+			router.GET("/scim", ClientCertAuthMiddleware([]string{"scim-connector"}, tc.fallback), func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{})
+			})
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/scim", nil)
+			require.NoError(t, err)
+			request.TLS = tc.tlsState
+			if tc.header != "" {
+				request.Header.Set("Authorization", tc.header)
+			}
+
+			router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestClientCertificateMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ca := mtlstest.NewCA(t, "test-ca")
+	cert := mtlstest.NewLeaf(t, ca, "mobile-gateway").Cert
+
+	router := gin.New()
+	router.Use(ClientCertificateMiddleware())
+	router.GET("/", func(c *gin.Context) {
+		stored, ok := mtls.FromContext(c.Request.Context())
+		require.True(t, ok)
+		require.Equal(t, cert, stored)
+		c.Status(http.StatusOK)
+	})
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/", nil)
+	require.NoError(t, err)
+	request.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert, ca.Cert}}}
+
+	router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
@@ -0,0 +1,68 @@
+package org
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/middleware"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+)
+
+const organizationIdParam = "orgId"
+
+// ActiveOrgMiddleware confines a request to the organization its access token is scoped to. The :orgId route
+// parameter must match the token's org_id claim, and the user must still be a member, so a removed member's token
+// stops working before it expires. The member's role is stored under constant.OrgRoleKey. It must run after
+// auth.AccessAuthMiddleware.
+func ActiveOrgMiddleware(store db.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		activeOrgId := c.GetInt64(constant.OrgIdKey)
+		if activeOrgId == 0 {
+			middleware.HandleError(c, errors.CodeForbidden, "No active organization", fmt.Errorf("%v is not found in payload", constant.OrgIdKey))
+			return
+		}
+
+		orgId, err := strconv.ParseInt(c.Param(organizationIdParam), 10, 64)
+		if err != nil || orgId != activeOrgId {
+			middleware.HandleError(c, errors.CodeForbidden, "Organization is not the active organization", fmt.Errorf("route organization %q does not match active organization %d", c.Param(organizationIdParam), activeOrgId))
+			return
+		}
+
+		membership, err := store.GetMembership(c.Request.Context(), sqlc.GetMembershipParams{
+			OrganizationID: activeOrgId,
+			UserID:         c.GetInt64(constant.UserIdKey),
+		})
+		if err != nil {
+			if stderrors.Is(err, sql.ErrNoRows) {
+				middleware.HandleError(c, errors.CodeForbidden, "User is not a member of the organization", err)
+				return
+			}
+			middleware.HandleError(c, errors.CodeInternal, "Failed to get membership", err)
+			return
+		}
+
+		c.Set(constant.OrgRoleKey, membership.Role)
+
+		c.Next()
+	}
+}
+
+// RequireOrgRole only lets members holding one of roles through. It must run after ActiveOrgMiddleware.
+func RequireOrgRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString(constant.OrgRoleKey)
+		if !slices.Contains(roles, role) {
+			middleware.HandleError(c, errors.CodeForbidden, "Insufficient organization role", fmt.Errorf("organization role %q is not one of %v", role, roles))
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,142 @@
+package org
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActiveOrgMiddleware(t *testing.T) {
+	const userId = int64(7)
+
+	testCases := []struct {
+		name          string
+		activeOrgId   int64
+		path          string
+		buildStub     func(store *db.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "member of the active organization",
+			activeOrgId: 3,
+			path:        "/orgs/3/members",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetMembership(gomock.Any(), sqlc.GetMembershipParams{OrganizationID: 3, UserID: userId}).
+					Times(1).Return(sqlc.Membership{Role: constant.OrgRoleAdmin}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, constant.OrgRoleAdmin, recorder.Body.String())
+			},
+		},
+		{
+			name:        "no active organization",
+			activeOrgId: 0,
+			path:        "/orgs/3/members",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetMembership(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:        "other organization",
+			activeOrgId: 3,
+			path:        "/orgs/4/members",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetMembership(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:        "membership revoked",
+			activeOrgId: 3,
+			path:        "/orgs/3/members",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetMembership(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.Membership{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:        "database error",
+			activeOrgId: 3,
+			path:        "/orgs/3/members",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetMembership(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.Membership{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := db.NewMockStore(ctrl)
+			testCase.buildStub(store)
+
+			router := gin.New()
+			router.GET("/orgs/:orgId/members", func(c *gin.Context) {
+				c.Set(constant.UserIdKey, userId)
+				c.Set(constant.OrgIdKey, testCase.activeOrgId)
+				c.Next()
+			}, ActiveOrgMiddleware(store), func(c *gin.Context) {
+				c.String(http.StatusOK, c.GetString(constant.OrgRoleKey))
+			})
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, testCase.path, nil)
+			require.NoError(t, err)
+
+			router.ServeHTTP(recorder, request)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRequireOrgRole(t *testing.T) {
+	testCases := []struct {
+		name       string
+		role       string
+		wantStatus int
+	}{
+		{name: "owner", role: constant.OrgRoleOwner, wantStatus: http.StatusOK},
+		{name: "admin", role: constant.OrgRoleAdmin, wantStatus: http.StatusOK},
+		{name: "member", role: constant.OrgRoleMember, wantStatus: http.StatusForbidden},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/", func(c *gin.Context) {
+				c.Set(constant.OrgRoleKey, testCase.role)
+				c.Next()
+			}, RequireOrgRole(constant.OrgRoleOwner, constant.OrgRoleAdmin), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/", nil)
+			require.NoError(t, err)
+
+			router.ServeHTTP(recorder, request)
+			require.Equal(t, testCase.wantStatus, recorder.Code)
+		})
+	}
+}
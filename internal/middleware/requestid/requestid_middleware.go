@@ -0,0 +1,23 @@
+package requestid
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/requestid"
+)
+
+// RequestIDMiddleware accepts or generates the X-Request-ID and traceparent of the request, stores them in the gin
+// context and the request context, see requestid.FromContext, and echoes them in the response. It runs first so
+// every log line and error response of the request carries them.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ids := requestid.New(c.GetHeader(requestid.Header), c.GetHeader(requestid.TraceparentHeader))
+		c.Set(constant.RequestIdKey, ids.RequestID)
+		c.Request = c.Request.WithContext(requestid.WithIDs(c.Request.Context(), ids))
+
+		c.Header(requestid.Header, ids.RequestID)
+		c.Header(requestid.TraceparentHeader, ids.Traceparent())
+
+		c.Next()
+	}
+}
@@ -0,0 +1,71 @@
+package requestid
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hanifsyahsn/go_boilerplate/internal/middleware"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/requestid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(RequestIDMiddleware())
+	router.GET("/", func(c *gin.Context) {
+		ids := requestid.FromContext(c.Request.Context())
+		require.Equal(t, c.GetString(constant.RequestIdKey), ids.RequestID)
+		middleware.HandleError(c, errors.CodeForbidden, "Forbidden", fmt.Errorf("no access"))
+	})
+
+	testCases := []struct {
+		name          string
+		setupRequest  func(request *http.Request)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Be able to echo the IDs of the client",
+			setupRequest: func(request *http.Request) {
+				request.Header.Set(requestid.Header, "req-1")
+				request.Header.Set(requestid.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, "req-1", recorder.Header().Get(requestid.Header))
+				require.Regexp(t, `^00-4bf92f3577b34da6a3ce929d0e0e4736-[0-9a-f]{16}-01$`, recorder.Header().Get(requestid.TraceparentHeader))
+			},
+		},
+		{
+			name:         "Be able to generate missing IDs",
+			setupRequest: func(request *http.Request) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.NotEmpty(t, recorder.Header().Get(requestid.Header))
+				require.NotEmpty(t, recorder.Header().Get(requestid.TraceparentHeader))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/", nil)
+			require.NoError(t, err)
+			testCase.setupRequest(request)
+
+			router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusForbidden, recorder.Code)
+			testCase.checkResponse(t, recorder)
+
+			var body map[string]string
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+			require.Equal(t, recorder.Header().Get(requestid.Header), body[constant.RequestIdKey])
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/requestid"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingMiddleware starts a server span per request, named after its route and continuing the trace of the
+// traceparent header. It must run after RequestIDMiddleware: the trace context stored by it is replaced with the
+// span, so the logs and the traceparent returned to the client name it.
+func TracingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parent := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		name := c.Request.Method
+		if route != "" {
+			name += " " + route
+		}
+		ctx, span := tracing.Start(parent, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+				semconv.ClientAddress(c.ClientIP()),
+			),
+		)
+		defer span.End()
+
+		// Without a tracer provider the span is the one of the caller, the generated IDs are kept then
+		spanContext := span.SpanContext()
+		if spanContext.IsValid() && spanContext.SpanID() != trace.SpanContextFromContext(parent).SpanID() {
+			ids := requestid.FromContext(ctx)
+			ids.TraceID, ids.SpanID, ids.Flags = spanContext.TraceID().String(), spanContext.SpanID().String(), spanContext.TraceFlags().String()
+			ctx = requestid.WithIDs(ctx, ids)
+			c.Header(requestid.TraceparentHeader, ids.Traceparent())
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	requestIdMiddleware "github.com/hanifsyahsn/go_boilerplate/internal/middleware/requestid"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/requestid"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/tracing"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/tracing/tracingtest"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	exporter := tracingtest.NewExporter(t)
+
+	router := gin.New()
+	router.Use(requestIdMiddleware.RequestIDMiddleware(), TracingMiddleware())
+	router.GET("/users/:id", func(c *gin.Context) {
+		_, span := tracing.Start(c.Request.Context(), "GetUser")
+		span.End()
+
+		ids := requestid.FromContext(c.Request.Context())
+		require.Equal(t, trace.SpanContextFromContext(c.Request.Context()).SpanID().String(), ids.SpanID)
+		c.Status(http.StatusInternalServerError)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/users/7", nil)
+	request.Header.Set(requestid.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	child, server := spans[0], spans[1]
+
+	require.Equal(t, "GET /users/:id", server.Name)
+	require.Equal(t, trace.SpanKindServer, server.SpanKind)
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+	require.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+	require.Contains(t, server.Attributes, semconv.HTTPRoute("/users/:id"))
+	require.Contains(t, server.Attributes, semconv.HTTPResponseStatusCode(http.StatusInternalServerError))
+	require.Equal(t, codes.Error, server.Status.Code)
+
+	require.Equal(t, "GetUser", child.Name)
+	require.Equal(t, server.SpanContext.SpanID(), child.Parent.SpanID())
+
+	require.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-"+server.SpanContext.SpanID().String()+"-01", recorder.Header().Get(requestid.TraceparentHeader))
+}
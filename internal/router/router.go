@@ -1,39 +1,209 @@
 package router
 
 import (
+	"log/slog"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 	"github.com/hanifsyahsn/go_boilerplate/internal/config"
 	"github.com/hanifsyahsn/go_boilerplate/internal/db"
 	autHandler "github.com/hanifsyahsn/go_boilerplate/internal/handler/authhandler"
+	"github.com/hanifsyahsn/go_boilerplate/internal/handler/invitationhandler"
+	"github.com/hanifsyahsn/go_boilerplate/internal/handler/magiclinkhandler"
+	"github.com/hanifsyahsn/go_boilerplate/internal/handler/oauthhandler"
+	"github.com/hanifsyahsn/go_boilerplate/internal/handler/orghandler"
+	"github.com/hanifsyahsn/go_boilerplate/internal/handler/scimhandler"
+	"github.com/hanifsyahsn/go_boilerplate/internal/handler/socialhandler"
+	"github.com/hanifsyahsn/go_boilerplate/internal/handler/webauthnhandler"
+	auditMiddleware "github.com/hanifsyahsn/go_boilerplate/internal/middleware/audit"
 	authMiddleware "github.com/hanifsyahsn/go_boilerplate/internal/middleware/auth"
 	"github.com/hanifsyahsn/go_boilerplate/internal/middleware/cors"
-	"github.com/hanifsyahsn/go_boilerplate/internal/middleware/limiter"
+	deviceMiddleware "github.com/hanifsyahsn/go_boilerplate/internal/middleware/device"
+	dpopMiddleware "github.com/hanifsyahsn/go_boilerplate/internal/middleware/dpop"
+	rateLimitMiddleware "github.com/hanifsyahsn/go_boilerplate/internal/middleware/limiter"
+	mtlsMiddleware "github.com/hanifsyahsn/go_boilerplate/internal/middleware/mtls"
+	orgMiddleware "github.com/hanifsyahsn/go_boilerplate/internal/middleware/org"
 	authService "github.com/hanifsyahsn/go_boilerplate/internal/service/authservice"
+	invitationService "github.com/hanifsyahsn/go_boilerplate/internal/service/invitationservice"
+	magicLinkService "github.com/hanifsyahsn/go_boilerplate/internal/service/magiclinkservice"
+	oauthService "github.com/hanifsyahsn/go_boilerplate/internal/service/oauthservice"
+	orgService "github.com/hanifsyahsn/go_boilerplate/internal/service/orgservice"
+	scimService "github.com/hanifsyahsn/go_boilerplate/internal/service/scimservice"
+	socialService "github.com/hanifsyahsn/go_boilerplate/internal/service/socialservice"
+	webAuthnService "github.com/hanifsyahsn/go_boilerplate/internal/service/webauthnservice"
 	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/jticache"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/logger"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/mailer"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/passkey"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/ratelimit"
 	"github.com/hanifsyahsn/go_boilerplate/internal/util/redis"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/social"
 	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
 )
 
-func SetupRouter(r *gin.Engine, store db.Store, tokenMaker token.Maker, config config.Config, redis redis.Client) {
+func SetupRouter(r *gin.Engine, store db.Store, tokenMaker token.Maker, config config.Config, redis redis.Client, rateLimiter ratelimit.Limiter, jtis *jticache.Cache) {
 	gin.SetMode(config.GinMode)
 	r.Use(cors.CORSMiddleware())
+	r.Use(deviceMiddleware.DeviceMiddleware())
+	r.Use(dpopMiddleware.DPoPMiddleware(redis, config.PublicURL, config.DPoPProofMaxAge, config.RedisFailOpen()))
+	r.Use(mtlsMiddleware.ClientCertificateMiddleware())
+
+	// Client IPs come from X-Forwarded-For only when the request passed through one of the trusted proxies
+	if config.TrustedProxies != "" {
+		if err := r.SetTrustedProxies(strings.Split(config.TrustedProxies, ",")); err != nil {
+			logger.Fatal("Invalid trusted proxies configuration", slog.Any("err", err))
+		}
+	}
+
+	policies, err := ratelimit.ParsePolicies(config.RateLimitPolicies)
+	if err != nil {
+		logger.Fatal("Invalid rate limit configuration", slog.Any("err", err))
+	}
+	allowlist, err := ratelimit.ParsePrefixes(config.RateLimitAllowlist)
+	if err != nil {
+		logger.Fatal("Invalid rate limit allowlist configuration", slog.Any("err", err))
+	}
+	limiter := rateLimitMiddleware.New(rateLimiter, policies, allowlist)
 
-	authSvc := authService.NewService(store, util.HashPassword, util.CheckPasswordHash, tokenMaker, config, redis)
+	authenticator, err := authService.NewAuthenticator(config, store, util.CheckPasswordHash)
+	if err != nil {
+		logger.Fatal("Invalid auth backend configuration", slog.Any("err", err))
+	}
+	authSvc := authService.NewService(store, util.HashPassword, util.CheckPasswordHash, tokenMaker, config, redis).WithAuthenticator(authenticator)
 	authHandler := autHandler.NewHandler(store, authSvc)
 
 	auth := r.Group("/auth")
-	auth.Use(limiter.RateLimitIpMiddleware())
-	auth.POST("/register", authHandler.Register)
-	auth.POST("/login", authHandler.Login)
+	auth.Use(limiter.Middleware("auth"))
+	auth.POST("/register", limiter.Middleware("register"), authHandler.Register)
+	auth.POST("/login", limiter.Middleware("login"), authHandler.Login)
+
+	socialSvc := socialService.NewService(store, redis, social.NewProviders(config), authSvc)
+	socialHandler := socialhandler.NewHandler(socialSvc)
+	auth.GET("/social/:provider/login", socialHandler.Login)
+	auth.GET("/social/:provider/callback", socialHandler.Callback)
+
+	mail := mailer.NewMailer(config)
+
+	invitationSvc := invitationService.NewService(store, mail, authSvc, config)
+	invitationHandler := invitationhandler.NewHandler(invitationSvc)
+	auth.POST("/register/invitation", invitationHandler.Complete)
+
+	magicLinkSvc := magicLinkService.NewService(store, mail, authSvc, config)
+	magicLinkHandler := magiclinkhandler.NewHandler(magicLinkSvc, int(config.MagicLinkDuration.Seconds()))
+	auth.POST("/magic-link", limiter.Middleware("magic_link"), magicLinkHandler.Request)
+	auth.POST("/magic-link/consume", magicLinkHandler.Consume)
+
+	webAuthn, err := passkey.NewWebAuthn(config)
+	if err != nil {
+		logger.Fatal("Invalid WebAuthn configuration", slog.Any("err", err))
+	}
+	webAuthnSvc := webAuthnService.NewService(store, redis, webAuthn, authSvc)
+	webAuthnHandler := webauthnhandler.NewHandler(webAuthnSvc)
+	// Passkey logins carry no email to count them by, so they are limited per IP; one login takes both requests
+	auth.POST("/webauthn/login/begin", limiter.Middleware("passkey_login"), webAuthnHandler.BeginLogin)
+	auth.POST("/webauthn/login/finish", limiter.Middleware("passkey_login"), webAuthnHandler.FinishLogin)
+
+	accessAuth := authMiddleware.AccessAuthMiddleware(tokenMaker, redis, jtis)
 
 	authAccessProtected := auth.Group("/")
-	authAccessProtected.Use(authMiddleware.AccessAuthMiddleware(tokenMaker, redis))
-	authAccessProtected.Use(limiter.RateLimitUserMiddleware())
+	authAccessProtected.Use(accessAuth)
+	authAccessProtected.Use(limiter.Middleware("user"))
+	authAccessProtected.Use(auditMiddleware.AuditMiddleware(store))
 	authAccessProtected.GET("/me", authHandler.Me)
+	authAccessProtected.POST("/reauthenticate", authHandler.Reauthenticate)
+	authAccessProtected.GET("/sessions", authHandler.ListSessions)
+	authAccessProtected.DELETE("/sessions/:id", authHandler.RevokeSession)
+	authAccessProtected.POST("/logout-all", authHandler.LogoutAll)
+
+	// Enrolling a passkey adds a second factor, so it requires a recent password entry
+	recentAuth := authMiddleware.RequireRecentAuth(config.ReauthMaxAge)
+	authAccessProtected.POST("/webauthn/register/begin", recentAuth, webAuthnHandler.BeginRegistration)
+	authAccessProtected.POST("/webauthn/register/finish", recentAuth, webAuthnHandler.FinishRegistration)
+
+	orgSvc := orgService.NewService(store, mail, authSvc, config)
+	orgHandler := orghandler.NewHandler(orgSvc)
+	authAccessProtected.POST("/switch-org", orgHandler.Switch)
 
 	authRefreshProtected := auth.Group("/")
 	authRefreshProtected.Use(authMiddleware.RefreshAuthMiddleware(tokenMaker))
-	authRefreshProtected.Use(limiter.RateLimitUserMiddleware())
+	authRefreshProtected.Use(limiter.Middleware("user"))
 	authRefreshProtected.POST("/logout", authHandler.Logout)
 	authRefreshProtected.POST("/refresh", authHandler.RefreshAccessToken)
+
+	orgs := r.Group("/orgs")
+	orgs.Use(accessAuth)
+	orgs.Use(limiter.Middleware("user"))
+	orgs.Use(auditMiddleware.AuditMiddleware(store))
+	orgs.POST("", orgHandler.Create)
+	orgs.GET("", orgHandler.ListMemberships)
+	orgs.POST("/invitations/accept", orgHandler.AcceptInvitation)
+
+	// Everything under /orgs/:orgId is confined to the organization the access token is scoped to
+	activeOrg := orgs.Group("/:orgId")
+	activeOrg.Use(orgMiddleware.ActiveOrgMiddleware(store))
+	activeOrg.GET("/members", orgHandler.ListMembers)
+	activeOrg.POST("/invitations", orgMiddleware.RequireOrgRole(constant.OrgRoleOwner, constant.OrgRoleAdmin), orgHandler.Invite)
+
+	admin := r.Group("/admin")
+	admin.Use(accessAuth)
+	admin.Use(auditMiddleware.AuditMiddleware(store))
+
+	// Support staff may impersonate without being admins, so the route checks the permission instead of the role
+	admin.POST("/users/:id/impersonate", authMiddleware.RequirePermissionMiddleware(store, constant.PermissionImpersonate), authHandler.Impersonate)
+
+	adminOnly := admin.Group("")
+	adminOnly.Use(authMiddleware.RequireRoleMiddleware(store, constant.AdminRole))
+	adminOnly.POST("/invitations", invitationHandler.Create)
+	adminOnly.GET("/invitations", invitationHandler.List)
+	adminOnly.POST("/invitations/:id/resend", invitationHandler.Resend)
+	adminOnly.DELETE("/invitations/:id", invitationHandler.Revoke)
+
+	// The OpenID Connect provider is only exposed when ID tokens can be verified with the published public key
+	if config.OIDCProviderEnabled() {
+		oauthSvc := oauthService.NewService(store, authenticator, tokenMaker, config)
+		oauthHandler := oauthhandler.NewHandler(oauthSvc)
+
+		r.GET("/.well-known/openid-configuration", oauthHandler.Discovery)
+
+		oauth := r.Group("/oauth")
+		oauth.GET("/authorize", oauthHandler.Authorize)
+		oauth.POST("/authorize", limiter.Middleware("oauth_consent"), oauthHandler.Consent)
+		// The token endpoint checks client secrets and redeems authorization codes, so guessing is limited per IP
+		oauth.POST("/token", limiter.Middleware("oauth_token"), oauthHandler.Token)
+		oauth.GET("/userinfo", oauthHandler.UserInfo)
+		oauth.POST("/userinfo", oauthHandler.UserInfo)
+		oauth.GET("/jwks", oauthHandler.JWKS)
+	}
+
+	// SCIM provisioning is only exposed once an API token or trusted client certificates are configured
+	if config.SCIMEnabled() {
+		scimSvc := scimService.NewService(store, redis, util.HashPassword, config)
+		scimHandler := scimhandler.NewHandler(scimSvc)
+
+		var scimAuth gin.HandlerFunc
+		if config.SCIMAPIToken != "" {
+			scimAuth = authMiddleware.APITokenMiddleware(config.SCIMAPIToken)
+		}
+		if config.SCIMClientCertNames != "" {
+			scimAuth = mtlsMiddleware.ClientCertAuthMiddleware(strings.Split(config.SCIMClientCertNames, ","), scimAuth)
+		}
+
+		scim := r.Group("/scim/v2")
+		scim.Use(limiter.Middleware("scim"), scimAuth)
+		scim.GET("/ServiceProviderConfig", scimHandler.ServiceProviderConfig)
+		scim.GET("/Users", scimHandler.ListUsers)
+		scim.POST("/Users", scimHandler.CreateUser)
+		scim.GET("/Users/:id", scimHandler.GetUser)
+		scim.PUT("/Users/:id", scimHandler.ReplaceUser)
+		scim.PATCH("/Users/:id", scimHandler.PatchUser)
+		scim.DELETE("/Users/:id", scimHandler.DeleteUser)
+		scim.GET("/Groups", scimHandler.ListGroups)
+		scim.POST("/Groups", scimHandler.CreateGroup)
+		scim.GET("/Groups/:id", scimHandler.GetGroup)
+		scim.PUT("/Groups/:id", scimHandler.ReplaceGroup)
+		scim.PATCH("/Groups/:id", scimHandler.PatchGroup)
+		scim.DELETE("/Groups/:id", scimHandler.DeleteGroup)
+	}
 }
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"database/sql"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/jticache"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/metrics"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/ratelimit"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/redis"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// registerMetrics exposes the connection pool and the Stats of the components built by NewServer. The memory limiter
+// is nil when the rate limits are counted in Redis.
+func registerMetrics(conn *sql.DB, memoryLimiter *ratelimit.MemoryLimiter, breaker *redis.CircuitBreaker, jtis *jticache.Cache) {
+	metrics.Registry.MustRegister(collectors.NewDBStatsCollector(conn, "postgres"))
+
+	if memoryLimiter != nil {
+		metrics.Registry.MustRegister(
+			gauge("rate_limit_memory_entries", "Buckets held by the in-memory rate limiter.", func() float64 {
+				return float64(memoryLimiter.Stats().Entries)
+			}),
+			counter("rate_limit_memory_evicted_total", "Buckets evicted to stay within RATE_LIMIT_MEMORY_CAPACITY.", func() float64 {
+				return float64(memoryLimiter.Stats().Evicted)
+			}),
+			counter("rate_limit_memory_expired_total", "Full buckets removed by the janitor.", func() float64 {
+				return float64(memoryLimiter.Stats().Expired)
+			}),
+		)
+	}
+
+	metrics.Registry.MustRegister(
+		gauge("redis_breaker_state", "State of the Redis circuit breaker: 0 closed, 1 open, 2 half-open.", func() float64 {
+			return float64(breaker.Stats().State)
+		}),
+		counter("redis_breaker_opened_total", "Times the Redis circuit breaker opened.", func() float64 {
+			return float64(breaker.Stats().Opened)
+		}),
+		counter("redis_breaker_rejected_total", "Redis commands failed by the open circuit breaker.", func() float64 {
+			return float64(breaker.Stats().Rejected)
+		}),
+		gauge("jti_cache_entries", "Sessions held by the access token jti cache.", func() float64 {
+			return float64(jtis.Stats().Entries)
+		}),
+		counter("jti_cache_hits_total", "Access tokens accepted from the jti cache without asking Redis.", func() float64 {
+			return float64(jtis.Stats().Hits)
+		}),
+		counter("jti_cache_misses_total", "Access tokens checked against Redis.", func() float64 {
+			return float64(jtis.Stats().Misses)
+		}),
+		counter("jti_cache_invalidations_total", "jti cache entries dropped because their session changed.", func() float64 {
+			return float64(jtis.Stats().Invalidations)
+		}),
+		counter("jti_cache_degraded_accepted_total", "Access tokens accepted from the jti cache while Redis was unavailable.", func() float64 {
+			return float64(jtis.Stats().Accepted)
+		}),
+		counter("jti_cache_degraded_rejected_total", "Access tokens rejected while Redis was unavailable.", func() float64 {
+			return float64(jtis.Stats().Rejected)
+		}),
+	)
+}
+
+func gauge(name, help string, value func() float64) prometheus.GaugeFunc {
+	return prometheus.NewGaugeFunc(prometheus.GaugeOpts{Namespace: metrics.Namespace, Name: name, Help: help}, value)
+}
+
+func counter(name, help string, value func() float64) prometheus.CounterFunc {
+	return prometheus.NewCounterFunc(prometheus.CounterOpts{Namespace: metrics.Namespace, Name: name, Help: help}, value)
+}
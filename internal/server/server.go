@@ -2,8 +2,9 @@ package server
 
 import (
 	"context"
+	"database/sql"
 	"errors"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -13,70 +14,197 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/hanifsyahsn/go_boilerplate/internal/config"
 	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/handler/healthhandler"
+	"github.com/hanifsyahsn/go_boilerplate/internal/middleware/logging"
+	metricsMiddleware "github.com/hanifsyahsn/go_boilerplate/internal/middleware/metrics"
+	requestIdMiddleware "github.com/hanifsyahsn/go_boilerplate/internal/middleware/requestid"
+	tracingMiddleware "github.com/hanifsyahsn/go_boilerplate/internal/middleware/tracing"
 	"github.com/hanifsyahsn/go_boilerplate/internal/router"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/health"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/jticache"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/logger"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/metrics"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/mtls"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/ratelimit"
 	"github.com/hanifsyahsn/go_boilerplate/internal/util/redis"
 	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
-	goRedis "github.com/redis/go-redis/v9"
 )
 
 type Server struct {
 	// We use this to have our graceful shutdown since gin doesn't have a stop / shutdown method
 	httpServer *http.Server
-	redis      redis.Client
+	// adminServer serves /metrics on ADMIN_SERVER_ADDRESS, it is nil when that is empty
+	adminServer *http.Server
+	redis       redis.Client
+	// tls is set when the server listens with HTTPS
+	tls bool
+	// background runs alongside the server until it shuts down, e.g. certificate reloading
+	background []func(ctx context.Context)
+	// health fails the readiness probe for drainDelay before the server stops
+	health     *health.Checker
+	drainDelay time.Duration
 }
 
-func NewServer(store db.Store, address string, tokenMaker token.Maker, config config.Config) *Server {
-	goRedisClient := goRedis.NewClient(&goRedis.Options{
-		Addr:     config.RedisAddress,
-		Password: config.RedisPassword,
-		DB:       0,
-	})
+func NewServer(store db.Store, conn *sql.DB, address string, tokenMaker token.Maker, config config.Config) *Server {
+	goRedisClient, err := redis.NewUniversalClient(config)
+	if err != nil {
+		logger.Fatal("Invalid Redis configuration", slog.Any("err", err))
+	}
 
 	if _, err := goRedisClient.Ping(context.Background()).Result(); err != nil {
-		log.Fatalf("Failed to connect to Redis: %v", err)
+		if !config.RedisFailOpen() {
+			logger.Fatal("Failed to connect to Redis", slog.Any("err", err))
+		}
+		slog.Warn("Failed to connect to Redis, starting in degraded mode", slog.Any("err", err))
 	}
 
-	redisClient := redis.NewRedisClient(goRedisClient)
+	// Commands rejected by the open breaker are traced as well
+	breaker := redis.NewCircuitBreaker(redis.NewRedisClient(goRedisClient), config.RedisBreakerFailures, config.RedisBreakerCooldown)
+	redisClient := redis.NewTracedClient(breaker)
+
+	var background []func(ctx context.Context)
+
+	var rateLimiter ratelimit.Limiter
+	var memoryLimiter *ratelimit.MemoryLimiter
+	if config.RateLimitBackend == "memory" {
+		memoryLimiter = ratelimit.NewMemoryLimiter(config.RateLimitMemoryCapacity)
+		background = append(background, func(ctx context.Context) {
+			memoryLimiter.RunJanitor(ctx, config.RateLimitJanitorInterval)
+		})
+		rateLimiter = memoryLimiter
+	} else {
+		rateLimiter = ratelimit.NewRedisLimiter(redisClient)
+	}
 
-	r := gin.Default()
-	router.SetupRouter(r, store, tokenMaker, config, redisClient)
+	jtiCacheOptions := jticache.Options{Capacity: config.JTICacheCapacity, TTL: config.JTICacheTTL}
+	if config.RedisFailOpen() {
+		jtiCacheOptions.FailOpenTTL = config.RedisFailOpenCacheTTL
+	}
+	jtis := jticache.New(jtiCacheOptions)
+	background = append(background, func(ctx context.Context) {
+		redis.ListenAccessInvalidations(ctx, redisClient, jtis.Forget, jtis.Clear)
+	})
+
+	registerMetrics(conn, memoryLimiter, breaker, jtis)
+
+	checker := health.NewChecker(config.HealthCheckTimeout,
+		health.Check{Name: "postgres", Run: conn.PingContext},
+		// Access tokens are still accepted from the jti cache without Redis, see REDIS_FAILURE_MODE
+		health.Check{Name: "redis", Run: redisClient.Ping, Optional: config.RedisFailOpen()},
+		health.Check{Name: "signing_keys", Run: func(ctx context.Context) error {
+			return token.CheckKeys(ctx, tokenMaker)
+		}},
+	)
+	healthHandler := healthhandler.NewHandler(checker)
+
+	r := gin.New()
+	// The probes are registered ahead of the middlewares, so they are neither logged, traced nor measured
+	r.GET("/healthz", healthHandler.Liveness)
+	r.GET("/readyz", healthHandler.Readiness)
+	r.Use(
+		requestIdMiddleware.RequestIDMiddleware(),
+		tracingMiddleware.TracingMiddleware(),
+		metricsMiddleware.MetricsMiddleware(),
+		logging.LoggingMiddleware(slog.Default()),
+		logging.RecoveryMiddleware(),
+	)
+	router.SetupRouter(r, store, tokenMaker, config, redisClient, rateLimiter, jtis)
 
 	srv := &http.Server{
 		Addr:    address,
 		Handler: r,
 	}
 
+	if config.TLSEnabled() {
+		certificates, err := mtls.NewReloader(config.TLSCertFile, config.TLSKeyFile, config.TLSClientCAFile)
+		if err != nil {
+			logger.Fatal("Failed to load TLS certificates", slog.Any("err", err))
+		}
+		srv.TLSConfig = certificates.TLSConfig(mtls.ClientAuthType(config.TLSClientAuth))
+		background = append(background, func(ctx context.Context) {
+			certificates.Watch(ctx, config.TLSReloadInterval)
+		})
+	}
+
+	var adminServer *http.Server
+	if config.AdminServerEnabled() {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler())
+		adminServer = &http.Server{
+			Addr:    config.AdminServerAddress,
+			Handler: mux,
+		}
+	}
+
 	return &Server{
-		httpServer: srv,
-		redis:      redisClient,
+		httpServer:  srv,
+		adminServer: adminServer,
+		redis:       redisClient,
+		tls:         config.TLSEnabled(),
+		background:  background,
+		health:      checker,
+		drainDelay:  config.ShutdownDrainDelay,
 	}
 }
 
 func (server *Server) Run() error {
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	for _, task := range server.background {
+		go task(backgroundCtx)
+	}
 
 	go func() {
-		log.Printf("Server running on %s", server.httpServer.Addr)
-		if err := server.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			log.Fatalf("Failed to start server: %v", err)
+		var err error
+		if server.tls {
+			slog.Info("Server running with TLS", slog.String("address", server.httpServer.Addr))
+			// The certificate comes from TLSConfig so it can be reloaded
+			err = server.httpServer.ListenAndServeTLS("", "")
+		} else {
+			slog.Info("Server running", slog.String("address", server.httpServer.Addr))
+			err = server.httpServer.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatal("Failed to start server", slog.Any("err", err))
 		}
 	}()
 
+	if server.adminServer != nil {
+		go func() {
+			slog.Info("Admin server running", slog.String("address", server.adminServer.Addr))
+			if err := server.adminServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Fatal("Failed to start admin server", slog.Any("err", err))
+			}
+		}()
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 	<-quit
-	log.Println("Shutting down server...")
+	slog.Info("Shutting down server...")
+
+	// Load balancers stop routing new requests once they see the readiness fail, the server keeps serving meanwhile
+	server.health.Drain()
+	time.Sleep(server.drainDelay)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := server.httpServer.Shutdown(ctx); err != nil {
-		log.Fatal("Server forced to shutdown:", err)
+		logger.Fatal("Server forced to shutdown", slog.Any("err", err))
+	}
+
+	// Metrics stay available until the requests in flight have been handled
+	if server.adminServer != nil {
+		if err := server.adminServer.Shutdown(ctx); err != nil {
+			slog.Error("Error shutting down admin server", slog.Any("err", err))
+		}
 	}
 
 	if err := server.redis.Close(); err != nil {
-		log.Println("Error closing Redis:", err)
+		slog.Error("Error closing Redis", slog.Any("err", err))
 	}
 
-	log.Println("Server exited gracefully")
+	slog.Info("Server exited gracefully")
 	return nil
 }
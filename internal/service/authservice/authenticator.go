@@ -0,0 +1,180 @@
+package authservice
+
+import (
+	"context"
+	"database/sql"
+	ierr "errors"
+	"fmt"
+	"strings"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/config"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/ldapauth"
+)
+
+// Authenticator checks a login's credentials and returns the matching local user
+type Authenticator interface {
+	Authenticate(ctx context.Context, email, password string) (sqlc.User, error)
+}
+
+// NewAuthenticator builds the login credential check from AUTH_BACKENDS. Backends are tried in the configured
+// order and the next one is only consulted when the user is unknown to the previous one.
+func NewAuthenticator(config config.Config, store db.Store, checkPassword func(ctx context.Context, password, hash string) error) (Authenticator, error) {
+	var authenticators ChainAuthenticator
+	for _, backend := range strings.Split(config.AuthBackends, ",") {
+		switch strings.ToLower(strings.TrimSpace(backend)) {
+		case "local":
+			authenticators = append(authenticators, NewPasswordAuthenticator(store, checkPassword))
+		case "ldap":
+			groupRoles, err := ldapauth.ParseGroupRoles(config.LDAPGroupRoles)
+			if err != nil {
+				return nil, err
+			}
+			directory := ldapauth.NewDirectory(ldapauth.Config{
+				URL:            config.LDAPURL,
+				BindDN:         config.LDAPBindDN,
+				BindPassword:   config.LDAPBindPassword,
+				BaseDN:         config.LDAPBaseDN,
+				UserFilter:     config.LDAPUserFilter,
+				EmailAttribute: config.LDAPEmailAttribute,
+				NameAttribute:  config.LDAPNameAttribute,
+				GroupAttribute: config.LDAPGroupAttribute,
+				StartTLS:       config.LDAPStartTLS,
+				Timeout:        config.LDAPTimeout,
+			})
+			authenticators = append(authenticators, NewLDAPAuthenticator(store, directory, groupRoles, config.LDAPDefaultRole))
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown auth backend %q", backend)
+		}
+	}
+
+	switch len(authenticators) {
+	case 0:
+		return nil, fmt.Errorf("no auth backend configured")
+	case 1:
+		return authenticators[0], nil
+	default:
+		return authenticators, nil
+	}
+}
+
+// PasswordAuthenticator checks the password against the bcrypt hash stored on the user. Users without one are
+// reported as not found, so the chain can ask the next backend.
+type PasswordAuthenticator struct {
+	store         db.Store
+	checkPassword func(ctx context.Context, password, hash string) error
+}
+
+func NewPasswordAuthenticator(store db.Store, checkPassword func(ctx context.Context, password, hash string) error) *PasswordAuthenticator {
+	return &PasswordAuthenticator{store: store, checkPassword: checkPassword}
+}
+
+func (a *PasswordAuthenticator) Authenticate(ctx context.Context, email, password string) (user sqlc.User, errs error) {
+	user, err := a.store.GetUser(ctx, email)
+	if err != nil {
+		if ierr.Is(err, sql.ErrNoRows) {
+			errs = errors.New(errors.CodeNotFound, "User is not found", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to get user", err)
+		return
+	}
+	// Users provisioned by the directory or a social login have no local password, leave them to the next backend
+	if user.Password == "" {
+		errs = errors.New(errors.CodeNotFound, "User is not found", nil)
+		return
+	}
+
+	err = a.checkPassword(ctx, password, user.Password)
+	if err != nil {
+		errs = errors.New(errors.CodeUnauthorized, "Wrong Password", err)
+		return
+	}
+	return
+}
+
+// ChainAuthenticator tries each authenticator in order until one knows the user
+type ChainAuthenticator []Authenticator
+
+func (c ChainAuthenticator) Authenticate(ctx context.Context, email, password string) (user sqlc.User, errs error) {
+	for _, authenticator := range c {
+		user, errs = authenticator.Authenticate(ctx, email, password)
+		var appErr *errors.AppError
+		if errs == nil || !ierr.As(errs, &appErr) || appErr.Code != errors.CodeNotFound {
+			return
+		}
+	}
+	return
+}
+
+// Directory is the part of ldapauth.Directory the LDAP authenticator needs
+type Directory interface {
+	Authenticate(ctx context.Context, username, password string) (ldapauth.Entry, error)
+}
+
+// LDAPAuthenticator binds against the directory and provisions the user on first login. The name and role are
+// synchronised from the directory on every login, so removing someone from a group takes effect on their next one.
+// Users with a local password are never adopted, a directory entry sharing their email cannot log in as them.
+type LDAPAuthenticator struct {
+	store       db.Store
+	directory   Directory
+	groupRoles  []ldapauth.GroupRole
+	defaultRole string
+}
+
+func NewLDAPAuthenticator(store db.Store, directory Directory, groupRoles []ldapauth.GroupRole, defaultRole string) *LDAPAuthenticator {
+	return &LDAPAuthenticator{store: store, directory: directory, groupRoles: groupRoles, defaultRole: defaultRole}
+}
+
+func (a *LDAPAuthenticator) Authenticate(ctx context.Context, email, password string) (user sqlc.User, errs error) {
+	entry, err := a.directory.Authenticate(ctx, email, password)
+	if err != nil {
+		switch {
+		case ierr.Is(err, ldapauth.ErrUserNotFound):
+			errs = errors.New(errors.CodeNotFound, "User is not found", err)
+		case ierr.Is(err, ldapauth.ErrInvalidCredentials):
+			errs = errors.New(errors.CodeUnauthorized, "Wrong Password", err)
+		default:
+			errs = errors.New(errors.CodeInternal, "Failed to authenticate user", err)
+		}
+		return
+	}
+
+	name := entry.Name
+	if name == "" {
+		name = entry.Email
+	}
+	role := ldapauth.RoleForGroups(a.groupRoles, entry.Groups, a.defaultRole)
+
+	user, err = a.store.GetUser(ctx, entry.Email)
+	if err != nil {
+		if !ierr.Is(err, sql.ErrNoRows) {
+			errs = errors.New(errors.CodeInternal, "Failed to get user", err)
+			return
+		}
+
+		// Directory users have no local password, so the local backend can never log them in
+		user, err = a.store.CreateUserWithRole(ctx, ToCreateUserWithRoleParams(name, entry.Email, role))
+		if err != nil {
+			errs = errors.New(errors.CodeInternal, "Failed to provision user", err)
+			return
+		}
+		return
+	}
+	if user.Password != "" {
+		errs = errors.New(errors.CodeConflict, "Email already exists", nil)
+		return
+	}
+
+	if user.Name != name || user.Role != role {
+		user, err = a.store.UpdateUserNameAndRole(ctx, ToUpdateUserNameAndRoleParams(user.ID, name, role))
+		if err != nil {
+			errs = errors.New(errors.CodeInternal, "Failed to update user", err)
+			return
+		}
+	}
+	return
+}
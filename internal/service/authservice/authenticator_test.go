@@ -0,0 +1,255 @@
+package authservice
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hanifsyahsn/go_boilerplate/internal/config"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/factory/userfactory"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/ldapauth"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/ldapauth/ldaptest"
+	"github.com/stretchr/testify/require"
+)
+
+const adminsGroup = "cn=admins,ou=groups,dc=example,dc=com"
+
+func newTestLDAPAuthenticator(t *testing.T, store db.Store) *LDAPAuthenticator {
+	server := ldaptest.NewServer(t, "cn=service,dc=example,dc=com", "service-secret",
+		ldaptest.Entry{
+			DN:       "uid=alice,ou=people,dc=example,dc=com",
+			Password: "alice-secret",
+			Attributes: map[string][]string{
+				"objectClass": {"person"},
+				"mail":        {"alice@example.com"},
+				"displayName": {"Alice"},
+				"memberOf":    {adminsGroup},
+			},
+		},
+	)
+
+	directory := ldapauth.NewDirectory(ldapauth.Config{
+		URL:            server.URL(),
+		BindDN:         "cn=service,dc=example,dc=com",
+		BindPassword:   "service-secret",
+		BaseDN:         "dc=example,dc=com",
+		UserFilter:     "(&(objectClass=person)(mail={username}))",
+		EmailAttribute: "mail",
+		NameAttribute:  "displayName",
+		GroupAttribute: "memberOf",
+		Timeout:        5 * time.Second,
+	})
+	return NewLDAPAuthenticator(store, directory, []ldapauth.GroupRole{{Group: adminsGroup, Role: "admin"}}, "user")
+}
+
+// directoryUser builds a user provisioned by the directory, which has no local password
+func directoryUser(opts *userfactory.Options) sqlc.User {
+	user := userfactory.NewOptions(opts)
+	user.Password = ""
+	return user
+}
+
+func requireCode(t *testing.T, err error, code errors.Code) {
+	appErr, ok := err.(*errors.AppError)
+	require.True(t, ok, "expected *errors.AppError, got %T", err)
+	require.Equal(t, code, appErr.Code)
+}
+
+func TestLDAPAuthenticator(t *testing.T) {
+	testCases := []struct {
+		name          string
+		email         string
+		password      string
+		buildStub     func(store *db.MockStore)
+		checkResponse func(t *testing.T, user sqlc.User, err error)
+	}{
+		{
+			name:     "provisions new user",
+			email:    "alice@example.com",
+			password: "alice-secret",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), "alice@example.com").Return(sqlc.User{}, sql.ErrNoRows)
+				store.EXPECT().CreateUserWithRole(gomock.Any(), ToCreateUserWithRoleParams("Alice", "alice@example.com", "admin")).
+					Return(userfactory.NewOptions(&userfactory.Options{ID: 7, Name: "Alice", Email: "alice@example.com", Role: "admin"}), nil)
+			},
+			checkResponse: func(t *testing.T, user sqlc.User, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(7), user.ID)
+				require.Equal(t, "admin", user.Role)
+			},
+		},
+		{
+			name:     "synchronises existing user",
+			email:    "alice@example.com",
+			password: "alice-secret",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), "alice@example.com").
+					Return(directoryUser(&userfactory.Options{ID: 7, Name: "Old", Email: "alice@example.com"}), nil)
+				store.EXPECT().UpdateUserNameAndRole(gomock.Any(), ToUpdateUserNameAndRoleParams(7, "Alice", "admin")).
+					Return(userfactory.NewOptions(&userfactory.Options{ID: 7, Name: "Alice", Email: "alice@example.com", Role: "admin"}), nil)
+			},
+			checkResponse: func(t *testing.T, user sqlc.User, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "Alice", user.Name)
+				require.Equal(t, "admin", user.Role)
+			},
+		},
+		{
+			name:     "existing user up to date",
+			email:    "alice@example.com",
+			password: "alice-secret",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), "alice@example.com").
+					Return(directoryUser(&userfactory.Options{ID: 7, Name: "Alice", Email: "alice@example.com", Role: "admin"}), nil)
+				store.EXPECT().UpdateUserNameAndRole(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, user sqlc.User, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(7), user.ID)
+			},
+		},
+		{
+			name:     "local account with the same email",
+			email:    "alice@example.com",
+			password: "alice-secret",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), "alice@example.com").
+					Return(userfactory.NewOptions(&userfactory.Options{ID: 7, Name: "Local Admin", Email: "alice@example.com", Role: "user"}), nil)
+				store.EXPECT().UpdateUserNameAndRole(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, user sqlc.User, err error) {
+				require.ErrorContains(t, err, "Email already exists")
+				requireCode(t, err, errors.CodeConflict)
+			},
+		},
+		{
+			name:     "wrong password",
+			email:    "alice@example.com",
+			password: "wrong",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, user sqlc.User, err error) {
+				require.ErrorContains(t, err, "Wrong Password")
+				requireCode(t, err, errors.CodeUnauthorized)
+			},
+		},
+		{
+			name:     "unknown user",
+			email:    "carol@example.com",
+			password: "carol-secret",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, user sqlc.User, err error) {
+				require.ErrorContains(t, err, "User is not found")
+				requireCode(t, err, errors.CodeNotFound)
+			},
+		},
+		{
+			name:     "failed to provision user",
+			email:    "alice@example.com",
+			password: "alice-secret",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), "alice@example.com").Return(sqlc.User{}, sql.ErrNoRows)
+				store.EXPECT().CreateUserWithRole(gomock.Any(), gomock.Any()).Return(sqlc.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, user sqlc.User, err error) {
+				require.ErrorContains(t, err, "Failed to provision user")
+				requireCode(t, err, errors.CodeInternal)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStore := db.NewMockStore(ctrl)
+			testCase.buildStub(mockStore)
+
+			user, err := newTestLDAPAuthenticator(t, mockStore).Authenticate(context.Background(), testCase.email, testCase.password)
+			testCase.checkResponse(t, user, err)
+		})
+	}
+}
+
+func TestChainAuthenticator(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := db.NewMockStore(ctrl)
+	password := util.RandomString(10)
+	hash, err := util.HashPassword(context.Background(), password)
+	require.NoError(t, err)
+	localUser := userfactory.NewOptions(&userfactory.Options{Password: hash})
+
+	chain := ChainAuthenticator{newTestLDAPAuthenticator(t, mockStore), NewPasswordAuthenticator(mockStore, util.CheckPasswordHash)}
+
+	// Unknown to the directory, so the local backend is asked
+	mockStore.EXPECT().GetUser(gomock.Any(), localUser.Email).Return(localUser, nil).Times(2)
+	user, err := chain.Authenticate(context.Background(), localUser.Email, password)
+	require.NoError(t, err)
+	require.Equal(t, localUser.ID, user.ID)
+
+	_, err = chain.Authenticate(context.Background(), localUser.Email, "wrong")
+	requireCode(t, err, errors.CodeUnauthorized)
+
+	// A wrong directory password stops the chain instead of falling back to the local one
+	_, err = chain.Authenticate(context.Background(), "alice@example.com", "wrong")
+	requireCode(t, err, errors.CodeUnauthorized)
+}
+
+func TestChainAuthenticatorLocalFirst(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := db.NewMockStore(ctrl)
+	directoryAlice := directoryUser(&userfactory.Options{ID: 7, Name: "Alice", Email: "alice@example.com", Role: "admin"})
+
+	chain := ChainAuthenticator{NewPasswordAuthenticator(mockStore, util.CheckPasswordHash), newTestLDAPAuthenticator(t, mockStore)}
+
+	// The provisioned directory user has no local password, so the directory is asked on every login
+	mockStore.EXPECT().GetUser(gomock.Any(), "alice@example.com").Return(directoryAlice, nil).Times(5)
+	for range 2 {
+		user, err := chain.Authenticate(context.Background(), "alice@example.com", "alice-secret")
+		require.NoError(t, err)
+		require.Equal(t, directoryAlice.ID, user.ID)
+	}
+
+	_, err := chain.Authenticate(context.Background(), "alice@example.com", "wrong")
+	requireCode(t, err, errors.CodeUnauthorized)
+}
+
+func TestNewAuthenticator(t *testing.T) {
+	testConf := conf
+
+	testConf.AuthBackends = "local"
+	authenticator, err := NewAuthenticator(testConf, nil, util.CheckPasswordHash)
+	require.NoError(t, err)
+	require.IsType(t, &PasswordAuthenticator{}, authenticator)
+
+	testConf.AuthBackends = "ldap, local"
+	testConf.LDAPGroupRoles = adminsGroup + ":admin"
+	authenticator, err = NewAuthenticator(testConf, nil, util.CheckPasswordHash)
+	require.NoError(t, err)
+	require.Len(t, authenticator, 2)
+
+	testConf.LDAPGroupRoles = "admins"
+	_, err = NewAuthenticator(testConf, nil, util.CheckPasswordHash)
+	require.Error(t, err)
+
+	testConf.AuthBackends = "kerberos"
+	_, err = NewAuthenticator(testConf, nil, util.CheckPasswordHash)
+	require.Error(t, err)
+
+	_, err = NewAuthenticator(config.Config{}, nil, util.CheckPasswordHash)
+	require.Error(t, err)
+}
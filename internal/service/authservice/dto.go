@@ -1,6 +1,8 @@
 package authservice
 
 import (
+	"time"
+
 	"github.com/hanifsyahsn/go_boilerplate/internal/service/userservice"
 )
 
@@ -30,10 +32,41 @@ type LoginResponse struct {
 	TokenResponse TokenResponse            `json:"token"`
 }
 
+type ReauthenticateRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
 type MeResponse struct {
-	UserResponse userservice.UserResponse `json:"user"`
+	UserResponse         userservice.UserResponse         `json:"user"`
+	ActiveOrganizationID *int64                           `json:"active_organization_id"`
+	Memberships          []userservice.MembershipResponse `json:"memberships"`
+	Impersonator         *userservice.UserResponse        `json:"impersonator"`
 }
 
 type RefreshTokenResponse struct {
 	AccessToken string `json:"access_token"`
 }
+
+type SessionResponse struct {
+	ID          string    `json:"id"`
+	DeviceLabel string    `json:"device_label"`
+	IpAddress   string    `json:"ip_address"`
+	UserAgent   string    `json:"user_agent"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastUsedAt  time.Time `json:"last_used_at"`
+	Current     bool      `json:"current"`
+}
+
+type SessionUri struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+type ImpersonateUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type ImpersonationResponse struct {
+	UserResponse userservice.UserResponse `json:"user"`
+	AccessToken  string                   `json:"access_token"`
+	ExpiresIn    int64                    `json:"expires_in"`
+}
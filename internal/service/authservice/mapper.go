@@ -1,10 +1,13 @@
 package authservice
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
 	"github.com/hanifsyahsn/go_boilerplate/internal/service/userservice"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/device"
 )
 
 func ToCreateUserParams(req RegisterRequest) (res sqlc.CreateUserParams) {
@@ -40,11 +43,52 @@ func ToRegisterResponse(user sqlc.User, accessToken string, refreshToken string)
 	return
 }
 
-func ToUpsertRefreshTokenParams(userId int64, refreshToken string, refreshTokenExpiration time.Time) (res sqlc.UpsertRefreshTokenParams) {
-	res = sqlc.UpsertRefreshTokenParams{
+func ToCreateRefreshTokenParams(userId int64, refreshToken string, refreshTokenExpiration time.Time, sessionId string, client device.Info) (res sqlc.CreateRefreshTokenParams) {
+	res = sqlc.CreateRefreshTokenParams{
 		UserID:       userId,
 		RefreshToken: refreshToken,
 		ExpiredAt:    refreshTokenExpiration,
+		SessionID:    sessionId,
+		DeviceLabel:  client.Label,
+		IpAddress:    client.IPAddress,
+		UserAgent:    client.UserAgent,
+	}
+	return
+}
+
+func ToDeleteRefreshTokenBySessionIdParams(userId int64, sessionId string) (res sqlc.DeleteRefreshTokenBySessionIdParams) {
+	res = sqlc.DeleteRefreshTokenBySessionIdParams{
+		UserID:    userId,
+		SessionID: sessionId,
+	}
+	return
+}
+
+func ToTouchRefreshTokenParams(id int64, client device.Info) (res sqlc.TouchRefreshTokenParams) {
+	res = sqlc.TouchRefreshTokenParams{
+		ID:        id,
+		IpAddress: client.IPAddress,
+	}
+	return
+}
+
+func ToSessionResponse(session sqlc.RefreshToken, currentSessionId string) (res SessionResponse) {
+	res = SessionResponse{
+		ID:          session.SessionID,
+		DeviceLabel: session.DeviceLabel,
+		IpAddress:   session.IpAddress,
+		UserAgent:   session.UserAgent,
+		CreatedAt:   session.CreatedAt,
+		LastUsedAt:  session.LastUsedAt,
+		Current:     session.SessionID == currentSessionId,
+	}
+	return
+}
+
+func ToSessionResponses(sessions []sqlc.RefreshToken, currentSessionId string) (res []SessionResponse) {
+	res = make([]SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		res = append(res, ToSessionResponse(session, currentSessionId))
 	}
 	return
 }
@@ -67,10 +111,65 @@ func ToGetRefreshTokenByUserIdParams(refreshToken string, userId int64) (res sql
 	return
 }
 
-func ToMeResponse(user sqlc.User) (res MeResponse) {
+func ToMeResponse(user sqlc.User, memberships []sqlc.ListMembershipsByUserIdRow, activeOrgId int64, impersonator sqlc.User) (res MeResponse) {
 	userResponse := userservice.SqlcUserToUserResponse(user)
 	res = MeResponse{
 		UserResponse: userResponse,
+		Memberships:  userservice.SqlcMembershipsToMembershipResponses(memberships),
+	}
+	if activeOrgId != 0 {
+		res.ActiveOrganizationID = &activeOrgId
+	}
+	if impersonator.ID != 0 {
+		impersonatorResponse := userservice.SqlcUserToUserResponse(impersonator)
+		res.Impersonator = &impersonatorResponse
+	}
+	return
+}
+
+func ToHasRolePermissionParams(role, permission string) (res sqlc.HasRolePermissionParams) {
+	res = sqlc.HasRolePermissionParams{
+		Name:       role,
+		Permission: permission,
+	}
+	return
+}
+
+func ToImpersonationAuditLogParams(userId, adminId int64, client device.Info, requestId string) (res sqlc.CreateAuditLogParams) {
+	res = sqlc.CreateAuditLogParams{
+		UserID:         userId,
+		ImpersonatorID: sql.NullInt64{Int64: adminId, Valid: true},
+		Action:         constant.AuditActionImpersonationStart,
+		IpAddress:      client.IPAddress,
+		UserAgent:      client.UserAgent,
+		RequestID:      requestId,
+	}
+	return
+}
+
+func ToImpersonationResponse(user sqlc.User, accessToken string, expiresIn int64) (res ImpersonationResponse) {
+	res = ImpersonationResponse{
+		UserResponse: userservice.SqlcUserToUserResponse(user),
+		AccessToken:  accessToken,
+		ExpiresIn:    expiresIn,
+	}
+	return
+}
+
+func ToCreateUserWithRoleParams(name, email, role string) (res sqlc.CreateUserWithRoleParams) {
+	res = sqlc.CreateUserWithRoleParams{
+		Name:  name,
+		Email: email,
+		Role:  role,
+	}
+	return
+}
+
+func ToUpdateUserNameAndRoleParams(userId int64, name, role string) (res sqlc.UpdateUserNameAndRoleParams) {
+	res = sqlc.UpdateUserNameAndRoleParams{
+		ID:   userId,
+		Name: name,
+		Role: role,
 	}
 	return
 }
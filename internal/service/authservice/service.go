@@ -5,17 +5,22 @@ import (
 	"database/sql"
 	ierr "errors"
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/hanifsyahsn/go_boilerplate/internal/config"
 	"github.com/hanifsyahsn/go_boilerplate/internal/db"
 	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
 	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/device"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/dpop"
 	"github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/metrics"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/mtls"
 	"github.com/hanifsyahsn/go_boilerplate/internal/util/redis"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/requestid"
 	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
 	"github.com/lib/pq"
 	redisClient "github.com/redis/go-redis/v9"
@@ -23,27 +28,53 @@ import (
 
 type Service struct {
 	store         db.Store
-	hashPassword  func(password string) (string, error)
-	checkPassword func(password, hash string) error
+	hashPassword  func(ctx context.Context, password string) (string, error)
+	checkPassword func(ctx context.Context, password, hash string) error
 	tokenMaker    token.Maker
 	config        config.Config
 	redis         redis.Client
+	authenticator Authenticator
 }
 
 func NewService(
 	store db.Store,
-	hashFunc func(string) (string, error),
-	checkPassword func(password, hash string) error,
+	hashFunc func(context.Context, string) (string, error),
+	checkPassword func(ctx context.Context, password, hash string) error,
 	tokenMaker token.Maker,
 	config config.Config,
 	redis redis.Client,
 ) *Service {
-	return &Service{store: store, hashPassword: hashFunc, checkPassword: checkPassword, tokenMaker: tokenMaker, config: config, redis: redis}
+	return &Service{
+		store:         store,
+		hashPassword:  hashFunc,
+		checkPassword: checkPassword,
+		tokenMaker:    tokenMaker,
+		config:        config,
+		redis:         redis,
+		authenticator: NewPasswordAuthenticator(store, checkPassword),
+	}
+}
+
+// WithAuthenticator replaces the local password check used by LoginService
+func (service *Service) WithAuthenticator(authenticator Authenticator) *Service {
+	service.authenticator = authenticator
+	return service
 }
 
 func (service *Service) RegisterService(context context.Context, request RegisterRequest) (user sqlc.User, accessToken, refreshToken string, errs error) {
+	if !service.config.SelfRegistrationEnabled() {
+		errs = errors.New(errors.CodeForbidden, "Self-registration is disabled", nil)
+		return
+	}
+
+	return service.CreateAccountService(context, request)
+}
+
+// CreateAccountService registers a user regardless of the registration mode and starts their session. The hooks
+// run in the registration transaction, see db.RegisterTxHook.
+func (service *Service) CreateAccountService(context context.Context, request RegisterRequest, hooks ...db.RegisterTxHook) (user sqlc.User, accessToken, refreshToken string, errs error) {
 	var err error
-	request.Password, err = service.hashPassword(request.Password)
+	request.Password, err = service.hashPassword(context, request.Password)
 	if err != nil {
 		errs = errors.New(errors.CodeInternal, "Failed to process user password", err)
 		return
@@ -51,7 +82,7 @@ func (service *Service) RegisterService(context context.Context, request Registe
 
 	arg := ToCreateUserParams(request)
 
-	user, accessToken, refreshToken, accessClaims, _, err := service.store.RegisterTx(context, arg)
+	user, accessToken, refreshToken, accessClaims, _, err := service.store.RegisterTx(context, arg, hooks...)
 	if err != nil {
 		var pqErr *pq.Error
 		if ierr.As(err, &pqErr) {
@@ -66,33 +97,96 @@ func (service *Service) RegisterService(context context.Context, request Registe
 		return
 	}
 
-	err = service.setJti(accessClaims, user.ID)
+	err = service.setJti(context, accessClaims, user.ID)
 	if err != nil {
 		errs = err
 		return
 	}
 
+	metrics.Registrations.Inc()
 	return
 }
 
+// ImpersonationTokenDuration is the lifetime of the tokens issued by ImpersonateService
+func (service *Service) ImpersonationTokenDuration() time.Duration {
+	return service.config.ImpersonationTokenDuration
+}
+
+// SelfRegistrationEnabled reports whether new accounts may be created without an invitation
+func (service *Service) SelfRegistrationEnabled() bool {
+	return service.config.SelfRegistrationEnabled()
+}
+
 func (service *Service) LoginService(context context.Context, request LoginRequest) (user sqlc.User, accessToken, refreshToken string, errs error) {
-	user, err := service.store.GetUser(context, request.Email)
-	if err != nil {
-		if ierr.Is(err, sql.ErrNoRows) {
-			errs = errors.New(errors.CodeNotFound, "User is not found", err)
-			return
-		}
-		errs = errors.New(errors.CodeInternal, "Failed to get user", err)
+	user, errs = service.authenticator.Authenticate(context, request.Email, request.Password)
+	if errs != nil {
+		metrics.Logins.WithLabelValues(metrics.LoginFailure).Inc()
 		return
 	}
 
-	err = service.checkPassword(request.Password, user.Password)
-	if err != nil {
-		errs = errors.New(errors.CodeUnauthorized, "Wrong Password", err)
+	accessToken, refreshToken, errs = service.IssueTokensService(context, user)
+	switch {
+	case errs == nil:
+		metrics.Logins.WithLabelValues(metrics.LoginSuccess).Inc()
+	case !user.Active:
+		metrics.Logins.WithLabelValues(metrics.LoginLocked).Inc()
+	default:
+		metrics.Logins.WithLabelValues(metrics.LoginFailure).Inc()
+	}
+	return
+}
+
+// ReauthenticateService confirms the password of an already signed in user and re-issues the tokens of the
+// current session with a fresh auth_time, see auth.RequireRecentAuth.
+func (service *Service) ReauthenticateService(context context.Context, email, password string, session token.Session) (user sqlc.User, accessToken, refreshToken string, errs error) {
+	if session.ActorId != 0 {
+		errs = errors.New(errors.CodeForbidden, "Not allowed while impersonating", nil)
+		return
+	}
+
+	user, errs = service.authenticator.Authenticate(context, email, password)
+	if errs != nil {
 		return
 	}
 
-	accessToken, refreshToken, accessClaims, refreshClaims, err := service.tokenMaker.CreateToken(user, service.config.AccessTokenDuration, service.config.RefreshTokenDuration)
+	session.AuthTime = time.Now().Unix()
+	accessToken, refreshToken, errs = service.IssueSessionTokensService(context, user, session)
+	return
+}
+
+// IssueTokensService starts a new session for an already authenticated user: it creates the access / refresh
+// token pair, stores the refresh token with the client info of the request and registers the access token jti of
+// the session in Redis.
+func (service *Service) IssueTokensService(context context.Context, user sqlc.User) (accessToken, refreshToken string, errs error) {
+	return service.IssueSessionTokensService(context, user, token.Session{})
+}
+
+// IssueSessionTokensService is IssueTokensService with session claims, e.g. the active organization. When the
+// session id is set the tokens replace that session instead of starting a new one. Impersonation sessions are
+// never re-issued, see ImpersonateService.
+func (service *Service) IssueSessionTokensService(context context.Context, user sqlc.User, session token.Session) (accessToken, refreshToken string, errs error) {
+	if !user.Active {
+		errs = errors.New(errors.CodeUnauthorized, "User is deactivated", nil)
+		return
+	}
+
+	if session.ActorId != 0 {
+		errs = errors.New(errors.CodeForbidden, "Impersonation sessions cannot be re-issued", nil)
+		return
+	}
+
+	if session.SessionId == "" {
+		session.SessionId = uuid.New().String()
+	} else {
+		_, err := service.store.DeleteRefreshTokenBySessionId(context, ToDeleteRefreshTokenBySessionIdParams(user.ID, session.SessionId))
+		if err != nil && !ierr.Is(err, sql.ErrNoRows) {
+			errs = errors.New(errors.CodeInternal, "Failed to login user", err)
+			return
+		}
+	}
+
+	session = mtls.Bind(context, dpop.Bind(context, session))
+	accessToken, refreshToken, accessClaims, refreshClaims, err := service.tokenMaker.CreateToken(context, user, session, service.config.AccessTokenDuration, service.config.RefreshTokenDuration)
 	if err != nil {
 		errs = errors.New(errors.CodeInternal, "Failed to login user", err)
 		return
@@ -105,23 +199,15 @@ func (service *Service) LoginService(context context.Context, request LoginReque
 	}
 	expiresAt := time.Unix(refreshTokenExp, 0)
 
-	upsertRefreshTokenParams := ToUpsertRefreshTokenParams(user.ID, token.HashToken(refreshToken), expiresAt)
+	createRefreshTokenParams := ToCreateRefreshTokenParams(user.ID, token.HashToken(refreshToken), expiresAt, session.SessionId, device.FromContext(context))
 
-	_, err = service.store.UpsertRefreshToken(context, upsertRefreshTokenParams)
+	_, err = service.store.CreateRefreshToken(context, createRefreshTokenParams)
 	if err != nil {
 		errs = errors.New(errors.CodeInternal, "Failed to login user", err)
 		return
 	}
 
-	userKey := "user:access:" + strconv.Itoa(int(user.ID))
-
-	err = service.redis.Del(userKey)
-	if err != nil && !ierr.Is(err, redisClient.Nil) {
-		errs = errors.New(errors.CodeInternal, "Failed to login user", err)
-		return
-	}
-
-	err = service.setJti(accessClaims, user.ID)
+	err = service.setJti(context, accessClaims, user.ID)
 	if err != nil {
 		errs = err
 		return
@@ -130,7 +216,7 @@ func (service *Service) LoginService(context context.Context, request LoginReque
 	return
 }
 
-func (service *Service) setJti(accessClaims jwt.MapClaims, userID int64) (errs error) {
+func (service *Service) setJti(context context.Context, accessClaims jwt.MapClaims, userID int64) (errs error) {
 	jti, ok := accessClaims[constant.JsonWebTokenIdKey].(string)
 	if !ok {
 		errs = errors.New(errors.CodeInternal, "Failed to register user", fmt.Errorf("JSON web token ID is not a string"))
@@ -148,9 +234,7 @@ func (service *Service) setJti(accessClaims jwt.MapClaims, userID int64) (errs e
 		ttl = time.Second
 	}
 
-	key := "user:access:" + strconv.Itoa(int(userID))
-
-	err := service.redis.Set(key, jti, ttl)
+	err := redis.SetUserAccess(context, service.redis, userID, token.SessionFromClaims(accessClaims).SessionId, jti, ttl)
 	if err != nil {
 		errs = errors.New(errors.CodeInternal, "Failed to register user", err)
 		return
@@ -161,7 +245,7 @@ func (service *Service) setJti(accessClaims jwt.MapClaims, userID int64) (errs e
 func (service *Service) LogoutService(context context.Context, refreshToken string, userId int64) (errs error) {
 	hashedToken := token.HashToken(refreshToken)
 	arg := ToGetRefreshTokenByUserIdParams(hashedToken, userId)
-	_, err := service.store.GetRefreshTokenByUserId(context, arg)
+	storedToken, err := service.store.GetRefreshTokenByUserId(context, arg)
 	if err != nil {
 		if ierr.Is(err, sql.ErrNoRows) {
 			errs = errors.New(errors.CodeNotFound, "Failed to logout user", err)
@@ -177,21 +261,22 @@ func (service *Service) LogoutService(context context.Context, refreshToken stri
 		return
 	}
 
-	userKey := "user:access:" + strconv.Itoa(int(userId))
-
-	err = service.redis.Del(userKey)
+	err = redis.DeleteUserAccess(context, service.redis, userId, storedToken.SessionID)
 	if err != nil {
 		errs = errors.New(errors.CodeInternal, "Failed to logout user", err)
 		return
 	}
 
+	metrics.Logouts.Inc()
 	return
 }
 
-func (service *Service) RefreshAccessTokenService(context context.Context, refreshToken, email string, userId int64, jti string) (accessToken, refreshTokenR string, errs error) {
+// RefreshAccessTokenService issues a new access token for the session of the refresh token. The new jti replaces
+// the one registered for the session, so the previous access token of the session stops working.
+func (service *Service) RefreshAccessTokenService(context context.Context, refreshToken, email string, userId int64, session token.Session) (accessToken, refreshTokenR string, errs error) {
 	hashedToken := token.HashToken(refreshToken)
 	arg := ToGetRefreshTokenByUserIdParams(hashedToken, userId)
-	_, err := service.store.GetRefreshTokenByUserId(context, arg)
+	storedToken, err := service.store.GetRefreshTokenByUserId(context, arg)
 	if err != nil {
 		if ierr.Is(err, sql.ErrNoRows) {
 			errs = errors.New(errors.CodeNotFound, "Failed to refresh token", err)
@@ -200,18 +285,164 @@ func (service *Service) RefreshAccessTokenService(context context.Context, refre
 		errs = errors.New(errors.CodeInternal, "Failed to refresh token", err)
 		return
 	}
+	session.SessionId = storedToken.SessionID
+	session = mtls.Bind(context, dpop.Bind(context, session))
 
-	accessToken, err = service.tokenMaker.RefreshToken(email, userId, service.config.AccessTokenDuration, jti)
+	jti := uuid.New().String()
+	accessToken, err = service.tokenMaker.RefreshToken(context, email, userId, session, service.config.AccessTokenDuration, jti)
 	if err != nil {
 		errs = errors.New(errors.CodeInternal, "Failed to refresh token", err)
 		return
 	}
 
+	err = service.store.TouchRefreshToken(context, ToTouchRefreshTokenParams(storedToken.ID, device.FromContext(context)))
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to refresh token", err)
+		return
+	}
+
+	err = redis.SetUserAccess(context, service.redis, userId, session.SessionId, jti, service.config.AccessTokenDuration)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to refresh token", err)
+		return
+	}
+
+	metrics.Refreshes.Inc()
 	refreshTokenR = refreshToken
 	return
 }
 
-func (service *Service) MeService(context context.Context, email string) (user sqlc.User, errs error) {
+// ListSessionsService returns the active sessions of a user, most recently used first
+func (service *Service) ListSessionsService(context context.Context, userId int64) (sessions []sqlc.RefreshToken, errs error) {
+	sessions, err := service.store.ListRefreshTokensByUserId(context, userId)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to list sessions", err)
+		return
+	}
+	return
+}
+
+// RevokeSessionService signs a single device out: its refresh token is deleted and its access token jti dropped
+func (service *Service) RevokeSessionService(context context.Context, userId int64, sessionId string) (errs error) {
+	_, err := service.store.DeleteRefreshTokenBySessionId(context, ToDeleteRefreshTokenBySessionIdParams(userId, sessionId))
+	if err != nil {
+		if ierr.Is(err, sql.ErrNoRows) {
+			errs = errors.New(errors.CodeNotFound, "Session is not found", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to revoke session", err)
+		return
+	}
+
+	err = redis.DeleteUserAccess(context, service.redis, userId, sessionId)
+	if err != nil && !ierr.Is(err, redisClient.Nil) {
+		errs = errors.New(errors.CodeInternal, "Failed to revoke session", err)
+		return
+	}
+	return
+}
+
+// LogoutAllService signs the user out of every session, including the current one
+func (service *Service) LogoutAllService(context context.Context, userId int64) (errs error) {
+	sessions, err := service.store.ListRefreshTokensByUserId(context, userId)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to logout user", err)
+		return
+	}
+
+	err = service.store.DeleteRefreshTokensByUserId(context, userId)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to logout user", err)
+		return
+	}
+
+	sessionIds := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		sessionIds = append(sessionIds, session.SessionID)
+	}
+	err = redis.DeleteUserAccess(context, service.redis, userId, sessionIds...)
+	if err != nil && !ierr.Is(err, redisClient.Nil) {
+		errs = errors.New(errors.CodeInternal, "Failed to logout user", err)
+		return
+	}
+
+	metrics.Logouts.Inc()
+	return
+}
+
+// ImpersonateService lets an admin act as another user. The access token carries the admin in its act claim, is
+// short-lived and comes without a refresh token, so the session ends when it expires. The session is still recorded
+// like a login so listing, revoking or logging out every session of the user, and SCIM deprovisioning, end it too.
+// Users who may impersonate others themselves cannot be impersonated.
+func (service *Service) ImpersonateService(context context.Context, adminId, userId int64) (user sqlc.User, accessToken string, errs error) {
+	if adminId == userId {
+		errs = errors.New(errors.CodeBadRequest, "Cannot impersonate yourself", nil)
+		return
+	}
+
+	user, err := service.store.GetUserByID(context, userId)
+	if err != nil {
+		if ierr.Is(err, sql.ErrNoRows) {
+			errs = errors.New(errors.CodeNotFound, "User is not found", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to impersonate user", err)
+		return
+	}
+
+	if !user.Active {
+		errs = errors.New(errors.CodeForbidden, "User is deactivated", nil)
+		return
+	}
+
+	privileged, err := service.store.HasRolePermission(context, ToHasRolePermissionParams(user.Role, constant.PermissionImpersonate))
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to impersonate user", err)
+		return
+	}
+	if privileged {
+		errs = errors.New(errors.CodeForbidden, "Cannot impersonate a privileged user", nil)
+		return
+	}
+
+	session := mtls.Bind(context, dpop.Bind(context, token.Session{SessionId: uuid.New().String(), ActorId: adminId}))
+	accessToken, refreshToken, accessClaims, refreshClaims, err := service.tokenMaker.CreateToken(context, user, session, service.config.ImpersonationTokenDuration, service.config.ImpersonationTokenDuration)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to impersonate user", err)
+		return
+	}
+
+	refreshTokenExp, ok := refreshClaims[constant.ExpirationKey].(int64)
+	if !ok {
+		errs = errors.New(errors.CodeInternal, "Failed to impersonate user", fmt.Errorf("token expiration is not an integer"))
+		return
+	}
+
+	// The refresh token is never handed out, its row only records the session
+	createRefreshTokenParams := ToCreateRefreshTokenParams(user.ID, token.HashToken(refreshToken), time.Unix(refreshTokenExp, 0), session.SessionId, device.FromContext(context))
+	_, err = service.store.CreateRefreshToken(context, createRefreshTokenParams)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to impersonate user", err)
+		return
+	}
+
+	err = service.setJti(context, accessClaims, user.ID)
+	if err != nil {
+		errs = err
+		return
+	}
+
+	_, err = service.store.CreateAuditLog(context, ToImpersonationAuditLogParams(user.ID, adminId, device.FromContext(context), requestid.FromContext(context).RequestID))
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to impersonate user", err)
+		return
+	}
+	return
+}
+
+// MeService returns the user together with the organizations they are a member of and, during impersonation, the
+// acting admin
+func (service *Service) MeService(context context.Context, email string, impersonatorId int64) (user sqlc.User, memberships []sqlc.ListMembershipsByUserIdRow, impersonator sqlc.User, errs error) {
 	user, err := service.store.GetUser(context, email)
 	if err != nil {
 		if ierr.Is(err, sql.ErrNoRows) {
@@ -221,5 +452,19 @@ func (service *Service) MeService(context context.Context, email string) (user s
 		errs = errors.New(errors.CodeInternal, "Failed to get user", err)
 		return
 	}
+
+	memberships, err = service.store.ListMembershipsByUserId(context, user.ID)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to get user", err)
+		return
+	}
+
+	if impersonatorId != 0 {
+		impersonator, err = service.store.GetUserByID(context, impersonatorId)
+		if err != nil {
+			errs = errors.New(errors.CodeInternal, "Failed to get user", err)
+			return
+		}
+	}
 	return
 }
@@ -16,9 +16,15 @@ import (
 	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
 	"github.com/hanifsyahsn/go_boilerplate/internal/factory/userfactory"
 	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/device"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/dpop"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/metrics"
 	"github.com/hanifsyahsn/go_boilerplate/internal/util/redis"
 	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
 	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 
 	//"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
@@ -34,7 +40,7 @@ func (e eqCreateUserParamsMatcher) Matches(x interface{}) bool {
 	if !ok {
 		return false
 	}
-	err := util.CheckPasswordHash(e.password, arg.Password)
+	err := util.CheckPasswordHash(context.Background(), e.password, arg.Password)
 	if err != nil {
 		return false
 	}
@@ -55,7 +61,7 @@ func TestRegisterService(t *testing.T) {
 
 	testCases := []struct {
 		name               string
-		svc                func(mockStore *db.MockStore, hashFunc func(string) (string, error), checkPassword func(password, hash string) error, tk token.Maker, conf config.Config, redis redis.Client) *Service
+		svc                func(mockStore *db.MockStore, hashFunc func(context.Context, string) (string, error), checkPassword func(ctx context.Context, password, hash string) error, tk token.Maker, conf config.Config, redis redis.Client) *Service
 		registerRequest    RegisterRequest
 		toCreateUserParams func(r RegisterRequest) sqlc.CreateUserParams
 		user               sqlc.User
@@ -66,7 +72,7 @@ func TestRegisterService(t *testing.T) {
 	}{
 		{
 			name: "success",
-			svc: func(mockStore *db.MockStore, hashFunc func(string) (string, error), checkPassword func(password, hash string) error, tk token.Maker, conf config.Config, redis redis.Client) *Service {
+			svc: func(mockStore *db.MockStore, hashFunc func(context.Context, string) (string, error), checkPassword func(ctx context.Context, password, hash string) error, tk token.Maker, conf config.Config, redis redis.Client) *Service {
 				service := NewService(mockStore, hashFunc, checkPassword, tk, conf, redis)
 				require.NotNil(t, service)
 				return service
@@ -85,7 +91,7 @@ func TestRegisterService(t *testing.T) {
 				return
 			},
 			token: func(tk token.Maker, conf config.Config, user sqlc.User) (accessToken string, refreshToken string, err error) {
-				accessToken, refreshToken, accessTokenClaims, refreshTokenClaims, err := tk.CreateToken(user, conf.AccessTokenDuration, conf.RefreshTokenDuration)
+				accessToken, refreshToken, accessTokenClaims, refreshTokenClaims, err := tk.CreateToken(context.Background(), user, token.Session{}, conf.AccessTokenDuration, conf.RefreshTokenDuration)
 				tokenChecker(t, err, accessToken, refreshToken, accessTokenClaims, refreshTokenClaims)
 				return
 			},
@@ -95,7 +101,8 @@ func TestRegisterService(t *testing.T) {
 					"exp": time.Now().Add(time.Hour).Unix(),
 				}
 				store.EXPECT().RegisterTx(gomock.Any(), EqCreateUserParams(param, password)).Times(1).Return(user, accessToken, refreshToken, claims, jwt.MapClaims{}, nil)
-				client.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil)
+				client.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil)
+				client.EXPECT().Publish(gomock.Any(), redis.AccessInvalidationChannel, gomock.Any()).Times(1).Return(nil)
 
 			},
 			checkResponse: func(t *testing.T, got, registerResponse RegisterResponse, err error) {
@@ -104,8 +111,8 @@ func TestRegisterService(t *testing.T) {
 		},
 		{
 			name: "failed to hash password",
-			svc: func(mockStore *db.MockStore, hashFunc func(string) (string, error), checkPassword func(password, hash string) error, tk token.Maker, conf config.Config, redis redis.Client) *Service {
-				hashFunc = func(password string) (string, error) {
+			svc: func(mockStore *db.MockStore, hashFunc func(context.Context, string) (string, error), checkPassword func(ctx context.Context, password, hash string) error, tk token.Maker, conf config.Config, redis redis.Client) *Service {
+				hashFunc = func(ctx context.Context, password string) (string, error) {
 					return "", fmt.Errorf("failed to hash password")
 				}
 				service := NewService(mockStore, hashFunc, checkPassword, tk, conf, redis)
@@ -135,7 +142,7 @@ func TestRegisterService(t *testing.T) {
 		},
 		{
 			name: "email unique violation",
-			svc: func(mockStore *db.MockStore, hashFunc func(string) (string, error), checkPassword func(password, hash string) error, tk token.Maker, conf config.Config, redis redis.Client) *Service {
+			svc: func(mockStore *db.MockStore, hashFunc func(context.Context, string) (string, error), checkPassword func(ctx context.Context, password, hash string) error, tk token.Maker, conf config.Config, redis redis.Client) *Service {
 				service := NewService(mockStore, hashFunc, checkPassword, tk, conf, redis)
 				require.NotNil(t, service)
 				return service
@@ -170,7 +177,7 @@ func TestRegisterService(t *testing.T) {
 		},
 		{
 			name: "failed to register user",
-			svc: func(mockStore *db.MockStore, hashFunc func(string) (string, error), checkPassword func(password, hash string) error, tk token.Maker, conf config.Config, redis redis.Client) *Service {
+			svc: func(mockStore *db.MockStore, hashFunc func(context.Context, string) (string, error), checkPassword func(ctx context.Context, password, hash string) error, tk token.Maker, conf config.Config, redis redis.Client) *Service {
 				service := NewService(mockStore, hashFunc, checkPassword, tk, conf, redis)
 				require.NotNil(t, service)
 				return service
@@ -252,35 +259,54 @@ func responseChecker(t *testing.T, res RegisterResponse, registerResponse Regist
 	require.WithinDuration(t, registerResponse.UserResponse.UpdatedAt, res.UserResponse.UpdatedAt, time.Second)
 }
 
+func TestRegisterServiceSelfRegistrationDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := db.NewMockStore(ctrl)
+	mockStore.EXPECT().RegisterTx(gomock.Any(), gomock.Any()).Times(0)
+
+	inviteOnly := conf
+	inviteOnly.RegistrationMode = "invite"
+	svc := NewService(mockStore, util.HashPassword, util.CheckPasswordHash, tokenMaker, inviteOnly, redis.NewMockClient(ctrl))
+
+	_, _, _, err := svc.RegisterService(context.Background(), RegisterRequest{Name: util.RandomString(6), Email: "new@mail.com", Password: util.RandomString(10)})
+	requireCode(t, err, errors.CodeForbidden)
+}
+
 func TestMeService(t *testing.T) {
 	testCases := []struct {
 		name          string
-		svc           func(store *db.MockStore, hashPassword func(string) (string, error), checkPasswordHash func(string, string) error, tokenMaker token.Maker, config config.Config, redis redis.Client) *Service
+		svc           func(store *db.MockStore, hashPassword func(context.Context, string) (string, error), checkPasswordHash func(context.Context, string, string) error, tokenMaker token.Maker, config config.Config, redis redis.Client) *Service
 		user          sqlc.User
 		buildStub     func(store *db.MockStore, user sqlc.User)
 		checkResponse func(t *testing.T, expect, got sqlc.User, err error)
 	}{
 		{
 			name: "success",
-			svc: func(store *db.MockStore, hashPassword func(string) (string, error), checkPasswordHash func(string, string) error, tokenMaker token.Maker, config config.Config, redis redis.Client) *Service {
+			svc: func(store *db.MockStore, hashPassword func(context.Context, string) (string, error), checkPasswordHash func(context.Context, string, string) error, tokenMaker token.Maker, config config.Config, redis redis.Client) *Service {
 				return NewService(store, hashPassword, checkPasswordHash, tokenMaker, config, redis)
 			},
 			user: userfactory.NewOptions(nil),
 			buildStub: func(store *db.MockStore, user sqlc.User) {
 				store.EXPECT().GetUser(gomock.Any(), user.Email).Return(user, nil)
+				store.EXPECT().ListMembershipsByUserId(gomock.Any(), user.ID).Return([]sqlc.ListMembershipsByUserIdRow{}, nil)
 			},
 			checkResponse: func(t *testing.T, expect, got sqlc.User, err error) {
 				meServiceResponseChecker(t, expect, got, err)
 			},
 		},
 		{
-			name: "success",
-			svc: func(store *db.MockStore, hashPassword func(string) (string, error), checkPasswordHash func(string, string) error, tokenMaker token.Maker, config config.Config, redis redis.Client) *Service {
+			name: "success with memberships",
+			svc: func(store *db.MockStore, hashPassword func(context.Context, string) (string, error), checkPasswordHash func(context.Context, string, string) error, tokenMaker token.Maker, config config.Config, redis redis.Client) *Service {
 				return NewService(store, hashPassword, checkPasswordHash, tokenMaker, config, redis)
 			},
 			user: userfactory.NewOptions(nil),
 			buildStub: func(store *db.MockStore, user sqlc.User) {
 				store.EXPECT().GetUser(gomock.Any(), user.Email).Return(user, nil)
+				store.EXPECT().ListMembershipsByUserId(gomock.Any(), user.ID).Return([]sqlc.ListMembershipsByUserIdRow{
+					{OrganizationID: 1, Name: util.RandomString(6), Role: constant.OrgRoleOwner},
+				}, nil)
 			},
 			checkResponse: func(t *testing.T, expect, got sqlc.User, err error) {
 				meServiceResponseChecker(t, expect, got, err)
@@ -288,7 +314,7 @@ func TestMeService(t *testing.T) {
 		},
 		{
 			name: "user not found",
-			svc: func(store *db.MockStore, hashPassword func(string) (string, error), checkPasswordHash func(string, string) error, tokenMaker token.Maker, config config.Config, redis redis.Client) *Service {
+			svc: func(store *db.MockStore, hashPassword func(context.Context, string) (string, error), checkPasswordHash func(context.Context, string, string) error, tokenMaker token.Maker, config config.Config, redis redis.Client) *Service {
 				return NewService(store, hashPassword, checkPasswordHash, tokenMaker, config, redis)
 			},
 			user: userfactory.NewOptions(nil),
@@ -302,7 +328,7 @@ func TestMeService(t *testing.T) {
 		},
 		{
 			name: "failed to get user",
-			svc: func(store *db.MockStore, hashPassword func(string) (string, error), checkPasswordHash func(string, string) error, tokenMaker token.Maker, config config.Config, redis redis.Client) *Service {
+			svc: func(store *db.MockStore, hashPassword func(context.Context, string) (string, error), checkPasswordHash func(context.Context, string, string) error, tokenMaker token.Maker, config config.Config, redis redis.Client) *Service {
 				return NewService(store, hashPassword, checkPasswordHash, tokenMaker, config, redis)
 			},
 			user: userfactory.NewOptions(nil),
@@ -314,6 +340,20 @@ func TestMeService(t *testing.T) {
 				require.ErrorContains(t, err, "Failed to get user")
 			},
 		},
+		{
+			name: "failed to get memberships",
+			svc: func(store *db.MockStore, hashPassword func(context.Context, string) (string, error), checkPasswordHash func(context.Context, string, string) error, tokenMaker token.Maker, config config.Config, redis redis.Client) *Service {
+				return NewService(store, hashPassword, checkPasswordHash, tokenMaker, config, redis)
+			},
+			user: userfactory.NewOptions(nil),
+			buildStub: func(store *db.MockStore, user sqlc.User) {
+				store.EXPECT().GetUser(gomock.Any(), user.Email).Return(user, nil)
+				store.EXPECT().ListMembershipsByUserId(gomock.Any(), user.ID).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, expect, got sqlc.User, err error) {
+				require.ErrorContains(t, err, "Failed to get user")
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -330,7 +370,7 @@ func TestMeService(t *testing.T) {
 
 			user := testCase.user
 
-			userRes, err := svc.MeService(context.Background(), user.Email)
+			userRes, _, _, err := svc.MeService(context.Background(), user.Email, 0)
 			testCase.checkResponse(t, user, userRes, err)
 		})
 	}
@@ -344,3 +384,457 @@ func meServiceResponseChecker(t *testing.T, expect, got sqlc.User, err error) {
 	require.WithinDuration(t, expect.CreatedAt, got.CreatedAt, time.Second)
 	require.WithinDuration(t, expect.UpdatedAt, got.UpdatedAt, time.Second)
 }
+
+func TestIssueTokensServiceStartsSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := db.NewMockStore(ctrl)
+	mockRedis := redis.NewMockClient(ctrl)
+	svc := NewService(mockStore, util.HashPassword, util.CheckPasswordHash, tokenMaker, conf, mockRedis)
+
+	user := userfactory.NewOptions(nil)
+	client := device.NewInfo("10.0.0.1", "curl/8.7.1")
+
+	var stored sqlc.CreateRefreshTokenParams
+	mockStore.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, arg sqlc.CreateRefreshTokenParams) (sqlc.RefreshToken, error) {
+		stored = arg
+		return sqlc.RefreshToken{}, nil
+	})
+	mockRedis.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, key string, value interface{}, ttl time.Duration) error {
+		require.Equal(t, redis.UserAccessKey(user.ID, stored.SessionID), key)
+		return nil
+	})
+	mockRedis.EXPECT().Publish(gomock.Any(), redis.AccessInvalidationChannel, gomock.Any()).Times(1).Return(nil)
+
+	accessToken, refreshToken, err := svc.IssueTokensService(device.WithInfo(context.Background(), client), user)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, stored.SessionID)
+	require.Equal(t, token.HashToken(refreshToken), stored.RefreshToken)
+	require.Equal(t, "curl", stored.DeviceLabel)
+	require.Equal(t, client.IPAddress, stored.IpAddress)
+	require.Equal(t, client.UserAgent, stored.UserAgent)
+
+	_, claims, err := tokenMaker.VerifyToken(accessToken)
+	require.NoError(t, err)
+	require.Equal(t, stored.SessionID, token.SessionFromClaims(claims).SessionId)
+}
+
+func TestIssueTokensServiceBindsDPoPKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := db.NewMockStore(ctrl)
+	mockRedis := redis.NewMockClient(ctrl)
+	svc := NewService(mockStore, util.HashPassword, util.CheckPasswordHash, tokenMaker, conf, mockRedis)
+
+	mockStore.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.RefreshToken{}, nil)
+	mockRedis.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil)
+	mockRedis.EXPECT().Publish(gomock.Any(), redis.AccessInvalidationChannel, gomock.Any()).Times(1).Return(nil)
+
+	ctx := dpop.WithProof(context.Background(), dpop.Proof{Thumbprint: "thumbprint", JTI: "proof-jti"})
+	accessToken, refreshToken, err := svc.IssueTokensService(ctx, userfactory.NewOptions(nil))
+	require.NoError(t, err)
+
+	for _, issued := range []string{accessToken, refreshToken} {
+		_, claims, err := tokenMaker.VerifyToken(issued)
+		require.NoError(t, err)
+		require.Equal(t, "thumbprint", token.SessionFromClaims(claims).Jkt)
+	}
+}
+
+func TestRefreshAccessTokenService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := db.NewMockStore(ctrl)
+	mockRedis := redis.NewMockClient(ctrl)
+	svc := NewService(mockStore, util.HashPassword, util.CheckPasswordHash, tokenMaker, conf, mockRedis)
+
+	user := userfactory.NewOptions(nil)
+	sessionId := uuid.New().String()
+	client := device.NewInfo("10.0.0.2", "curl/8.7.1")
+
+	mockStore.EXPECT().GetRefreshTokenByUserId(gomock.Any(), ToGetRefreshTokenByUserIdParams(token.HashToken("refresh"), user.ID)).Times(1).Return(sqlc.RefreshToken{ID: 9, SessionID: sessionId}, nil)
+	mockStore.EXPECT().TouchRefreshToken(gomock.Any(), sqlc.TouchRefreshTokenParams{ID: 9, IpAddress: client.IPAddress}).Times(1).Return(nil)
+
+	var storedJti interface{}
+	mockRedis.EXPECT().Set(gomock.Any(), redis.UserAccessKey(user.ID, sessionId), gomock.Any(), conf.AccessTokenDuration).Times(1).DoAndReturn(func(_ context.Context, _ string, value interface{}, _ time.Duration) error {
+		storedJti = value
+		return nil
+	})
+	mockRedis.EXPECT().Publish(gomock.Any(), redis.AccessInvalidationChannel, redis.UserAccessKey(user.ID, sessionId)).Times(1).Return(nil)
+
+	accessToken, _, err := svc.RefreshAccessTokenService(device.WithInfo(context.Background(), client), "refresh", user.Email, user.ID, token.Session{OrgId: 3})
+	require.NoError(t, err)
+
+	_, claims, err := tokenMaker.VerifyToken(accessToken)
+	require.NoError(t, err)
+	require.NotEmpty(t, claims[constant.JsonWebTokenIdKey])
+	require.Equal(t, claims[constant.JsonWebTokenIdKey], storedJti)
+	require.Equal(t, token.Session{OrgId: 3, SessionId: sessionId}, token.SessionFromClaims(claims))
+}
+
+func TestRevokeSessionService(t *testing.T) {
+	sessionId := uuid.New().String()
+	arg := sqlc.DeleteRefreshTokenBySessionIdParams{UserID: 1, SessionID: sessionId}
+
+	testCases := []struct {
+		name      string
+		buildStub func(store *db.MockStore, client *redis.MockClient)
+		code      errors.Code
+	}{
+		{
+			name: "OK",
+			buildStub: func(store *db.MockStore, client *redis.MockClient) {
+				store.EXPECT().DeleteRefreshTokenBySessionId(gomock.Any(), arg).Times(1).Return(sqlc.RefreshToken{SessionID: sessionId}, nil)
+				client.EXPECT().Del(gomock.Any(), redis.UserAccessKey(1, sessionId)).Times(1).Return(nil)
+				client.EXPECT().Publish(gomock.Any(), redis.AccessInvalidationChannel, redis.UserAccessKey(1, sessionId)).Times(1).Return(nil)
+			},
+		},
+		{
+			name: "not found",
+			buildStub: func(store *db.MockStore, client *redis.MockClient) {
+				store.EXPECT().DeleteRefreshTokenBySessionId(gomock.Any(), arg).Times(1).Return(sqlc.RefreshToken{}, sql.ErrNoRows)
+				client.EXPECT().Del(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: errors.CodeNotFound,
+		},
+		{
+			name: "failed to delete",
+			buildStub: func(store *db.MockStore, client *redis.MockClient) {
+				store.EXPECT().DeleteRefreshTokenBySessionId(gomock.Any(), arg).Times(1).Return(sqlc.RefreshToken{}, sql.ErrConnDone)
+				client.EXPECT().Del(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: errors.CodeInternal,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStore := db.NewMockStore(ctrl)
+			mockRedis := redis.NewMockClient(ctrl)
+			testCase.buildStub(mockStore, mockRedis)
+
+			svc := NewService(mockStore, util.HashPassword, util.CheckPasswordHash, tokenMaker, conf, mockRedis)
+
+			err := svc.RevokeSessionService(context.Background(), 1, sessionId)
+			if testCase.code == "" {
+				require.NoError(t, err)
+				return
+			}
+			requireCode(t, err, testCase.code)
+		})
+	}
+}
+
+func TestLogoutAllService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := db.NewMockStore(ctrl)
+	mockRedis := redis.NewMockClient(ctrl)
+	svc := NewService(mockStore, util.HashPassword, util.CheckPasswordHash, tokenMaker, conf, mockRedis)
+
+	gomock.InOrder(
+		mockStore.EXPECT().ListRefreshTokensByUserId(gomock.Any(), int64(1)).Times(1).Return([]sqlc.RefreshToken{{SessionID: "a"}, {SessionID: "b"}}, nil),
+		mockStore.EXPECT().DeleteRefreshTokensByUserId(gomock.Any(), int64(1)).Times(1).Return(nil),
+		mockRedis.EXPECT().Del(gomock.Any(), redis.UserAccessKey(1, "a"), redis.UserAccessKey(1, "b")).Times(1).Return(nil),
+		mockRedis.EXPECT().Publish(gomock.Any(), redis.AccessInvalidationChannel, redis.UserAccessKey(1, "a")).Times(1).Return(nil),
+		mockRedis.EXPECT().Publish(gomock.Any(), redis.AccessInvalidationChannel, redis.UserAccessKey(1, "b")).Times(1).Return(nil),
+	)
+
+	require.NoError(t, svc.LogoutAllService(context.Background(), 1))
+}
+
+func TestMeServiceImpersonated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := db.NewMockStore(ctrl)
+	svc := NewService(mockStore, util.HashPassword, util.CheckPasswordHash, tokenMaker, conf, redis.NewMockClient(ctrl))
+
+	user := userfactory.NewOptions(nil)
+	admin := userfactory.NewOptions(&userfactory.Options{ID: 2, Role: constant.AdminRole})
+	mockStore.EXPECT().GetUser(gomock.Any(), user.Email).Return(user, nil)
+	mockStore.EXPECT().ListMembershipsByUserId(gomock.Any(), user.ID).Return([]sqlc.ListMembershipsByUserIdRow{}, nil)
+	mockStore.EXPECT().GetUserByID(gomock.Any(), admin.ID).Return(admin, nil)
+
+	got, memberships, impersonator, err := svc.MeService(context.Background(), user.Email, admin.ID)
+	require.NoError(t, err)
+
+	res := ToMeResponse(got, memberships, 0, impersonator)
+	require.NotNil(t, res.Impersonator)
+	require.Equal(t, admin.ID, res.Impersonator.ID)
+}
+
+func TestImpersonateService(t *testing.T) {
+	user := userfactory.NewOptions(nil)
+	adminId := int64(2)
+	userPermission := ToHasRolePermissionParams(user.Role, constant.PermissionImpersonate)
+	var recorded sqlc.CreateRefreshTokenParams
+
+	testCases := []struct {
+		name          string
+		userId        int64
+		buildStub     func(store *db.MockStore, client *redis.MockClient)
+		checkResponse func(t *testing.T, accessToken string, err error)
+	}{
+		{
+			name:   "OK",
+			userId: user.ID,
+			buildStub: func(store *db.MockStore, client *redis.MockClient) {
+				store.EXPECT().GetUserByID(gomock.Any(), user.ID).Times(1).Return(user, nil)
+				store.EXPECT().HasRolePermission(gomock.Any(), userPermission).Times(1).Return(false, nil)
+				client.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, key string, _ interface{}, ttl time.Duration) error {
+					require.LessOrEqual(t, ttl, conf.ImpersonationTokenDuration)
+					return nil
+				})
+				client.EXPECT().Publish(gomock.Any(), redis.AccessInvalidationChannel, gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().CreateAuditLog(gomock.Any(), ToImpersonationAuditLogParams(user.ID, adminId, device.Info{}, "")).Times(1).Return(sqlc.AuditLog{}, nil)
+				store.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, arg sqlc.CreateRefreshTokenParams) (sqlc.RefreshToken, error) {
+					recorded = arg
+					return sqlc.RefreshToken{}, nil
+				})
+			},
+			checkResponse: func(t *testing.T, accessToken string, err error) {
+				require.NoError(t, err)
+				_, claims, err := tokenMaker.VerifyToken(accessToken)
+				require.NoError(t, err)
+				session := token.SessionFromClaims(claims)
+				require.Equal(t, adminId, session.ActorId)
+				require.NotEmpty(t, session.SessionId)
+				require.Equal(t, float64(user.ID), claims[constant.SubKey])
+
+				// The session is recorded so that logging out every session of the user revokes it
+				require.Equal(t, user.ID, recorded.UserID)
+				require.Equal(t, session.SessionId, recorded.SessionID)
+				require.WithinDuration(t, time.Now().Add(conf.ImpersonationTokenDuration), recorded.ExpiredAt, time.Minute)
+			},
+		},
+		{
+			name:   "session not recorded",
+			userId: user.ID,
+			buildStub: func(store *db.MockStore, client *redis.MockClient) {
+				store.EXPECT().GetUserByID(gomock.Any(), user.ID).Times(1).Return(user, nil)
+				store.EXPECT().HasRolePermission(gomock.Any(), userPermission).Times(1).Return(false, nil)
+				store.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.RefreshToken{}, sql.ErrConnDone)
+				client.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, accessToken string, err error) {
+				requireCode(t, err, errors.CodeInternal)
+			},
+		},
+		{
+			name:   "yourself",
+			userId: adminId,
+			buildStub: func(store *db.MockStore, client *redis.MockClient) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, accessToken string, err error) {
+				requireCode(t, err, errors.CodeBadRequest)
+			},
+		},
+		{
+			name:   "user not found",
+			userId: user.ID,
+			buildStub: func(store *db.MockStore, client *redis.MockClient) {
+				store.EXPECT().GetUserByID(gomock.Any(), user.ID).Times(1).Return(sqlc.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, accessToken string, err error) {
+				requireCode(t, err, errors.CodeNotFound)
+			},
+		},
+		{
+			name:   "deactivated user",
+			userId: user.ID,
+			buildStub: func(store *db.MockStore, client *redis.MockClient) {
+				store.EXPECT().GetUserByID(gomock.Any(), user.ID).Times(1).Return(userfactory.NewOptions(&userfactory.Options{Inactive: true}), nil)
+				store.EXPECT().HasRolePermission(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, accessToken string, err error) {
+				requireCode(t, err, errors.CodeForbidden)
+			},
+		},
+		{
+			name:   "privileged user",
+			userId: user.ID,
+			buildStub: func(store *db.MockStore, client *redis.MockClient) {
+				store.EXPECT().GetUserByID(gomock.Any(), user.ID).Times(1).Return(user, nil)
+				store.EXPECT().HasRolePermission(gomock.Any(), userPermission).Times(1).Return(true, nil)
+				client.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, accessToken string, err error) {
+				requireCode(t, err, errors.CodeForbidden)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStore := db.NewMockStore(ctrl)
+			mockRedis := redis.NewMockClient(ctrl)
+			testCase.buildStub(mockStore, mockRedis)
+
+			svc := NewService(mockStore, util.HashPassword, util.CheckPasswordHash, tokenMaker, conf, mockRedis)
+
+			_, accessToken, err := svc.ImpersonateService(context.Background(), adminId, testCase.userId)
+			testCase.checkResponse(t, accessToken, err)
+		})
+	}
+}
+
+func TestIssueSessionTokensServiceRejectsImpersonation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := db.NewMockStore(ctrl)
+	mockStore.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Times(0)
+	svc := NewService(mockStore, util.HashPassword, util.CheckPasswordHash, tokenMaker, conf, redis.NewMockClient(ctrl))
+
+	_, _, err := svc.IssueSessionTokensService(context.Background(), userfactory.NewOptions(nil), token.Session{SessionId: uuid.New().String(), ActorId: 2})
+	requireCode(t, err, errors.CodeForbidden)
+}
+
+func TestReauthenticateService(t *testing.T) {
+	password := util.RandomString(10)
+	hash, err := util.HashPassword(context.Background(), password)
+	require.NoError(t, err)
+	user := userfactory.NewOptions(&userfactory.Options{Password: hash})
+	sessionId := uuid.New().String()
+	staleAuthTime := time.Now().Add(-time.Hour).Unix()
+
+	testCases := []struct {
+		name          string
+		password      string
+		session       token.Session
+		buildStub     func(store *db.MockStore, client *redis.MockClient)
+		checkResponse func(t *testing.T, accessToken string, err error)
+	}{
+		{
+			name:     "OK",
+			password: password,
+			session:  token.Session{SessionId: sessionId, OrgId: 3, AuthTime: staleAuthTime},
+			buildStub: func(store *db.MockStore, client *redis.MockClient) {
+				store.EXPECT().GetUser(gomock.Any(), user.Email).Times(1).Return(user, nil)
+				store.EXPECT().DeleteRefreshTokenBySessionId(gomock.Any(), ToDeleteRefreshTokenBySessionIdParams(user.ID, sessionId)).Times(1).Return(sqlc.RefreshToken{}, nil)
+				store.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.RefreshToken{}, nil)
+				client.EXPECT().Set(gomock.Any(), redis.UserAccessKey(user.ID, sessionId), gomock.Any(), gomock.Any()).Times(1).Return(nil)
+				client.EXPECT().Publish(gomock.Any(), redis.AccessInvalidationChannel, redis.UserAccessKey(user.ID, sessionId)).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, accessToken string, err error) {
+				require.NoError(t, err)
+				_, claims, err := tokenMaker.VerifyToken(accessToken)
+				require.NoError(t, err)
+				session := token.SessionFromClaims(claims)
+				require.Equal(t, sessionId, session.SessionId)
+				require.Equal(t, int64(3), session.OrgId)
+				require.WithinDuration(t, time.Now(), time.Unix(session.AuthTime, 0), time.Second)
+			},
+		},
+		{
+			name:     "wrong password",
+			password: "wrong",
+			session:  token.Session{SessionId: sessionId, AuthTime: staleAuthTime},
+			buildStub: func(store *db.MockStore, client *redis.MockClient) {
+				store.EXPECT().GetUser(gomock.Any(), user.Email).Times(1).Return(user, nil)
+				store.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, accessToken string, err error) {
+				requireCode(t, err, errors.CodeUnauthorized)
+			},
+		},
+		{
+			name:     "impersonation session",
+			password: password,
+			session:  token.Session{SessionId: sessionId, ActorId: 2},
+			buildStub: func(store *db.MockStore, client *redis.MockClient) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, accessToken string, err error) {
+				requireCode(t, err, errors.CodeForbidden)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStore := db.NewMockStore(ctrl)
+			mockRedis := redis.NewMockClient(ctrl)
+			testCase.buildStub(mockStore, mockRedis)
+
+			svc := NewService(mockStore, util.HashPassword, util.CheckPasswordHash, tokenMaker, conf, mockRedis)
+
+			_, accessToken, _, err := svc.ReauthenticateService(context.Background(), user.Email, testCase.password, testCase.session)
+			testCase.checkResponse(t, accessToken, err)
+		})
+	}
+}
+
+func TestLoginServiceMetrics(t *testing.T) {
+	password := util.RandomString(10)
+	hash, err := util.HashPassword(context.Background(), password)
+	require.NoError(t, err)
+	active := userfactory.NewOptions(&userfactory.Options{Password: hash})
+	inactive := userfactory.NewOptions(&userfactory.Options{Password: hash, Inactive: true})
+
+	testCases := []struct {
+		outcome   string
+		user      sqlc.User
+		password  string
+		buildStub func(store *db.MockStore, client *redis.MockClient)
+	}{
+		{
+			outcome:  metrics.LoginSuccess,
+			user:     active,
+			password: password,
+			buildStub: func(store *db.MockStore, client *redis.MockClient) {
+				store.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.RefreshToken{}, nil)
+				client.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil)
+				client.EXPECT().Publish(gomock.Any(), redis.AccessInvalidationChannel, gomock.Any()).Times(1).Return(nil)
+			},
+		},
+		{
+			outcome:   metrics.LoginFailure,
+			user:      active,
+			password:  "wrong",
+			buildStub: func(store *db.MockStore, client *redis.MockClient) {},
+		},
+		{
+			outcome:   metrics.LoginLocked,
+			user:      inactive,
+			password:  password,
+			buildStub: func(store *db.MockStore, client *redis.MockClient) {},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.outcome, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStore := db.NewMockStore(ctrl)
+			mockRedis := redis.NewMockClient(ctrl)
+			mockStore.EXPECT().GetUser(gomock.Any(), testCase.user.Email).Times(1).Return(testCase.user, nil)
+			testCase.buildStub(mockStore, mockRedis)
+
+			svc := NewService(mockStore, util.HashPassword, util.CheckPasswordHash, tokenMaker, conf, mockRedis)
+
+			logins := testutil.ToFloat64(metrics.Logins.WithLabelValues(testCase.outcome))
+			_, _, _, err := svc.LoginService(context.Background(), LoginRequest{Email: testCase.user.Email, Password: testCase.password})
+			require.Equal(t, testCase.outcome == metrics.LoginSuccess, err == nil)
+			require.Equal(t, logins+1, testutil.ToFloat64(metrics.Logins.WithLabelValues(testCase.outcome)))
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package invitationservice
+
+import "time"
+
+// DefaultListLimit is the page size used when a list request does not ask for one
+const DefaultListLimit = 20
+
+type CreateInvitationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	// Role defaults to constant.DefaultRole
+	Role string `json:"role"`
+}
+
+type InvitationUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type ListInvitationsRequest struct {
+	Limit  int32 `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int32 `form:"offset" binding:"omitempty,min=0"`
+}
+
+type CompleteInvitationRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Name     string `json:"name" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+type InvitationResponse struct {
+	ID        int64     `json:"id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	InvitedBy int64     `json:"invited_by"`
+	ExpiredAt time.Time `json:"expired_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ListInvitationsResponse struct {
+	Invitations []InvitationResponse `json:"invitations"`
+	Total       int64                `json:"total"`
+}
@@ -0,0 +1,62 @@
+package invitationservice
+
+import (
+	"time"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/service/authservice"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
+)
+
+func ToCreateUserInvitationParams(inviterId int64, email, role, invitationToken string, expiresAt time.Time) (res sqlc.CreateUserInvitationParams) {
+	res = sqlc.CreateUserInvitationParams{
+		Email:     email,
+		Role:      role,
+		Token:     token.HashToken(invitationToken),
+		InvitedBy: inviterId,
+		ExpiredAt: expiresAt,
+	}
+	return
+}
+
+func ToUpdateUserInvitationTokenParams(id int64, invitationToken string, expiresAt time.Time) (res sqlc.UpdateUserInvitationTokenParams) {
+	res = sqlc.UpdateUserInvitationTokenParams{
+		ID:        id,
+		Token:     token.HashToken(invitationToken),
+		ExpiredAt: expiresAt,
+	}
+	return
+}
+
+func ToRegisterRequest(invitation sqlc.UserInvitation, request CompleteInvitationRequest) (res authservice.RegisterRequest) {
+	res = authservice.RegisterRequest{
+		Name:     request.Name,
+		Email:    invitation.Email,
+		Password: request.Password,
+	}
+	return
+}
+
+func ToInvitationResponse(invitation sqlc.UserInvitation) (res InvitationResponse) {
+	res = InvitationResponse{
+		ID:        invitation.ID,
+		Email:     invitation.Email,
+		Role:      invitation.Role,
+		InvitedBy: invitation.InvitedBy,
+		ExpiredAt: invitation.ExpiredAt,
+		CreatedAt: invitation.CreatedAt,
+		UpdatedAt: invitation.UpdatedAt,
+	}
+	return
+}
+
+func ToListInvitationsResponse(invitations []sqlc.UserInvitation, total int64) (res ListInvitationsResponse) {
+	res = ListInvitationsResponse{
+		Invitations: make([]InvitationResponse, 0, len(invitations)),
+		Total:       total,
+	}
+	for _, invitation := range invitations {
+		res.Invitations = append(res.Invitations, ToInvitationResponse(invitation))
+	}
+	return
+}
@@ -0,0 +1,185 @@
+package invitationservice
+
+import (
+	"context"
+	"database/sql"
+	ierr "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/config"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/service/authservice"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/mailer"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
+	"github.com/lib/pq"
+)
+
+type Service struct {
+	store       db.Store
+	mailer      mailer.Mailer
+	authService *authservice.Service
+	config      config.Config
+}
+
+func NewService(store db.Store, mailer mailer.Mailer, authService *authservice.Service, config config.Config) *Service {
+	return &Service{store: store, mailer: mailer, authService: authService, config: config}
+}
+
+// CreateService invites an email to sign up with the given role and mails it a single-use signup link
+func (service *Service) CreateService(context context.Context, inviterId int64, request CreateInvitationRequest) (invitation sqlc.UserInvitation, errs error) {
+	if request.Role == "" {
+		request.Role = constant.DefaultRole
+	}
+
+	_, err := service.store.GetRoleByName(context, request.Role)
+	if err != nil {
+		if ierr.Is(err, sql.ErrNoRows) {
+			errs = errors.New(errors.CodeBadRequest, "Role does not exist", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to create invitation", err)
+		return
+	}
+
+	_, err = service.store.GetUser(context, request.Email)
+	if err == nil {
+		errs = errors.New(errors.CodeConflict, "Email already exists", nil)
+		return
+	}
+	if !ierr.Is(err, sql.ErrNoRows) {
+		errs = errors.New(errors.CodeInternal, "Failed to create invitation", err)
+		return
+	}
+
+	invitationToken, err := token.GenerateOpaqueToken()
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to create invitation", err)
+		return
+	}
+
+	arg := ToCreateUserInvitationParams(inviterId, request.Email, request.Role, invitationToken, time.Now().Add(service.config.UserInvitationDuration))
+	invitation, err = service.store.CreateUserInvitation(context, arg)
+	if err != nil {
+		var pqErr *pq.Error
+		if ierr.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" && strings.Contains(pqErr.Constraint, "user_invitations_email_unique") {
+			errs = errors.New(errors.CodeConflict, "Email already has a pending invitation", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to create invitation", err)
+		return
+	}
+
+	errs = service.sendInvitation(context, invitation, invitationToken, "Failed to create invitation")
+	return
+}
+
+func (service *Service) ListService(context context.Context, request ListInvitationsRequest) (invitations []sqlc.UserInvitation, total int64, errs error) {
+	if request.Limit == 0 {
+		request.Limit = DefaultListLimit
+	}
+
+	invitations, err := service.store.ListUserInvitations(context, sqlc.ListUserInvitationsParams{Limit: request.Limit, Offset: request.Offset})
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to get invitations", err)
+		return
+	}
+
+	total, err = service.store.CountUserInvitations(context)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to get invitations", err)
+		return
+	}
+	return
+}
+
+// ResendService mails a fresh signup link and restarts the expiry. The previous link stops working.
+func (service *Service) ResendService(context context.Context, id int64) (invitation sqlc.UserInvitation, errs error) {
+	invitation, errs = service.getInvitation(context, id, "Failed to resend invitation")
+	if errs != nil {
+		return
+	}
+
+	invitationToken, err := token.GenerateOpaqueToken()
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to resend invitation", err)
+		return
+	}
+
+	arg := ToUpdateUserInvitationTokenParams(invitation.ID, invitationToken, time.Now().Add(service.config.UserInvitationDuration))
+	invitation, err = service.store.UpdateUserInvitationToken(context, arg)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to resend invitation", err)
+		return
+	}
+
+	errs = service.sendInvitation(context, invitation, invitationToken, "Failed to resend invitation")
+	return
+}
+
+func (service *Service) RevokeService(context context.Context, id int64) (errs error) {
+	_, errs = service.getInvitation(context, id, "Failed to revoke invitation")
+	if errs != nil {
+		return
+	}
+
+	err := service.store.DeleteUserInvitation(context, id)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to revoke invitation", err)
+		return
+	}
+	return
+}
+
+// CompleteService signs up the invitee under the invited email and role. It works regardless of the registration
+// mode; the invitation is consumed in the registration transaction.
+func (service *Service) CompleteService(context context.Context, request CompleteInvitationRequest) (user sqlc.User, accessToken, refreshToken string, errs error) {
+	invitation, err := service.store.GetUserInvitationByToken(context, token.HashToken(request.Token))
+	if err != nil {
+		if ierr.Is(err, sql.ErrNoRows) {
+			errs = errors.New(errors.CodeNotFound, "Invitation is invalid or has already been used", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to register user", err)
+		return
+	}
+
+	if time.Now().After(invitation.ExpiredAt) {
+		errs = errors.New(errors.CodeTokenExpired, "Invitation has expired", fmt.Errorf("invitation expired at %s", invitation.ExpiredAt))
+		return
+	}
+
+	return service.authService.CreateAccountService(context, ToRegisterRequest(invitation, request), db.AcceptUserInvitation(invitation))
+}
+
+func (service *Service) getInvitation(context context.Context, id int64, failure string) (invitation sqlc.UserInvitation, errs error) {
+	invitation, err := service.store.GetUserInvitation(context, id)
+	if err != nil {
+		if ierr.Is(err, sql.ErrNoRows) {
+			errs = errors.New(errors.CodeNotFound, "Invitation is not found", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, failure, err)
+		return
+	}
+	return
+}
+
+func (service *Service) sendInvitation(context context.Context, invitation sqlc.UserInvitation, invitationToken, failure string) (errs error) {
+	err := service.mailer.Send(context, mailer.Message{
+		To:      invitation.Email,
+		Subject: "You have been invited to sign up",
+		Body: fmt.Sprintf("You have been invited to create an account. Use the link below to sign up. It expires in %s and can only be used once.\n\n%s\n\nIf you were not expecting this email you can ignore it.\n",
+			service.config.UserInvitationDuration, util.WithQueryParam(service.config.UserInvitationURL, "token", invitationToken)),
+	})
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, failure, err)
+		return
+	}
+	return
+}
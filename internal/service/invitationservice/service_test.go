@@ -0,0 +1,305 @@
+package invitationservice
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/factory/userfactory"
+	"github.com/hanifsyahsn/go_boilerplate/internal/service/authservice"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	appErrors "github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/mailer"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/redis"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(ctrl *gomock.Controller) (*Service, *db.MockStore, *mailer.MockMailer, *redis.MockClient) {
+	mockStore := db.NewMockStore(ctrl)
+	mockMailer := mailer.NewMockMailer(ctrl)
+	mockRedis := redis.NewMockClient(ctrl)
+	// Invitations must keep working while self-registration is disabled
+	inviteOnly := conf
+	inviteOnly.RegistrationMode = "invite"
+	authSvc := authservice.NewService(mockStore, util.HashPassword, util.CheckPasswordHash, tokenMaker, inviteOnly, mockRedis)
+	return NewService(mockStore, mockMailer, authSvc, inviteOnly), mockStore, mockMailer, mockRedis
+}
+
+func TestCreateService(t *testing.T) {
+	request := CreateInvitationRequest{Email: "invitee@mail.com", Role: constant.AdminRole}
+
+	testCases := []struct {
+		name          string
+		request       CreateInvitationRequest
+		buildStub     func(store *db.MockStore, mail *mailer.MockMailer)
+		checkResponse func(t *testing.T, invitation sqlc.UserInvitation, err error)
+	}{
+		{
+			name:    "OK",
+			request: request,
+			buildStub: func(store *db.MockStore, mail *mailer.MockMailer) {
+				var stored sqlc.CreateUserInvitationParams
+				store.EXPECT().GetRoleByName(gomock.Any(), constant.AdminRole).Times(1).Return(sqlc.Role{Name: constant.AdminRole}, nil)
+				store.EXPECT().GetUser(gomock.Any(), request.Email).Times(1).Return(sqlc.User{}, sql.ErrNoRows)
+				store.EXPECT().CreateUserInvitation(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, arg sqlc.CreateUserInvitationParams) (sqlc.UserInvitation, error) {
+					stored = arg
+					return sqlc.UserInvitation{ID: 1, Email: arg.Email, Role: arg.Role, InvitedBy: arg.InvitedBy, ExpiredAt: arg.ExpiredAt}, nil
+				})
+				mail.EXPECT().Send(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, message mailer.Message) error {
+					require.Equal(t, request.Email, message.To)
+					// Only the digest of the mailed token is persisted
+					require.Equal(t, token.HashToken(extractInvitationToken(t, message.Body)), stored.Token)
+					require.WithinDuration(t, time.Now().Add(conf.UserInvitationDuration), stored.ExpiredAt, time.Minute)
+					return nil
+				})
+			},
+			checkResponse: func(t *testing.T, invitation sqlc.UserInvitation, err error) {
+				require.NoError(t, err)
+				require.Equal(t, constant.AdminRole, invitation.Role)
+				require.Equal(t, int64(9), invitation.InvitedBy)
+			},
+		},
+		{
+			name:    "defaults to the default role",
+			request: CreateInvitationRequest{Email: request.Email},
+			buildStub: func(store *db.MockStore, mail *mailer.MockMailer) {
+				store.EXPECT().GetRoleByName(gomock.Any(), constant.DefaultRole).Times(1).Return(sqlc.Role{Name: constant.DefaultRole}, nil)
+				store.EXPECT().GetUser(gomock.Any(), request.Email).Times(1).Return(sqlc.User{}, sql.ErrNoRows)
+				store.EXPECT().CreateUserInvitation(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, arg sqlc.CreateUserInvitationParams) (sqlc.UserInvitation, error) {
+					return sqlc.UserInvitation{Email: arg.Email, Role: arg.Role}, nil
+				})
+				mail.EXPECT().Send(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, invitation sqlc.UserInvitation, err error) {
+				require.NoError(t, err)
+				require.Equal(t, constant.DefaultRole, invitation.Role)
+			},
+		},
+		{
+			name:    "unknown role",
+			request: request,
+			buildStub: func(store *db.MockStore, mail *mailer.MockMailer) {
+				store.EXPECT().GetRoleByName(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.Role{}, sql.ErrNoRows)
+				store.EXPECT().CreateUserInvitation(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, invitation sqlc.UserInvitation, err error) {
+				requireAppErrorCode(t, err, appErrors.CodeBadRequest)
+			},
+		},
+		{
+			name:    "existing user",
+			request: request,
+			buildStub: func(store *db.MockStore, mail *mailer.MockMailer) {
+				store.EXPECT().GetRoleByName(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.Role{}, nil)
+				store.EXPECT().GetUser(gomock.Any(), request.Email).Times(1).Return(userfactory.NewOptions(nil), nil)
+				store.EXPECT().CreateUserInvitation(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, invitation sqlc.UserInvitation, err error) {
+				requireAppErrorCode(t, err, appErrors.CodeConflict)
+			},
+		},
+		{
+			name:    "pending invitation",
+			request: request,
+			buildStub: func(store *db.MockStore, mail *mailer.MockMailer) {
+				store.EXPECT().GetRoleByName(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.Role{}, nil)
+				store.EXPECT().GetUser(gomock.Any(), request.Email).Times(1).Return(sqlc.User{}, sql.ErrNoRows)
+				store.EXPECT().CreateUserInvitation(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.UserInvitation{}, &pq.Error{
+					Code:       "23505",
+					Constraint: "user_invitations_email_unique",
+				})
+				mail.EXPECT().Send(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, invitation sqlc.UserInvitation, err error) {
+				requireAppErrorCode(t, err, appErrors.CodeConflict)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, mockStore, mockMailer, _ := newTestService(ctrl)
+
+			testCase.buildStub(mockStore, mockMailer)
+
+			invitation, err := svc.CreateService(context.Background(), 9, testCase.request)
+			testCase.checkResponse(t, invitation, err)
+		})
+	}
+}
+
+func TestListService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc, mockStore, _, _ := newTestService(ctrl)
+
+	mockStore.EXPECT().ListUserInvitations(gomock.Any(), sqlc.ListUserInvitationsParams{Limit: DefaultListLimit}).Times(1).Return([]sqlc.UserInvitation{{ID: 1}}, nil)
+	mockStore.EXPECT().CountUserInvitations(gomock.Any()).Times(1).Return(int64(1), nil)
+
+	invitations, total, err := svc.ListService(context.Background(), ListInvitationsRequest{})
+	require.NoError(t, err)
+	require.Len(t, invitations, 1)
+	require.Equal(t, int64(1), total)
+}
+
+func TestResendService(t *testing.T) {
+	invitation := sqlc.UserInvitation{ID: 3, Email: "invitee@mail.com", Role: constant.DefaultRole, Token: token.HashToken("old")}
+
+	t.Run("OK", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		svc, mockStore, mockMailer, _ := newTestService(ctrl)
+
+		var stored sqlc.UpdateUserInvitationTokenParams
+		mockStore.EXPECT().GetUserInvitation(gomock.Any(), invitation.ID).Times(1).Return(invitation, nil)
+		mockStore.EXPECT().UpdateUserInvitationToken(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, arg sqlc.UpdateUserInvitationTokenParams) (sqlc.UserInvitation, error) {
+			stored = arg
+			updated := invitation
+			updated.Token = arg.Token
+			updated.ExpiredAt = arg.ExpiredAt
+			return updated, nil
+		})
+		mockMailer.EXPECT().Send(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, message mailer.Message) error {
+			require.Equal(t, token.HashToken(extractInvitationToken(t, message.Body)), stored.Token)
+			return nil
+		})
+
+		_, err := svc.ResendService(context.Background(), invitation.ID)
+		require.NoError(t, err)
+		require.Equal(t, invitation.ID, stored.ID)
+		require.NotEqual(t, invitation.Token, stored.Token)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		svc, mockStore, _, _ := newTestService(ctrl)
+
+		mockStore.EXPECT().GetUserInvitation(gomock.Any(), invitation.ID).Times(1).Return(sqlc.UserInvitation{}, sql.ErrNoRows)
+		mockStore.EXPECT().UpdateUserInvitationToken(gomock.Any(), gomock.Any()).Times(0)
+
+		_, err := svc.ResendService(context.Background(), invitation.ID)
+		requireAppErrorCode(t, err, appErrors.CodeNotFound)
+	})
+}
+
+func TestRevokeService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc, mockStore, _, _ := newTestService(ctrl)
+
+	mockStore.EXPECT().GetUserInvitation(gomock.Any(), int64(3)).Times(1).Return(sqlc.UserInvitation{ID: 3}, nil)
+	mockStore.EXPECT().DeleteUserInvitation(gomock.Any(), int64(3)).Times(1).Return(nil)
+
+	require.NoError(t, svc.RevokeService(context.Background(), 3))
+}
+
+func TestCompleteService(t *testing.T) {
+	invitationToken := util.RandomString(43)
+	invitation := sqlc.UserInvitation{
+		ID:        3,
+		Email:     "invitee@mail.com",
+		Role:      constant.AdminRole,
+		ExpiredAt: time.Now().Add(time.Hour),
+	}
+	request := CompleteInvitationRequest{Token: invitationToken, Name: util.RandomString(6), Password: util.RandomString(10)}
+
+	testCases := []struct {
+		name          string
+		buildStub     func(store *db.MockStore, client *redis.MockClient)
+		checkResponse func(t *testing.T, user sqlc.User, accessToken string, err error)
+	}{
+		{
+			name: "OK",
+			buildStub: func(store *db.MockStore, client *redis.MockClient) {
+				user := userfactory.NewOptions(&userfactory.Options{Email: invitation.Email, Role: invitation.Role})
+				store.EXPECT().GetUserInvitationByToken(gomock.Any(), token.HashToken(invitationToken)).Times(1).Return(invitation, nil)
+				store.EXPECT().RegisterTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, arg sqlc.CreateUserParams, hooks ...db.RegisterTxHook) (sqlc.User, string, string, jwt.MapClaims, jwt.MapClaims, error) {
+					// The invitee cannot pick a different email
+					require.Equal(t, invitation.Email, arg.Email)
+					require.Equal(t, request.Name, arg.Name)
+					require.NoError(t, util.CheckPasswordHash(context.Background(), request.Password, arg.Password))
+					require.Len(t, hooks, 1)
+					claims := jwt.MapClaims{
+						constant.JsonWebTokenIdKey: uuid.New().String(),
+						constant.ExpirationKey:     time.Now().Add(time.Minute).Unix(),
+					}
+					return user, "access", "refresh", claims, claims, nil
+				})
+				client.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil)
+				client.EXPECT().Publish(gomock.Any(), redis.AccessInvalidationChannel, gomock.Any()).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, user sqlc.User, accessToken string, err error) {
+				require.NoError(t, err)
+				require.Equal(t, constant.AdminRole, user.Role)
+				require.Equal(t, "access", accessToken)
+			},
+		},
+		{
+			name: "unknown token",
+			buildStub: func(store *db.MockStore, client *redis.MockClient) {
+				store.EXPECT().GetUserInvitationByToken(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.UserInvitation{}, sql.ErrNoRows)
+				store.EXPECT().RegisterTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, user sqlc.User, accessToken string, err error) {
+				requireAppErrorCode(t, err, appErrors.CodeNotFound)
+			},
+		},
+		{
+			name: "expired",
+			buildStub: func(store *db.MockStore, client *redis.MockClient) {
+				expired := invitation
+				expired.ExpiredAt = time.Now().Add(-time.Second)
+				store.EXPECT().GetUserInvitationByToken(gomock.Any(), gomock.Any()).Times(1).Return(expired, nil)
+				store.EXPECT().RegisterTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, user sqlc.User, accessToken string, err error) {
+				requireAppErrorCode(t, err, appErrors.CodeTokenExpired)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, mockStore, _, mockRedis := newTestService(ctrl)
+
+			testCase.buildStub(mockStore, mockRedis)
+
+			user, accessToken, _, err := svc.CompleteService(context.Background(), request)
+			testCase.checkResponse(t, user, accessToken, err)
+		})
+	}
+}
+
+func extractInvitationToken(t *testing.T, body string) string {
+	for _, field := range strings.Fields(body) {
+		if strings.HasPrefix(field, conf.UserInvitationURL) {
+			link, err := url.Parse(field)
+			require.NoError(t, err)
+			return link.Query().Get("token")
+		}
+	}
+	t.Fatalf("no invitation link in mail body: %q", body)
+	return ""
+}
+
+func requireAppErrorCode(t *testing.T, err error, code appErrors.Code) {
+	var appErr *appErrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, code, appErr.Code)
+}
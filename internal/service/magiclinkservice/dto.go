@@ -0,0 +1,9 @@
+package magiclinkservice
+
+type MagicLinkRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ConsumeRequest struct {
+	Token string `json:"token" binding:"required"`
+}
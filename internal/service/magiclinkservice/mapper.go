@@ -0,0 +1,18 @@
+package magiclinkservice
+
+import (
+	"time"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
+)
+
+func ToCreateMagicLinkTokenParams(userId int64, linkToken, nonce string, expiresAt time.Time) (res sqlc.CreateMagicLinkTokenParams) {
+	res = sqlc.CreateMagicLinkTokenParams{
+		UserID:    userId,
+		Token:     token.HashToken(linkToken),
+		Nonce:     token.HashToken(nonce),
+		ExpiredAt: expiresAt,
+	}
+	return
+}
@@ -0,0 +1,117 @@
+package magiclinkservice
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	ierr "errors"
+	"fmt"
+	"time"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/config"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/service/authservice"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/mailer"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
+)
+
+type Service struct {
+	store       db.Store
+	mailer      mailer.Mailer
+	authService *authservice.Service
+	config      config.Config
+}
+
+func NewService(store db.Store, mailer mailer.Mailer, authService *authservice.Service, config config.Config) *Service {
+	return &Service{store: store, mailer: mailer, authService: authService, config: config}
+}
+
+// RequestService emails a single-use sign-in link to the user. The returned browser nonce must be stored in a
+// cookie; the link only works in a browser presenting it. An existing nonce is reused so that several tabs
+// requesting links do not invalidate each other. Unknown emails get the same response to avoid account enumeration.
+func (service *Service) RequestService(context context.Context, request MagicLinkRequest, currentNonce string) (nonce string, errs error) {
+	nonce = currentNonce
+	if nonce == "" {
+		var err error
+		nonce, err = token.GenerateOpaqueToken()
+		if err != nil {
+			errs = errors.New(errors.CodeInternal, "Failed to send magic link", err)
+			return
+		}
+	}
+
+	user, err := service.store.GetUser(context, request.Email)
+	if err != nil {
+		if ierr.Is(err, sql.ErrNoRows) {
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to send magic link", err)
+		return
+	}
+
+	linkToken, err := token.GenerateOpaqueToken()
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to send magic link", err)
+		return
+	}
+
+	arg := ToCreateMagicLinkTokenParams(user.ID, linkToken, nonce, time.Now().Add(service.config.MagicLinkDuration))
+	_, err = service.store.CreateMagicLinkToken(context, arg)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to send magic link", err)
+		return
+	}
+
+	err = service.mailer.Send(context, mailer.Message{
+		To:      user.Email,
+		Subject: "Your sign-in link",
+		Body: fmt.Sprintf("Use the link below to sign in. It expires in %s and can only be used once, in the browser it was requested from.\n\n%s\n\nIf you did not request this email you can ignore it.\n",
+			service.config.MagicLinkDuration, util.WithQueryParam(service.config.MagicLinkURL, "token", linkToken)),
+	})
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to send magic link", err)
+		return
+	}
+
+	return
+}
+
+// ConsumeService redeems a magic link. The token is deleted on lookup so it cannot be replayed, even when the
+// browser check below fails.
+func (service *Service) ConsumeService(context context.Context, request ConsumeRequest, nonce string) (user sqlc.User, accessToken, refreshToken string, errs error) {
+	magicLink, err := service.store.ConsumeMagicLinkToken(context, token.HashToken(request.Token))
+	if err != nil {
+		if ierr.Is(err, sql.ErrNoRows) {
+			errs = errors.New(errors.CodeUnauthorized, "Magic link is invalid or has already been used", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to login user", err)
+		return
+	}
+
+	if time.Now().After(magicLink.ExpiredAt) {
+		errs = errors.New(errors.CodeTokenExpired, "Magic link has expired", fmt.Errorf("magic link expired at %s", magicLink.ExpiredAt))
+		return
+	}
+
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(token.HashToken(nonce)), []byte(magicLink.Nonce)) != 1 {
+		errs = errors.New(errors.CodeUnauthorized, "Magic link must be opened in the browser that requested it", fmt.Errorf("magic link nonce mismatch"))
+		return
+	}
+
+	user, err = service.store.GetUserByID(context, magicLink.UserID)
+	if err != nil {
+		if ierr.Is(err, sql.ErrNoRows) {
+			errs = errors.New(errors.CodeNotFound, "User is not found", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to get user", err)
+		return
+	}
+
+	accessToken, refreshToken, errs = service.authService.IssueTokensService(context, user)
+	return
+}
@@ -0,0 +1,214 @@
+package magiclinkservice
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/factory/userfactory"
+	"github.com/hanifsyahsn/go_boilerplate/internal/service/authservice"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	appErrors "github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/mailer"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/redis"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(ctrl *gomock.Controller) (*Service, *db.MockStore, *mailer.MockMailer, *redis.MockClient) {
+	mockStore := db.NewMockStore(ctrl)
+	mockMailer := mailer.NewMockMailer(ctrl)
+	mockRedis := redis.NewMockClient(ctrl)
+	authSvc := authservice.NewService(mockStore, util.HashPassword, util.CheckPasswordHash, tokenMaker, conf, mockRedis)
+	return NewService(mockStore, mockMailer, authSvc, conf), mockStore, mockMailer, mockRedis
+}
+
+func TestRequestService(t *testing.T) {
+	user := userfactory.NewOptions(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc, mockStore, mockMailer, _ := newTestService(ctrl)
+
+	var stored sqlc.CreateMagicLinkTokenParams
+	var sent mailer.Message
+	mockStore.EXPECT().GetUser(gomock.Any(), user.Email).Times(1).Return(user, nil)
+	mockStore.EXPECT().CreateMagicLinkToken(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, arg sqlc.CreateMagicLinkTokenParams) (sqlc.MagicLinkToken, error) {
+		stored = arg
+		return sqlc.MagicLinkToken{}, nil
+	})
+	mockMailer.EXPECT().Send(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, message mailer.Message) error {
+		sent = message
+		return nil
+	})
+
+	nonce, err := svc.RequestService(context.Background(), MagicLinkRequest{Email: user.Email}, "")
+	require.NoError(t, err)
+	require.NotEmpty(t, nonce)
+	require.Equal(t, user.Email, sent.To)
+
+	linkToken := extractLinkToken(t, sent.Body)
+	// Only digests are persisted
+	require.Equal(t, token.HashToken(linkToken), stored.Token)
+	require.Equal(t, token.HashToken(nonce), stored.Nonce)
+	require.Equal(t, user.ID, stored.UserID)
+	require.WithinDuration(t, time.Now().Add(conf.MagicLinkDuration), stored.ExpiredAt, time.Minute)
+}
+
+func TestRequestServiceReusesNonce(t *testing.T) {
+	user := userfactory.NewOptions(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc, mockStore, mockMailer, _ := newTestService(ctrl)
+
+	mockStore.EXPECT().GetUser(gomock.Any(), user.Email).Times(1).Return(user, nil)
+	mockStore.EXPECT().CreateMagicLinkToken(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.MagicLinkToken{}, nil)
+	mockMailer.EXPECT().Send(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+
+	nonce, err := svc.RequestService(context.Background(), MagicLinkRequest{Email: user.Email}, "existing")
+	require.NoError(t, err)
+	require.Equal(t, "existing", nonce)
+}
+
+func TestRequestServiceUnknownEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc, mockStore, mockMailer, _ := newTestService(ctrl)
+
+	mockStore.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.User{}, sql.ErrNoRows)
+	mockStore.EXPECT().CreateMagicLinkToken(gomock.Any(), gomock.Any()).Times(0)
+	mockMailer.EXPECT().Send(gomock.Any(), gomock.Any()).Times(0)
+
+	// Same response as for a known email so accounts cannot be enumerated
+	nonce, err := svc.RequestService(context.Background(), MagicLinkRequest{Email: "nobody@mail.com"}, "")
+	require.NoError(t, err)
+	require.NotEmpty(t, nonce)
+}
+
+func TestConsumeService(t *testing.T) {
+	user := userfactory.NewOptions(nil)
+	linkToken := util.RandomString(43)
+	nonce := util.RandomString(43)
+
+	testCases := []struct {
+		name          string
+		nonce         string
+		buildStub     func(store *db.MockStore, redis *redis.MockClient)
+		checkResponse func(t *testing.T, got sqlc.User, accessToken string, err error)
+	}{
+		{
+			name:  "OK",
+			nonce: nonce,
+			buildStub: func(store *db.MockStore, client *redis.MockClient) {
+				store.EXPECT().ConsumeMagicLinkToken(gomock.Any(), token.HashToken(linkToken)).Times(1).Return(sqlc.MagicLinkToken{
+					UserID:    user.ID,
+					Nonce:     token.HashToken(nonce),
+					ExpiredAt: time.Now().Add(time.Minute),
+				}, nil)
+				store.EXPECT().GetUserByID(gomock.Any(), user.ID).Times(1).Return(user, nil)
+				store.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.RefreshToken{}, nil)
+				client.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil)
+				client.EXPECT().Publish(gomock.Any(), redis.AccessInvalidationChannel, gomock.Any()).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, got sqlc.User, accessToken string, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user.ID, got.ID)
+				require.NotEmpty(t, accessToken)
+			},
+		},
+		{
+			name:  "used or unknown link",
+			nonce: nonce,
+			buildStub: func(store *db.MockStore, client *redis.MockClient) {
+				store.EXPECT().ConsumeMagicLinkToken(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.MagicLinkToken{}, sql.ErrNoRows)
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, got sqlc.User, accessToken string, err error) {
+				requireAppErrorCode(t, err, appErrors.CodeUnauthorized)
+			},
+		},
+		{
+			name:  "expired link",
+			nonce: nonce,
+			buildStub: func(store *db.MockStore, client *redis.MockClient) {
+				store.EXPECT().ConsumeMagicLinkToken(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.MagicLinkToken{
+					UserID:    user.ID,
+					Nonce:     token.HashToken(nonce),
+					ExpiredAt: time.Now().Add(-time.Second),
+				}, nil)
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, got sqlc.User, accessToken string, err error) {
+				requireAppErrorCode(t, err, appErrors.CodeTokenExpired)
+			},
+		},
+		{
+			name:  "forwarded to another browser",
+			nonce: util.RandomString(43),
+			buildStub: func(store *db.MockStore, client *redis.MockClient) {
+				store.EXPECT().ConsumeMagicLinkToken(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.MagicLinkToken{
+					UserID:    user.ID,
+					Nonce:     token.HashToken(nonce),
+					ExpiredAt: time.Now().Add(time.Minute),
+				}, nil)
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, got sqlc.User, accessToken string, err error) {
+				requireAppErrorCode(t, err, appErrors.CodeUnauthorized)
+			},
+		},
+		{
+			name:  "missing nonce cookie",
+			nonce: "",
+			buildStub: func(store *db.MockStore, client *redis.MockClient) {
+				store.EXPECT().ConsumeMagicLinkToken(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.MagicLinkToken{
+					UserID:    user.ID,
+					Nonce:     token.HashToken(""),
+					ExpiredAt: time.Now().Add(time.Minute),
+				}, nil)
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, got sqlc.User, accessToken string, err error) {
+				requireAppErrorCode(t, err, appErrors.CodeUnauthorized)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, mockStore, _, mockRedis := newTestService(ctrl)
+
+			testCase.buildStub(mockStore, mockRedis)
+
+			got, accessToken, _, err := svc.ConsumeService(context.Background(), ConsumeRequest{Token: linkToken}, testCase.nonce)
+			testCase.checkResponse(t, got, accessToken, err)
+		})
+	}
+}
+
+func extractLinkToken(t *testing.T, body string) string {
+	for _, field := range strings.Fields(body) {
+		if strings.HasPrefix(field, conf.MagicLinkURL) {
+			link, err := url.Parse(field)
+			require.NoError(t, err)
+			return link.Query().Get("token")
+		}
+	}
+	t.Fatalf("no magic link in mail body: %q", body)
+	return ""
+}
+
+func requireAppErrorCode(t *testing.T, err error, code appErrors.Code) {
+	var appErr *appErrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, code, appErr.Code)
+}
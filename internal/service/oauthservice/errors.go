@@ -0,0 +1,44 @@
+package oauthservice
+
+import "net/http"
+
+// Error codes from RFC 6749 section 4.1.2.1 and 5.2
+const (
+	ErrInvalidRequest          = "invalid_request"
+	ErrInvalidClient           = "invalid_client"
+	ErrInvalidGrant            = "invalid_grant"
+	ErrInvalidScope            = "invalid_scope"
+	ErrInvalidToken            = "invalid_token"
+	ErrAccessDenied            = "access_denied"
+	ErrUnsupportedGrantType    = "unsupported_grant_type"
+	ErrUnsupportedResponseType = "unsupported_response_type"
+)
+
+// Error is a protocol error that must be reported to the client in the OAuth format
+// (as JSON at the token endpoint, or as redirect parameters at the authorization endpoint).
+type Error struct {
+	Code        string
+	Description string
+	Err         error
+}
+
+func (e *Error) Error() string {
+	return e.Description
+}
+
+func newError(code, description string, err error) *Error {
+	return &Error{
+		Code:        code,
+		Description: description,
+		Err:         err,
+	}
+}
+
+func (e *Error) HTTPStatus() int {
+	switch e.Code {
+	case ErrInvalidClient, ErrInvalidToken:
+		return http.StatusUnauthorized
+	default:
+		return http.StatusBadRequest
+	}
+}
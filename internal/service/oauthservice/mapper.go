@@ -0,0 +1,71 @@
+package oauthservice
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+)
+
+func ToCreateOAuthClientParams(req RegisterClientRequest, clientId, clientSecret string) (res sqlc.CreateOAuthClientParams) {
+	res = sqlc.CreateOAuthClientParams{
+		ClientID:     clientId,
+		ClientSecret: clientSecret,
+		Name:         req.Name,
+		RedirectUris: req.RedirectURIs,
+	}
+	return
+}
+
+func ToCreateOAuthAuthorizationCodeParams(req AuthorizeRequest, code string, userId int64, authTime, expiresAt time.Time) (res sqlc.CreateOAuthAuthorizationCodeParams) {
+	res = sqlc.CreateOAuthAuthorizationCodeParams{
+		Code:                code,
+		ClientID:            req.ClientID,
+		UserID:              userId,
+		RedirectUri:         req.RedirectURI,
+		Scope:               req.Scope,
+		Nonce:               req.Nonce,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		AuthTime:            authTime,
+		ExpiredAt:           expiresAt,
+	}
+	return
+}
+
+func ToCreateOAuthRefreshTokenParams(clientId string, userId int64, refreshToken, scope string, authTime, expiresAt time.Time) (res sqlc.CreateOAuthRefreshTokenParams) {
+	res = sqlc.CreateOAuthRefreshTokenParams{
+		ClientID:     clientId,
+		UserID:       userId,
+		RefreshToken: refreshToken,
+		Scope:        scope,
+		AuthTime:     authTime,
+		ExpiredAt:    expiresAt,
+	}
+	return
+}
+
+func ToTokenResponse(accessToken, refreshToken, idToken, scope string, expiresIn time.Duration) (res TokenResponse) {
+	res = TokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(expiresIn.Seconds()),
+		RefreshToken: refreshToken,
+		IDToken:      idToken,
+		Scope:        scope,
+	}
+	return
+}
+
+func ToUserInfoResponse(user sqlc.User, scope string) (res UserInfoResponse) {
+	res = UserInfoResponse{
+		Sub: strconv.FormatInt(user.ID, 10),
+	}
+	if hasScope(scope, ScopeProfile) {
+		res.Name = user.Name
+	}
+	if hasScope(scope, ScopeEmail) {
+		res.Email = user.Email
+	}
+	return
+}
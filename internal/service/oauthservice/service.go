@@ -0,0 +1,483 @@
+package oauthservice
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	ierr "errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/hanifsyahsn/go_boilerplate/internal/config"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/service/authservice"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/pkce"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
+)
+
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeRefreshToken      = "refresh_token"
+
+	ResponseTypeCode = "code"
+	DecisionApprove  = "approve"
+)
+
+type Service struct {
+	store         db.Store
+	authenticator authservice.Authenticator
+	tokenMaker    token.Maker
+	config        config.Config
+}
+
+// NewService checks the credentials of the consent step with authenticator, the same one LoginService uses, so every
+// AUTH_BACKENDS user can sign in to a client.
+func NewService(
+	store db.Store,
+	authenticator authservice.Authenticator,
+	tokenMaker token.Maker,
+	config config.Config,
+) *Service {
+	return &Service{store: store, authenticator: authenticator, tokenMaker: tokenMaker, config: config}
+}
+
+// RegisterClient stores a new client. Confidential clients get a secret that is returned once and only its hash is kept.
+func (service *Service) RegisterClient(context context.Context, request RegisterClientRequest) (client sqlc.OauthClient, clientSecret string, errs error) {
+	for _, redirectURI := range request.RedirectURIs {
+		u, err := url.Parse(redirectURI)
+		if err != nil || !u.IsAbs() || u.Fragment != "" {
+			errs = errors.New(errors.CodeBadRequest, "Redirect URIs must be absolute and must not contain a fragment", err)
+			return
+		}
+	}
+
+	clientId := uuid.New().String()
+
+	var hashedSecret string
+	if !request.Public {
+		var err error
+		clientSecret, err = token.GenerateOpaqueToken()
+		if err != nil {
+			errs = errors.New(errors.CodeInternal, "Failed to register client", err)
+			return
+		}
+		hashedSecret = token.HashToken(clientSecret)
+	}
+
+	client, err := service.store.CreateOAuthClient(context, ToCreateOAuthClientParams(request, clientId, hashedSecret))
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to register client", err)
+		return
+	}
+
+	return
+}
+
+// ValidateAuthorizeRequest returns an *errors.AppError when the client or redirect URI cannot be trusted,
+// in which case the user agent must not be redirected. Any other problem is returned as an *Error to be
+// sent back to the client's redirect URI.
+func (service *Service) ValidateAuthorizeRequest(context context.Context, request AuthorizeRequest) (client sqlc.OauthClient, errs error) {
+	client, err := service.store.GetOAuthClient(context, request.ClientID)
+	if err != nil {
+		if ierr.Is(err, sql.ErrNoRows) {
+			errs = errors.New(errors.CodeBadRequest, "Unknown client", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to get client", err)
+		return
+	}
+
+	if !slices.Contains(client.RedirectUris, request.RedirectURI) {
+		errs = errors.New(errors.CodeBadRequest, "Redirect URI is not registered for this client", fmt.Errorf("redirect uri %q is not registered for %s", request.RedirectURI, client.ClientID))
+		return
+	}
+
+	if request.ResponseType != ResponseTypeCode {
+		errs = newError(ErrUnsupportedResponseType, "Only the authorization code flow is supported", nil)
+		return
+	}
+	if !hasScope(request.Scope, ScopeOpenID) {
+		errs = newError(ErrInvalidScope, "The openid scope is required", nil)
+		return
+	}
+	if request.CodeChallenge == "" || request.CodeChallengeMethod != pkce.MethodS256 {
+		errs = newError(ErrInvalidRequest, "PKCE with the S256 method is required", nil)
+		return
+	}
+
+	return
+}
+
+func (service *Service) Authorize(context context.Context, request ConsentRequest) (client sqlc.OauthClient, redirectURL string, errs error) {
+	client, errs = service.ValidateAuthorizeRequest(context, request.AuthorizeRequest)
+	if errs != nil {
+		return
+	}
+
+	if request.Decision != DecisionApprove {
+		errs = newError(ErrAccessDenied, "The user denied the request", nil)
+		return
+	}
+
+	user, err := service.authenticator.Authenticate(context, request.Email, request.Password)
+	if err != nil {
+		var appErr *errors.AppError
+		if ierr.As(err, &appErr) && appErr.Code == errors.CodeInternal {
+			errs = err
+			return
+		}
+		errs = errors.New(errors.CodeUnauthorized, "Wrong email or password", err)
+		return
+	}
+	if !user.Active {
+		errs = errors.New(errors.CodeUnauthorized, "User is deactivated", nil)
+		return
+	}
+
+	code, err := token.GenerateOpaqueToken()
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to authorize client", err)
+		return
+	}
+
+	now := time.Now()
+	arg := ToCreateOAuthAuthorizationCodeParams(request.AuthorizeRequest, token.HashToken(code), user.ID, now, now.Add(service.config.OAuthCodeDuration))
+
+	_, err = service.store.CreateOAuthAuthorizationCode(context, arg)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to authorize client", err)
+		return
+	}
+
+	params := url.Values{"code": {code}}
+	if request.State != "" {
+		params.Set("state", request.State)
+	}
+
+	redirectURL, err = appendQuery(request.RedirectURI, params)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to authorize client", err)
+		return
+	}
+
+	return
+}
+
+// ErrorRedirect builds the redirect URL that reports an authorization error back to the client
+func ErrorRedirect(redirectURI, state string, oauthErr *Error) (string, error) {
+	params := url.Values{
+		"error":             {oauthErr.Code},
+		"error_description": {oauthErr.Description},
+	}
+	if state != "" {
+		params.Set("state", state)
+	}
+	return appendQuery(redirectURI, params)
+}
+
+func (service *Service) Token(context context.Context, request TokenRequest) (res TokenResponse, errs error) {
+	client, errs := service.authenticateClient(context, request.ClientID, request.ClientSecret)
+	if errs != nil {
+		return
+	}
+
+	switch request.GrantType {
+	case GrantTypeAuthorizationCode:
+		return service.exchangeAuthorizationCode(context, client, request)
+	case GrantTypeRefreshToken:
+		return service.exchangeRefreshToken(context, client, request)
+	default:
+		errs = newError(ErrUnsupportedGrantType, "Unsupported grant type", fmt.Errorf("grant type %q", request.GrantType))
+		return
+	}
+}
+
+func (service *Service) authenticateClient(context context.Context, clientId, clientSecret string) (client sqlc.OauthClient, errs error) {
+	if clientId == "" {
+		errs = newError(ErrInvalidClient, "Client authentication failed", fmt.Errorf("client_id is missing"))
+		return
+	}
+
+	client, err := service.store.GetOAuthClient(context, clientId)
+	if err != nil {
+		if ierr.Is(err, sql.ErrNoRows) {
+			errs = newError(ErrInvalidClient, "Client authentication failed", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to get client", err)
+		return
+	}
+
+	// Public clients have no secret and rely on PKCE alone
+	if client.ClientSecret != "" {
+		if subtle.ConstantTimeCompare([]byte(token.HashToken(clientSecret)), []byte(client.ClientSecret)) != 1 {
+			errs = newError(ErrInvalidClient, "Client authentication failed", fmt.Errorf("client secret does not match"))
+			return
+		}
+	}
+
+	return
+}
+
+func (service *Service) exchangeAuthorizationCode(context context.Context, client sqlc.OauthClient, request TokenRequest) (res TokenResponse, errs error) {
+	if request.Code == "" {
+		errs = newError(ErrInvalidRequest, "code is required", nil)
+		return
+	}
+
+	// Deleting on read makes the code single-use even under concurrent redemption
+	authCode, err := service.store.ConsumeOAuthAuthorizationCode(context, token.HashToken(request.Code))
+	if err != nil {
+		if ierr.Is(err, sql.ErrNoRows) {
+			errs = newError(ErrInvalidGrant, "Authorization code is invalid or has already been used", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to exchange authorization code", err)
+		return
+	}
+
+	if authCode.ClientID != client.ClientID {
+		errs = newError(ErrInvalidGrant, "Authorization code was issued to another client", nil)
+		return
+	}
+	if time.Now().After(authCode.ExpiredAt) {
+		errs = newError(ErrInvalidGrant, "Authorization code has expired", nil)
+		return
+	}
+	if authCode.RedirectUri != request.RedirectURI {
+		errs = newError(ErrInvalidGrant, "redirect_uri does not match the authorization request", nil)
+		return
+	}
+	if !pkce.VerifyCodeChallenge(request.CodeVerifier, authCode.CodeChallenge) {
+		errs = newError(ErrInvalidGrant, "PKCE verification failed", nil)
+		return
+	}
+
+	user, errs := service.getGrantUser(context, authCode.UserID)
+	if errs != nil {
+		return
+	}
+
+	return service.issueTokens(context, client.ClientID, user, authCode.Scope, authCode.Nonce, authCode.AuthTime)
+}
+
+func (service *Service) exchangeRefreshToken(context context.Context, client sqlc.OauthClient, request TokenRequest) (res TokenResponse, errs error) {
+	if request.RefreshToken == "" {
+		errs = newError(ErrInvalidRequest, "refresh_token is required", nil)
+		return
+	}
+
+	// Refresh tokens rotate: the presented one is deleted and a new one is issued
+	stored, err := service.store.ConsumeOAuthRefreshToken(context, token.HashToken(request.RefreshToken))
+	if err != nil {
+		if ierr.Is(err, sql.ErrNoRows) {
+			errs = newError(ErrInvalidGrant, "Refresh token is invalid or has already been used", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to refresh token", err)
+		return
+	}
+
+	if stored.ClientID != client.ClientID {
+		errs = newError(ErrInvalidGrant, "Refresh token was issued to another client", nil)
+		return
+	}
+	if time.Now().After(stored.ExpiredAt) {
+		errs = newError(ErrInvalidGrant, "Refresh token has expired", nil)
+		return
+	}
+
+	user, errs := service.getGrantUser(context, stored.UserID)
+	if errs != nil {
+		return
+	}
+
+	return service.issueTokens(context, client.ClientID, user, stored.Scope, "", stored.AuthTime)
+}
+
+func (service *Service) getGrantUser(context context.Context, userId int64) (user sqlc.User, errs error) {
+	user, err := service.store.GetUserByID(context, userId)
+	if err != nil {
+		if ierr.Is(err, sql.ErrNoRows) {
+			errs = newError(ErrInvalidGrant, "User no longer exists", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to get user", err)
+		return
+	}
+	if !user.Active {
+		errs = newError(ErrInvalidGrant, "User is deactivated", nil)
+		return
+	}
+	return
+}
+
+func (service *Service) issueTokens(context context.Context, clientId string, user sqlc.User, scope, nonce string, authTime time.Time) (res TokenResponse, errs error) {
+	now := time.Now()
+	subject := strconv.FormatInt(user.ID, 10)
+
+	// The string subject keeps these tokens from being accepted by AccessAuthMiddleware
+	accessClaims := jwt.MapClaims{
+		constant.SubKey:            subject,
+		constant.EmailKey:          user.Email,
+		constant.AudienceKey:       clientId,
+		constant.ClientIdKey:       clientId,
+		constant.ScopeKey:          scope,
+		constant.ExpirationKey:     now.Add(service.config.AccessTokenDuration).Unix(),
+		constant.IssuedAtKey:       now.Unix(),
+		constant.AuthTimeKey:       authTime.Unix(),
+		constant.JsonWebTokenIdKey: uuid.New().String(),
+	}
+
+	accessToken, err := service.tokenMaker.SignClaims(context, accessClaims)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to issue token", err)
+		return
+	}
+
+	refreshToken, err := token.GenerateOpaqueToken()
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to issue token", err)
+		return
+	}
+
+	arg := ToCreateOAuthRefreshTokenParams(clientId, user.ID, token.HashToken(refreshToken), scope, authTime, now.Add(service.config.RefreshTokenDuration))
+	_, err = service.store.CreateOAuthRefreshToken(context, arg)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to issue token", err)
+		return
+	}
+
+	idClaims := jwt.MapClaims{
+		constant.SubKey:          subject,
+		constant.AudienceKey:     clientId,
+		constant.ExpirationKey:   now.Add(service.config.IDTokenDuration).Unix(),
+		constant.IssuedAtKey:     now.Unix(),
+		constant.AuthTimeKey:     authTime.Unix(),
+		constant.AccessTokenHash: accessTokenHash(accessToken),
+	}
+	if nonce != "" {
+		idClaims[constant.NonceKey] = nonce
+	}
+	if hasScope(scope, ScopeEmail) {
+		idClaims[constant.EmailKey] = user.Email
+	}
+	if hasScope(scope, ScopeProfile) {
+		idClaims[constant.NameKey] = user.Name
+	}
+
+	idToken, err := service.tokenMaker.SignClaims(context, idClaims)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to issue token", err)
+		return
+	}
+
+	res = ToTokenResponse(accessToken, refreshToken, idToken, scope, service.config.AccessTokenDuration)
+	return
+}
+
+func (service *Service) UserInfo(context context.Context, accessToken string) (res UserInfoResponse, errs error) {
+	_, claims, err := service.tokenMaker.VerifyToken(accessToken)
+	if err != nil {
+		errs = newError(ErrInvalidToken, "Access token is invalid", err)
+		return
+	}
+
+	if _, ok := claims[constant.ClientIdKey].(string); !ok {
+		errs = newError(ErrInvalidToken, "Access token was not issued to an OAuth client", nil)
+		return
+	}
+
+	scope, _ := claims[constant.ScopeKey].(string)
+	if !hasScope(scope, ScopeOpenID) {
+		errs = newError(ErrInvalidToken, "Access token lacks the openid scope", nil)
+		return
+	}
+
+	sub, _ := claims[constant.SubKey].(string)
+	userId, err := strconv.ParseInt(sub, 10, 64)
+	if err != nil {
+		errs = newError(ErrInvalidToken, "Access token subject is invalid", err)
+		return
+	}
+
+	user, err := service.store.GetUserByID(context, userId)
+	if err != nil {
+		if ierr.Is(err, sql.ErrNoRows) {
+			errs = newError(ErrInvalidToken, "User no longer exists", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to get user", err)
+		return
+	}
+	if !user.Active {
+		errs = newError(ErrInvalidToken, "User is deactivated", nil)
+		return
+	}
+
+	res = ToUserInfoResponse(user, scope)
+	return
+}
+
+func (service *Service) Discovery() DiscoveryResponse {
+	baseURL := strings.TrimSuffix(service.config.PublicURL, "/")
+	return DiscoveryResponse{
+		Issuer:                            service.config.TokenIssuer,
+		AuthorizationEndpoint:             baseURL + "/oauth/authorize",
+		TokenEndpoint:                     baseURL + "/oauth/token",
+		UserInfoEndpoint:                  baseURL + "/oauth/userinfo",
+		JwksURI:                           baseURL + "/oauth/jwks",
+		ResponseTypesSupported:            []string{ResponseTypeCode},
+		GrantTypesSupported:               []string{GrantTypeAuthorizationCode, GrantTypeRefreshToken},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{service.tokenMaker.Algorithm()},
+		ScopesSupported:                   []string{ScopeOpenID, ScopeProfile, ScopeEmail},
+		ClaimsSupported:                   []string{constant.SubKey, constant.IssuerKey, constant.AudienceKey, constant.ExpirationKey, constant.IssuedAtKey, constant.AuthTimeKey, constant.NonceKey, constant.EmailKey, constant.NameKey},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{pkce.MethodS256},
+	}
+}
+
+func (service *Service) JWKS() JWKSResponse {
+	return JWKSResponse{Keys: service.tokenMaker.JWKS()}
+}
+
+func hasScope(scope, want string) bool {
+	return slices.Contains(strings.Fields(scope), want)
+}
+
+// accessTokenHash is the OIDC at_hash: the left half of the SHA-256 digest of the access token
+func accessTokenHash(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return base64.RawURLEncoding.EncodeToString(sum[:len(sum)/2])
+}
+
+func appendQuery(rawURL string, params url.Values) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	for key, values := range params {
+		for _, value := range values {
+			query.Add(key, value)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,475 @@
+package oauthservice
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/factory/userfactory"
+	"github.com/hanifsyahsn/go_boilerplate/internal/service/authservice"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/pkce"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRedirectURI  = "https://app.example.com/callback"
+	testCodeVerifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+)
+
+func newTestService(store db.Store) *Service {
+	return NewService(store, authservice.NewPasswordAuthenticator(store, util.CheckPasswordHash), tokenMaker, conf)
+}
+
+func newTestClient(secret string) sqlc.OauthClient {
+	client := sqlc.OauthClient{
+		ID:           1,
+		ClientID:     util.RandomString(12),
+		Name:         util.RandomString(6),
+		RedirectUris: []string{testRedirectURI},
+	}
+	if secret != "" {
+		client.ClientSecret = token.HashToken(secret)
+	}
+	return client
+}
+
+func newTestAuthorizationCode(client sqlc.OauthClient, user sqlc.User) sqlc.OauthAuthorizationCode {
+	return sqlc.OauthAuthorizationCode{
+		ClientID:            client.ClientID,
+		UserID:              user.ID,
+		RedirectUri:         testRedirectURI,
+		Scope:               "openid email profile",
+		Nonce:               "n-0S6_WzA2Mj",
+		CodeChallenge:       pkce.S256CodeChallenge(testCodeVerifier),
+		CodeChallengeMethod: pkce.MethodS256,
+		AuthTime:            time.Now(),
+		ExpiredAt:           time.Now().Add(time.Minute),
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	user := userfactory.NewOptions(nil)
+	password := util.RandomString(10)
+	hashed, err := util.HashPassword(context.Background(), password)
+	require.NoError(t, err)
+	user.Password = hashed
+
+	client := newTestClient("")
+	request := ConsentRequest{
+		AuthorizeRequest: AuthorizeRequest{
+			ResponseType:        ResponseTypeCode,
+			ClientID:            client.ClientID,
+			RedirectURI:         testRedirectURI,
+			Scope:               "openid email",
+			State:               "af0ifjsldkj",
+			CodeChallenge:       pkce.S256CodeChallenge(testCodeVerifier),
+			CodeChallengeMethod: pkce.MethodS256,
+		},
+		Email:    user.Email,
+		Password: password,
+		Decision: DecisionApprove,
+	}
+
+	testCases := []struct {
+		name          string
+		request       func() ConsentRequest
+		buildStub     func(store *db.MockStore)
+		checkResponse func(t *testing.T, redirectURL string, err error)
+	}{
+		{
+			name:    "success",
+			request: func() ConsentRequest { return request },
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), client.ClientID).Times(1).Return(client, nil)
+				store.EXPECT().GetUser(gomock.Any(), user.Email).Times(1).Return(user, nil)
+				store.EXPECT().CreateOAuthAuthorizationCode(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.OauthAuthorizationCode{}, nil)
+			},
+			checkResponse: func(t *testing.T, redirectURL string, err error) {
+				require.NoError(t, err)
+				u, err := url.Parse(redirectURL)
+				require.NoError(t, err)
+				require.NotEmpty(t, u.Query().Get("code"))
+				require.Equal(t, "af0ifjsldkj", u.Query().Get("state"))
+			},
+		},
+		{
+			name: "unregistered redirect uri",
+			request: func() ConsentRequest {
+				r := request
+				r.RedirectURI = "https://evil.example.com/callback"
+				return r
+			},
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), client.ClientID).Times(1).Return(client, nil)
+			},
+			checkResponse: func(t *testing.T, redirectURL string, err error) {
+				require.ErrorContains(t, err, "Redirect URI is not registered")
+				require.Empty(t, redirectURL)
+			},
+		},
+		{
+			name: "missing pkce",
+			request: func() ConsentRequest {
+				r := request
+				r.CodeChallenge = ""
+				return r
+			},
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), client.ClientID).Times(1).Return(client, nil)
+			},
+			checkResponse: func(t *testing.T, redirectURL string, err error) {
+				var oauthErr *Error
+				require.ErrorAs(t, err, &oauthErr)
+				require.Equal(t, ErrInvalidRequest, oauthErr.Code)
+			},
+		},
+		{
+			name: "user denied",
+			request: func() ConsentRequest {
+				r := request
+				r.Decision = "deny"
+				return r
+			},
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), client.ClientID).Times(1).Return(client, nil)
+			},
+			checkResponse: func(t *testing.T, redirectURL string, err error) {
+				var oauthErr *Error
+				require.ErrorAs(t, err, &oauthErr)
+				require.Equal(t, ErrAccessDenied, oauthErr.Code)
+			},
+		},
+		{
+			name: "wrong password",
+			request: func() ConsentRequest {
+				r := request
+				r.Password = util.RandomString(10)
+				return r
+			},
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), client.ClientID).Times(1).Return(client, nil)
+				store.EXPECT().GetUser(gomock.Any(), user.Email).Times(1).Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, redirectURL string, err error) {
+				require.ErrorContains(t, err, "Wrong email or password")
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStore := db.NewMockStore(ctrl)
+			testCase.buildStub(mockStore)
+
+			svc := newTestService(mockStore)
+			_, redirectURL, err := svc.Authorize(context.Background(), testCase.request())
+			testCase.checkResponse(t, redirectURL, err)
+		})
+	}
+}
+
+// authenticatorFunc stands in for a non local backend such as LDAP
+type authenticatorFunc func(ctx context.Context, email, password string) (sqlc.User, error)
+
+func (f authenticatorFunc) Authenticate(ctx context.Context, email, password string) (sqlc.User, error) {
+	return f(ctx, email, password)
+}
+
+func TestAuthorizeAuthenticator(t *testing.T) {
+	user := userfactory.NewOptions(nil)
+	client := newTestClient("")
+	request := ConsentRequest{
+		AuthorizeRequest: AuthorizeRequest{
+			ResponseType:        ResponseTypeCode,
+			ClientID:            client.ClientID,
+			RedirectURI:         testRedirectURI,
+			Scope:               "openid email",
+			CodeChallenge:       pkce.S256CodeChallenge(testCodeVerifier),
+			CodeChallengeMethod: pkce.MethodS256,
+		},
+		Email:    user.Email,
+		Password: util.RandomString(10),
+		Decision: DecisionApprove,
+	}
+
+	testCases := []struct {
+		name          string
+		authenticate  authenticatorFunc
+		buildStub     func(store *db.MockStore)
+		checkResponse func(t *testing.T, redirectURL string, err error)
+	}{
+		{
+			name: "directory user",
+			authenticate: func(ctx context.Context, email, password string) (sqlc.User, error) {
+				require.Equal(t, request.Email, email)
+				require.Equal(t, request.Password, password)
+				return user, nil
+			},
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), client.ClientID).Times(1).Return(client, nil)
+				store.EXPECT().CreateOAuthAuthorizationCode(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.OauthAuthorizationCode{}, nil)
+			},
+			checkResponse: func(t *testing.T, redirectURL string, err error) {
+				require.NoError(t, err)
+				u, err := url.Parse(redirectURL)
+				require.NoError(t, err)
+				require.NotEmpty(t, u.Query().Get("code"))
+			},
+		},
+		{
+			name: "unknown user",
+			authenticate: func(ctx context.Context, email, password string) (sqlc.User, error) {
+				return sqlc.User{}, errors.New(errors.CodeNotFound, "User is not found", sql.ErrNoRows)
+			},
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), client.ClientID).Times(1).Return(client, nil)
+				store.EXPECT().CreateOAuthAuthorizationCode(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, redirectURL string, err error) {
+				var appErr *errors.AppError
+				require.ErrorAs(t, err, &appErr)
+				require.Equal(t, errors.CodeUnauthorized, appErr.Code)
+				require.Equal(t, "Wrong email or password", appErr.Message)
+			},
+		},
+		{
+			name: "backend unavailable",
+			authenticate: func(ctx context.Context, email, password string) (sqlc.User, error) {
+				return sqlc.User{}, errors.New(errors.CodeInternal, "Failed to authenticate user", sql.ErrConnDone)
+			},
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), client.ClientID).Times(1).Return(client, nil)
+				store.EXPECT().CreateOAuthAuthorizationCode(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, redirectURL string, err error) {
+				var appErr *errors.AppError
+				require.ErrorAs(t, err, &appErr)
+				require.Equal(t, errors.CodeInternal, appErr.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStore := db.NewMockStore(ctrl)
+			testCase.buildStub(mockStore)
+
+			svc := NewService(mockStore, testCase.authenticate, tokenMaker, conf)
+			_, redirectURL, err := svc.Authorize(context.Background(), request)
+			testCase.checkResponse(t, redirectURL, err)
+		})
+	}
+}
+
+func TestTokenAuthorizationCode(t *testing.T) {
+	user := userfactory.NewOptions(nil)
+	secret := util.RandomString(20)
+	client := newTestClient(secret)
+	code := util.RandomString(20)
+
+	request := TokenRequest{
+		GrantType:    GrantTypeAuthorizationCode,
+		Code:         code,
+		RedirectURI:  testRedirectURI,
+		CodeVerifier: testCodeVerifier,
+		ClientID:     client.ClientID,
+		ClientSecret: secret,
+	}
+
+	testCases := []struct {
+		name          string
+		request       func() TokenRequest
+		buildStub     func(store *db.MockStore)
+		checkResponse func(t *testing.T, res TokenResponse, err error)
+	}{
+		{
+			name:    "success",
+			request: func() TokenRequest { return request },
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), client.ClientID).Times(1).Return(client, nil)
+				store.EXPECT().ConsumeOAuthAuthorizationCode(gomock.Any(), token.HashToken(code)).Times(1).Return(newTestAuthorizationCode(client, user), nil)
+				store.EXPECT().GetUserByID(gomock.Any(), user.ID).Times(1).Return(user, nil)
+				store.EXPECT().CreateOAuthRefreshToken(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.OauthRefreshToken{}, nil)
+			},
+			checkResponse: func(t *testing.T, res TokenResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "Bearer", res.TokenType)
+				require.NotEmpty(t, res.AccessToken)
+				require.NotEmpty(t, res.RefreshToken)
+
+				_, idClaims, err := tokenMaker.VerifyToken(res.IDToken)
+				require.NoError(t, err)
+				require.Equal(t, strconv.FormatInt(user.ID, 10), idClaims[constant.SubKey])
+				require.Equal(t, client.ClientID, idClaims[constant.AudienceKey])
+				require.Equal(t, "n-0S6_WzA2Mj", idClaims[constant.NonceKey])
+				require.Equal(t, user.Email, idClaims[constant.EmailKey])
+				require.Equal(t, accessTokenHash(res.AccessToken), idClaims[constant.AccessTokenHash])
+			},
+		},
+		{
+			name: "wrong client secret",
+			request: func() TokenRequest {
+				r := request
+				r.ClientSecret = util.RandomString(20)
+				return r
+			},
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), client.ClientID).Times(1).Return(client, nil)
+			},
+			checkResponse: func(t *testing.T, res TokenResponse, err error) {
+				var oauthErr *Error
+				require.ErrorAs(t, err, &oauthErr)
+				require.Equal(t, ErrInvalidClient, oauthErr.Code)
+			},
+		},
+		{
+			name: "code already used",
+			request: func() TokenRequest {
+				return request
+			},
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), client.ClientID).Times(1).Return(client, nil)
+				store.EXPECT().ConsumeOAuthAuthorizationCode(gomock.Any(), token.HashToken(code)).Times(1).Return(sqlc.OauthAuthorizationCode{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, res TokenResponse, err error) {
+				var oauthErr *Error
+				require.ErrorAs(t, err, &oauthErr)
+				require.Equal(t, ErrInvalidGrant, oauthErr.Code)
+			},
+		},
+		{
+			name: "wrong code verifier",
+			request: func() TokenRequest {
+				r := request
+				r.CodeVerifier = util.RandomString(50)
+				return r
+			},
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), client.ClientID).Times(1).Return(client, nil)
+				store.EXPECT().ConsumeOAuthAuthorizationCode(gomock.Any(), token.HashToken(code)).Times(1).Return(newTestAuthorizationCode(client, user), nil)
+			},
+			checkResponse: func(t *testing.T, res TokenResponse, err error) {
+				require.ErrorContains(t, err, "PKCE verification failed")
+			},
+		},
+		{
+			name: "expired code",
+			request: func() TokenRequest {
+				return request
+			},
+			buildStub: func(store *db.MockStore) {
+				authCode := newTestAuthorizationCode(client, user)
+				authCode.ExpiredAt = time.Now().Add(-time.Second)
+				store.EXPECT().GetOAuthClient(gomock.Any(), client.ClientID).Times(1).Return(client, nil)
+				store.EXPECT().ConsumeOAuthAuthorizationCode(gomock.Any(), token.HashToken(code)).Times(1).Return(authCode, nil)
+			},
+			checkResponse: func(t *testing.T, res TokenResponse, err error) {
+				require.ErrorContains(t, err, "Authorization code has expired")
+			},
+		},
+		{
+			name: "unsupported grant type",
+			request: func() TokenRequest {
+				r := request
+				r.GrantType = "password"
+				return r
+			},
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), client.ClientID).Times(1).Return(client, nil)
+			},
+			checkResponse: func(t *testing.T, res TokenResponse, err error) {
+				var oauthErr *Error
+				require.ErrorAs(t, err, &oauthErr)
+				require.Equal(t, ErrUnsupportedGrantType, oauthErr.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStore := db.NewMockStore(ctrl)
+			testCase.buildStub(mockStore)
+
+			svc := newTestService(mockStore)
+			res, err := svc.Token(context.Background(), testCase.request())
+			testCase.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestTokenRefreshTokenRotation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user := userfactory.NewOptions(nil)
+	client := newTestClient("")
+	refreshToken := util.RandomString(20)
+
+	mockStore := db.NewMockStore(ctrl)
+	mockStore.EXPECT().GetOAuthClient(gomock.Any(), client.ClientID).Times(1).Return(client, nil)
+	mockStore.EXPECT().ConsumeOAuthRefreshToken(gomock.Any(), token.HashToken(refreshToken)).Times(1).Return(sqlc.OauthRefreshToken{
+		ClientID:  client.ClientID,
+		UserID:    user.ID,
+		Scope:     "openid",
+		AuthTime:  time.Now(),
+		ExpiredAt: time.Now().Add(time.Hour),
+	}, nil)
+	mockStore.EXPECT().GetUserByID(gomock.Any(), user.ID).Times(1).Return(user, nil)
+	mockStore.EXPECT().CreateOAuthRefreshToken(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.OauthRefreshToken{}, nil)
+
+	svc := newTestService(mockStore)
+	res, err := svc.Token(context.Background(), TokenRequest{
+		GrantType:    GrantTypeRefreshToken,
+		RefreshToken: refreshToken,
+		ClientID:     client.ClientID,
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, refreshToken, res.RefreshToken)
+	require.Equal(t, "openid", res.Scope)
+}
+
+func TestUserInfo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user := userfactory.NewOptions(nil)
+	mockStore := db.NewMockStore(ctrl)
+	svc := newTestService(mockStore)
+
+	// First-party access tokens carry a numeric subject and no client_id, so they are rejected
+	firstPartyToken, _, _, _, err := tokenMaker.CreateToken(context.Background(), user, token.Session{}, time.Minute, time.Hour)
+	require.NoError(t, err)
+	_, err = svc.UserInfo(context.Background(), firstPartyToken)
+	require.ErrorContains(t, err, "not issued to an OAuth client")
+
+	client := newTestClient("")
+	mockStore.EXPECT().CreateOAuthRefreshToken(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.OauthRefreshToken{}, nil)
+	tokens, err := svc.issueTokens(context.Background(), client.ClientID, user, "openid email", "", time.Now())
+	require.NoError(t, err)
+
+	mockStore.EXPECT().GetUserByID(gomock.Any(), user.ID).Times(1).Return(user, nil)
+	res, err := svc.UserInfo(context.Background(), tokens.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, strconv.FormatInt(user.ID, 10), res.Sub)
+	require.Equal(t, user.Email, res.Email)
+	require.Empty(t, res.Name)
+}
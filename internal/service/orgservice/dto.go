@@ -0,0 +1,56 @@
+package orgservice
+
+import (
+	"time"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/service/userservice"
+)
+
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=255"`
+}
+
+type OrganizationResponse struct {
+	ID         int64                          `json:"id"`
+	Name       string                         `json:"name"`
+	Membership userservice.MembershipResponse `json:"membership"`
+	CreatedAt  time.Time                      `json:"created_at"`
+}
+
+type MembershipsResponse struct {
+	Memberships []userservice.MembershipResponse `json:"memberships"`
+}
+
+type MemberResponse struct {
+	UserID    int64     `json:"user_id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type MembersResponse struct {
+	Members []MemberResponse `json:"members"`
+}
+
+type InviteRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=admin member"`
+}
+
+type InvitationResponse struct {
+	ID             int64     `json:"id"`
+	OrganizationID int64     `json:"organization_id"`
+	Email          string    `json:"email"`
+	Role           string    `json:"role"`
+	ExpiredAt      time.Time `json:"expired_at"`
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// SwitchOrganizationRequest selects the organization the next tokens are scoped to. Zero leaves any organization.
+type SwitchOrganizationRequest struct {
+	OrganizationID int64 `json:"organization_id" binding:"min=0"`
+}
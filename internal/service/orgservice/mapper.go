@@ -0,0 +1,68 @@
+package orgservice
+
+import (
+	"time"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/service/userservice"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
+)
+
+func ToOrganizationResponse(organization sqlc.Organization, membership sqlc.Membership) (res OrganizationResponse) {
+	res = OrganizationResponse{
+		ID:   organization.ID,
+		Name: organization.Name,
+		Membership: userservice.MembershipResponse{
+			OrganizationID: organization.ID,
+			Name:           organization.Name,
+			Role:           membership.Role,
+			CreatedAt:      membership.CreatedAt,
+		},
+		CreatedAt: organization.CreatedAt,
+	}
+	return
+}
+
+func ToMembershipsResponse(memberships []sqlc.ListMembershipsByUserIdRow) (res MembershipsResponse) {
+	res = MembershipsResponse{
+		Memberships: userservice.SqlcMembershipsToMembershipResponses(memberships),
+	}
+	return
+}
+
+func ToMembersResponse(members []sqlc.ListMembersByOrganizationIdRow) (res MembersResponse) {
+	res = MembersResponse{Members: make([]MemberResponse, 0, len(members))}
+	for _, member := range members {
+		res.Members = append(res.Members, MemberResponse{
+			UserID:    member.UserID,
+			Name:      member.Name,
+			Email:     member.Email,
+			Role:      member.Role,
+			CreatedAt: member.CreatedAt,
+		})
+	}
+	return
+}
+
+func ToInvitationResponse(invitation sqlc.OrganizationInvitation) (res InvitationResponse) {
+	res = InvitationResponse{
+		ID:             invitation.ID,
+		OrganizationID: invitation.OrganizationID,
+		Email:          invitation.Email,
+		Role:           invitation.Role,
+		ExpiredAt:      invitation.ExpiredAt,
+	}
+	return
+}
+
+func ToUpsertOrganizationInvitationParams(organizationId, inviterId int64, request InviteRequest, invitationToken string, expiresAt time.Time) (res sqlc.UpsertOrganizationInvitationParams) {
+	res = sqlc.UpsertOrganizationInvitationParams{
+		OrganizationID: organizationId,
+		Email:          request.Email,
+		Role:           request.Role,
+		Token:          token.HashToken(invitationToken),
+		InvitedBy:      inviterId,
+		ExpiredAt:      expiresAt,
+	}
+	return
+}
@@ -0,0 +1,183 @@
+package orgservice
+
+import (
+	"context"
+	"database/sql"
+	ierr "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/config"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/service/authservice"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/mailer"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
+	"github.com/lib/pq"
+)
+
+type Service struct {
+	store       db.Store
+	mailer      mailer.Mailer
+	authService *authservice.Service
+	config      config.Config
+}
+
+func NewService(store db.Store, mailer mailer.Mailer, authService *authservice.Service, config config.Config) *Service {
+	return &Service{store: store, mailer: mailer, authService: authService, config: config}
+}
+
+// CreateOrganizationService creates an organization owned by the calling user
+func (service *Service) CreateOrganizationService(context context.Context, userId int64, request CreateOrganizationRequest) (organization sqlc.Organization, membership sqlc.Membership, errs error) {
+	organization, membership, err := service.store.CreateOrganizationTx(context, request.Name, userId)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to create organization", err)
+		return
+	}
+	return
+}
+
+func (service *Service) ListMembershipsService(context context.Context, userId int64) (memberships []sqlc.ListMembershipsByUserIdRow, errs error) {
+	memberships, err := service.store.ListMembershipsByUserId(context, userId)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to get memberships", err)
+		return
+	}
+	return
+}
+
+func (service *Service) ListMembersService(context context.Context, organizationId int64) (members []sqlc.ListMembersByOrganizationIdRow, errs error) {
+	members, err := service.store.ListMembersByOrganizationId(context, organizationId)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to get members", err)
+		return
+	}
+	return
+}
+
+// InviteService emails an invitation link for the organization. Inviting an email with a pending invitation
+// replaces it, so the previous link stops working.
+func (service *Service) InviteService(context context.Context, organizationId, inviterId int64, request InviteRequest) (invitation sqlc.OrganizationInvitation, errs error) {
+	organization, err := service.store.GetOrganization(context, organizationId)
+	if err != nil {
+		if ierr.Is(err, sql.ErrNoRows) {
+			errs = errors.New(errors.CodeNotFound, "Organization is not found", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to invite member", err)
+		return
+	}
+
+	user, err := service.store.GetUser(context, request.Email)
+	if err != nil && !ierr.Is(err, sql.ErrNoRows) {
+		errs = errors.New(errors.CodeInternal, "Failed to invite member", err)
+		return
+	}
+	if err == nil {
+		_, err = service.store.GetMembership(context, sqlc.GetMembershipParams{OrganizationID: organizationId, UserID: user.ID})
+		if err == nil {
+			errs = errors.New(errors.CodeConflict, "User is already a member of the organization", nil)
+			return
+		}
+		if !ierr.Is(err, sql.ErrNoRows) {
+			errs = errors.New(errors.CodeInternal, "Failed to invite member", err)
+			return
+		}
+	}
+
+	invitationToken, err := token.GenerateOpaqueToken()
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to invite member", err)
+		return
+	}
+
+	arg := ToUpsertOrganizationInvitationParams(organizationId, inviterId, request, invitationToken, time.Now().Add(service.config.OrgInvitationDuration))
+	invitation, err = service.store.UpsertOrganizationInvitation(context, arg)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to invite member", err)
+		return
+	}
+
+	err = service.mailer.Send(context, mailer.Message{
+		To:      request.Email,
+		Subject: fmt.Sprintf("You have been invited to join %s", organization.Name),
+		Body: fmt.Sprintf("You have been invited to join %s as %s. Use the link below to accept the invitation. It expires in %s.\n\n%s\n\nIf you were not expecting this email you can ignore it.\n",
+			organization.Name, request.Role, service.config.OrgInvitationDuration, util.WithQueryParam(service.config.OrgInvitationURL, "token", invitationToken)),
+	})
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to invite member", err)
+		return
+	}
+
+	return
+}
+
+// AcceptInvitationService adds the calling user to the inviting organization. The invitation is bound to the
+// invited email, so a leaked link cannot be redeemed by a different account.
+func (service *Service) AcceptInvitationService(context context.Context, userId int64, email string, request AcceptInvitationRequest) (membership sqlc.Membership, errs error) {
+	invitation, err := service.store.GetOrganizationInvitationByToken(context, token.HashToken(request.Token))
+	if err != nil {
+		if ierr.Is(err, sql.ErrNoRows) {
+			errs = errors.New(errors.CodeNotFound, "Invitation is invalid or has already been used", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to accept invitation", err)
+		return
+	}
+
+	if time.Now().After(invitation.ExpiredAt) {
+		errs = errors.New(errors.CodeTokenExpired, "Invitation has expired", fmt.Errorf("invitation expired at %s", invitation.ExpiredAt))
+		return
+	}
+
+	if !strings.EqualFold(invitation.Email, email) {
+		errs = errors.New(errors.CodeForbidden, "Invitation was sent to a different email", fmt.Errorf("invitation email mismatch"))
+		return
+	}
+
+	membership, err = service.store.AcceptOrganizationInvitationTx(context, invitation, userId)
+	if err != nil {
+		var pqErr *pq.Error
+		if ierr.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" && strings.Contains(pqErr.Constraint, "memberships_organization_user_unique") {
+			errs = errors.New(errors.CodeConflict, "User is already a member of the organization", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to accept invitation", err)
+		return
+	}
+	return
+}
+
+// SwitchOrganizationService re-issues the session tokens scoped to the given organization, which the user must be
+// a member of. An organization id of zero issues tokens without an active organization. The new tokens replace the
+// current session rather than starting another one.
+func (service *Service) SwitchOrganizationService(context context.Context, userId int64, session token.Session, request SwitchOrganizationRequest) (user sqlc.User, accessToken, refreshToken string, errs error) {
+	if request.OrganizationID != 0 {
+		_, err := service.store.GetMembership(context, sqlc.GetMembershipParams{OrganizationID: request.OrganizationID, UserID: userId})
+		if err != nil {
+			if ierr.Is(err, sql.ErrNoRows) {
+				errs = errors.New(errors.CodeForbidden, "User is not a member of the organization", err)
+				return
+			}
+			errs = errors.New(errors.CodeInternal, "Failed to switch organization", err)
+			return
+		}
+	}
+
+	user, err := service.store.GetUserByID(context, userId)
+	if err != nil {
+		if ierr.Is(err, sql.ErrNoRows) {
+			errs = errors.New(errors.CodeNotFound, "User is not found", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to switch organization", err)
+		return
+	}
+
+	session.OrgId = request.OrganizationID
+	accessToken, refreshToken, errs = service.authService.IssueSessionTokensService(context, user, session)
+	return
+}
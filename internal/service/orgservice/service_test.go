@@ -0,0 +1,327 @@
+package orgservice
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/factory/userfactory"
+	"github.com/hanifsyahsn/go_boilerplate/internal/service/authservice"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	appErrors "github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/mailer"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/redis"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(ctrl *gomock.Controller) (*Service, *db.MockStore, *mailer.MockMailer, *redis.MockClient) {
+	mockStore := db.NewMockStore(ctrl)
+	mockMailer := mailer.NewMockMailer(ctrl)
+	mockRedis := redis.NewMockClient(ctrl)
+	authSvc := authservice.NewService(mockStore, util.HashPassword, util.CheckPasswordHash, tokenMaker, conf, mockRedis)
+	return NewService(mockStore, mockMailer, authSvc, conf), mockStore, mockMailer, mockRedis
+}
+
+func TestCreateOrganizationService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc, mockStore, _, _ := newTestService(ctrl)
+
+	name := util.RandomString(10)
+	mockStore.EXPECT().CreateOrganizationTx(gomock.Any(), name, int64(1)).Times(1).Return(
+		sqlc.Organization{ID: 2, Name: name},
+		sqlc.Membership{OrganizationID: 2, UserID: 1, Role: constant.OrgRoleOwner},
+		nil,
+	)
+
+	organization, membership, err := svc.CreateOrganizationService(context.Background(), 1, CreateOrganizationRequest{Name: name})
+	require.NoError(t, err)
+	require.Equal(t, name, organization.Name)
+	require.Equal(t, constant.OrgRoleOwner, membership.Role)
+}
+
+func TestInviteService(t *testing.T) {
+	organization := sqlc.Organization{ID: 2, Name: util.RandomString(10)}
+	request := InviteRequest{Email: "invitee@mail.com", Role: constant.OrgRoleMember}
+
+	testCases := []struct {
+		name          string
+		buildStub     func(store *db.MockStore, mail *mailer.MockMailer)
+		checkResponse func(t *testing.T, err error)
+	}{
+		{
+			name: "OK new user",
+			buildStub: func(store *db.MockStore, mail *mailer.MockMailer) {
+				var stored sqlc.UpsertOrganizationInvitationParams
+				store.EXPECT().GetOrganization(gomock.Any(), organization.ID).Times(1).Return(organization, nil)
+				store.EXPECT().GetUser(gomock.Any(), request.Email).Times(1).Return(sqlc.User{}, sql.ErrNoRows)
+				store.EXPECT().UpsertOrganizationInvitation(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, arg sqlc.UpsertOrganizationInvitationParams) (sqlc.OrganizationInvitation, error) {
+					stored = arg
+					return sqlc.OrganizationInvitation{OrganizationID: arg.OrganizationID, Email: arg.Email, Role: arg.Role}, nil
+				})
+				mail.EXPECT().Send(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, message mailer.Message) error {
+					require.Equal(t, request.Email, message.To)
+					require.Contains(t, message.Subject, organization.Name)
+					// Only the digest of the mailed token is persisted
+					require.Equal(t, token.HashToken(extractInvitationToken(t, message.Body)), stored.Token)
+					require.Equal(t, int64(1), stored.InvitedBy)
+					require.WithinDuration(t, time.Now().Add(conf.OrgInvitationDuration), stored.ExpiredAt, time.Minute)
+					return nil
+				})
+			},
+			checkResponse: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "already a member",
+			buildStub: func(store *db.MockStore, mail *mailer.MockMailer) {
+				store.EXPECT().GetOrganization(gomock.Any(), organization.ID).Times(1).Return(organization, nil)
+				store.EXPECT().GetUser(gomock.Any(), request.Email).Times(1).Return(sqlc.User{ID: 5}, nil)
+				store.EXPECT().GetMembership(gomock.Any(), sqlc.GetMembershipParams{OrganizationID: organization.ID, UserID: 5}).Times(1).Return(sqlc.Membership{}, nil)
+				store.EXPECT().UpsertOrganizationInvitation(gomock.Any(), gomock.Any()).Times(0)
+				mail.EXPECT().Send(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, err error) {
+				requireAppErrorCode(t, err, appErrors.CodeConflict)
+			},
+		},
+		{
+			name: "unknown organization",
+			buildStub: func(store *db.MockStore, mail *mailer.MockMailer) {
+				store.EXPECT().GetOrganization(gomock.Any(), organization.ID).Times(1).Return(sqlc.Organization{}, sql.ErrNoRows)
+				store.EXPECT().UpsertOrganizationInvitation(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, err error) {
+				requireAppErrorCode(t, err, appErrors.CodeNotFound)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, mockStore, mockMailer, _ := newTestService(ctrl)
+
+			testCase.buildStub(mockStore, mockMailer)
+
+			_, err := svc.InviteService(context.Background(), organization.ID, 1, request)
+			testCase.checkResponse(t, err)
+		})
+	}
+}
+
+func TestAcceptInvitationService(t *testing.T) {
+	invitationToken := util.RandomString(43)
+	user := userfactory.NewOptions(&userfactory.Options{Email: "invitee@mail.com"})
+	invitation := sqlc.OrganizationInvitation{
+		ID:             3,
+		OrganizationID: 2,
+		Email:          user.Email,
+		Role:           constant.OrgRoleAdmin,
+		ExpiredAt:      time.Now().Add(time.Hour),
+	}
+
+	testCases := []struct {
+		name          string
+		email         string
+		buildStub     func(store *db.MockStore)
+		checkResponse func(t *testing.T, membership sqlc.Membership, err error)
+	}{
+		{
+			name:  "OK",
+			email: user.Email,
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetOrganizationInvitationByToken(gomock.Any(), token.HashToken(invitationToken)).Times(1).Return(invitation, nil)
+				store.EXPECT().AcceptOrganizationInvitationTx(gomock.Any(), invitation, user.ID).Times(1).Return(sqlc.Membership{
+					OrganizationID: invitation.OrganizationID,
+					UserID:         user.ID,
+					Role:           invitation.Role,
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, membership sqlc.Membership, err error) {
+				require.NoError(t, err)
+				require.Equal(t, constant.OrgRoleAdmin, membership.Role)
+			},
+		},
+		{
+			name:  "unknown token",
+			email: user.Email,
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetOrganizationInvitationByToken(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.OrganizationInvitation{}, sql.ErrNoRows)
+				store.EXPECT().AcceptOrganizationInvitationTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, membership sqlc.Membership, err error) {
+				requireAppErrorCode(t, err, appErrors.CodeNotFound)
+			},
+		},
+		{
+			name:  "expired",
+			email: user.Email,
+			buildStub: func(store *db.MockStore) {
+				expired := invitation
+				expired.ExpiredAt = time.Now().Add(-time.Second)
+				store.EXPECT().GetOrganizationInvitationByToken(gomock.Any(), gomock.Any()).Times(1).Return(expired, nil)
+				store.EXPECT().AcceptOrganizationInvitationTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, membership sqlc.Membership, err error) {
+				requireAppErrorCode(t, err, appErrors.CodeTokenExpired)
+			},
+		},
+		{
+			name:  "different account",
+			email: "someone@mail.com",
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetOrganizationInvitationByToken(gomock.Any(), gomock.Any()).Times(1).Return(invitation, nil)
+				store.EXPECT().AcceptOrganizationInvitationTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, membership sqlc.Membership, err error) {
+				requireAppErrorCode(t, err, appErrors.CodeForbidden)
+			},
+		},
+		{
+			name:  "already a member",
+			email: user.Email,
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetOrganizationInvitationByToken(gomock.Any(), gomock.Any()).Times(1).Return(invitation, nil)
+				store.EXPECT().AcceptOrganizationInvitationTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(sqlc.Membership{}, &pq.Error{
+					Code:       "23505",
+					Constraint: "memberships_organization_user_unique",
+				})
+			},
+			checkResponse: func(t *testing.T, membership sqlc.Membership, err error) {
+				requireAppErrorCode(t, err, appErrors.CodeConflict)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, mockStore, _, _ := newTestService(ctrl)
+
+			testCase.buildStub(mockStore)
+
+			membership, err := svc.AcceptInvitationService(context.Background(), user.ID, testCase.email, AcceptInvitationRequest{Token: invitationToken})
+			testCase.checkResponse(t, membership, err)
+		})
+	}
+}
+
+func TestSwitchOrganizationService(t *testing.T) {
+	user := userfactory.NewOptions(nil)
+	sessionId := "3f1f9e0a-54b4-4a53-b6c5-0a3f4b5e2c11"
+	authTime := time.Now().Add(-time.Hour).Unix()
+	replaceSession := sqlc.DeleteRefreshTokenBySessionIdParams{UserID: user.ID, SessionID: sessionId}
+
+	testCases := []struct {
+		name          string
+		orgId         int64
+		actorId       int64
+		buildStub     func(store *db.MockStore, client *redis.MockClient)
+		checkResponse func(t *testing.T, accessToken string, err error)
+	}{
+		{
+			name:  "OK",
+			orgId: 2,
+			buildStub: func(store *db.MockStore, client *redis.MockClient) {
+				store.EXPECT().GetMembership(gomock.Any(), sqlc.GetMembershipParams{OrganizationID: 2, UserID: user.ID}).Times(1).Return(sqlc.Membership{Role: constant.OrgRoleMember}, nil)
+				store.EXPECT().GetUserByID(gomock.Any(), user.ID).Times(1).Return(user, nil)
+				store.EXPECT().DeleteRefreshTokenBySessionId(gomock.Any(), replaceSession).Times(1).Return(sqlc.RefreshToken{}, nil)
+				store.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.RefreshToken{}, nil)
+				client.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil)
+				client.EXPECT().Publish(gomock.Any(), redis.AccessInvalidationChannel, gomock.Any()).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, accessToken string, err error) {
+				require.NoError(t, err)
+				_, claims, err := tokenMaker.VerifyToken(accessToken)
+				require.NoError(t, err)
+				require.Equal(t, token.Session{OrgId: 2, SessionId: sessionId, AuthTime: authTime}, token.SessionFromClaims(claims))
+			},
+		},
+		{
+			name:  "leave organization",
+			orgId: 0,
+			buildStub: func(store *db.MockStore, client *redis.MockClient) {
+				store.EXPECT().GetMembership(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetUserByID(gomock.Any(), user.ID).Times(1).Return(user, nil)
+				store.EXPECT().DeleteRefreshTokenBySessionId(gomock.Any(), replaceSession).Times(1).Return(sqlc.RefreshToken{}, nil)
+				store.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.RefreshToken{}, nil)
+				client.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil)
+				client.EXPECT().Publish(gomock.Any(), redis.AccessInvalidationChannel, gomock.Any()).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, accessToken string, err error) {
+				require.NoError(t, err)
+				_, claims, err := tokenMaker.VerifyToken(accessToken)
+				require.NoError(t, err)
+				require.NotContains(t, claims, constant.OrgIdKey)
+			},
+		},
+		{
+			name:    "impersonation session",
+			orgId:   2,
+			actorId: 7,
+			buildStub: func(store *db.MockStore, client *redis.MockClient) {
+				store.EXPECT().GetMembership(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.Membership{Role: constant.OrgRoleMember}, nil)
+				store.EXPECT().GetUserByID(gomock.Any(), user.ID).Times(1).Return(user, nil)
+				store.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, accessToken string, err error) {
+				requireAppErrorCode(t, err, appErrors.CodeForbidden)
+			},
+		},
+		{
+			name:  "not a member",
+			orgId: 2,
+			buildStub: func(store *db.MockStore, client *redis.MockClient) {
+				store.EXPECT().GetMembership(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.Membership{}, sql.ErrNoRows)
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, accessToken string, err error) {
+				requireAppErrorCode(t, err, appErrors.CodeForbidden)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, mockStore, _, mockRedis := newTestService(ctrl)
+
+			testCase.buildStub(mockStore, mockRedis)
+
+			_, accessToken, _, err := svc.SwitchOrganizationService(context.Background(), user.ID, token.Session{SessionId: sessionId, ActorId: testCase.actorId, AuthTime: authTime}, SwitchOrganizationRequest{OrganizationID: testCase.orgId})
+			testCase.checkResponse(t, accessToken, err)
+		})
+	}
+}
+
+func extractInvitationToken(t *testing.T, body string) string {
+	for _, field := range strings.Fields(body) {
+		if strings.HasPrefix(field, conf.OrgInvitationURL) {
+			link, err := url.Parse(field)
+			require.NoError(t, err)
+			return link.Query().Get("token")
+		}
+	}
+	t.Fatalf("no invitation link in mail body: %q", body)
+	return ""
+}
+
+func requireAppErrorCode(t *testing.T, err error, code appErrors.Code) {
+	var appErr *appErrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, code, appErr.Code)
+}
@@ -0,0 +1,38 @@
+package scimservice
+
+import "net/http"
+
+// scimType values from RFC 7644 section 3.12
+const (
+	ErrInvalidFilter = "invalidFilter"
+	ErrInvalidSyntax = "invalidSyntax"
+	ErrInvalidPath   = "invalidPath"
+	ErrInvalidValue  = "invalidValue"
+	ErrMutability    = "mutability"
+	ErrUniqueness    = "uniqueness"
+)
+
+// Error is a protocol error that must be reported to the client in the SCIM error format
+type Error struct {
+	Status   int
+	ScimType string
+	Detail   string
+	Err      error
+}
+
+func (e *Error) Error() string {
+	return e.Detail
+}
+
+func newError(status int, scimType, detail string, err error) *Error {
+	return &Error{
+		Status:   status,
+		ScimType: scimType,
+		Detail:   detail,
+		Err:      err,
+	}
+}
+
+func newBadRequest(scimType, detail string, err error) *Error {
+	return newError(http.StatusBadRequest, scimType, detail, err)
+}
@@ -0,0 +1,41 @@
+package scimservice
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// parseEqFilter parses the one filter form identity providers use to look up an account before provisioning
+// it: `<attribute> eq "<value>"`. Anything else is rejected with invalidFilter.
+func parseEqFilter(filter, attribute string) (value string, errs error) {
+	filter = strings.TrimSpace(filter)
+
+	name, rest, ok := strings.Cut(filter, " ")
+	if !ok || !strings.EqualFold(name, attribute) {
+		errs = newBadRequest(ErrInvalidFilter, "Only '"+attribute+" eq' filters are supported", nil)
+		return
+	}
+
+	operator, operand, ok := strings.Cut(strings.TrimSpace(rest), " ")
+	if !ok || !strings.EqualFold(operator, "eq") {
+		errs = newBadRequest(ErrInvalidFilter, "Only '"+attribute+" eq' filters are supported", nil)
+		return
+	}
+
+	if err := json.Unmarshal([]byte(strings.TrimSpace(operand)), &value); err != nil {
+		errs = newBadRequest(ErrInvalidFilter, "Filter value must be a quoted string", err)
+		return
+	}
+	return
+}
+
+// parseMemberPath extracts the user id from a `members[value eq "<id>"]` path
+func parseMemberPath(path string) (value string, ok bool) {
+	open := strings.Index(path, "[")
+	if open < 0 || !strings.HasSuffix(path, "]") || !strings.EqualFold(path[:open], "members") {
+		return "", false
+	}
+
+	value, err := parseEqFilter(path[open+1:len(path)-1], "value")
+	return value, err == nil
+}
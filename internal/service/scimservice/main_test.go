@@ -0,0 +1,26 @@
+package scimservice
+
+import (
+	"log"
+	"os"
+	"testing"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/config"
+)
+
+var conf config.Config
+
+func TestMain(m *testing.M) {
+	var err error
+	conf, err = config.LoadConfig("../../..")
+	if err != nil {
+		log.Fatal("Cannot load config: ", err)
+	}
+
+	if err = conf.Validate(); err != nil {
+		log.Fatal("Invalid configuration:", err)
+	}
+
+	code := m.Run()
+	os.Exit(code)
+}
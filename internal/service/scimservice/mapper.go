@@ -0,0 +1,153 @@
+package scimservice
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+)
+
+func ToUserResource(user sqlc.User, role *sqlc.Role, baseURL string) (res UserResource) {
+	id := strconv.FormatInt(user.ID, 10)
+	active := user.Active
+	res = UserResource{
+		Schemas:     []string{UserSchema},
+		ID:          id,
+		ExternalID:  user.ExternalID.String,
+		UserName:    user.Email,
+		Name:        &Name{Formatted: user.Name},
+		DisplayName: user.Name,
+		Emails:      []Email{{Value: user.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta: &Meta{
+			ResourceType: "User",
+			Created:      user.CreatedAt,
+			LastModified: user.UpdatedAt,
+			Location:     baseURL + "/Users/" + id,
+		},
+	}
+	if role != nil {
+		res.Groups = []Reference{ToGroupReference(*role, baseURL)}
+	}
+	return
+}
+
+func ToGroupResource(role sqlc.Role, members []sqlc.User, baseURL string) (res GroupResource) {
+	id := strconv.FormatInt(role.ID, 10)
+	res = GroupResource{
+		Schemas:     []string{GroupSchema},
+		ID:          id,
+		ExternalID:  role.ExternalID.String,
+		DisplayName: role.Name,
+		Members:     []Reference{},
+		Meta: &Meta{
+			ResourceType: "Group",
+			Created:      role.CreatedAt,
+			LastModified: role.UpdatedAt,
+			Location:     baseURL + "/Groups/" + id,
+		},
+	}
+	for _, member := range members {
+		res.Members = append(res.Members, ToMemberReference(member, baseURL))
+	}
+	return
+}
+
+func ToGroupReference(role sqlc.Role, baseURL string) (res Reference) {
+	id := strconv.FormatInt(role.ID, 10)
+	res = Reference{
+		Value:   id,
+		Display: role.Name,
+		Ref:     baseURL + "/Groups/" + id,
+	}
+	return
+}
+
+func ToMemberReference(user sqlc.User, baseURL string) (res Reference) {
+	id := strconv.FormatInt(user.ID, 10)
+	res = Reference{
+		Value:   id,
+		Display: user.Name,
+		Ref:     baseURL + "/Users/" + id,
+	}
+	return
+}
+
+func ToListResponse[T any](resources []T, total int64, startIndex int) (res ListResponse[T]) {
+	if resources == nil {
+		resources = []T{}
+	}
+	res = ListResponse[T]{
+		Schemas:      []string{ListResponseSchema},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}
+	return
+}
+
+func ToCreateProvisionedUserParams(req UserResource, password string) (res sqlc.CreateProvisionedUserParams) {
+	res = sqlc.CreateProvisionedUserParams{
+		Name:       displayName(req),
+		Email:      req.UserName,
+		Password:   password,
+		Active:     req.Active == nil || *req.Active,
+		ExternalID: nullString(req.ExternalID),
+	}
+	return
+}
+
+func ToUpdateProvisionedUserParams(userId int64, req UserResource) (res sqlc.UpdateProvisionedUserParams) {
+	res = sqlc.UpdateProvisionedUserParams{
+		ID:         userId,
+		Name:       displayName(req),
+		Email:      req.UserName,
+		Active:     req.Active == nil || *req.Active,
+		ExternalID: nullString(req.ExternalID),
+	}
+	return
+}
+
+func ToCreateRoleParams(req GroupResource) (res sqlc.CreateRoleParams) {
+	res = sqlc.CreateRoleParams{
+		Name:       req.DisplayName,
+		ExternalID: nullString(req.ExternalID),
+	}
+	return
+}
+
+func ToUpdateRoleTxParams(roleId int64, req GroupResource) (res db.UpdateRoleTxParams) {
+	res = db.UpdateRoleTxParams{
+		Role: sqlc.UpdateRoleParams{
+			ID:         roleId,
+			Name:       req.DisplayName,
+			ExternalID: nullString(req.ExternalID),
+		},
+		DefaultRole: constant.DefaultRole,
+	}
+	return
+}
+
+// displayName picks the most complete name the identity provider sent, falling back to the userName
+func displayName(req UserResource) string {
+	if req.Name != nil {
+		if req.Name.Formatted != "" {
+			return req.Name.Formatted
+		}
+		if full := strings.TrimSpace(req.Name.GivenName + " " + req.Name.FamilyName); full != "" {
+			return full
+		}
+	}
+	if req.DisplayName != "" {
+		return req.DisplayName
+	}
+	return req.UserName
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
@@ -0,0 +1,710 @@
+package scimservice
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	ierr "errors"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/config"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/redis"
+	"github.com/lib/pq"
+	redisClient "github.com/redis/go-redis/v9"
+)
+
+type Service struct {
+	store        db.Store
+	redis        redis.Client
+	hashPassword func(ctx context.Context, password string) (string, error)
+	baseURL      string
+}
+
+func NewService(store db.Store, redis redis.Client, hashPassword func(context.Context, string) (string, error), config config.Config) *Service {
+	return &Service{
+		store:        store,
+		redis:        redis,
+		hashPassword: hashPassword,
+		baseURL:      strings.TrimSuffix(config.PublicURL, "/") + "/scim/v2",
+	}
+}
+
+func (service *Service) ServiceProviderConfigService() (res ServiceProviderConfig) {
+	res = ServiceProviderConfig{
+		Schemas: []string{ServiceProviderConfigSchema},
+		Patch:   Supported{Supported: true},
+		Filter:  FilterSupported{Supported: true, MaxResults: MaxResults},
+		AuthenticationSchemes: []AuthenticationScheme{{
+			Type:        "oauthbearertoken",
+			Name:        "Bearer Token",
+			Description: "Authentication with the SCIM API token",
+		}},
+	}
+	return
+}
+
+func (service *Service) ListUsersService(context context.Context, request ListRequest) (res ListResponse[UserResource], errs error) {
+	startIndex, count := page(request)
+
+	var users []sqlc.User
+	var total int64
+	if request.Filter != "" {
+		email, err := parseEqFilter(request.Filter, "userName")
+		if err != nil {
+			errs = err
+			return
+		}
+
+		user, err := service.store.GetUser(context, email)
+		if err != nil && !ierr.Is(err, sql.ErrNoRows) {
+			errs = errors.New(errors.CodeInternal, "Failed to list users", err)
+			return
+		}
+		if err == nil {
+			total = 1
+			if startIndex == 1 && count > 0 {
+				users = []sqlc.User{user}
+			}
+		}
+	} else {
+		var err error
+		total, err = service.store.CountUsers(context)
+		if err != nil {
+			errs = errors.New(errors.CodeInternal, "Failed to list users", err)
+			return
+		}
+
+		if count > 0 {
+			users, err = service.store.ListUsers(context, sqlc.ListUsersParams{Limit: int32(count), Offset: int32(startIndex - 1)})
+			if err != nil {
+				errs = errors.New(errors.CodeInternal, "Failed to list users", err)
+				return
+			}
+		}
+	}
+
+	roles := map[string]*sqlc.Role{}
+	resources := make([]UserResource, 0, len(users))
+	for _, user := range users {
+		role, ok := roles[user.Role]
+		if !ok {
+			role, errs = service.findRole(context, user.Role)
+			if errs != nil {
+				return
+			}
+			roles[user.Role] = role
+		}
+		resources = append(resources, ToUserResource(user, role, service.baseURL))
+	}
+
+	res = ToListResponse(resources, total, startIndex)
+	return
+}
+
+func (service *Service) GetUserService(context context.Context, id string) (res UserResource, errs error) {
+	user, errs := service.getUser(context, id)
+	if errs != nil {
+		return
+	}
+	return service.toUserResource(context, user)
+}
+
+func (service *Service) CreateUserService(context context.Context, request UserResource) (res UserResource, errs error) {
+	// Users provisioned without a password can only sign in through another backend (LDAP, social, passkey, ...)
+	var password string
+	if request.Password != "" {
+		var err error
+		password, err = service.hashPassword(context, request.Password)
+		if err != nil {
+			errs = errors.New(errors.CodeInternal, "Failed to process user password", err)
+			return
+		}
+	}
+
+	user, err := service.store.CreateProvisionedUser(context, ToCreateProvisionedUserParams(request, password))
+	if err != nil {
+		if isUniqueViolation(err, "users_email_unique") {
+			errs = newError(http.StatusConflict, ErrUniqueness, "User already exists", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to create user", err)
+		return
+	}
+
+	return service.toUserResource(context, user)
+}
+
+func (service *Service) ReplaceUserService(context context.Context, id string, request UserResource) (res UserResource, errs error) {
+	user, errs := service.getUser(context, id)
+	if errs != nil {
+		return
+	}
+
+	return service.updateUser(context, user, ToUpdateProvisionedUserParams(user.ID, request))
+}
+
+func (service *Service) PatchUserService(context context.Context, id string, request PatchRequest) (res UserResource, errs error) {
+	user, errs := service.getUser(context, id)
+	if errs != nil {
+		return
+	}
+
+	arg := sqlc.UpdateProvisionedUserParams{
+		ID:         user.ID,
+		Name:       user.Name,
+		Email:      user.Email,
+		Active:     user.Active,
+		ExternalID: user.ExternalID,
+	}
+	for _, operation := range request.Operations {
+		if errs = applyUserOperation(&arg, operation); errs != nil {
+			return
+		}
+	}
+
+	return service.updateUser(context, user, arg)
+}
+
+func (service *Service) DeleteUserService(context context.Context, id string) (errs error) {
+	user, errs := service.getUser(context, id)
+	if errs != nil {
+		return
+	}
+
+	// refresh_tokens has no ON DELETE CASCADE, so the sessions have to go first anyway
+	if errs = service.revokeSessions(context, user.ID); errs != nil {
+		return
+	}
+
+	err := service.store.DeleteUser(context, user.ID)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to delete user", err)
+		return
+	}
+	return
+}
+
+func (service *Service) ListGroupsService(context context.Context, request ListRequest) (res ListResponse[GroupResource], errs error) {
+	startIndex, count := page(request)
+
+	var roles []sqlc.Role
+	var total int64
+	if request.Filter != "" {
+		name, err := parseEqFilter(request.Filter, "displayName")
+		if err != nil {
+			errs = err
+			return
+		}
+
+		role, err := service.store.GetRoleByName(context, name)
+		if err != nil && !ierr.Is(err, sql.ErrNoRows) {
+			errs = errors.New(errors.CodeInternal, "Failed to list groups", err)
+			return
+		}
+		if err == nil {
+			total = 1
+			if startIndex == 1 && count > 0 {
+				roles = []sqlc.Role{role}
+			}
+		}
+	} else {
+		var err error
+		total, err = service.store.CountRoles(context)
+		if err != nil {
+			errs = errors.New(errors.CodeInternal, "Failed to list groups", err)
+			return
+		}
+
+		if count > 0 {
+			roles, err = service.store.ListRoles(context, sqlc.ListRolesParams{Limit: int32(count), Offset: int32(startIndex - 1)})
+			if err != nil {
+				errs = errors.New(errors.CodeInternal, "Failed to list groups", err)
+				return
+			}
+		}
+	}
+
+	resources := make([]GroupResource, 0, len(roles))
+	for _, role := range roles {
+		var group GroupResource
+		group, errs = service.toGroupResource(context, role, request.ExcludedAttributes)
+		if errs != nil {
+			return
+		}
+		resources = append(resources, group)
+	}
+
+	res = ToListResponse(resources, total, startIndex)
+	return
+}
+
+func (service *Service) GetGroupService(context context.Context, id, excludedAttributes string) (res GroupResource, errs error) {
+	role, errs := service.getRole(context, id)
+	if errs != nil {
+		return
+	}
+	return service.toGroupResource(context, role, excludedAttributes)
+}
+
+func (service *Service) CreateGroupService(context context.Context, request GroupResource) (res GroupResource, errs error) {
+	members, errs := memberIds(request.Members)
+	if errs != nil {
+		return
+	}
+
+	role, err := service.store.CreateRoleTx(context, ToCreateRoleParams(request), members)
+	if err != nil {
+		if isUniqueViolation(err, "roles_name_unique") {
+			errs = newError(http.StatusConflict, ErrUniqueness, "Group already exists", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to create group", err)
+		return
+	}
+
+	return service.toGroupResource(context, role, "")
+}
+
+func (service *Service) ReplaceGroupService(context context.Context, id string, request GroupResource) (res GroupResource, errs error) {
+	role, errs := service.getRole(context, id)
+	if errs != nil {
+		return
+	}
+
+	arg := ToUpdateRoleTxParams(role.ID, request)
+	arg.ReplaceMembers = true
+	arg.AddMembers, errs = memberIds(request.Members)
+	if errs != nil {
+		return
+	}
+
+	return service.updateGroup(context, role, arg)
+}
+
+func (service *Service) PatchGroupService(context context.Context, id string, request PatchRequest) (res GroupResource, errs error) {
+	role, errs := service.getRole(context, id)
+	if errs != nil {
+		return
+	}
+
+	arg := db.UpdateRoleTxParams{
+		Role:        sqlc.UpdateRoleParams{ID: role.ID, Name: role.Name, ExternalID: role.ExternalID},
+		DefaultRole: constant.DefaultRole,
+	}
+	for _, operation := range request.Operations {
+		if errs = applyGroupOperation(&arg, operation); errs != nil {
+			return
+		}
+	}
+
+	return service.updateGroup(context, role, arg)
+}
+
+func (service *Service) DeleteGroupService(context context.Context, id string) (errs error) {
+	role, errs := service.getRole(context, id)
+	if errs != nil {
+		return
+	}
+	if role.Name == constant.DefaultRole {
+		errs = newBadRequest(ErrMutability, "The default group cannot be deleted", nil)
+		return
+	}
+
+	err := service.store.DeleteRoleTx(context, role.ID, constant.DefaultRole)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to delete group", err)
+		return
+	}
+	return
+}
+
+func (service *Service) updateUser(context context.Context, user sqlc.User, arg sqlc.UpdateProvisionedUserParams) (res UserResource, errs error) {
+	if arg.Email == "" {
+		errs = newBadRequest(ErrInvalidValue, "userName must not be empty", nil)
+		return
+	}
+
+	updated, err := service.store.UpdateProvisionedUser(context, arg)
+	if err != nil {
+		if isUniqueViolation(err, "users_email_unique") {
+			errs = newError(http.StatusConflict, ErrUniqueness, "User already exists", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to update user", err)
+		return
+	}
+
+	if user.Active && !updated.Active {
+		if errs = service.revokeSessions(context, updated.ID); errs != nil {
+			return
+		}
+	}
+
+	return service.toUserResource(context, updated)
+}
+
+func (service *Service) updateGroup(context context.Context, role sqlc.Role, arg db.UpdateRoleTxParams) (res GroupResource, errs error) {
+	if arg.Role.Name == "" {
+		errs = newBadRequest(ErrInvalidValue, "displayName must not be empty", nil)
+		return
+	}
+	if role.Name == constant.DefaultRole && arg.Role.Name != role.Name {
+		errs = newBadRequest(ErrMutability, "The default group cannot be renamed", nil)
+		return
+	}
+
+	updated, err := service.store.UpdateRoleTx(context, arg)
+	if err != nil {
+		if isUniqueViolation(err, "roles_name_unique") {
+			errs = newError(http.StatusConflict, ErrUniqueness, "Group already exists", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to update group", err)
+		return
+	}
+
+	return service.toGroupResource(context, updated, "")
+}
+
+// revokeSessions ends every session of a deprovisioned user the same way LogoutService ends one: the refresh
+// tokens are deleted and the access token jti is dropped from Redis so AccessAuthMiddleware rejects it. The refresh
+// tokens held by OAuth clients are deleted as well, so re-activating the user does not bring those grants back.
+func (service *Service) revokeSessions(context context.Context, userId int64) (errs error) {
+	sessions, err := service.store.ListRefreshTokensByUserId(context, userId)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to revoke user sessions", err)
+		return
+	}
+
+	err = service.store.DeleteRefreshTokensByUserId(context, userId)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to revoke user sessions", err)
+		return
+	}
+
+	err = service.store.DeleteOAuthRefreshTokensByUserId(context, userId)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to revoke user sessions", err)
+		return
+	}
+
+	sessionIds := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		sessionIds = append(sessionIds, session.SessionID)
+	}
+	err = redis.DeleteUserAccess(context, service.redis, userId, sessionIds...)
+	if err != nil && !ierr.Is(err, redisClient.Nil) {
+		errs = errors.New(errors.CodeInternal, "Failed to revoke user sessions", err)
+		return
+	}
+	return
+}
+
+func (service *Service) getUser(context context.Context, id string) (user sqlc.User, errs error) {
+	userId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		errs = newError(http.StatusNotFound, "", "User not found", err)
+		return
+	}
+
+	user, err = service.store.GetUserByID(context, userId)
+	if err != nil {
+		if ierr.Is(err, sql.ErrNoRows) {
+			errs = newError(http.StatusNotFound, "", "User not found", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to get user", err)
+		return
+	}
+	return
+}
+
+func (service *Service) getRole(context context.Context, id string) (role sqlc.Role, errs error) {
+	roleId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		errs = newError(http.StatusNotFound, "", "Group not found", err)
+		return
+	}
+
+	role, err = service.store.GetRole(context, roleId)
+	if err != nil {
+		if ierr.Is(err, sql.ErrNoRows) {
+			errs = newError(http.StatusNotFound, "", "Group not found", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to get group", err)
+		return
+	}
+	return
+}
+
+// findRole returns nil for roles that only exist on users, e.g. ones assigned by the LDAP group mapping
+func (service *Service) findRole(context context.Context, name string) (role *sqlc.Role, errs error) {
+	found, err := service.store.GetRoleByName(context, name)
+	if err != nil {
+		if ierr.Is(err, sql.ErrNoRows) {
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to get group", err)
+		return
+	}
+	role = &found
+	return
+}
+
+func (service *Service) toUserResource(context context.Context, user sqlc.User) (res UserResource, errs error) {
+	role, errs := service.findRole(context, user.Role)
+	if errs != nil {
+		return
+	}
+	res = ToUserResource(user, role, service.baseURL)
+	return
+}
+
+func (service *Service) toGroupResource(context context.Context, role sqlc.Role, excludedAttributes string) (res GroupResource, errs error) {
+	var members []sqlc.User
+	if !excludes(excludedAttributes, "members") {
+		var err error
+		members, err = service.store.ListUsersByRole(context, role.Name)
+		if err != nil {
+			errs = errors.New(errors.CodeInternal, "Failed to get group members", err)
+			return
+		}
+	}
+	res = ToGroupResource(role, members, service.baseURL)
+	return
+}
+
+func applyUserOperation(arg *sqlc.UpdateProvisionedUserParams, operation PatchOperation) (errs error) {
+	switch strings.ToLower(operation.Op) {
+	case "add", "replace":
+		if operation.Path != "" {
+			return setUserAttribute(arg, operation.Path, operation.Value)
+		}
+
+		values, errs := patchValues(operation.Value)
+		if errs != nil {
+			return errs
+		}
+		for _, path := range sortedKeys(values) {
+			if errs = setUserAttribute(arg, path, values[path]); errs != nil {
+				return errs
+			}
+		}
+	case "remove":
+		if strings.EqualFold(operation.Path, "externalId") {
+			arg.ExternalID = sql.NullString{}
+		}
+	default:
+		errs = newBadRequest(ErrInvalidSyntax, "Unsupported patch operation '"+operation.Op+"'", nil)
+	}
+	return
+}
+
+// setUserAttribute applies one attribute. Attributes that are not stored on users (phone numbers, the
+// enterprise extension, ...) are accepted and ignored, since identity providers send them unconditionally.
+func setUserAttribute(arg *sqlc.UpdateProvisionedUserParams, path string, value json.RawMessage) (errs error) {
+	switch strings.ToLower(path) {
+	case "username":
+		arg.Email, errs = decodeString(path, value)
+	case "displayname", "name.formatted":
+		arg.Name, errs = decodeString(path, value)
+	case "name":
+		var name Name
+		if err := json.Unmarshal(value, &name); err != nil {
+			return newBadRequest(ErrInvalidValue, "name must be an object", err)
+		}
+		arg.Name = displayName(UserResource{Name: &name, UserName: arg.Name})
+	case "externalid":
+		var externalId string
+		externalId, errs = decodeString(path, value)
+		arg.ExternalID = nullString(externalId)
+	case "active":
+		arg.Active, errs = decodeBool(path, value)
+	}
+	return
+}
+
+func applyGroupOperation(arg *db.UpdateRoleTxParams, operation PatchOperation) (errs error) {
+	op := strings.ToLower(operation.Op)
+
+	if value, ok := parseMemberPath(operation.Path); ok {
+		if op != "remove" {
+			return newBadRequest(ErrInvalidPath, "Only remove is supported on a filtered members path", nil)
+		}
+		userId, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return newBadRequest(ErrInvalidValue, "Member value must be a user id", err)
+		}
+		removeMembers(arg, []int64{userId})
+		return
+	}
+
+	switch op {
+	case "add", "replace":
+		if operation.Path != "" {
+			return setGroupAttribute(arg, operation.Path, operation.Value, op == "replace")
+		}
+
+		values, errs := patchValues(operation.Value)
+		if errs != nil {
+			return errs
+		}
+		for _, path := range sortedKeys(values) {
+			if errs = setGroupAttribute(arg, path, values[path], op == "replace"); errs != nil {
+				return errs
+			}
+		}
+	case "remove":
+		switch strings.ToLower(operation.Path) {
+		case "members":
+			if len(operation.Value) == 0 || string(operation.Value) == "null" {
+				arg.ReplaceMembers = true
+				arg.AddMembers = nil
+				arg.RemoveMembers = nil
+				return
+			}
+			members, errs := decodeMembers(operation.Value)
+			if errs != nil {
+				return errs
+			}
+			removeMembers(arg, members)
+		case "externalid":
+			arg.Role.ExternalID = sql.NullString{}
+		default:
+			errs = newBadRequest(ErrInvalidPath, "Unsupported remove path '"+operation.Path+"'", nil)
+		}
+	default:
+		errs = newBadRequest(ErrInvalidSyntax, "Unsupported patch operation '"+operation.Op+"'", nil)
+	}
+	return
+}
+
+func setGroupAttribute(arg *db.UpdateRoleTxParams, path string, value json.RawMessage, replace bool) (errs error) {
+	switch strings.ToLower(path) {
+	case "displayname":
+		arg.Role.Name, errs = decodeString(path, value)
+	case "externalid":
+		var externalId string
+		externalId, errs = decodeString(path, value)
+		arg.Role.ExternalID = nullString(externalId)
+	case "members":
+		members, errs := decodeMembers(value)
+		if errs != nil {
+			return errs
+		}
+		if replace {
+			arg.ReplaceMembers = true
+			arg.AddMembers = members
+			arg.RemoveMembers = nil
+			return nil
+		}
+		addMembers(arg, members)
+	default:
+		errs = newBadRequest(ErrInvalidPath, "Unsupported group attribute '"+path+"'", nil)
+	}
+	return
+}
+
+// addMembers and removeMembers keep the two lists disjoint, so the last operation on a user wins
+func addMembers(arg *db.UpdateRoleTxParams, members []int64) {
+	arg.RemoveMembers = slices.DeleteFunc(arg.RemoveMembers, func(id int64) bool { return slices.Contains(members, id) })
+	arg.AddMembers = append(arg.AddMembers, members...)
+}
+
+func removeMembers(arg *db.UpdateRoleTxParams, members []int64) {
+	arg.AddMembers = slices.DeleteFunc(arg.AddMembers, func(id int64) bool { return slices.Contains(members, id) })
+	arg.RemoveMembers = append(arg.RemoveMembers, members...)
+}
+
+func memberIds(members []Reference) (ids []int64, errs error) {
+	for _, member := range members {
+		id, err := strconv.ParseInt(member.Value, 10, 64)
+		if err != nil {
+			errs = newBadRequest(ErrInvalidValue, "Member value must be a user id", err)
+			return
+		}
+		ids = append(ids, id)
+	}
+	return
+}
+
+func decodeMembers(value json.RawMessage) (ids []int64, errs error) {
+	var members []Reference
+	if err := json.Unmarshal(value, &members); err != nil {
+		errs = newBadRequest(ErrInvalidValue, "members must be a list of references", err)
+		return
+	}
+	return memberIds(members)
+}
+
+func patchValues(value json.RawMessage) (values map[string]json.RawMessage, errs error) {
+	if err := json.Unmarshal(value, &values); err != nil {
+		errs = newBadRequest(ErrInvalidSyntax, "Patch value must be an object when no path is given", err)
+	}
+	return
+}
+
+func decodeString(path string, value json.RawMessage) (res string, errs error) {
+	if err := json.Unmarshal(value, &res); err != nil {
+		errs = newBadRequest(ErrInvalidValue, path+" must be a string", err)
+	}
+	return
+}
+
+// decodeBool also accepts "True" / "False" strings, which Azure AD sends for active
+func decodeBool(path string, value json.RawMessage) (res bool, errs error) {
+	if err := json.Unmarshal(value, &res); err == nil {
+		return
+	}
+
+	var text string
+	if err := json.Unmarshal(value, &text); err == nil {
+		if parsed, err := strconv.ParseBool(strings.ToLower(text)); err == nil {
+			res = parsed
+			return
+		}
+	}
+	errs = newBadRequest(ErrInvalidValue, path+" must be a boolean", nil)
+	return
+}
+
+func page(request ListRequest) (startIndex, count int) {
+	startIndex = max(request.StartIndex, 1)
+	count = MaxResults
+	if request.Count != nil {
+		count = min(max(*request.Count, 0), MaxResults)
+	}
+	return
+}
+
+func excludes(excludedAttributes, attribute string) bool {
+	for _, excluded := range strings.Split(excludedAttributes, ",") {
+		if strings.EqualFold(strings.TrimSpace(excluded), attribute) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(values map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return ierr.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" && strings.Contains(pqErr.Constraint, constraint)
+}
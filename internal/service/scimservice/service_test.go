@@ -0,0 +1,448 @@
+package scimservice
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	ierr "errors"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/factory/userfactory"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/redis"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+var defaultRole = sqlc.Role{ID: 1, Name: "user"}
+
+func newTestService(ctrl *gomock.Controller) (*Service, *db.MockStore, *redis.MockClient) {
+	mockStore := db.NewMockStore(ctrl)
+	mockRedis := redis.NewMockClient(ctrl)
+	return NewService(mockStore, mockRedis, util.HashPassword, conf), mockStore, mockRedis
+}
+
+func requireScimError(t *testing.T, err error, status int, scimType string) {
+	var scimErr *Error
+	require.True(t, ierr.As(err, &scimErr), "expected *Error, got %T", err)
+	require.Equal(t, status, scimErr.Status)
+	require.Equal(t, scimType, scimErr.ScimType)
+}
+
+func expectRevoke(store *db.MockStore, mockRedis *redis.MockClient, userId int64) {
+	store.EXPECT().ListRefreshTokensByUserId(gomock.Any(), userId).Times(1).Return([]sqlc.RefreshToken{{UserID: userId, SessionID: "sid-1"}}, nil)
+	store.EXPECT().DeleteRefreshTokensByUserId(gomock.Any(), userId).Times(1).Return(nil)
+	store.EXPECT().DeleteOAuthRefreshTokensByUserId(gomock.Any(), userId).Times(1).Return(nil)
+	mockRedis.EXPECT().Del(gomock.Any(), "user:access:1:sid-1").Times(1).Return(nil)
+	mockRedis.EXPECT().Publish(gomock.Any(), redis.AccessInvalidationChannel, "user:access:1:sid-1").Times(1).Return(nil)
+}
+
+func patch(t *testing.T, operations ...map[string]any) PatchRequest {
+	body, err := json.Marshal(map[string]any{"schemas": []string{PatchOpSchema}, "Operations": operations})
+	require.NoError(t, err)
+
+	var req PatchRequest
+	require.NoError(t, json.Unmarshal(body, &req))
+	return req
+}
+
+func TestCreateUserService(t *testing.T) {
+	testCases := []struct {
+		name          string
+		request       UserResource
+		buildStub     func(store *db.MockStore)
+		checkResponse func(t *testing.T, res UserResource, err error)
+	}{
+		{
+			name:    "success",
+			request: UserResource{UserName: "jane@example.com", Name: &Name{GivenName: "Jane", FamilyName: "Doe"}, ExternalID: "00u1", Password: "secret-password"},
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().CreateProvisionedUser(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, arg sqlc.CreateProvisionedUserParams) (sqlc.User, error) {
+					require.Equal(t, "Jane Doe", arg.Name)
+					require.Equal(t, "jane@example.com", arg.Email)
+					require.True(t, arg.Active)
+					require.Equal(t, sql.NullString{String: "00u1", Valid: true}, arg.ExternalID)
+					require.NoError(t, util.CheckPasswordHash(context.Background(), "secret-password", arg.Password))
+					return userfactory.NewOptions(&userfactory.Options{ID: 1, Name: arg.Name, Email: arg.Email}), nil
+				})
+				store.EXPECT().GetRoleByName(gomock.Any(), "user").Times(1).Return(defaultRole, nil)
+			},
+			checkResponse: func(t *testing.T, res UserResource, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "1", res.ID)
+				require.Equal(t, "jane@example.com", res.UserName)
+				require.True(t, *res.Active)
+				require.Empty(t, res.Password)
+				require.Equal(t, []Reference{{Value: "1", Display: "user", Ref: conf.PublicURL + "/scim/v2/Groups/1"}}, res.Groups)
+				require.Equal(t, conf.PublicURL+"/scim/v2/Users/1", res.Meta.Location)
+			},
+		},
+		{
+			name:    "without password",
+			request: UserResource{UserName: "jane@example.com"},
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().CreateProvisionedUser(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, arg sqlc.CreateProvisionedUserParams) (sqlc.User, error) {
+					require.Empty(t, arg.Password)
+					require.Equal(t, "jane@example.com", arg.Name)
+					return userfactory.NewOptions(&userfactory.Options{Email: arg.Email}), nil
+				})
+				store.EXPECT().GetRoleByName(gomock.Any(), "user").Times(1).Return(sqlc.Role{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, res UserResource, err error) {
+				require.NoError(t, err)
+				require.Empty(t, res.Groups)
+			},
+		},
+		{
+			name:    "already exists",
+			request: UserResource{UserName: "jane@example.com"},
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().CreateProvisionedUser(gomock.Any(), gomock.Any()).Times(1).
+					Return(sqlc.User{}, &pq.Error{Code: "23505", Constraint: "users_email_unique"})
+			},
+			checkResponse: func(t *testing.T, res UserResource, err error) {
+				requireScimError(t, err, http.StatusConflict, ErrUniqueness)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, mockStore, _ := newTestService(ctrl)
+
+			testCase.buildStub(mockStore)
+
+			res, err := svc.CreateUserService(context.Background(), testCase.request)
+			testCase.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestListUsersService(t *testing.T) {
+	user := userfactory.NewOptions(&userfactory.Options{Email: "jane@example.com"})
+	count := 2
+
+	testCases := []struct {
+		name          string
+		request       ListRequest
+		buildStub     func(store *db.MockStore)
+		checkResponse func(t *testing.T, res ListResponse[UserResource], err error)
+	}{
+		{
+			name:    "filter by userName",
+			request: ListRequest{Filter: `userName eq "jane@example.com"`},
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), "jane@example.com").Times(1).Return(user, nil)
+				store.EXPECT().GetRoleByName(gomock.Any(), "user").Times(1).Return(defaultRole, nil)
+			},
+			checkResponse: func(t *testing.T, res ListResponse[UserResource], err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(1), res.TotalResults)
+				require.Equal(t, 1, res.ItemsPerPage)
+				require.Equal(t, "jane@example.com", res.Resources[0].UserName)
+			},
+		},
+		{
+			name:    "filter without match",
+			request: ListRequest{Filter: `userName eq "nobody@example.com"`},
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), "nobody@example.com").Times(1).Return(sqlc.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, res ListResponse[UserResource], err error) {
+				require.NoError(t, err)
+				require.Zero(t, res.TotalResults)
+				require.NotNil(t, res.Resources)
+				require.Empty(t, res.Resources)
+			},
+		},
+		{
+			name:      "unsupported filter",
+			request:   ListRequest{Filter: `emails co "example.com"`},
+			buildStub: func(store *db.MockStore) {},
+			checkResponse: func(t *testing.T, res ListResponse[UserResource], err error) {
+				requireScimError(t, err, http.StatusBadRequest, ErrInvalidFilter)
+			},
+		},
+		{
+			name:    "pagination",
+			request: ListRequest{StartIndex: 3, Count: &count},
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().CountUsers(gomock.Any()).Times(1).Return(int64(5), nil)
+				store.EXPECT().ListUsers(gomock.Any(), sqlc.ListUsersParams{Limit: 2, Offset: 2}).Times(1).
+					Return([]sqlc.User{user, userfactory.NewOptions(&userfactory.Options{ID: 2})}, nil)
+				// The role lookup is shared by users holding the same role
+				store.EXPECT().GetRoleByName(gomock.Any(), "user").Times(1).Return(defaultRole, nil)
+			},
+			checkResponse: func(t *testing.T, res ListResponse[UserResource], err error) {
+				require.NoError(t, err)
+				require.Equal(t, []string{ListResponseSchema}, res.Schemas)
+				require.Equal(t, int64(5), res.TotalResults)
+				require.Equal(t, 3, res.StartIndex)
+				require.Equal(t, 2, res.ItemsPerPage)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, mockStore, _ := newTestService(ctrl)
+
+			testCase.buildStub(mockStore)
+
+			res, err := svc.ListUsersService(context.Background(), testCase.request)
+			testCase.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestPatchUserService(t *testing.T) {
+	user := userfactory.NewOptions(&userfactory.Options{Name: "Jane", Email: "jane@example.com"})
+
+	testCases := []struct {
+		name          string
+		request       PatchRequest
+		buildStub     func(store *db.MockStore, redis *redis.MockClient)
+		checkResponse func(t *testing.T, res UserResource, err error)
+	}{
+		{
+			name:    "deactivate with path",
+			request: patch(t, map[string]any{"op": "Replace", "path": "active", "value": "False"}),
+			buildStub: func(store *db.MockStore, redis *redis.MockClient) {
+				store.EXPECT().UpdateProvisionedUser(gomock.Any(), sqlc.UpdateProvisionedUserParams{ID: user.ID, Name: user.Name, Email: user.Email, Active: false}).
+					Times(1).Return(userfactory.NewOptions(&userfactory.Options{Name: user.Name, Email: user.Email, Inactive: true}), nil)
+				expectRevoke(store, redis, user.ID)
+				store.EXPECT().GetRoleByName(gomock.Any(), "user").Times(1).Return(defaultRole, nil)
+			},
+			checkResponse: func(t *testing.T, res UserResource, err error) {
+				require.NoError(t, err)
+				require.False(t, *res.Active)
+			},
+		},
+		{
+			name:    "deactivate without path",
+			request: patch(t, map[string]any{"op": "replace", "value": map[string]any{"active": false, "name.formatted": "Jane Doe"}}),
+			buildStub: func(store *db.MockStore, redis *redis.MockClient) {
+				store.EXPECT().UpdateProvisionedUser(gomock.Any(), sqlc.UpdateProvisionedUserParams{ID: user.ID, Name: "Jane Doe", Email: user.Email, Active: false}).
+					Times(1).Return(userfactory.NewOptions(&userfactory.Options{Name: "Jane Doe", Email: user.Email, Inactive: true}), nil)
+				expectRevoke(store, redis, user.ID)
+				store.EXPECT().GetRoleByName(gomock.Any(), "user").Times(1).Return(defaultRole, nil)
+			},
+			checkResponse: func(t *testing.T, res UserResource, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "Jane Doe", res.DisplayName)
+			},
+		},
+		{
+			name: "ignores unknown attributes",
+			request: patch(t,
+				map[string]any{"op": "add", "path": "phoneNumbers[type eq \"work\"].value", "value": "123"},
+				map[string]any{"op": "replace", "path": "externalId", "value": "00u1"},
+			),
+			buildStub: func(store *db.MockStore, redis *redis.MockClient) {
+				store.EXPECT().UpdateProvisionedUser(gomock.Any(), sqlc.UpdateProvisionedUserParams{ID: user.ID, Name: user.Name, Email: user.Email, Active: true, ExternalID: sql.NullString{String: "00u1", Valid: true}}).
+					Times(1).Return(user, nil)
+				store.EXPECT().GetRoleByName(gomock.Any(), "user").Times(1).Return(defaultRole, nil)
+			},
+			checkResponse: func(t *testing.T, res UserResource, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:      "invalid active value",
+			request:   patch(t, map[string]any{"op": "replace", "path": "active", "value": "maybe"}),
+			buildStub: func(store *db.MockStore, redis *redis.MockClient) {},
+			checkResponse: func(t *testing.T, res UserResource, err error) {
+				requireScimError(t, err, http.StatusBadRequest, ErrInvalidValue)
+			},
+		},
+		{
+			name:      "unsupported operation",
+			request:   patch(t, map[string]any{"op": "move", "path": "active"}),
+			buildStub: func(store *db.MockStore, redis *redis.MockClient) {},
+			checkResponse: func(t *testing.T, res UserResource, err error) {
+				requireScimError(t, err, http.StatusBadRequest, ErrInvalidSyntax)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, mockStore, mockRedis := newTestService(ctrl)
+
+			mockStore.EXPECT().GetUserByID(gomock.Any(), user.ID).Times(1).Return(user, nil)
+			testCase.buildStub(mockStore, mockRedis)
+
+			res, err := svc.PatchUserService(context.Background(), "1", testCase.request)
+			testCase.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestDeleteUserService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc, mockStore, mockRedis := newTestService(ctrl)
+
+	user := userfactory.NewOptions(nil)
+	gomock.InOrder(
+		mockStore.EXPECT().GetUserByID(gomock.Any(), user.ID).Times(1).Return(user, nil),
+		mockStore.EXPECT().ListRefreshTokensByUserId(gomock.Any(), user.ID).Times(1).Return([]sqlc.RefreshToken{{UserID: user.ID, SessionID: "sid-1"}, {UserID: user.ID, SessionID: "sid-2"}}, nil),
+		mockStore.EXPECT().DeleteRefreshTokensByUserId(gomock.Any(), user.ID).Times(1).Return(nil),
+		mockStore.EXPECT().DeleteOAuthRefreshTokensByUserId(gomock.Any(), user.ID).Times(1).Return(nil),
+		mockRedis.EXPECT().Del(gomock.Any(), "user:access:1:sid-1", "user:access:1:sid-2").Times(1).Return(nil),
+		mockRedis.EXPECT().Publish(gomock.Any(), redis.AccessInvalidationChannel, "user:access:1:sid-1").Times(1).Return(nil),
+		mockRedis.EXPECT().Publish(gomock.Any(), redis.AccessInvalidationChannel, "user:access:1:sid-2").Times(1).Return(nil),
+		mockStore.EXPECT().DeleteUser(gomock.Any(), user.ID).Times(1).Return(nil),
+	)
+
+	require.NoError(t, svc.DeleteUserService(context.Background(), "1"))
+
+	// The user is kept while the grants of OAuth clients could not be revoked
+	gomock.InOrder(
+		mockStore.EXPECT().GetUserByID(gomock.Any(), user.ID).Times(1).Return(user, nil),
+		mockStore.EXPECT().ListRefreshTokensByUserId(gomock.Any(), user.ID).Times(1).Return(nil, nil),
+		mockStore.EXPECT().DeleteRefreshTokensByUserId(gomock.Any(), user.ID).Times(1).Return(nil),
+		mockStore.EXPECT().DeleteOAuthRefreshTokensByUserId(gomock.Any(), user.ID).Times(1).Return(sql.ErrConnDone),
+	)
+	mockStore.EXPECT().DeleteUser(gomock.Any(), gomock.Any()).Times(0)
+	err := svc.DeleteUserService(context.Background(), "1")
+	require.ErrorContains(t, err, "Failed to revoke user sessions")
+
+	mockStore.EXPECT().GetUserByID(gomock.Any(), int64(2)).Times(1).Return(sqlc.User{}, sql.ErrNoRows)
+	requireScimError(t, svc.DeleteUserService(context.Background(), "2"), http.StatusNotFound, "")
+	requireScimError(t, svc.DeleteUserService(context.Background(), "abc"), http.StatusNotFound, "")
+}
+
+func TestPatchGroupService(t *testing.T) {
+	admins := sqlc.Role{ID: 2, Name: "admin"}
+
+	testCases := []struct {
+		name          string
+		role          sqlc.Role
+		request       PatchRequest
+		buildStub     func(store *db.MockStore)
+		checkResponse func(t *testing.T, res GroupResource, err error)
+	}{
+		{
+			name: "add and remove members",
+			role: admins,
+			request: patch(t,
+				map[string]any{"op": "add", "path": "members", "value": []map[string]string{{"value": "3"}, {"value": "4"}}},
+				map[string]any{"op": "remove", "path": `members[value eq "4"]`},
+				map[string]any{"op": "remove", "path": "members", "value": []map[string]string{{"value": "5"}}},
+			),
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().UpdateRoleTx(gomock.Any(), db.UpdateRoleTxParams{
+					Role:          sqlc.UpdateRoleParams{ID: admins.ID, Name: admins.Name},
+					DefaultRole:   "user",
+					AddMembers:    []int64{3},
+					RemoveMembers: []int64{4, 5},
+				}).Times(1).Return(admins, nil)
+				store.EXPECT().ListUsersByRole(gomock.Any(), admins.Name).Times(1).
+					Return([]sqlc.User{userfactory.NewOptions(&userfactory.Options{ID: 3, Role: admins.Name})}, nil)
+			},
+			checkResponse: func(t *testing.T, res GroupResource, err error) {
+				require.NoError(t, err)
+				require.Len(t, res.Members, 1)
+				require.Equal(t, "3", res.Members[0].Value)
+			},
+		},
+		{
+			name:    "replace members and rename",
+			role:    admins,
+			request: patch(t, map[string]any{"op": "replace", "value": map[string]any{"displayName": "owner", "members": []map[string]string{{"value": "3"}}}}),
+			buildStub: func(store *db.MockStore) {
+				store.EXPECT().UpdateRoleTx(gomock.Any(), db.UpdateRoleTxParams{
+					Role:           sqlc.UpdateRoleParams{ID: admins.ID, Name: "owner"},
+					DefaultRole:    "user",
+					ReplaceMembers: true,
+					AddMembers:     []int64{3},
+				}).Times(1).Return(sqlc.Role{ID: admins.ID, Name: "owner"}, nil)
+				store.EXPECT().ListUsersByRole(gomock.Any(), "owner").Times(1).Return(nil, nil)
+			},
+			checkResponse: func(t *testing.T, res GroupResource, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "owner", res.DisplayName)
+				require.NotNil(t, res.Members)
+			},
+		},
+		{
+			name:      "rename default group",
+			role:      defaultRole,
+			request:   patch(t, map[string]any{"op": "replace", "path": "displayName", "value": "member"}),
+			buildStub: func(store *db.MockStore) {},
+			checkResponse: func(t *testing.T, res GroupResource, err error) {
+				requireScimError(t, err, http.StatusBadRequest, ErrMutability)
+			},
+		},
+		{
+			name:      "invalid member id",
+			role:      admins,
+			request:   patch(t, map[string]any{"op": "add", "path": "members", "value": []map[string]string{{"value": "jane"}}}),
+			buildStub: func(store *db.MockStore) {},
+			checkResponse: func(t *testing.T, res GroupResource, err error) {
+				requireScimError(t, err, http.StatusBadRequest, ErrInvalidValue)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, mockStore, _ := newTestService(ctrl)
+
+			mockStore.EXPECT().GetRole(gomock.Any(), testCase.role.ID).Times(1).Return(testCase.role, nil)
+			testCase.buildStub(mockStore)
+
+			res, err := svc.PatchGroupService(context.Background(), strconv.FormatInt(testCase.role.ID, 10), testCase.request)
+			testCase.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestDeleteGroupService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc, mockStore, _ := newTestService(ctrl)
+
+	mockStore.EXPECT().GetRole(gomock.Any(), int64(2)).Times(1).Return(sqlc.Role{ID: 2, Name: "admin"}, nil)
+	mockStore.EXPECT().DeleteRoleTx(gomock.Any(), int64(2), "user").Times(1).Return(nil)
+	require.NoError(t, svc.DeleteGroupService(context.Background(), "2"))
+
+	mockStore.EXPECT().GetRole(gomock.Any(), int64(1)).Times(1).Return(defaultRole, nil)
+	requireScimError(t, svc.DeleteGroupService(context.Background(), "1"), http.StatusBadRequest, ErrMutability)
+}
+
+func TestParseEqFilter(t *testing.T) {
+	value, err := parseEqFilter(`userName eq "jane@example.com"`, "userName")
+	require.NoError(t, err)
+	require.Equal(t, "jane@example.com", value)
+
+	value, err = parseEqFilter(`  USERNAME EQ "a \"quoted\" name" `, "userName")
+	require.NoError(t, err)
+	require.Equal(t, `a "quoted" name`, value)
+
+	for _, filter := range []string{`userName eq jane`, `userName sw "j"`, `displayName eq "x"`, `userName`, ``} {
+		_, err = parseEqFilter(filter, "userName")
+		requireScimError(t, err, http.StatusBadRequest, ErrInvalidFilter)
+	}
+
+	value, ok := parseMemberPath(`members[value eq "12"]`)
+	require.True(t, ok)
+	require.Equal(t, "12", value)
+
+	_, ok = parseMemberPath("members")
+	require.False(t, ok)
+}
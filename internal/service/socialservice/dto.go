@@ -0,0 +1,8 @@
+package socialservice
+
+// loginState is kept in Redis between the redirect to the provider and the callback
+type loginState struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+}
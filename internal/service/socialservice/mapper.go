@@ -0,0 +1,30 @@
+package socialservice
+
+import (
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/social"
+)
+
+func ToCreateUserIdentityParams(userId int64, provider string, identity social.Identity) (res sqlc.CreateUserIdentityParams) {
+	res = sqlc.CreateUserIdentityParams{
+		UserID:   userId,
+		Provider: provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	}
+	return
+}
+
+func ToCreateUserParams(identity social.Identity) (res sqlc.CreateUserParams) {
+	name := identity.Name
+	if name == "" {
+		name = identity.Email
+	}
+	// Social accounts have no password, so password login can never succeed for them
+	res = sqlc.CreateUserParams{
+		Name:     name,
+		Email:    identity.Email,
+		Password: "",
+	}
+	return
+}
@@ -0,0 +1,172 @@
+package socialservice
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	ierr "errors"
+	"fmt"
+	"time"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/service/authservice"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/pkce"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/redis"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/social"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
+	redisClient "github.com/redis/go-redis/v9"
+)
+
+// StateDuration bounds how long the user may take at the provider before the callback is rejected
+const StateDuration = 10 * time.Minute
+
+type Service struct {
+	store       db.Store
+	redis       redis.Client
+	providers   map[string]social.Provider
+	authService *authservice.Service
+}
+
+func NewService(store db.Store, redis redis.Client, providers map[string]social.Provider, authService *authservice.Service) *Service {
+	return &Service{store: store, redis: redis, providers: providers, authService: authService}
+}
+
+func (service *Service) BeginService(context context.Context, providerName string) (redirectURL, state string, errs error) {
+	provider, ok := service.providers[providerName]
+	if !ok {
+		errs = errors.New(errors.CodeNotFound, "Unknown login provider", fmt.Errorf("provider %q is not configured", providerName))
+		return
+	}
+
+	values := make([]string, 3)
+	for i := range values {
+		var err error
+		values[i], err = token.GenerateOpaqueToken()
+		if err != nil {
+			errs = errors.New(errors.CodeInternal, "Failed to start login", err)
+			return
+		}
+	}
+	state = values[0]
+	loginState := loginState{Provider: providerName, Nonce: values[1], CodeVerifier: values[2]}
+
+	value, err := json.Marshal(loginState)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to start login", err)
+		return
+	}
+
+	err = service.redis.Set(context, stateKey(state), string(value), StateDuration)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to start login", err)
+		return
+	}
+
+	redirectURL, err = provider.AuthCodeURL(context, state, loginState.Nonce, pkce.S256CodeChallenge(loginState.CodeVerifier))
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to start login", err)
+		return
+	}
+
+	return
+}
+
+func (service *Service) CallbackService(context context.Context, providerName, state, code string) (user sqlc.User, accessToken, refreshToken string, errs error) {
+	provider, ok := service.providers[providerName]
+	if !ok {
+		errs = errors.New(errors.CodeNotFound, "Unknown login provider", fmt.Errorf("provider %q is not configured", providerName))
+		return
+	}
+
+	// State is single-use, reading and deleting it in one command keeps concurrent callbacks from both redeeming it
+	value, err := service.redis.GetDel(context, stateKey(state))
+	if err != nil {
+		if ierr.Is(err, redisClient.Nil) {
+			errs = errors.New(errors.CodeUnauthorized, "Login session has expired", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to complete login", err)
+		return
+	}
+
+	var stored loginState
+	if err = json.Unmarshal([]byte(value), &stored); err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to complete login", err)
+		return
+	}
+	if stored.Provider != providerName {
+		errs = errors.New(errors.CodeUnauthorized, "Login session does not match the provider", fmt.Errorf("state was issued for %q", stored.Provider))
+		return
+	}
+
+	identity, err := provider.Exchange(context, code, stored.CodeVerifier, stored.Nonce)
+	if err != nil {
+		errs = errors.New(errors.CodeUnauthorized, "Failed to verify the external identity", err)
+		return
+	}
+
+	user, errs = service.resolveUser(context, providerName, identity)
+	if errs != nil {
+		return
+	}
+
+	accessToken, refreshToken, errs = service.authService.IssueTokensService(context, user)
+	return
+}
+
+// resolveUser finds the account linked to the external identity. Unknown identities are linked to an existing
+// account only when the provider vouches for the email address; otherwise a new account is created, unless
+// self-registration is disabled.
+func (service *Service) resolveUser(context context.Context, providerName string, identity social.Identity) (user sqlc.User, errs error) {
+	linked, err := service.store.GetUserIdentity(context, sqlc.GetUserIdentityParams{Provider: providerName, Subject: identity.Subject})
+	if err == nil {
+		user, err = service.store.GetUserByID(context, linked.UserID)
+		if err != nil {
+			errs = errors.New(errors.CodeInternal, "Failed to get user", err)
+			return
+		}
+		return
+	}
+	if !ierr.Is(err, sql.ErrNoRows) {
+		errs = errors.New(errors.CodeInternal, "Failed to get user identity", err)
+		return
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		errs = errors.New(errors.CodeBadRequest, "The provider did not share a verified email address", nil)
+		return
+	}
+
+	user, err = service.store.GetUser(context, identity.Email)
+	if err == nil {
+		_, err = service.store.CreateUserIdentity(context, ToCreateUserIdentityParams(user.ID, providerName, identity))
+		if err != nil {
+			errs = errors.New(errors.CodeInternal, "Failed to link user identity", err)
+			return
+		}
+		return
+	}
+	if !ierr.Is(err, sql.ErrNoRows) {
+		errs = errors.New(errors.CodeInternal, "Failed to get user", err)
+		return
+	}
+
+	if !service.authService.SelfRegistrationEnabled() {
+		errs = errors.New(errors.CodeForbidden, "Self-registration is disabled", nil)
+		return
+	}
+
+	user, err = service.store.SocialRegisterTx(context, ToCreateUserParams(identity), ToCreateUserIdentityParams(0, providerName, identity))
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to register user", err)
+		return
+	}
+
+	return
+}
+
+func stateKey(state string) string {
+	return "social:state:" + state
+}
@@ -0,0 +1,213 @@
+package socialservice
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/factory/userfactory"
+	"github.com/hanifsyahsn/go_boilerplate/internal/service/authservice"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/redis"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/social"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/social/socialtest"
+	redisClient "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+const testProvider = "fake"
+
+func TestCallbackService(t *testing.T) {
+	user := userfactory.NewOptions(nil)
+
+	testCases := []struct {
+		name             string
+		identity         social.Identity
+		registrationMode string
+		microsoft        bool // shapes the ID token like Microsoft, with xms_edov instead of email_verified
+		buildStub        func(store *db.MockStore, redis *redis.MockClient, identity social.Identity)
+		checkResponse    func(t *testing.T, got sqlc.User, accessToken string, err error)
+	}{
+		{
+			name:     "already linked identity",
+			identity: social.Identity{Subject: util.RandomString(10), Email: user.Email, EmailVerified: true},
+			buildStub: func(store *db.MockStore, client *redis.MockClient, identity social.Identity) {
+				store.EXPECT().GetUserIdentity(gomock.Any(), sqlc.GetUserIdentityParams{Provider: testProvider, Subject: identity.Subject}).Times(1).Return(sqlc.UserIdentity{UserID: user.ID}, nil)
+				store.EXPECT().GetUserByID(gomock.Any(), user.ID).Times(1).Return(user, nil)
+				expectIssueTokens(store, client)
+			},
+			checkResponse: func(t *testing.T, got sqlc.User, accessToken string, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user.ID, got.ID)
+				require.NotEmpty(t, accessToken)
+			},
+		},
+		{
+			name:     "links existing account with matching verified email",
+			identity: social.Identity{Subject: util.RandomString(10), Email: user.Email, EmailVerified: true},
+			buildStub: func(store *db.MockStore, client *redis.MockClient, identity social.Identity) {
+				store.EXPECT().GetUserIdentity(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.UserIdentity{}, sql.ErrNoRows)
+				store.EXPECT().GetUser(gomock.Any(), user.Email).Times(1).Return(user, nil)
+				store.EXPECT().CreateUserIdentity(gomock.Any(), ToCreateUserIdentityParams(user.ID, testProvider, identity)).Times(1).Return(sqlc.UserIdentity{}, nil)
+				expectIssueTokens(store, client)
+			},
+			checkResponse: func(t *testing.T, got sqlc.User, accessToken string, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user.ID, got.ID)
+			},
+		},
+		{
+			name:     "does not link unverified email",
+			identity: social.Identity{Subject: util.RandomString(10), Email: user.Email, EmailVerified: false},
+			buildStub: func(store *db.MockStore, client *redis.MockClient, identity social.Identity) {
+				store.EXPECT().GetUserIdentity(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.UserIdentity{}, sql.ErrNoRows)
+				store.EXPECT().CreateUserIdentity(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, got sqlc.User, accessToken string, err error) {
+				require.ErrorContains(t, err, "verified email")
+			},
+		},
+		{
+			name:     "registers new user",
+			identity: social.Identity{Subject: util.RandomString(10), Email: "new@example.com", EmailVerified: true, Name: "New"},
+			buildStub: func(store *db.MockStore, client *redis.MockClient, identity social.Identity) {
+				store.EXPECT().GetUserIdentity(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.UserIdentity{}, sql.ErrNoRows)
+				store.EXPECT().GetUser(gomock.Any(), identity.Email).Times(1).Return(sqlc.User{}, sql.ErrNoRows)
+				store.EXPECT().SocialRegisterTx(gomock.Any(), ToCreateUserParams(identity), ToCreateUserIdentityParams(0, testProvider, identity)).Times(1).Return(user, nil)
+				expectIssueTokens(store, client)
+			},
+			checkResponse: func(t *testing.T, got sqlc.User, accessToken string, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user.ID, got.ID)
+			},
+		},
+		{
+			name:      "registers new Microsoft user with a tenant-owned email domain",
+			identity:  social.Identity{Subject: util.RandomString(10), Email: "new@contoso.com", EmailVerified: true, Name: "New"},
+			microsoft: true,
+			buildStub: func(store *db.MockStore, client *redis.MockClient, identity social.Identity) {
+				store.EXPECT().GetUserIdentity(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.UserIdentity{}, sql.ErrNoRows)
+				store.EXPECT().GetUser(gomock.Any(), identity.Email).Times(1).Return(sqlc.User{}, sql.ErrNoRows)
+				store.EXPECT().SocialRegisterTx(gomock.Any(), ToCreateUserParams(identity), ToCreateUserIdentityParams(0, testProvider, identity)).Times(1).Return(user, nil)
+				expectIssueTokens(store, client)
+			},
+			checkResponse: func(t *testing.T, got sqlc.User, accessToken string, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user.ID, got.ID)
+			},
+		},
+		{
+			name:      "does not link Microsoft user without a tenant-owned email domain",
+			identity:  social.Identity{Subject: util.RandomString(10), Email: user.Email, EmailVerified: false},
+			microsoft: true,
+			buildStub: func(store *db.MockStore, client *redis.MockClient, identity social.Identity) {
+				store.EXPECT().GetUserIdentity(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.UserIdentity{}, sql.ErrNoRows)
+				store.EXPECT().CreateUserIdentity(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, got sqlc.User, accessToken string, err error) {
+				require.ErrorContains(t, err, "verified email")
+			},
+		},
+		{
+			name:             "does not register new user while self-registration is disabled",
+			identity:         social.Identity{Subject: util.RandomString(10), Email: "new@example.com", EmailVerified: true, Name: "New"},
+			registrationMode: "invite",
+			buildStub: func(store *db.MockStore, client *redis.MockClient, identity social.Identity) {
+				store.EXPECT().GetUserIdentity(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.UserIdentity{}, sql.ErrNoRows)
+				store.EXPECT().GetUser(gomock.Any(), identity.Email).Times(1).Return(sqlc.User{}, sql.ErrNoRows)
+				store.EXPECT().SocialRegisterTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, got sqlc.User, accessToken string, err error) {
+				require.ErrorContains(t, err, "Self-registration is disabled")
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStore := db.NewMockStore(ctrl)
+			mockRedis := redis.NewMockClient(ctrl)
+			fake := socialtest.NewFakeProvider(t, "client")
+
+			testConf := conf
+			if testCase.registrationMode != "" {
+				testConf.RegistrationMode = testCase.registrationMode
+			}
+
+			providerConf := fake.Config()
+			var tamper func(claims jwt.MapClaims)
+			if testCase.microsoft {
+				providerConf.EmailVerifiedClaim = social.MicrosoftEmailVerifiedClaim
+				tamper = func(claims jwt.MapClaims) {
+					delete(claims, "email_verified")
+					claims["tid"] = "9188040d-6c67-4c5b-b112-36a304b66dad"
+					claims[social.MicrosoftEmailVerifiedClaim] = testCase.identity.EmailVerified
+				}
+			}
+
+			authSvc := authservice.NewService(mockStore, util.HashPassword, util.CheckPasswordHash, tokenMaker, testConf, mockRedis)
+			svc := NewService(mockStore, mockRedis, map[string]social.Provider{testProvider: social.NewOIDCProvider(providerConf)}, authSvc)
+
+			// Begin: capture the state stored in Redis and the parameters sent to the provider
+			var storedState string
+			mockRedis.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), StateDuration).Times(1).DoAndReturn(func(_ context.Context, key string, value interface{}, _ interface{}) error {
+				storedState = value.(string)
+				return nil
+			})
+			redirectURL, state, err := svc.BeginService(context.Background(), testProvider)
+			require.NoError(t, err)
+
+			u, err := url.Parse(redirectURL)
+			require.NoError(t, err)
+			require.Equal(t, state, u.Query().Get("state"))
+
+			code := fake.IssueCode(socialtest.Grant{
+				Identity:      testCase.identity,
+				Nonce:         u.Query().Get("nonce"),
+				CodeChallenge: u.Query().Get("code_challenge"),
+				Tamper:        tamper,
+			})
+
+			mockRedis.EXPECT().GetDel(gomock.Any(), stateKey(state)).Times(1).Return(storedState, nil)
+			testCase.buildStub(mockStore, mockRedis, testCase.identity)
+
+			got, accessToken, _, err := svc.CallbackService(context.Background(), testProvider, state, code)
+			testCase.checkResponse(t, got, accessToken, err)
+		})
+	}
+}
+
+func TestCallbackServiceExpiredState(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRedis := redis.NewMockClient(ctrl)
+	mockRedis.EXPECT().GetDel(gomock.Any(), gomock.Any()).Times(1).Return("", redisClient.Nil)
+
+	fake := socialtest.NewFakeProvider(t, "client")
+	svc := NewService(db.NewMockStore(ctrl), mockRedis, map[string]social.Provider{testProvider: fake.Provider()}, nil)
+
+	_, _, _, err := svc.CallbackService(context.Background(), testProvider, "unknown", "code")
+	require.ErrorContains(t, err, "Login session has expired")
+}
+
+func TestBeginServiceUnknownProvider(t *testing.T) {
+	svc := NewService(nil, nil, map[string]social.Provider{}, nil)
+
+	_, _, err := svc.BeginService(context.Background(), "myspace")
+	require.ErrorContains(t, err, "Unknown login provider")
+}
+
+func expectIssueTokens(store *db.MockStore, client *redis.MockClient) {
+	store.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.RefreshToken{}, nil)
+	client.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil)
+	client.EXPECT().Publish(gomock.Any(), redis.AccessInvalidationChannel, gomock.Any()).Times(1).Return(nil)
+}
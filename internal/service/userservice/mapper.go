@@ -7,7 +7,21 @@ func SqlcUserToUserResponse(user sqlc.User) UserResponse {
 		ID:        user.ID,
 		Name:      user.Name,
 		Email:     user.Email,
+		Role:      user.Role,
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
 	}
 }
+
+func SqlcMembershipsToMembershipResponses(memberships []sqlc.ListMembershipsByUserIdRow) []MembershipResponse {
+	res := make([]MembershipResponse, 0, len(memberships))
+	for _, membership := range memberships {
+		res = append(res, MembershipResponse{
+			OrganizationID: membership.OrganizationID,
+			Name:           membership.Name,
+			Role:           membership.Role,
+			CreatedAt:      membership.CreatedAt,
+		})
+	}
+	return res
+}
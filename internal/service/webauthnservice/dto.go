@@ -0,0 +1,10 @@
+package webauthnservice
+
+import "time"
+
+type CredentialResponse struct {
+	ID           int64     `json:"id"`
+	CredentialID string    `json:"credential_id"`
+	Transports   []string  `json:"transports"`
+	CreatedAt    time.Time `json:"created_at"`
+}
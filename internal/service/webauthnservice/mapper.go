@@ -0,0 +1,47 @@
+package webauthnservice
+
+import (
+	"encoding/base64"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+)
+
+func ToCreateWebauthnCredentialParams(userId int64, credential *webauthn.Credential) (res sqlc.CreateWebauthnCredentialParams) {
+	transports := make([]string, len(credential.Transport))
+	for i, transport := range credential.Transport {
+		transports[i] = string(transport)
+	}
+
+	res = sqlc.CreateWebauthnCredentialParams{
+		UserID:          userId,
+		CredentialID:    credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		Transports:      transports,
+		Aaguid:          credential.Authenticator.AAGUID,
+		SignCount:       int64(credential.Authenticator.SignCount),
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+	}
+	return
+}
+
+func ToUpdateWebauthnCredentialSignCountParams(credential *webauthn.Credential) (res sqlc.UpdateWebauthnCredentialSignCountParams) {
+	res = sqlc.UpdateWebauthnCredentialSignCountParams{
+		CredentialID: credential.ID,
+		SignCount:    int64(credential.Authenticator.SignCount),
+		BackupState:  credential.Flags.BackupState,
+	}
+	return
+}
+
+func ToCredentialResponse(credential sqlc.WebauthnCredential) (res CredentialResponse) {
+	res = CredentialResponse{
+		ID:           credential.ID,
+		CredentialID: base64.RawURLEncoding.EncodeToString(credential.CredentialID),
+		Transports:   credential.Transports,
+		CreatedAt:    credential.CreatedAt,
+	}
+	return
+}
@@ -0,0 +1,218 @@
+package webauthnservice
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	ierr "errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/service/authservice"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/passkey"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/redis"
+	"github.com/lib/pq"
+	redisClient "github.com/redis/go-redis/v9"
+)
+
+type Service struct {
+	store       db.Store
+	redis       redis.Client
+	webAuthn    *webauthn.WebAuthn
+	authService *authservice.Service
+}
+
+func NewService(store db.Store, redis redis.Client, webAuthn *webauthn.WebAuthn, authService *authservice.Service) *Service {
+	return &Service{store: store, redis: redis, webAuthn: webAuthn, authService: authService}
+}
+
+// BeginRegistrationService starts enrolling a new passkey for the signed-in user. Only one registration can be
+// pending per user, starting a new one replaces the previous challenge.
+func (service *Service) BeginRegistrationService(context context.Context, userId int64) (creation *protocol.CredentialCreation, errs error) {
+	user, errs := service.loadUser(context, userId)
+	if errs != nil {
+		return
+	}
+
+	exclusions := webauthn.Credentials(user.WebAuthnCredentials()).CredentialDescriptors()
+	creation, session, err := service.webAuthn.BeginRegistration(user, webauthn.WithExclusions(exclusions))
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to start passkey registration", err)
+		return
+	}
+
+	errs = service.saveSession(context, registrationKey(userId), session, service.webAuthn.Config.Timeouts.Registration)
+	return
+}
+
+func (service *Service) FinishRegistrationService(context context.Context, userId int64, body []byte) (credential sqlc.WebauthnCredential, errs error) {
+	session, errs := service.takeSession(context, registrationKey(userId))
+	if errs != nil {
+		return
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(body)
+	if err != nil {
+		errs = errors.New(errors.CodeBadRequest, "Invalid passkey registration response", err)
+		return
+	}
+
+	user, errs := service.loadUser(context, userId)
+	if errs != nil {
+		return
+	}
+
+	created, err := service.webAuthn.CreateCredential(user, session, parsed)
+	if err != nil {
+		errs = errors.New(errors.CodeUnauthorized, "Passkey registration could not be verified", err)
+		return
+	}
+
+	credential, err = service.store.CreateWebauthnCredential(context, ToCreateWebauthnCredentialParams(userId, created))
+	if err != nil {
+		var pqErr *pq.Error
+		if ierr.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			errs = errors.New(errors.CodeConflict, "Passkey is already registered", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to save passkey", err)
+		return
+	}
+
+	return
+}
+
+// BeginLoginService starts a usernameless login, the authenticator tells us who the user is.
+// The session is keyed by its challenge, which the client echoes back inside clientDataJSON.
+func (service *Service) BeginLoginService(context context.Context) (assertion *protocol.CredentialAssertion, errs error) {
+	assertion, session, err := service.webAuthn.BeginDiscoverableLogin()
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to start passkey login", err)
+		return
+	}
+
+	errs = service.saveSession(context, loginKey(session.Challenge), session, service.webAuthn.Config.Timeouts.Login)
+	return
+}
+
+func (service *Service) FinishLoginService(context context.Context, body []byte) (user sqlc.User, accessToken, refreshToken string, errs error) {
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(body)
+	if err != nil {
+		errs = errors.New(errors.CodeBadRequest, "Invalid passkey login response", err)
+		return
+	}
+
+	session, errs := service.takeSession(context, loginKey(parsed.Response.CollectedClientData.Challenge))
+	if errs != nil {
+		return
+	}
+
+	var loadErr error
+	webAuthnUser, credential, err := service.webAuthn.ValidatePasskeyLogin(func(_, userHandle []byte) (webauthn.User, error) {
+		userId, err := passkey.UserIdFromHandle(userHandle)
+		if err != nil {
+			return nil, err
+		}
+		var found *passkey.User
+		found, loadErr = service.loadUser(context, userId)
+		if loadErr != nil {
+			return nil, loadErr
+		}
+		return found, nil
+	}, session, parsed)
+	if err != nil {
+		var appErr *errors.AppError
+		if ierr.As(loadErr, &appErr) && appErr.Code == errors.CodeInternal {
+			errs = loadErr
+			return
+		}
+		errs = errors.New(errors.CodeUnauthorized, "Passkey could not be verified", err)
+		return
+	}
+
+	// A counter that did not move forward means the private key may have been copied
+	if credential.Authenticator.CloneWarning {
+		errs = errors.New(errors.CodeUnauthorized, "Passkey could not be verified", fmt.Errorf("sign counter did not increase, possible cloned authenticator"))
+		return
+	}
+
+	err = service.store.UpdateWebauthnCredentialSignCount(context, ToUpdateWebauthnCredentialSignCountParams(credential))
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to login user", err)
+		return
+	}
+
+	user = webAuthnUser.(*passkey.User).User()
+	accessToken, refreshToken, errs = service.authService.IssueTokensService(context, user)
+	return
+}
+
+func (service *Service) loadUser(context context.Context, userId int64) (user *passkey.User, errs error) {
+	found, err := service.store.GetUserByID(context, userId)
+	if err != nil {
+		if ierr.Is(err, sql.ErrNoRows) {
+			errs = errors.New(errors.CodeNotFound, "User is not found", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to get user", err)
+		return
+	}
+
+	credentials, err := service.store.ListWebauthnCredentialsByUserId(context, userId)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to get passkeys", err)
+		return
+	}
+
+	user = passkey.NewUser(found, credentials)
+	return
+}
+
+func (service *Service) saveSession(context context.Context, key string, session *webauthn.SessionData, timeout webauthn.TimeoutConfig) (errs error) {
+	value, err := json.Marshal(session)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to start passkey ceremony", err)
+		return
+	}
+
+	err = service.redis.Set(context, key, string(value), timeout.Timeout)
+	if err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to start passkey ceremony", err)
+		return
+	}
+	return
+}
+
+// takeSession loads and deletes a ceremony in one command so every challenge can be answered only once, even by
+// concurrent requests
+func (service *Service) takeSession(context context.Context, key string) (session webauthn.SessionData, errs error) {
+	value, err := service.redis.GetDel(context, key)
+	if err != nil {
+		if ierr.Is(err, redisClient.Nil) {
+			errs = errors.New(errors.CodeUnauthorized, "Passkey challenge has expired", err)
+			return
+		}
+		errs = errors.New(errors.CodeInternal, "Failed to load passkey challenge", err)
+		return
+	}
+
+	if err = json.Unmarshal([]byte(value), &session); err != nil {
+		errs = errors.New(errors.CodeInternal, "Failed to load passkey challenge", err)
+		return
+	}
+	return
+}
+
+func registrationKey(userId int64) string {
+	return "webauthn:registration:" + strconv.Itoa(int(userId))
+}
+
+func loginKey(challenge string) string {
+	return "webauthn:login:" + strings.TrimRight(challenge, "=")
+}
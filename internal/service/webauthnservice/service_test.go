@@ -0,0 +1,198 @@
+package webauthnservice
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	"github.com/hanifsyahsn/go_boilerplate/internal/factory/userfactory"
+	"github.com/hanifsyahsn/go_boilerplate/internal/service/authservice"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util"
+	appErrors "github.com/hanifsyahsn/go_boilerplate/internal/util/errors"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/passkey"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/passkey/passkeytest"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/redis"
+	redisClient "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+const testOrigin = "http://localhost:3000"
+
+type testEnv struct {
+	service       *Service
+	store         *db.MockStore
+	authenticator *passkeytest.Authenticator
+	user          sqlc.User
+	credentials   []sqlc.WebauthnCredential
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockStore := db.NewMockStore(ctrl)
+	webAuthn, err := passkey.NewWebAuthn(conf)
+	require.NoError(t, err)
+
+	env := &testEnv{
+		store:         mockStore,
+		authenticator: passkeytest.NewAuthenticator(testOrigin),
+		user:          userfactory.NewOptions(nil),
+	}
+	authSvc := authservice.NewService(mockStore, util.HashPassword, util.CheckPasswordHash, tokenMaker, conf, memoryRedis(ctrl))
+	env.service = NewService(mockStore, memoryRedis(ctrl), webAuthn, authSvc)
+
+	mockStore.EXPECT().GetUserByID(gomock.Any(), env.user.ID).AnyTimes().Return(env.user, nil)
+	mockStore.EXPECT().ListWebauthnCredentialsByUserId(gomock.Any(), env.user.ID).AnyTimes().DoAndReturn(func(_ context.Context, _ int64) ([]sqlc.WebauthnCredential, error) {
+		return env.credentials, nil
+	})
+	mockStore.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).AnyTimes().Return(sqlc.RefreshToken{}, nil)
+	return env
+}
+
+// register runs a full registration ceremony with the software authenticator
+func (env *testEnv) register(t *testing.T) {
+	env.store.EXPECT().CreateWebauthnCredential(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, arg sqlc.CreateWebauthnCredentialParams) (sqlc.WebauthnCredential, error) {
+		credential := sqlc.WebauthnCredential{
+			ID:              int64(len(env.credentials) + 1),
+			UserID:          arg.UserID,
+			CredentialID:    arg.CredentialID,
+			PublicKey:       arg.PublicKey,
+			AttestationType: arg.AttestationType,
+			Transports:      arg.Transports,
+			Aaguid:          arg.Aaguid,
+			SignCount:       arg.SignCount,
+			BackupEligible:  arg.BackupEligible,
+			BackupState:     arg.BackupState,
+		}
+		env.credentials = append(env.credentials, credential)
+		return credential, nil
+	})
+
+	creation, err := env.service.BeginRegistrationService(context.Background(), env.user.ID)
+	require.NoError(t, err)
+	options, err := json.Marshal(creation)
+	require.NoError(t, err)
+
+	response, err := env.authenticator.Register(options)
+	require.NoError(t, err)
+
+	credential, err := env.service.FinishRegistrationService(context.Background(), env.user.ID, response)
+	require.NoError(t, err)
+	require.Equal(t, env.user.ID, credential.UserID)
+	require.Equal(t, []string{"internal"}, credential.Transports)
+}
+
+func (env *testEnv) assertion(t *testing.T) []byte {
+	assertion, err := env.service.BeginLoginService(context.Background())
+	require.NoError(t, err)
+	options, err := json.Marshal(assertion)
+	require.NoError(t, err)
+
+	response, err := env.authenticator.Login(options)
+	require.NoError(t, err)
+	return response
+}
+
+func TestPasskeyRegistrationAndLogin(t *testing.T) {
+	env := newTestEnv(t)
+	env.register(t)
+
+	var updated sqlc.UpdateWebauthnCredentialSignCountParams
+	env.store.EXPECT().UpdateWebauthnCredentialSignCount(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, arg sqlc.UpdateWebauthnCredentialSignCountParams) error {
+		updated = arg
+		return nil
+	})
+
+	user, accessToken, refreshToken, err := env.service.FinishLoginService(context.Background(), env.assertion(t))
+	require.NoError(t, err)
+	require.Equal(t, env.user.ID, user.ID)
+	require.NotEmpty(t, accessToken)
+	require.NotEmpty(t, refreshToken)
+
+	require.Equal(t, env.credentials[0].CredentialID, updated.CredentialID)
+	require.EqualValues(t, 1, updated.SignCount)
+}
+
+func TestPasskeyLoginChallengeIsSingleUse(t *testing.T) {
+	env := newTestEnv(t)
+	env.register(t)
+	env.store.EXPECT().UpdateWebauthnCredentialSignCount(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+
+	response := env.assertion(t)
+	_, _, _, err := env.service.FinishLoginService(context.Background(), response)
+	require.NoError(t, err)
+
+	_, _, _, err = env.service.FinishLoginService(context.Background(), response)
+	requireAppErrorCode(t, err, appErrors.CodeUnauthorized)
+}
+
+func TestPasskeyLoginRejectsClonedAuthenticator(t *testing.T) {
+	env := newTestEnv(t)
+	env.register(t)
+	env.credentials[0].SignCount = 10
+	env.authenticator.SetSignCount(4)
+	env.store.EXPECT().UpdateWebauthnCredentialSignCount(gomock.Any(), gomock.Any()).Times(0)
+
+	_, _, _, err := env.service.FinishLoginService(context.Background(), env.assertion(t))
+	requireAppErrorCode(t, err, appErrors.CodeUnauthorized)
+}
+
+func TestPasskeyLoginUnknownCredential(t *testing.T) {
+	env := newTestEnv(t)
+	env.register(t)
+	// The passkey was removed server side but is still on the device
+	env.credentials = nil
+	env.store.EXPECT().UpdateWebauthnCredentialSignCount(gomock.Any(), gomock.Any()).Times(0)
+
+	_, _, _, err := env.service.FinishLoginService(context.Background(), env.assertion(t))
+	requireAppErrorCode(t, err, appErrors.CodeUnauthorized)
+}
+
+func TestPasskeyRegistrationRequiresPendingChallenge(t *testing.T) {
+	env := newTestEnv(t)
+
+	creation, err := env.service.BeginRegistrationService(context.Background(), env.user.ID)
+	require.NoError(t, err)
+	options, err := json.Marshal(creation)
+	require.NoError(t, err)
+	response, err := env.authenticator.Register(options)
+	require.NoError(t, err)
+
+	// A different challenge replaces the pending one
+	_, err = env.service.BeginRegistrationService(context.Background(), env.user.ID)
+	require.NoError(t, err)
+
+	env.store.EXPECT().CreateWebauthnCredential(gomock.Any(), gomock.Any()).Times(0)
+	_, err = env.service.FinishRegistrationService(context.Background(), env.user.ID, response)
+	requireAppErrorCode(t, err, appErrors.CodeUnauthorized)
+}
+
+// memoryRedis backs the mock client with a map so ceremonies can round-trip through it
+func memoryRedis(ctrl *gomock.Controller) *redis.MockClient {
+	values := map[string]string{}
+	client := redis.NewMockClient(ctrl)
+	client.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(func(_ context.Context, key string, value interface{}, _ interface{}) error {
+		values[key] = value.(string)
+		return nil
+	})
+	client.EXPECT().GetDel(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(func(_ context.Context, key string) (string, error) {
+		value, ok := values[key]
+		if !ok {
+			return "", redisClient.Nil
+		}
+		delete(values, key)
+		return value, nil
+	})
+	client.EXPECT().Publish(gomock.Any(), redis.AccessInvalidationChannel, gomock.Any()).AnyTimes().Return(nil)
+	return client
+}
+
+func requireAppErrorCode(t *testing.T, err error, code appErrors.Code) {
+	var appErr *appErrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, code, appErr.Code, appErr.Err)
+}
@@ -10,4 +10,38 @@ const (
 	IssuedAtKey       = "iat"
 	IssuerKey         = "iss"
 	JsonWebTokenIdKey = "jti"
+	AudienceKey       = "aud"
+	NonceKey          = "nonce"
+	AuthTimeKey       = "auth_time"
+	ScopeKey          = "scope"
+	ClientIdKey       = "client_id"
+	NameKey           = "name"
+	AccessTokenHash   = "at_hash"
+	SocialStateKey    = "social_state"
+	MagicLinkNonceKey = "magic_link_nonce"
+	OrgIdKey          = "org_id"
+	OrgRoleKey        = "org_role"
+	SessionIdKey      = "sid"
+	ActorKey          = "act"
+	ImpersonatorIdKey = "impersonator_id"
+	ConfirmationKey   = "cnf"
+	JwkThumbprintKey  = "jkt"
+	CertThumbprintKey = "x5t#S256"
+	RequestIdKey      = "request_id"
+
+	// DefaultRole is the users.role column default; users leaving a group fall back to it
+	DefaultRole = "user"
+	// AdminRole grants access to the /admin API
+	AdminRole = "admin"
+
+	// PermissionImpersonate allows signing in as another user, see the role_permissions table
+	PermissionImpersonate = "users:impersonate"
+
+	// AuditActionImpersonationStart is the audit_logs action recorded when an admin starts impersonating a user
+	AuditActionImpersonationStart = "impersonation.start"
+
+	// Roles a user can hold within an organization
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
 )
@@ -66,6 +66,61 @@ func RemoveTokens(c *gin.Context) {
 	})
 }
 
+// ParseSocialState binds a social login attempt to the browser that started it
+func ParseSocialState(c *gin.Context, state string, maxAge int) {
+	secure := isSecureRequest(c)
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     constant.SocialStateKey,
+		Value:    state,
+		Path:     "/auth/social",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   secure,
+		// Lax so the cookie survives the top-level redirect back from the provider
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func RemoveSocialState(c *gin.Context) {
+	secure := isSecureRequest(c)
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     constant.SocialStateKey,
+		Value:    "",
+		Path:     "/auth/social",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// ParseMagicLinkNonce binds magic links to the browser that requested them
+func ParseMagicLinkNonce(c *gin.Context, nonce string, maxAge int) {
+	secure := isSecureRequest(c)
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     constant.MagicLinkNonceKey,
+		Value:    nonce,
+		Path:     "/auth/magic-link",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+func RemoveMagicLinkNonce(c *gin.Context) {
+	secure := isSecureRequest(c)
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     constant.MagicLinkNonceKey,
+		Value:    "",
+		Path:     "/auth/magic-link",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
 func isSecureRequest(c *gin.Context) bool {
 	if c.Request.TLS != nil {
 		return true
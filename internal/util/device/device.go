@@ -0,0 +1,74 @@
+package device
+
+import (
+	"context"
+	"strings"
+)
+
+// Info describes the client a session was started or last used from
+type Info struct {
+	Label     string
+	IPAddress string
+	UserAgent string
+}
+
+type contextKey struct{}
+
+// Ordered so that the more specific tokens win, e.g. Edge and Opera also advertise Chrome
+var browsers = []struct{ token, name string }{
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Firefox/", "Firefox"},
+	{"Chrome/", "Chrome"},
+	{"CriOS/", "Chrome"},
+	{"Safari/", "Safari"},
+	{"curl/", "curl"},
+}
+
+var platforms = []struct{ token, name string }{
+	{"Android", "Android"},
+	{"iPhone", "iOS"},
+	{"iPad", "iPadOS"},
+	{"Windows", "Windows"},
+	{"Mac OS X", "macOS"},
+	{"CrOS", "ChromeOS"},
+	{"Linux", "Linux"},
+}
+
+// NewInfo describes a client from its address and User-Agent header
+func NewInfo(ipAddress, userAgent string) Info {
+	return Info{Label: Label(userAgent), IPAddress: ipAddress, UserAgent: userAgent}
+}
+
+// Label turns a User-Agent header into a short human readable name such as "Chrome on macOS"
+func Label(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	browser := "Unknown browser"
+	for _, b := range browsers {
+		if strings.Contains(userAgent, b.token) {
+			browser = b.name
+			break
+		}
+	}
+
+	for _, p := range platforms {
+		if strings.Contains(userAgent, p.token) {
+			return browser + " on " + p.name
+		}
+	}
+	return browser
+}
+
+// WithInfo returns a copy of ctx carrying the client info
+func WithInfo(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, contextKey{}, info)
+}
+
+// FromContext returns the client info stored by WithInfo, or the zero Info
+func FromContext(ctx context.Context) Info {
+	info, _ := ctx.Value(contextKey{}).(Info)
+	return info
+}
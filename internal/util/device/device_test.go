@@ -0,0 +1,35 @@
+package device
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabel(t *testing.T) {
+	testCases := []struct {
+		userAgent string
+		label     string
+	}{
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36", "Chrome on macOS"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36 Edg/129.0.0.0", "Edge on Windows"},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.6 Mobile/15E148 Safari/604.1", "Safari on iOS"},
+		{"Mozilla/5.0 (Android 14; Mobile; rv:131.0) Gecko/131.0 Firefox/131.0", "Firefox on Android"},
+		{"Mozilla/5.0 (X11; Linux x86_64; rv:131.0) Gecko/20100101 Firefox/131.0", "Firefox on Linux"},
+		{"curl/8.7.1", "curl"},
+		{"SomeBot", "Unknown browser"},
+		{"", "Unknown device"},
+	}
+
+	for _, testCase := range testCases {
+		require.Equal(t, testCase.label, Label(testCase.userAgent), testCase.userAgent)
+	}
+}
+
+func TestContext(t *testing.T) {
+	require.Equal(t, Info{}, FromContext(context.Background()))
+
+	info := NewInfo("10.0.0.1", "curl/8.7.1")
+	require.Equal(t, info, FromContext(WithInfo(context.Background(), info)))
+}
@@ -0,0 +1,132 @@
+package dpop
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
+)
+
+const (
+	// HeaderName is the request header carrying the proof, and the Authorization scheme of bound access tokens
+	HeaderName = "DPoP"
+	// ProofType is the typ header every proof must declare
+	ProofType = "dpop+jwt"
+
+	methodKey          = "htm"
+	uriKey             = "htu"
+	accessTokenHashKey = "ath"
+	maxJtiLength       = 256
+)
+
+var ErrInvalidProof = errors.New("invalid DPoP proof")
+
+// Proof is a verified DPoP proof JWT (RFC 9449)
+type Proof struct {
+	// Thumbprint is the RFC 7638 thumbprint of the key the proof was signed with, the cnf.jkt of bound tokens
+	Thumbprint string
+	JTI        string
+	IssuedAt   time.Time
+	// AccessTokenHash is the ath claim, only present when the proof accompanies an access token
+	AccessTokenHash string
+}
+
+type contextKey struct{}
+
+// Verify checks the signature of a proof against its embedded jwk and that it was made for this request: the htm
+// and htu claims must match method and uri and iat must be within maxAge of now. Replay detection on the jti is
+// left to the caller.
+func Verify(proof, method, uri string, now time.Time, maxAge time.Duration) (Proof, error) {
+	var thumbprint string
+	parsed, err := jwt.Parse(proof, func(t *jwt.Token) (interface{}, error) {
+		if typ, _ := t.Header["typ"].(string); typ != ProofType {
+			return nil, fmt.Errorf("unexpected typ header %v", t.Header["typ"])
+		}
+		jwk, ok := t.Header["jwk"].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("jwk header is missing")
+		}
+		publicKey, err := token.ECPublicKeyFromJWK(jwk)
+		if err != nil {
+			return nil, err
+		}
+		thumbprint = token.ECThumbprint(publicKey)
+		return publicKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodES256.Alg()}))
+	if err != nil {
+		return Proof{}, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok {
+		return Proof{}, fmt.Errorf("%w: unexpected claims", ErrInvalidProof)
+	}
+
+	if htm, _ := claims[methodKey].(string); htm != method {
+		return Proof{}, fmt.Errorf("%w: htm %q does not match %q", ErrInvalidProof, htm, method)
+	}
+	htu, _ := claims[uriKey].(string)
+	if expected := normalizeURI(uri); expected == "" || normalizeURI(htu) != expected {
+		return Proof{}, fmt.Errorf("%w: htu %q does not match %q", ErrInvalidProof, htu, uri)
+	}
+
+	jti, _ := claims[constant.JsonWebTokenIdKey].(string)
+	if jti == "" || len(jti) > maxJtiLength {
+		return Proof{}, fmt.Errorf("%w: invalid jti", ErrInvalidProof)
+	}
+
+	iatVal, ok := claims[constant.IssuedAtKey].(float64)
+	if !ok {
+		return Proof{}, fmt.Errorf("%w: iat is missing", ErrInvalidProof)
+	}
+	issuedAt := time.Unix(int64(iatVal), 0)
+	if issuedAt.Before(now.Add(-maxAge)) || issuedAt.After(now.Add(maxAge)) {
+		return Proof{}, fmt.Errorf("%w: iat is outside the accepted window", ErrInvalidProof)
+	}
+
+	ath, _ := claims[accessTokenHashKey].(string)
+
+	return Proof{Thumbprint: thumbprint, JTI: jti, IssuedAt: issuedAt, AccessTokenHash: ath}, nil
+}
+
+// AccessTokenHash returns the ath value a proof must carry when presented together with accessToken
+func AccessTokenHash(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// normalizeURI drops the query and fragment and lowercases the scheme and host, as htu is compared without them
+func normalizeURI(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ""
+	}
+	return strings.ToLower(u.Scheme) + "://" + strings.ToLower(u.Host) + u.EscapedPath()
+}
+
+// WithProof returns a copy of ctx carrying the verified proof of the request
+func WithProof(ctx context.Context, proof Proof) context.Context {
+	return context.WithValue(ctx, contextKey{}, proof)
+}
+
+// FromContext returns the verified proof of the request, if the client sent one
+func FromContext(ctx context.Context) (Proof, bool) {
+	proof, ok := ctx.Value(contextKey{}).(Proof)
+	return proof, ok
+}
+
+// Bind binds session to the DPoP key of the request, if the client sent a proof
+func Bind(ctx context.Context, session token.Session) token.Session {
+	if proof, ok := FromContext(ctx); ok {
+		session.Jkt = proof.Thumbprint
+	}
+	return session
+}
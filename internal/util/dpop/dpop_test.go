@@ -0,0 +1,167 @@
+package dpop
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
+	"github.com/stretchr/testify/require"
+)
+
+const testURI = "https://api.example.com/auth/me"
+
+func signProof(t *testing.T, key *ecdsa.PrivateKey, typ string, claims jwt.MapClaims) string {
+	proof := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	proof.Header["typ"] = typ
+	proof.Header["jwk"] = token.ECPublicJWK(&key.PublicKey)
+	signed, err := proof.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func proofClaims(now time.Time) jwt.MapClaims {
+	return jwt.MapClaims{"htm": "GET", "htu": testURI, "iat": now.Unix(), "jti": uuid.NewString()}
+}
+
+func TestVerify(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	now := time.Now()
+
+	testCases := []struct {
+		name       string
+		proof      func() string
+		checkError func(t *testing.T, proof Proof, err error)
+	}{
+		{
+			name: "valid",
+			proof: func() string {
+				claims := proofClaims(now)
+				claims["ath"] = AccessTokenHash("access-token")
+				return signProof(t, key, ProofType, claims)
+			},
+			checkError: func(t *testing.T, proof Proof, err error) {
+				require.NoError(t, err)
+				require.Equal(t, token.ECThumbprint(&key.PublicKey), proof.Thumbprint)
+				require.NotEmpty(t, proof.JTI)
+				require.Equal(t, AccessTokenHash("access-token"), proof.AccessTokenHash)
+			},
+		},
+		{
+			name: "htu ignores query and host case",
+			proof: func() string {
+				claims := proofClaims(now)
+				claims["htu"] = "https://API.example.com/auth/me?foo=bar"
+				return signProof(t, key, ProofType, claims)
+			},
+			checkError: func(t *testing.T, proof Proof, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "wrong typ",
+			proof: func() string {
+				return signProof(t, key, "JWT", proofClaims(now))
+			},
+			checkError: func(t *testing.T, proof Proof, err error) {
+				require.ErrorIs(t, err, ErrInvalidProof)
+			},
+		},
+		{
+			name: "wrong method",
+			proof: func() string {
+				claims := proofClaims(now)
+				claims["htm"] = "POST"
+				return signProof(t, key, ProofType, claims)
+			},
+			checkError: func(t *testing.T, proof Proof, err error) {
+				require.ErrorIs(t, err, ErrInvalidProof)
+			},
+		},
+		{
+			name: "wrong uri",
+			proof: func() string {
+				claims := proofClaims(now)
+				claims["htu"] = "https://api.example.com/auth/refresh"
+				return signProof(t, key, ProofType, claims)
+			},
+			checkError: func(t *testing.T, proof Proof, err error) {
+				require.ErrorIs(t, err, ErrInvalidProof)
+			},
+		},
+		{
+			name: "stale iat",
+			proof: func() string {
+				return signProof(t, key, ProofType, proofClaims(now.Add(-2*time.Minute)))
+			},
+			checkError: func(t *testing.T, proof Proof, err error) {
+				require.ErrorIs(t, err, ErrInvalidProof)
+			},
+		},
+		{
+			name: "missing jti",
+			proof: func() string {
+				claims := proofClaims(now)
+				delete(claims, "jti")
+				return signProof(t, key, ProofType, claims)
+			},
+			checkError: func(t *testing.T, proof Proof, err error) {
+				require.ErrorIs(t, err, ErrInvalidProof)
+			},
+		},
+		{
+			name: "signed with another key",
+			proof: func() string {
+				other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				require.NoError(t, err)
+				proof := jwt.NewWithClaims(jwt.SigningMethodES256, proofClaims(now))
+				proof.Header["typ"] = ProofType
+				proof.Header["jwk"] = token.ECPublicJWK(&key.PublicKey)
+				signed, err := proof.SignedString(other)
+				require.NoError(t, err)
+				return signed
+			},
+			checkError: func(t *testing.T, proof Proof, err error) {
+				require.ErrorIs(t, err, ErrInvalidProof)
+			},
+		},
+		{
+			name: "symmetric algorithm",
+			proof: func() string {
+				proof := jwt.NewWithClaims(jwt.SigningMethodHS256, proofClaims(now))
+				proof.Header["typ"] = ProofType
+				proof.Header["jwk"] = token.ECPublicJWK(&key.PublicKey)
+				signed, err := proof.SignedString([]byte("secret"))
+				require.NoError(t, err)
+				return signed
+			},
+			checkError: func(t *testing.T, proof Proof, err error) {
+				require.ErrorIs(t, err, ErrInvalidProof)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			proof, err := Verify(tc.proof(), "GET", testURI, now, time.Minute)
+			tc.checkError(t, proof, err)
+		})
+	}
+}
+
+func TestBind(t *testing.T) {
+	session := token.Session{SessionId: uuid.NewString()}
+	require.Equal(t, session, Bind(context.Background(), session))
+
+	ctx := WithProof(context.Background(), Proof{Thumbprint: "thumbprint"})
+	bound := Bind(ctx, session)
+	require.Equal(t, "thumbprint", bound.Jkt)
+	require.Equal(t, session.SessionId, bound.SessionId)
+}
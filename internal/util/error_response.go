@@ -2,6 +2,7 @@ package util
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
 )
 
 func ErrorResponse(err error) gin.H {
@@ -14,3 +15,13 @@ func ErrorResponseWithCode(err error, code string) gin.H {
 		"code":  code,
 	}
 }
+
+// MessageResponse is the error body of the API. It names the request, see requestid.Header, so a failure seen by a
+// client can be found in the logs.
+func MessageResponse(c *gin.Context, message string) gin.H {
+	body := gin.H{"message": message}
+	if requestId := c.GetString(constant.RequestIdKey); requestId != "" {
+		body[constant.RequestIdKey] = requestId
+	}
+	return body
+}
@@ -6,8 +6,10 @@ func HTTPStatus(code Code) int {
 	switch code {
 	case CodeBadRequest:
 		return http.StatusBadRequest
-	case CodeUnauthorized:
+	case CodeUnauthorized, CodeTokenExpired, CodeReauthenticationRequired:
 		return http.StatusUnauthorized
+	case CodeForbidden:
+		return http.StatusForbidden
 	case CodeNotFound:
 		return http.StatusNotFound
 	case CodeConflict:
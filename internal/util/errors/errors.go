@@ -7,9 +7,12 @@ const (
 	CodeNotFound        Code = "NOT_FOUND"
 	CodeConflict        Code = "CONFLICT"
 	CodeUnauthorized    Code = "UNAUTHORIZED"
+	CodeForbidden       Code = "FORBIDDEN"
 	CodeBadRequest      Code = "BAD_REQUEST"
 	CodeTokenExpired    Code = "TOKEN_EXPIRED"
 	CodeTooManyRequests Code = "TOO_MANY_REQUESTS"
+	// CodeReauthenticationRequired tells the client to ask for the password again, see POST /auth/reauthenticate
+	CodeReauthenticationRequired Code = "REAUTHENTICATION_REQUIRED"
 )
 
 type AppError struct {
@@ -0,0 +1,104 @@
+// Package health answers the readiness probe by checking the dependencies of the server, and fails it on purpose once
+// the server starts shutting down so load balancers stop sending it traffic.
+package health
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/logger"
+)
+
+const (
+	StatusOK      = "ok"
+	StatusFailing = "failing"
+	// StatusDegraded reports a failing optional check, the server is still ready
+	StatusDegraded = "degraded"
+	// StatusShuttingDown reports a server draining its connections before it stops
+	StatusShuttingDown = "shutting_down"
+)
+
+// Check is a dependency the server needs to handle requests
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+	// Optional checks are reported without failing the readiness, e.g. Redis when REDIS_FAILURE_MODE is open
+	Optional bool
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Status   string `json:"status"`
+	Duration string `json:"duration"`
+}
+
+// Report is the readiness of the server with the result of every check
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+// Checker runs the checks of the readiness probe
+type Checker struct {
+	checks  []Check
+	timeout time.Duration
+	// draining is set once the server shuts down
+	draining atomic.Bool
+}
+
+// NewChecker gives each check at most timeout to answer
+func NewChecker(timeout time.Duration, checks ...Check) *Checker {
+	return &Checker{checks: checks, timeout: timeout}
+}
+
+// Drain fails every following readiness check
+func (checker *Checker) Drain() {
+	checker.draining.Store(true)
+}
+
+// Check runs the checks concurrently and reports whether the server is ready. Failures are logged with their cause,
+// which is left out of the report as it may describe the internal network.
+func (checker *Checker) Check(ctx context.Context) (report Report, ready bool) {
+	if checker.draining.Load() {
+		return Report{Status: StatusShuttingDown}, false
+	}
+
+	report = Report{Status: StatusOK, Checks: make(map[string]Result, len(checker.checks))}
+	ready = true
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range checker.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, checker.timeout)
+			defer cancel()
+			start := time.Now()
+			err := check.Run(checkCtx)
+			result := Result{Status: StatusOK, Duration: time.Since(start).Round(time.Microsecond).String()}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				logger.FromContext(ctx).Warn("readiness check failed", slog.String("check", check.Name), slog.Any("err", err))
+				result.Status = StatusFailing
+				if check.Optional {
+					if report.Status == StatusOK {
+						report.Status = StatusDegraded
+					}
+				} else {
+					report.Status = StatusFailing
+					ready = false
+				}
+			}
+			report.Checks[check.Name] = result
+		}()
+	}
+	wg.Wait()
+	return
+}
@@ -0,0 +1,91 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func ok(context.Context) error {
+	return nil
+}
+
+func fail(context.Context) error {
+	return errors.New("connection refused")
+}
+
+// hang answers only once its deadline has passed
+func hang(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestChecker(t *testing.T) {
+	testCases := []struct {
+		name        string
+		checks      []Check
+		status      string
+		ready       bool
+		redisStatus string
+	}{
+		{
+			name:        "OK",
+			checks:      []Check{{Name: "postgres", Run: ok}, {Name: "redis", Run: ok}},
+			status:      StatusOK,
+			ready:       true,
+			redisStatus: StatusOK,
+		},
+		{
+			name:        "failing check",
+			checks:      []Check{{Name: "postgres", Run: fail}, {Name: "redis", Run: ok}},
+			status:      StatusFailing,
+			ready:       false,
+			redisStatus: StatusOK,
+		},
+		{
+			name:        "failing optional check",
+			checks:      []Check{{Name: "postgres", Run: ok}, {Name: "redis", Run: fail, Optional: true}},
+			status:      StatusDegraded,
+			ready:       true,
+			redisStatus: StatusFailing,
+		},
+		{
+			name:        "timed out check",
+			checks:      []Check{{Name: "postgres", Run: hang}, {Name: "redis", Run: fail, Optional: true}},
+			status:      StatusFailing,
+			ready:       false,
+			redisStatus: StatusFailing,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			checker := NewChecker(50*time.Millisecond, testCase.checks...)
+
+			start := time.Now()
+			report, ready := checker.Check(context.Background())
+			require.Less(t, time.Since(start), time.Second)
+			require.Equal(t, testCase.ready, ready)
+			require.Equal(t, testCase.status, report.Status)
+			require.Len(t, report.Checks, len(testCase.checks))
+			require.Equal(t, testCase.redisStatus, report.Checks["redis"].Status)
+			for _, check := range testCase.checks {
+				require.NotEmpty(t, report.Checks[check.Name].Duration)
+			}
+		})
+	}
+}
+
+func TestCheckerDrain(t *testing.T) {
+	checker := NewChecker(time.Second, Check{Name: "postgres", Run: ok})
+	_, ready := checker.Check(context.Background())
+	require.True(t, ready)
+
+	checker.Drain()
+	report, ready := checker.Check(context.Background())
+	require.False(t, ready)
+	require.Equal(t, Report{Status: StatusShuttingDown}, report)
+}
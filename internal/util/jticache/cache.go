@@ -0,0 +1,185 @@
+// Package jticache remembers the access token IDs recently confirmed by Redis. Authenticated requests are served
+// from it without a Redis round trip, and in fail-open mode it keeps the sessions it confirmed working while Redis is
+// unreachable.
+package jticache
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Cache maps session keys (see redis.UserAccessKey) to the jti last confirmed for them. Entries never outlive the
+// token they were confirmed for.
+//
+// A revoked or replaced token can be accepted from the cache for at most TTL. Instances normally learn about the
+// change right away through Forget (see redis.ListenAccessInvalidations); the TTL only bounds the delay when such a
+// notification is lost. Forget leaves a tombstone, so the revoked token is neither confirmed again by a request that
+// raced the revocation nor accepted while Redis is unavailable.
+type Cache struct {
+	mu      sync.Mutex
+	options Options
+	entries map[string]entry
+
+	hits     atomic.Uint64
+	misses   atomic.Uint64
+	forgot   atomic.Uint64
+	accepted atomic.Uint64
+	rejected atomic.Uint64
+}
+
+// Options configures a Cache
+type Options struct {
+	Capacity int
+	// TTL is how long a confirmed jti is trusted without asking Redis, 0 always asks
+	TTL time.Duration
+	// FailOpenTTL is how long a confirmed jti is remembered to judge tokens while Redis is unavailable,
+	// 0 rejects every token then (fail-closed)
+	FailOpenTTL time.Duration
+}
+
+type entry struct {
+	jti            string
+	confirmedAt    time.Time
+	tokenExpiresAt time.Time
+	// revoked marks a tombstone left by Forget, jti is then the revoked token when it was known
+	revoked bool
+}
+
+// Stats reports the size of the cache and how it answered
+type Stats struct {
+	Entries int
+	Hits    uint64
+	Misses  uint64
+	// Invalidations counts the entries dropped because their session changed
+	Invalidations uint64
+	// Accepted counts tokens let through while Redis was unavailable
+	Accepted uint64
+	// Rejected counts tokens refused while Redis was unavailable
+	Rejected uint64
+}
+
+func New(options Options) *Cache {
+	return &Cache{options: options, entries: make(map[string]entry)}
+}
+
+// Lookup reports whether jti is the current token of the session according to a recent confirmation.
+// A miss means Redis has to be asked.
+func (cache *Cache) Lookup(key, jti string) bool {
+	if cache.options.TTL <= 0 {
+		return false
+	}
+
+	now := time.Now()
+	cache.mu.Lock()
+	current, exists := cache.entries[key]
+	cache.mu.Unlock()
+
+	if exists && !current.revoked && current.jti == jti && now.Before(current.tokenExpiresAt) && now.Before(current.confirmedAt.Add(cache.options.TTL)) {
+		cache.hits.Add(1)
+		return true
+	}
+	cache.misses.Add(1)
+	return false
+}
+
+// Remember records jti as the current token of the session, as just confirmed by Redis
+func (cache *Cache) Remember(key, jti string, tokenExpiresAt time.Time) {
+	now := time.Now()
+	if !now.Before(tokenExpiresAt) || cache.lifetime() <= 0 {
+		return
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	current, exists := cache.entries[key]
+	if exists && current.revoked && current.jti == jti && now.Before(current.tokenExpiresAt) {
+		// Confirmed by Redis before the revocation was published
+		return
+	}
+	if !exists && len(cache.entries) >= cache.options.Capacity {
+		cache.removeExpired(now)
+		// Still full: drop arbitrary entries, they will be confirmed by Redis again
+		for evicted := range cache.entries {
+			if len(cache.entries) < cache.options.Capacity {
+				break
+			}
+			delete(cache.entries, evicted)
+		}
+	}
+	cache.entries[key] = entry{jti: jti, confirmedAt: now, tokenExpiresAt: tokenExpiresAt}
+}
+
+// Forget replaces the session with a tombstone after its jti was replaced or deleted
+func (cache *Cache) Forget(key string) {
+	now := time.Now()
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	// Sessions the cache does not know are neither served from it nor accepted while Redis is unavailable anyway
+	current, exists := cache.entries[key]
+	if !exists || current.revoked {
+		return
+	}
+	cache.entries[key] = entry{jti: current.jti, confirmedAt: now, tokenExpiresAt: current.tokenExpiresAt, revoked: true}
+	cache.forgot.Add(1)
+}
+
+// Clear drops every session, e.g. when invalidations may have been missed
+func (cache *Cache) Clear() {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.forgot.Add(uint64(len(cache.entries)))
+	cache.entries = make(map[string]entry)
+}
+
+// Degraded decides on a token whose jti cannot be checked against Redis. In fail-open mode it is accepted only when
+// this instance confirmed that very jti within FailOpenTTL and has not heard of its revocation since; otherwise it is
+// refused.
+func (cache *Cache) Degraded(key, jti string) bool {
+	if cache.options.FailOpenTTL <= 0 {
+		cache.rejected.Add(1)
+		return false
+	}
+
+	now := time.Now()
+	cache.mu.Lock()
+	current, exists := cache.entries[key]
+	cache.mu.Unlock()
+
+	if exists && !current.revoked && current.jti == jti && now.Before(current.tokenExpiresAt) && now.Before(current.confirmedAt.Add(cache.options.FailOpenTTL)) {
+		cache.accepted.Add(1)
+		return true
+	}
+	cache.rejected.Add(1)
+	return false
+}
+
+func (cache *Cache) Stats() Stats {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	return Stats{
+		Entries:       len(cache.entries),
+		Hits:          cache.hits.Load(),
+		Misses:        cache.misses.Load(),
+		Invalidations: cache.forgot.Load(),
+		Accepted:      cache.accepted.Load(),
+		Rejected:      cache.rejected.Load(),
+	}
+}
+
+// lifetime is how long an entry is of any use
+func (cache *Cache) lifetime() time.Duration {
+	return max(cache.options.TTL, cache.options.FailOpenTTL)
+}
+
+// removeExpired must be called with the lock held
+func (cache *Cache) removeExpired(now time.Time) {
+	lifetime := cache.lifetime()
+	for key, entry := range cache.entries {
+		if !now.Before(entry.tokenExpiresAt) || !now.Before(entry.confirmedAt.Add(lifetime)) {
+			delete(cache.entries, key)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package jticache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheLookup(t *testing.T) {
+	cache := New(Options{Capacity: 10, TTL: time.Minute})
+	cache.Remember("user:access:1:a", "jti-1", time.Now().Add(time.Hour))
+
+	require.True(t, cache.Lookup("user:access:1:a", "jti-1"))
+	// A different jti has to be checked against Redis, it may be newer
+	require.False(t, cache.Lookup("user:access:1:a", "jti-2"))
+	require.False(t, cache.Lookup("user:access:1:b", "jti-1"))
+
+	cache.Forget("user:access:1:a")
+	require.False(t, cache.Lookup("user:access:1:a", "jti-1"))
+
+	cache.Remember("user:access:1:a", "jti-1", time.Now().Add(time.Hour))
+	cache.Remember("user:access:1:b", "jti-1", time.Now().Add(time.Hour))
+	cache.Clear()
+	require.False(t, cache.Lookup("user:access:1:b", "jti-1"))
+
+	require.Equal(t, Stats{Hits: 1, Misses: 4, Invalidations: 3}, cache.Stats())
+}
+
+func TestCacheExpiry(t *testing.T) {
+	cache := New(Options{Capacity: 10, TTL: 20 * time.Millisecond})
+
+	cache.Remember("user:access:1:a", "jti-1", time.Now().Add(time.Hour))
+	// Entries never outlive their token
+	cache.Remember("user:access:1:b", "jti-1", time.Now().Add(10*time.Millisecond))
+	require.True(t, cache.Lookup("user:access:1:a", "jti-1"))
+	require.True(t, cache.Lookup("user:access:1:b", "jti-1"))
+
+	time.Sleep(30 * time.Millisecond)
+	require.False(t, cache.Lookup("user:access:1:a", "jti-1"))
+	require.False(t, cache.Lookup("user:access:1:b", "jti-1"))
+
+	cache.Remember("user:access:1:c", "jti-1", time.Now().Add(-time.Second))
+	require.Equal(t, 2, cache.Stats().Entries)
+
+	disabled := New(Options{Capacity: 10})
+	disabled.Remember("user:access:1:a", "jti-1", time.Now().Add(time.Hour))
+	require.False(t, disabled.Lookup("user:access:1:a", "jti-1"))
+	require.Equal(t, 0, disabled.Stats().Entries)
+}
+
+func TestCacheDegraded(t *testing.T) {
+	failClosed := New(Options{Capacity: 10, TTL: time.Minute})
+	failClosed.Remember("user:access:1:a", "jti-1", time.Now().Add(time.Hour))
+	require.False(t, failClosed.Degraded("user:access:1:a", "jti-1"))
+
+	failOpen := New(Options{Capacity: 10, FailOpenTTL: time.Minute})
+	failOpen.Remember("user:access:1:a", "jti-1", time.Now().Add(time.Hour))
+	require.True(t, failOpen.Degraded("user:access:1:a", "jti-1"))
+	// The session is known to have a newer token
+	require.False(t, failOpen.Degraded("user:access:1:a", "jti-0"))
+	// Tokens this instance has not confirmed itself are refused
+	require.False(t, failOpen.Degraded("user:access:1:b", "jti-2"))
+
+	// A logged out session stays refused, even when a request that raced the logout confirms it again
+	failOpen.Forget("user:access:1:a")
+	failOpen.Remember("user:access:1:a", "jti-1", time.Now().Add(time.Hour))
+	require.False(t, failOpen.Degraded("user:access:1:a", "jti-1"))
+	// The next token of the session is remembered again
+	failOpen.Remember("user:access:1:a", "jti-2", time.Now().Add(time.Hour))
+	require.True(t, failOpen.Degraded("user:access:1:a", "jti-2"))
+
+	require.Equal(t, Stats{Entries: 1, Invalidations: 1, Accepted: 2, Rejected: 3}, failOpen.Stats())
+
+	expired := New(Options{Capacity: 10, FailOpenTTL: 10 * time.Millisecond})
+	expired.Remember("user:access:1:a", "jti-1", time.Now().Add(time.Hour))
+	time.Sleep(20 * time.Millisecond)
+	require.False(t, expired.Degraded("user:access:1:a", "jti-1"))
+}
+
+func TestCacheCapacity(t *testing.T) {
+	cache := New(Options{Capacity: 3, TTL: time.Minute})
+	cache.Remember("expired", "jti", time.Now().Add(time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+
+	for i := 0; i < 10; i++ {
+		cache.Remember(fmt.Sprintf("user:access:%d:a", i), "jti", time.Now().Add(time.Hour))
+		require.LessOrEqual(t, cache.Stats().Entries, 3)
+	}
+	// The latest entry is always kept
+	require.True(t, cache.Lookup("user:access:9:a", "jti"))
+}
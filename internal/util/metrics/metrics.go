@@ -0,0 +1,106 @@
+// Package metrics holds the Prometheus collectors of the application. They are registered in Registry, which the
+// admin server exposes on /metrics, rather than in the global registry of client_golang.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace prefixes the name of every metric of the application
+const Namespace = "go_boilerplate"
+
+// Login outcomes
+const (
+	LoginSuccess = "success"
+	LoginFailure = "failure"
+	// LoginLocked is a login of a deactivated account with the right credentials
+	LoginLocked = "locked"
+)
+
+// Reasons an access or refresh token is rejected
+const (
+	TokenExpired      = "expired"
+	TokenBadSignature = "bad_signature"
+	// TokenMalformed covers tokens that cannot be parsed or lack a claim
+	TokenMalformed = "malformed"
+	// TokenJTIMismatch is an access token replaced by a newer one of its session, or whose session ended
+	TokenJTIMismatch = "jti_mismatch"
+	// TokenUnbound is a token bound to a DPoP key or client certificate presented without it
+	TokenUnbound = "unbound"
+	// TokenUnverifiable is an access token that could not be checked against Redis
+	TokenUnverifiable = "unverifiable"
+)
+
+// Registry collects every metric served on /metrics, along with the Go runtime and process metrics
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+var (
+	HTTPRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of the HTTP requests by route pattern and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	RedisCommandDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "redis_command_duration_seconds",
+		Help:      "Round trip time of the Redis commands, a pipeline counting as one command.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"command"})
+
+	Logins = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "logins_total",
+		Help:      "Password logins by outcome: success, failure or locked.",
+	}, []string{"outcome"})
+
+	Registrations = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "registrations_total",
+		Help:      "Accounts created by registration or invitation.",
+	})
+
+	Refreshes = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "token_refreshes_total",
+		Help:      "Access tokens issued from a refresh token.",
+	})
+
+	Logouts = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "logouts_total",
+		Help:      "Logouts of the current session or of every session of the user.",
+	})
+
+	TokenVerificationFailures = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "token_verification_failures_total",
+		Help:      "Access and refresh tokens rejected by the auth middlewares by reason.",
+	}, []string{"reason"})
+
+	RateLimitRejections = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "rate_limit_rejections_total",
+		Help:      "Requests rejected with 429 by rate limit policy.",
+	}, []string{"policy"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler serves the metrics of Registry in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
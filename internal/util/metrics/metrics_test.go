@@ -0,0 +1,24 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	Logins.WithLabelValues(LoginSuccess).Inc()
+	RateLimitRejections.WithLabelValues("login").Inc()
+
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	body := recorder.Body.String()
+	require.Contains(t, body, `go_boilerplate_logins_total{outcome="success"} 1`)
+	require.Contains(t, body, `go_boilerplate_rate_limit_rejections_total{policy="login"} 1`)
+	require.Contains(t, body, "go_goroutines")
+	require.Contains(t, body, "process_start_time_seconds")
+}
@@ -0,0 +1,80 @@
+package mtls
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"strings"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
+)
+
+type contextKey struct{}
+
+// ClientAuthType maps the TLS_CLIENT_AUTH setting to the crypto/tls policy. Client certificates are always verified
+// against the configured CA when they are accepted at all.
+func ClientAuthType(mode string) tls.ClientAuthType {
+	switch mode {
+	case "optional":
+		return tls.VerifyClientCertIfGiven
+	case "require":
+		return tls.RequireAndVerifyClientCert
+	default:
+		return tls.NoClientCert
+	}
+}
+
+// Thumbprint returns the RFC 8705 x5t#S256 value of a certificate, the base64url SHA-256 hash of its DER encoding
+func Thumbprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// VerifiedCertificate returns the client certificate of a connection once it has been verified against the client
+// CA. Certificates that were only presented are ignored.
+func VerifiedCertificate(state *tls.ConnectionState) (*x509.Certificate, bool) {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+	return state.VerifiedChains[0][0], true
+}
+
+// MatchesName reports whether the certificate's subject common name or one of its DNS names is in names
+func MatchesName(cert *x509.Certificate, names []string) bool {
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if cert.Subject.CommonName == name {
+			return true
+		}
+		for _, dnsName := range cert.DNSNames {
+			if strings.EqualFold(dnsName, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WithCertificate returns a copy of ctx carrying the verified client certificate of the request
+func WithCertificate(ctx context.Context, cert *x509.Certificate) context.Context {
+	return context.WithValue(ctx, contextKey{}, cert)
+}
+
+// FromContext returns the verified client certificate of the request, if the client presented one
+func FromContext(ctx context.Context) (*x509.Certificate, bool) {
+	cert, ok := ctx.Value(contextKey{}).(*x509.Certificate)
+	return cert, ok && cert != nil
+}
+
+// Bind binds session to the client certificate of the request, if the client presented one
+func Bind(ctx context.Context, session token.Session) token.Session {
+	if cert, ok := FromContext(ctx); ok {
+		session.X5t = Thumbprint(cert)
+	}
+	return session
+}
@@ -0,0 +1,31 @@
+package mtls
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/mtls/mtlstest"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/token"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchesName(t *testing.T) {
+	ca := mtlstest.NewCA(t, "test-ca")
+	cert := mtlstest.NewLeaf(t, ca, "scim-connector", "scim.internal.example.com").Cert
+
+	require.True(t, MatchesName(cert, []string{"other", " scim-connector"}))
+	require.True(t, MatchesName(cert, []string{"SCIM.internal.example.com"}))
+	require.False(t, MatchesName(cert, []string{"", "other"}))
+}
+
+func TestBind(t *testing.T) {
+	cert := mtlstest.NewLeaf(t, mtlstest.NewCA(t, "test-ca"), "mobile-gateway").Cert
+	session := token.Session{SessionId: "session"}
+
+	require.Equal(t, session, Bind(context.Background(), session))
+
+	bound := Bind(WithCertificate(context.Background(), cert), session)
+	require.Equal(t, Thumbprint(cert), bound.X5t)
+	require.Len(t, bound.X5t, 43)
+	require.Equal(t, session.SessionId, bound.SessionId)
+}
@@ -0,0 +1,146 @@
+package mtls
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reloader serves the certificate, key and client CA bundle from disk and picks up changes to the files without a
+// restart, e.g. when cert-manager or a secret mount rotates them
+type Reloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+
+	mu          sync.RWMutex
+	certificate *tls.Certificate
+	clientCAs   *x509.CertPool
+	stamp       string
+}
+
+// NewReloader loads the files once and fails if they are unusable. clientCAFile may be empty when client
+// certificates are not accepted.
+func NewReloader(certFile, keyFile, clientCAFile string) (*Reloader, error) {
+	reloader := &Reloader{certFile: certFile, keyFile: keyFile, clientCAFile: clientCAFile}
+	if err := reloader.reload(); err != nil {
+		return nil, err
+	}
+	return reloader, nil
+}
+
+// TLSConfig builds a server configuration that resolves the current certificate and client CAs on every handshake
+func (reloader *Reloader) TLSConfig(clientAuth tls.ClientAuthType) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: clientAuth,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			reloader.mu.RLock()
+			defer reloader.mu.RUnlock()
+			return reloader.certificate, nil
+		},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			reloader.mu.RLock()
+			defer reloader.mu.RUnlock()
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				ClientAuth:   clientAuth,
+				Certificates: []tls.Certificate{*reloader.certificate},
+				ClientCAs:    reloader.clientCAs,
+				NextProtos:   []string{"h2", "http/1.1"},
+			}, nil
+		},
+	}
+}
+
+// Watch checks the files every interval until ctx is done and reloads them when they change. A failed reload keeps
+// serving the previous certificate.
+func (reloader *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := reloader.reloadIfChanged()
+			if err != nil {
+				slog.Error("Failed to reload TLS certificates", slog.Any("err", err))
+			} else if reloaded {
+				slog.Info("Reloaded TLS certificates")
+			}
+		}
+	}
+}
+
+func (reloader *Reloader) reloadIfChanged() (bool, error) {
+	stamp, err := reloader.fileStamp()
+	if err != nil {
+		return false, err
+	}
+
+	reloader.mu.RLock()
+	changed := stamp != reloader.stamp
+	reloader.mu.RUnlock()
+	if !changed {
+		return false, nil
+	}
+
+	return true, reloader.reload()
+}
+
+func (reloader *Reloader) reload() error {
+	// Stamped before reading so a write racing with the reload is picked up on the next check
+	stamp, err := reloader.fileStamp()
+	if err != nil {
+		return err
+	}
+
+	certificate, err := tls.LoadX509KeyPair(reloader.certFile, reloader.keyFile)
+	if err != nil {
+		return fmt.Errorf("load key pair: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if reloader.clientCAFile != "" {
+		pem, err := os.ReadFile(reloader.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("read client CA: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in %v", reloader.clientCAFile)
+		}
+	}
+
+	reloader.mu.Lock()
+	defer reloader.mu.Unlock()
+	reloader.certificate = &certificate
+	reloader.clientCAs = clientCAs
+	reloader.stamp = stamp
+	return nil
+}
+
+// fileStamp summarizes the modification time and size of the files; os.Stat follows symlinks, so swapping the
+// target of a mounted secret counts as a change
+func (reloader *Reloader) fileStamp() (string, error) {
+	var stamp strings.Builder
+	for _, file := range []string{reloader.certFile, reloader.keyFile, reloader.clientCAFile} {
+		if file == "" {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&stamp, "%v:%d:%d;", file, info.ModTime().UnixNano(), info.Size())
+	}
+	return stamp.String(), nil
+}
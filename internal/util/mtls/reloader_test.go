@@ -0,0 +1,117 @@
+package mtls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/mtls/mtlstest"
+	"github.com/stretchr/testify/require"
+)
+
+func serve(t *testing.T, config *tls.Config) string {
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", config)
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go http.Serve(listener, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cert, ok := VerifiedCertificate(r.TLS)
+		if !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(cert.Subject.CommonName))
+	}))
+
+	return "https://" + listener.Addr().String()
+}
+
+func newClient(ca mtlstest.Certificate, client *mtlstest.Certificate) *http.Client {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Cert)
+
+	config := &tls.Config{RootCAs: roots, ServerName: "localhost"}
+	if client != nil {
+		config.Certificates = []tls.Certificate{{Certificate: [][]byte{client.Cert.Raw}, PrivateKey: client.Key}}
+	}
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	ca := mtlstest.NewCA(t, "test-ca")
+	caFile := filepath.Join(dir, "ca.crt")
+	require.NoError(t, os.WriteFile(caFile, ca.CertPEM, 0o600))
+	certFile, keyFile := mtlstest.NewLeaf(t, ca, "server", "localhost").WriteFiles(t, dir, "server")
+
+	reloader, err := NewReloader(certFile, keyFile, caFile)
+	require.NoError(t, err)
+	url := serve(t, reloader.TLSConfig(ClientAuthType("optional")))
+
+	machine := mtlstest.NewLeaf(t, ca, "scim-connector")
+	res, err := newClient(ca, &machine).Get(url)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	res.Body.Close()
+
+	res, err = newClient(ca, nil).Get(url)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	res.Body.Close()
+
+	// A certificate from another CA is never treated as verified
+	stranger := mtlstest.NewLeaf(t, mtlstest.NewCA(t, "other-ca"), "stranger")
+	res, err = newClient(ca, &stranger).Get(url)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	res.Body.Close()
+
+	reloaded, err := reloader.reloadIfChanged()
+	require.NoError(t, err)
+	require.False(t, reloaded)
+
+	// Rotate both the server certificate and the client CA
+	rotatedCA := mtlstest.NewCA(t, "rotated-ca")
+	require.NoError(t, os.WriteFile(caFile, rotatedCA.CertPEM, 0o600))
+	mtlstest.NewLeaf(t, rotatedCA, "server", "localhost").WriteFiles(t, dir, "server")
+	future := time.Now().Add(time.Minute)
+	for _, file := range []string{certFile, keyFile, caFile} {
+		require.NoError(t, os.Chtimes(file, future, future))
+	}
+
+	reloaded, err = reloader.reloadIfChanged()
+	require.NoError(t, err)
+	require.True(t, reloaded)
+
+	rotatedMachine := mtlstest.NewLeaf(t, rotatedCA, "scim-connector")
+	res, err = newClient(rotatedCA, &rotatedMachine).Get(url)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	res.Body.Close()
+
+	_, err = newClient(ca, &machine).Get(url)
+	require.Error(t, err)
+}
+
+func TestReloaderKeepsCertificateOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	ca := mtlstest.NewCA(t, "test-ca")
+	certFile, keyFile := mtlstest.NewLeaf(t, ca, "server", "localhost").WriteFiles(t, dir, "server")
+
+	reloader, err := NewReloader(certFile, keyFile, "")
+	require.NoError(t, err)
+	previous := reloader.certificate
+
+	require.NoError(t, os.WriteFile(certFile, []byte("not a certificate"), 0o600))
+	reloaded, err := reloader.reloadIfChanged()
+	require.True(t, reloaded)
+	require.Error(t, err)
+	require.Same(t, previous, reloader.certificate)
+
+	_, err = NewReloader(filepath.Join(dir, "missing.crt"), keyFile, "")
+	require.Error(t, err)
+}
@@ -0,0 +1,105 @@
+package passkey
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/hanifsyahsn/go_boilerplate/internal/config"
+	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+)
+
+func NewWebAuthn(config config.Config) (*webauthn.WebAuthn, error) {
+	var origins []string
+	for _, origin := range strings.Split(config.WebAuthnRPOrigins, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+
+	timeout := webauthn.TimeoutConfig{Enforce: true, Timeout: config.WebAuthnTimeout, TimeoutUVD: config.WebAuthnTimeout}
+	return webauthn.New(&webauthn.Config{
+		RPID:          config.WebAuthnRPID,
+		RPDisplayName: config.WebAuthnRPName,
+		RPOrigins:     origins,
+		// Passkeys are discoverable credentials, the browser picks the account during login
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			ResidentKey:      protocol.ResidentKeyRequirementRequired,
+			UserVerification: protocol.VerificationPreferred,
+		},
+		AttestationPreference: protocol.PreferNoAttestation,
+		Timeouts:              webauthn.TimeoutsConfig{Login: timeout, Registration: timeout},
+	})
+}
+
+// UserHandle is the opaque user.id given to authenticators; it is what a discoverable assertion returns
+func UserHandle(userId int64) []byte {
+	handle := make([]byte, 8)
+	binary.BigEndian.PutUint64(handle, uint64(userId))
+	return handle
+}
+
+func UserIdFromHandle(handle []byte) (int64, error) {
+	if len(handle) != 8 {
+		return 0, errors.New("invalid user handle")
+	}
+	return int64(binary.BigEndian.Uint64(handle)), nil
+}
+
+// User adapts a stored user and its credentials to webauthn.User
+type User struct {
+	user        sqlc.User
+	credentials []webauthn.Credential
+}
+
+func NewUser(user sqlc.User, credentials []sqlc.WebauthnCredential) *User {
+	res := &User{user: user, credentials: make([]webauthn.Credential, len(credentials))}
+	for i, credential := range credentials {
+		res.credentials[i] = ToCredential(credential)
+	}
+	return res
+}
+
+func (u *User) WebAuthnID() []byte {
+	return UserHandle(u.user.ID)
+}
+
+func (u *User) WebAuthnName() string {
+	return u.user.Email
+}
+
+func (u *User) WebAuthnDisplayName() string {
+	return u.user.Name
+}
+
+func (u *User) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+func (u *User) User() sqlc.User {
+	return u.user
+}
+
+func ToCredential(credential sqlc.WebauthnCredential) webauthn.Credential {
+	transports := make([]protocol.AuthenticatorTransport, len(credential.Transports))
+	for i, transport := range credential.Transports {
+		transports[i] = protocol.AuthenticatorTransport(transport)
+	}
+
+	return webauthn.Credential{
+		ID:              credential.CredentialID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		Transport:       transports,
+		Flags: webauthn.CredentialFlags{
+			BackupEligible: credential.BackupEligible,
+			BackupState:    credential.BackupState,
+		},
+		Authenticator: webauthn.Authenticator{
+			AAGUID:    credential.Aaguid,
+			SignCount: uint32(credential.SignCount),
+		},
+	}
+}
@@ -1,15 +1,31 @@
 package util
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"context"
+	"errors"
 
-func HashPassword(password string) (string, error) {
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/tracing"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func HashPassword(ctx context.Context, password string) (string, error) {
+	_, span := tracing.Start(ctx, "bcrypt.hash")
 	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	tracing.End(span, err)
 	if err != nil {
 		return "", err
 	}
 	return string(hashedBytes), nil
 }
 
-func CheckPasswordHash(password, hash string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+func CheckPasswordHash(ctx context.Context, password, hash string) error {
+	_, span := tracing.Start(ctx, "bcrypt.compare")
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	// A wrong password is an answer, not a failure of the span
+	if err != nil && !errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		tracing.End(span, err)
+		return err
+	}
+	span.End()
+	return err
 }
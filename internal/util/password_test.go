@@ -1,23 +1,35 @@
 package util
 
 import (
+	"context"
 	"testing"
 
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/tracing/tracingtest"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
 	"golang.org/x/crypto/bcrypt"
 )
 
 func TestPassword(t *testing.T) {
+	exporter := tracingtest.NewExporter(t)
 	password := RandomString(6)
 
-	hashedPassword, err := HashPassword(password)
+	hashedPassword, err := HashPassword(context.Background(), password)
 	require.NoError(t, err)
 	require.NotEmpty(t, hashedPassword)
 
-	err = CheckPasswordHash(password, hashedPassword)
+	err = CheckPasswordHash(context.Background(), password, hashedPassword)
 	require.NoError(t, err)
 
 	wrongPassword := RandomString(6)
-	err = CheckPasswordHash(wrongPassword, hashedPassword)
+	err = CheckPasswordHash(context.Background(), wrongPassword, hashedPassword)
 	require.EqualError(t, err, bcrypt.ErrMismatchedHashAndPassword.Error())
+
+	// A wrong password is an expected outcome, not a failure of the span
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+	require.Equal(t, "bcrypt.hash", spans[0].Name)
+	require.Equal(t, "bcrypt.compare", spans[1].Name)
+	require.Equal(t, "bcrypt.compare", spans[2].Name)
+	require.NotEqual(t, codes.Error, spans[2].Status.Code)
 }
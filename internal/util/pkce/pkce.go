@@ -0,0 +1,29 @@
+// Package pkce implements the S256 method of Proof Key for Code Exchange (RFC 7636), for both the clients of the
+// social providers and the authorization server.
+package pkce
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"regexp"
+)
+
+const MethodS256 = "S256"
+
+// RFC 7636 section 4.1: 43-128 characters from the unreserved set
+var codeVerifierPattern = regexp.MustCompile(`^[A-Za-z0-9\-._~]{43,128}$`)
+
+func S256CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// VerifyCodeChallenge checks the code verifier presented on the token request against the challenge of the
+// authorization request
+func VerifyCodeChallenge(verifier, challenge string) bool {
+	if !codeVerifierPattern.MatchString(verifier) {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(S256CodeChallenge(verifier)), []byte(challenge)) == 1
+}
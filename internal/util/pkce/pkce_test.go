@@ -0,0 +1,15 @@
+package pkce
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestS256CodeChallenge(t *testing.T) {
+	// Test vector from RFC 7636 appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	require.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", S256CodeChallenge(verifier))
+	require.True(t, VerifyCodeChallenge(verifier, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"))
+	require.False(t, VerifyCodeChallenge("too-short", S256CodeChallenge("too-short")))
+}
@@ -0,0 +1,36 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit allows Burst requests at once, refilled at one request per Every
+type Limit struct {
+	Every time.Duration
+	Burst int
+}
+
+// PerMinute allows n requests per minute with a burst of n
+func PerMinute(n int) Limit {
+	return Limit{Every: time.Minute / time.Duration(n), Burst: n}
+}
+
+// Result describes the state of a key after a request was counted against it
+type Result struct {
+	Allowed bool
+	// Limit is the burst of the limit, the most requests the key can make at once
+	Limit int
+	// Remaining is how many more requests are allowed right now
+	Remaining int
+	// RetryAfter is how long to wait before the next request is allowed, 0 when Allowed
+	RetryAfter time.Duration
+	// ResetAfter is how long until the key is back at its full burst
+	ResetAfter time.Duration
+}
+
+// Limiter counts requests per key. Implementations must be safe for concurrent use; the Redis one shares the
+// counters between every replica of the service, the in-memory one only within a process.
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
@@ -0,0 +1,159 @@
+package ratelimit
+
+import (
+	"container/list"
+	"context"
+	"hash/maphash"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Keys are spread over this many independently locked shards so concurrent requests rarely wait on each other
+const memoryShards = 32
+
+// MemoryLimiter keeps a token bucket per key in the process. Every replica enforces its own limits, so it is meant
+// for development and single instance deployments.
+//
+// The store is bounded: each shard holds at most its share of the capacity and evicts its least recently used
+// bucket to make room. Buckets that have refilled completely are indistinguishable from new ones, so the janitor
+// (see RunJanitor) drops them without affecting any limit.
+type MemoryLimiter struct {
+	seed    maphash.Seed
+	shards  [memoryShards]*memoryShard
+	evicted atomic.Uint64
+	expired atomic.Uint64
+}
+
+type memoryShard struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	// recency orders the entries from most to least recently used
+	recency *list.List
+}
+
+type memoryEntry struct {
+	key    string
+	bucket *rate.Limiter
+	// fullAt is when the bucket will have refilled to its burst if no further requests arrive
+	fullAt time.Time
+}
+
+// MemoryStats reports the size of the store and how many buckets left it
+type MemoryStats struct {
+	Entries int
+	// Evicted counts buckets dropped to stay within the capacity, which resets the limit of their key
+	Evicted uint64
+	// Expired counts full buckets removed by the janitor
+	Expired uint64
+}
+
+// NewMemoryLimiter creates a store holding at most capacity buckets
+func NewMemoryLimiter(capacity int) *MemoryLimiter {
+	perShard := (capacity + memoryShards - 1) / memoryShards
+	if perShard < 1 {
+		perShard = 1
+	}
+
+	limiter := &MemoryLimiter{seed: maphash.MakeSeed()}
+	for i := range limiter.shards {
+		limiter.shards[i] = &memoryShard{capacity: perShard, entries: make(map[string]*list.Element), recency: list.New()}
+	}
+	return limiter
+}
+
+func (limiter *MemoryLimiter) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	shard := limiter.shards[maphash.String(limiter.seed, key)%memoryShards]
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	now := time.Now()
+	entry := limiter.entry(shard, key, limit)
+	bucket := entry.bucket
+
+	result := Result{Limit: limit.Burst}
+	reservation := bucket.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); !reservation.OK() || delay > 0 {
+		reservation.CancelAt(now)
+		result.RetryAfter = delay
+	} else {
+		result.Allowed = true
+	}
+
+	tokens := bucket.TokensAt(now)
+	if tokens > 0 {
+		result.Remaining = int(tokens)
+	}
+	result.ResetAfter = time.Duration((float64(limit.Burst) - tokens) * float64(limit.Every))
+	entry.fullAt = now.Add(result.ResetAfter)
+	return result, nil
+}
+
+// entry returns the bucket of key, creating it and evicting the least recently used one when the shard is full.
+// The shard must be locked.
+func (limiter *MemoryLimiter) entry(shard *memoryShard, key string, limit Limit) *memoryEntry {
+	if element, exists := shard.entries[key]; exists {
+		shard.recency.MoveToFront(element)
+		return element.Value.(*memoryEntry)
+	}
+
+	for len(shard.entries) >= shard.capacity {
+		oldest := shard.recency.Back()
+		shard.recency.Remove(oldest)
+		delete(shard.entries, oldest.Value.(*memoryEntry).key)
+		limiter.evicted.Add(1)
+	}
+
+	entry := &memoryEntry{key: key, bucket: rate.NewLimiter(rate.Every(limit.Every), limit.Burst)}
+	shard.entries[key] = shard.recency.PushFront(entry)
+	return entry
+}
+
+// RunJanitor removes refilled buckets every interval until ctx is done
+func (limiter *MemoryLimiter) RunJanitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			expired := limiter.removeExpired(now)
+			if expired > 0 {
+				stats := limiter.Stats()
+				slog.Debug("rate limiter store cleaned up", slog.Int("expired", expired), slog.Int("entries", stats.Entries), slog.Uint64("evicted_total", stats.Evicted))
+			}
+		}
+	}
+}
+
+func (limiter *MemoryLimiter) removeExpired(now time.Time) (removed int) {
+	for _, shard := range limiter.shards {
+		shard.mu.Lock()
+		for key, element := range shard.entries {
+			if !element.Value.(*memoryEntry).fullAt.After(now) {
+				shard.recency.Remove(element)
+				delete(shard.entries, key)
+				removed++
+			}
+		}
+		shard.mu.Unlock()
+	}
+	limiter.expired.Add(uint64(removed))
+	return
+}
+
+func (limiter *MemoryLimiter) Stats() MemoryStats {
+	stats := MemoryStats{Evicted: limiter.evicted.Load(), Expired: limiter.expired.Load()}
+	for _, shard := range limiter.shards {
+		shard.mu.Lock()
+		stats.Entries += len(shard.entries)
+		shard.mu.Unlock()
+	}
+	return stats
+}
@@ -0,0 +1,123 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryLimiter(t *testing.T) {
+	limiter := NewMemoryLimiter(100)
+	ctx := context.Background()
+	limit := Limit{Every: time.Minute, Burst: 2}
+
+	result, err := limiter.Allow(ctx, "user:user@example.com", limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+	require.Equal(t, 2, result.Limit)
+	require.Equal(t, 1, result.Remaining)
+
+	result, err = limiter.Allow(ctx, "user:user@example.com", limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+	require.Equal(t, 0, result.Remaining)
+
+	result, err = limiter.Allow(ctx, "user:user@example.com", limit)
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+	require.InDelta(t, time.Minute, result.RetryAfter, float64(time.Second))
+	require.InDelta(t, 2*time.Minute, result.ResetAfter, float64(time.Second))
+
+	result, err = limiter.Allow(ctx, "user:other@example.com", limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+}
+
+func TestMemoryLimiterEvictsLeastRecentlyUsed(t *testing.T) {
+	// One bucket per shard
+	limiter := NewMemoryLimiter(memoryShards)
+	ctx := context.Background()
+	limit := Limit{Every: time.Hour, Burst: 1}
+
+	for i := 0; i < 10*memoryShards; i++ {
+		_, err := limiter.Allow(ctx, fmt.Sprintf("ip:10.0.%d.%d", i/256, i%256), limit)
+		require.NoError(t, err)
+	}
+
+	stats := limiter.Stats()
+	require.LessOrEqual(t, stats.Entries, memoryShards)
+	require.Equal(t, uint64(10*memoryShards-stats.Entries), stats.Evicted)
+
+	// The most recent key of its shard is still limited
+	result, err := limiter.Allow(ctx, fmt.Sprintf("ip:10.0.%d.%d", (10*memoryShards-1)/256, (10*memoryShards-1)%256), limit)
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+}
+
+func TestMemoryLimiterJanitor(t *testing.T) {
+	limiter := NewMemoryLimiter(100)
+	ctx := context.Background()
+
+	_, err := limiter.Allow(ctx, "ip:short", Limit{Every: time.Second, Burst: 1})
+	require.NoError(t, err)
+	_, err = limiter.Allow(ctx, "ip:long", Limit{Every: time.Hour, Burst: 1})
+	require.NoError(t, err)
+
+	// Nothing has refilled yet
+	require.Equal(t, 0, limiter.removeExpired(time.Now()))
+
+	require.Equal(t, 1, limiter.removeExpired(time.Now().Add(2*time.Second)))
+	stats := limiter.Stats()
+	require.Equal(t, 1, stats.Entries)
+	require.Equal(t, uint64(1), stats.Expired)
+	require.Equal(t, uint64(0), stats.Evicted)
+
+	// The limited key survived the sweep
+	result, err := limiter.Allow(ctx, "ip:long", Limit{Every: time.Hour, Burst: 1})
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+
+	janitorCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		limiter.RunJanitor(janitorCtx, time.Millisecond)
+		close(done)
+	}()
+	cancel()
+	<-done
+}
+
+func TestMemoryLimiterConcurrent(t *testing.T) {
+	limiter := NewMemoryLimiter(64)
+	limit := Limit{Every: time.Hour, Burst: 10}
+
+	var wg sync.WaitGroup
+	allowed := make([]int, 8)
+	for worker := range allowed {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				result, err := limiter.Allow(context.Background(), "ip:shared", limit)
+				require.NoError(t, err)
+				if result.Allowed {
+					allowed[worker]++
+				}
+				_, err = limiter.Allow(context.Background(), fmt.Sprintf("ip:%d-%d", worker, i), limit)
+				require.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	total := 0
+	for _, n := range allowed {
+		total += n
+	}
+	require.Equal(t, 10, total)
+	require.LessOrEqual(t, limiter.Stats().Entries, 64)
+}
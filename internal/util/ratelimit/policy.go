@@ -0,0 +1,135 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KeyType decides what a policy counts requests by
+type KeyType string
+
+const (
+	KeyIP        KeyType = "ip"
+	KeyUser      KeyType = "user"
+	KeyAPIKey    KeyType = "api_key"
+	KeyBodyEmail KeyType = "body_email"
+)
+
+// Policy is a named limit. Method and Route optionally restrict it to one route when it is applied to a group;
+// Route is matched against the route pattern, e.g. "/auth/sessions/:id".
+type Policy struct {
+	Name   string
+	Key    KeyType
+	Limit  Limit
+	Method string
+	Route  string
+}
+
+// Applies reports whether the policy limits requests to the route pattern with the method
+func (policy Policy) Applies(method, route string) bool {
+	return (policy.Method == "" || strings.EqualFold(policy.Method, method)) && (policy.Route == "" || policy.Route == route)
+}
+
+// ParsePolicies reads policies separated by ";", each written as "name=key,requests/period,burst" with an
+// optional ",METHOD /route" scope, e.g. "login=body_email,5/15m,5,POST /auth/login"
+func ParsePolicies(value string) (map[string]Policy, error) {
+	policies := make(map[string]Policy)
+	for _, definition := range strings.Split(value, ";") {
+		definition = strings.TrimSpace(definition)
+		if definition == "" {
+			continue
+		}
+
+		policy, err := parsePolicy(definition)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate limit policy %q: %w", definition, err)
+		}
+		if _, exists := policies[policy.Name]; exists {
+			return nil, fmt.Errorf("duplicate rate limit policy %q", policy.Name)
+		}
+		policies[policy.Name] = policy
+	}
+	return policies, nil
+}
+
+func parsePolicy(definition string) (policy Policy, err error) {
+	name, spec, ok := strings.Cut(definition, "=")
+	policy.Name = strings.TrimSpace(name)
+	if !ok || policy.Name == "" {
+		return policy, fmt.Errorf("expected name=key,requests/period,burst")
+	}
+
+	fields := strings.Split(spec, ",")
+	if len(fields) < 3 || len(fields) > 4 {
+		return policy, fmt.Errorf("expected key,requests/period,burst[,METHOD /route]")
+	}
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+
+	policy.Key = KeyType(fields[0])
+	switch policy.Key {
+	case KeyIP, KeyUser, KeyAPIKey, KeyBodyEmail:
+	default:
+		return policy, fmt.Errorf("unknown key %q (expected: ip, user, api_key or body_email)", fields[0])
+	}
+
+	count, period, ok := strings.Cut(fields[1], "/")
+	requests, err := strconv.Atoi(count)
+	if !ok || err != nil || requests <= 0 {
+		return policy, fmt.Errorf("invalid rate %q, expected requests/period such as 5/1m", fields[1])
+	}
+	every, err := time.ParseDuration(period)
+	if err != nil || every <= 0 {
+		return policy, fmt.Errorf("invalid rate period %q", period)
+	}
+	policy.Limit.Every = every / time.Duration(requests)
+
+	policy.Limit.Burst, err = strconv.Atoi(fields[2])
+	if err != nil || policy.Limit.Burst <= 0 {
+		return policy, fmt.Errorf("invalid burst %q", fields[2])
+	}
+
+	if len(fields) == 4 {
+		scope := strings.Fields(fields[3])
+		switch {
+		case len(scope) == 1 && strings.HasPrefix(scope[0], "/"):
+			policy.Route = scope[0]
+		case len(scope) == 2 && strings.HasPrefix(scope[1], "/"):
+			policy.Method, policy.Route = strings.ToUpper(scope[0]), scope[1]
+		default:
+			return policy, fmt.Errorf("invalid scope %q, expected METHOD /route", fields[3])
+		}
+	}
+	return policy, nil
+}
+
+// ParsePrefixes reads comma separated CIDRs; single addresses are accepted as /32 or /128 prefixes
+func ParsePrefixes(value string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			addr, err := netip.ParseAddr(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid address %q: %w", entry, err)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", entry, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePolicies(t *testing.T) {
+	policies, err := ParsePolicies("auth=ip,5/1m,5; login = body_email,5/15m,5,post /auth/login;scim=api_key,10/1s,50,/scim/v2/Users;")
+	require.NoError(t, err)
+	require.Len(t, policies, 3)
+
+	require.Equal(t, Policy{Name: "auth", Key: KeyIP, Limit: Limit{Every: 12 * time.Second, Burst: 5}}, policies["auth"])
+	require.Equal(t, Policy{
+		Name:   "login",
+		Key:    KeyBodyEmail,
+		Limit:  Limit{Every: 3 * time.Minute, Burst: 5},
+		Method: "POST",
+		Route:  "/auth/login",
+	}, policies["login"])
+	require.Equal(t, "/scim/v2/Users", policies["scim"].Route)
+	require.Empty(t, policies["scim"].Method)
+
+	require.True(t, policies["auth"].Applies("GET", "/auth/me"))
+	require.True(t, policies["login"].Applies("POST", "/auth/login"))
+	require.False(t, policies["login"].Applies("GET", "/auth/login"))
+	require.False(t, policies["login"].Applies("POST", "/auth/register"))
+	require.True(t, policies["scim"].Applies("DELETE", "/scim/v2/Users"))
+}
+
+func TestParsePoliciesInvalid(t *testing.T) {
+	testCases := []struct {
+		name  string
+		value string
+	}{
+		{name: "MissingName", value: "=ip,5/1m,5"},
+		{name: "MissingFields", value: "auth=ip,5/1m"},
+		{name: "UnknownKey", value: "auth=cookie,5/1m,5"},
+		{name: "InvalidRate", value: "auth=ip,five/1m,5"},
+		{name: "ZeroRequests", value: "auth=ip,0/1m,5"},
+		{name: "InvalidPeriod", value: "auth=ip,5/minute,5"},
+		{name: "InvalidBurst", value: "auth=ip,5/1m,0"},
+		{name: "InvalidScope", value: "auth=ip,5/1m,5,POST auth/login"},
+		{name: "Duplicate", value: "auth=ip,5/1m,5;auth=user,1/1s,5"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := ParsePolicies(testCase.value)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestParsePrefixes(t *testing.T) {
+	prefixes, err := ParsePrefixes("10.0.0.0/8, 192.168.1.7,fd00::1/64")
+	require.NoError(t, err)
+	require.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("192.168.1.7/32"),
+		netip.MustParsePrefix("fd00::/64"),
+	}, prefixes)
+
+	prefixes, err = ParsePrefixes("")
+	require.NoError(t, err)
+	require.Empty(t, prefixes)
+
+	_, err = ParsePrefixes("10.0.0.0/33")
+	require.Error(t, err)
+	_, err = ParsePrefixes("internal")
+	require.Error(t, err)
+}
@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const keyPrefix = "ratelimit:"
+
+// gcraScript implements the generic cell rate algorithm: the key holds the theoretical arrival time (TAT) of the
+// next request in milliseconds and a request is allowed when it is at most burst intervals in the future. The clock
+// is Redis' own so replicas with skewed clocks still share one limit.
+//
+// KEYS[1] the limited key, ARGV[1] the emission interval in ms, ARGV[2] the burst.
+// Returns {allowed, remaining, retry after ms, reset after ms}.
+var gcraScript = redis.NewScript(`
+local interval = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local tat = tonumber(redis.call('GET', KEYS[1]))
+if tat == nil or tat < now then
+	tat = now
+end
+
+local new_tat = tat + interval
+local allow_at = new_tat - interval * burst
+if allow_at > now then
+	return {0, 0, allow_at - now, tat - now}
+end
+
+redis.call('SET', KEYS[1], new_tat, 'PX', new_tat - now)
+return {1, math.floor((now - allow_at) / interval), 0, new_tat - now}
+`)
+
+// Evaluator is the part of the Redis client the limiter needs, see redis.Client. Passing the application client
+// rather than a go-redis one puts the limiter behind the circuit breaker and the tracing of the other commands.
+type Evaluator interface {
+	Eval(ctx context.Context, script *redis.Script, keys []string, args ...interface{}) (interface{}, error)
+}
+
+// RedisLimiter is a Limiter whose counters live in Redis and are updated by one atomic script per request
+type RedisLimiter struct {
+	client Evaluator
+}
+
+func NewRedisLimiter(client Evaluator) *RedisLimiter {
+	return &RedisLimiter{client: client}
+}
+
+func (limiter *RedisLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	interval := limit.Every.Milliseconds()
+	if interval < 1 {
+		interval = 1
+	}
+
+	values, err := redis.NewCmdResult(limiter.client.Eval(ctx, gcraScript, []string{keyPrefix + key}, interval, limit.Burst)).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	if len(values) != 4 {
+		return Result{}, fmt.Errorf("unexpected rate limit script result %v", values)
+	}
+
+	return Result{
+		Allowed:    values[0] == 1,
+		Limit:      limit.Burst,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+		ResetAfter: time.Duration(values[3]) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,113 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/redis"
+	goRedis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func newRedisLimiter(t *testing.T) (*RedisLimiter, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	server.SetTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	client := goRedis.NewClient(&goRedis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewRedisLimiter(redis.NewRedisClient(client)), server
+}
+
+func TestRedisLimiter(t *testing.T) {
+	limiter, server := newRedisLimiter(t)
+	ctx := context.Background()
+	limit := Limit{Every: 10 * time.Second, Burst: 3}
+
+	for i := 2; i >= 0; i-- {
+		result, err := limiter.Allow(ctx, "ip:10.0.0.1", limit)
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, 3, result.Limit)
+		require.Equal(t, i, result.Remaining)
+	}
+
+	result, err := limiter.Allow(ctx, "ip:10.0.0.1", limit)
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+	require.Equal(t, 0, result.Remaining)
+	require.Equal(t, 10*time.Second, result.RetryAfter)
+	require.Equal(t, 30*time.Second, result.ResetAfter)
+
+	// Other keys have their own budget
+	result, err = limiter.Allow(ctx, "ip:10.0.0.2", limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+
+	// One interval later exactly one more request is allowed
+	server.SetTime(time.Date(2026, 1, 1, 0, 0, 10, 0, time.UTC))
+	result, err = limiter.Allow(ctx, "ip:10.0.0.1", limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+	require.Equal(t, 0, result.Remaining)
+
+	result, err = limiter.Allow(ctx, "ip:10.0.0.1", limit)
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+
+	// The key expires once the bucket would be full again
+	require.Equal(t, 30*time.Second, server.TTL(keyPrefix+"ip:10.0.0.1"))
+}
+
+func TestRedisLimiterSharedBetweenReplicas(t *testing.T) {
+	first, server := newRedisLimiter(t)
+	client := goRedis.NewClient(&goRedis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	second := NewRedisLimiter(redis.NewRedisClient(client))
+
+	ctx := context.Background()
+	limit := PerMinute(2)
+
+	result, err := first.Allow(ctx, "email:user@example.com", limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+
+	result, err = second.Allow(ctx, "email:user@example.com", limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+
+	result, err = first.Allow(ctx, "email:user@example.com", limit)
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+	require.Equal(t, 30*time.Second, result.RetryAfter)
+}
+
+func TestRedisLimiterUnavailable(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := goRedis.NewClient(&goRedis.Options{Addr: server.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+	limiter := NewRedisLimiter(redis.NewRedisClient(client))
+	server.Close()
+
+	_, err := limiter.Allow(context.Background(), "ip:10.0.0.1", PerMinute(5))
+	require.Error(t, err)
+}
+
+func TestRedisLimiterCircuitBreaker(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := goRedis.NewClient(&goRedis.Options{Addr: server.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+	breaker := redis.NewCircuitBreaker(redis.NewRedisClient(client), 1, time.Minute)
+	limiter := NewRedisLimiter(breaker)
+	server.Close()
+
+	_, err := limiter.Allow(context.Background(), "ip:10.0.0.1", PerMinute(5))
+	require.Error(t, err)
+	require.Equal(t, redis.BreakerOpen, breaker.Stats().State)
+
+	// Once open, the limiter fails without waiting for Redis
+	_, err = limiter.Allow(context.Background(), "ip:10.0.0.1", PerMinute(5))
+	require.ErrorIs(t, err, redis.ErrUnavailable)
+}
@@ -0,0 +1,80 @@
+package redis
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+// AccessInvalidationChannel carries the user access keys whose jti was replaced or deleted, so the instances caching
+// them (see jticache) drop their copy
+const AccessInvalidationChannel = "user:access:invalidate"
+
+// SetUserAccess registers jti as the current access token of the session, replacing the previous one everywhere
+func SetUserAccess(ctx context.Context, client Client, userId int64, sessionId, jti string, ttl time.Duration) error {
+	key := UserAccessKey(userId, sessionId)
+	if err := client.Set(ctx, key, jti, ttl); err != nil {
+		return err
+	}
+	publishInvalidation(ctx, client, key)
+	return nil
+}
+
+// DeleteUserAccess revokes the access tokens of the sessions everywhere
+func DeleteUserAccess(ctx context.Context, client Client, userId int64, sessionIds ...string) error {
+	if len(sessionIds) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(sessionIds))
+	for _, sessionId := range sessionIds {
+		keys = append(keys, UserAccessKey(userId, sessionId))
+	}
+	if err := client.Del(ctx, keys...); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		publishInvalidation(ctx, client, key)
+	}
+	return nil
+}
+
+// publishInvalidation only logs failures: Redis already holds the new state and caches expire on their own
+func publishInvalidation(ctx context.Context, client Client, key string) {
+	if err := client.Publish(ctx, AccessInvalidationChannel, key); err != nil {
+		logger.FromContext(ctx).Warn("failed to publish access invalidation", slog.String("key", key), slog.Any("err", err))
+	}
+}
+
+// ListenAccessInvalidations calls invalidate with every key published on AccessInvalidationChannel until ctx is done.
+// Notifications sent while the subscription was down are lost, so reset is called whenever it (re)connects.
+func ListenAccessInvalidations(ctx context.Context, client Client, invalidate func(key string), reset func()) {
+	subscription := client.Subscribe(ctx, AccessInvalidationChannel)
+	defer subscription.Close()
+
+	for {
+		message, err := subscription.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Warn("access invalidation subscription failed, retrying", slog.Any("err", err))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		switch message := message.(type) {
+		case *redis.Subscription:
+			reset()
+		case *redis.Message:
+			invalidate(message.Payload)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrUnavailable is returned without contacting Redis while the circuit breaker is open
+var ErrUnavailable = errors.New("redis is unavailable")
+
+// BreakerState is the state of a CircuitBreaker
+type BreakerState int32
+
+const (
+	// BreakerClosed passes every command to Redis
+	BreakerClosed BreakerState = iota
+	// BreakerOpen fails every command immediately until the cooldown has passed
+	BreakerOpen
+	// BreakerHalfOpen lets a single command through to probe whether Redis is back
+	BreakerHalfOpen
+)
+
+func (state BreakerState) String() string {
+	switch state {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreaker is a Client that stops calling Redis after consecutive failures, so an outage costs callers an
+// immediate ErrUnavailable instead of a timeout per request. Replies from Redis itself, such as redis.Nil or script
+// errors, are not failures.
+type CircuitBreaker struct {
+	client    Client
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+
+	opened   atomic.Uint64
+	rejected atomic.Uint64
+}
+
+// BreakerStats reports the state of the breaker and how often it tripped
+type BreakerStats struct {
+	State BreakerState
+	// Opened counts the transitions to the open state
+	Opened uint64
+	// Rejected counts the commands failed without contacting Redis
+	Rejected uint64
+}
+
+// NewCircuitBreaker opens after threshold consecutive failures and probes Redis again once cooldown has passed
+func NewCircuitBreaker(client Client, threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{client: client, threshold: threshold, cooldown: cooldown}
+}
+
+func (breaker *CircuitBreaker) Get(ctx context.Context, key string) (value string, err error) {
+	if err = breaker.before(); err != nil {
+		return
+	}
+	defer func() { breaker.after(ctx, err) }()
+	return breaker.client.Get(ctx, key)
+}
+
+func (breaker *CircuitBreaker) GetDel(ctx context.Context, key string) (value string, err error) {
+	if err = breaker.before(); err != nil {
+		return
+	}
+	defer func() { breaker.after(ctx, err) }()
+	return breaker.client.GetDel(ctx, key)
+}
+
+func (breaker *CircuitBreaker) MGet(ctx context.Context, keys ...string) (values []interface{}, err error) {
+	if err = breaker.before(); err != nil {
+		return
+	}
+	defer func() { breaker.after(ctx, err) }()
+	return breaker.client.MGet(ctx, keys...)
+}
+
+func (breaker *CircuitBreaker) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) (err error) {
+	if err = breaker.before(); err != nil {
+		return
+	}
+	defer func() { breaker.after(ctx, err) }()
+	return breaker.client.Set(ctx, key, value, ttl)
+}
+
+func (breaker *CircuitBreaker) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (set bool, err error) {
+	if err = breaker.before(); err != nil {
+		return
+	}
+	defer func() { breaker.after(ctx, err) }()
+	return breaker.client.SetNX(ctx, key, value, ttl)
+}
+
+func (breaker *CircuitBreaker) Del(ctx context.Context, keys ...string) (err error) {
+	if err = breaker.before(); err != nil {
+		return
+	}
+	defer func() { breaker.after(ctx, err) }()
+	return breaker.client.Del(ctx, keys...)
+}
+
+func (breaker *CircuitBreaker) Incr(ctx context.Context, key string) (value int64, err error) {
+	if err = breaker.before(); err != nil {
+		return
+	}
+	defer func() { breaker.after(ctx, err) }()
+	return breaker.client.Incr(ctx, key)
+}
+
+func (breaker *CircuitBreaker) Expire(ctx context.Context, key string, ttl time.Duration) (exists bool, err error) {
+	if err = breaker.before(); err != nil {
+		return
+	}
+	defer func() { breaker.after(ctx, err) }()
+	return breaker.client.Expire(ctx, key, ttl)
+}
+
+func (breaker *CircuitBreaker) Eval(ctx context.Context, script *redis.Script, keys []string, args ...interface{}) (result interface{}, err error) {
+	if err = breaker.before(); err != nil {
+		return
+	}
+	defer func() { breaker.after(ctx, err) }()
+	return breaker.client.Eval(ctx, script, keys, args...)
+}
+
+func (breaker *CircuitBreaker) Pipeline(ctx context.Context, fn func(pipe redis.Pipeliner) error) (cmds []redis.Cmder, err error) {
+	if err = breaker.before(); err != nil {
+		return
+	}
+	defer func() { breaker.after(ctx, err) }()
+	return breaker.client.Pipeline(ctx, fn)
+}
+
+func (breaker *CircuitBreaker) Publish(ctx context.Context, channel string, message interface{}) (err error) {
+	if err = breaker.before(); err != nil {
+		return
+	}
+	defer func() { breaker.after(ctx, err) }()
+	return breaker.client.Publish(ctx, channel, message)
+}
+
+func (breaker *CircuitBreaker) Ping(ctx context.Context) (err error) {
+	if err = breaker.before(); err != nil {
+		return
+	}
+	defer func() { breaker.after(ctx, err) }()
+	return breaker.client.Ping(ctx)
+}
+
+// Subscribe is not guarded, the subscription outlives any outage and reconnects by itself
+func (breaker *CircuitBreaker) Subscribe(ctx context.Context, channels ...string) *redis.PubSub {
+	return breaker.client.Subscribe(ctx, channels...)
+}
+
+func (breaker *CircuitBreaker) Close() error {
+	return breaker.client.Close()
+}
+
+func (breaker *CircuitBreaker) Stats() BreakerStats {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+	return BreakerStats{State: breaker.state, Opened: breaker.opened.Load(), Rejected: breaker.rejected.Load()}
+}
+
+// before admits a command, moving an open breaker whose cooldown has passed to half-open for a single probe
+func (breaker *CircuitBreaker) before() error {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	switch breaker.state {
+	case BreakerOpen:
+		if time.Since(breaker.openedAt) >= breaker.cooldown {
+			breaker.state = BreakerHalfOpen
+			return nil
+		}
+	case BreakerHalfOpen:
+		// The probe is still running
+	default:
+		return nil
+	}
+
+	breaker.rejected.Add(1)
+	return ErrUnavailable
+}
+
+func (breaker *CircuitBreaker) after(ctx context.Context, err error) {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	if isFailure(err) && errors.Is(ctx.Err(), context.Canceled) {
+		// Abandoned by its caller, so Redis may not have been reached: neither a success nor a failure. An
+		// abandoned probe hands the breaker back to open with its cooldown already passed, so the next command
+		// probes again.
+		if breaker.state == BreakerHalfOpen {
+			breaker.state = BreakerOpen
+		}
+		return
+	}
+
+	if !isFailure(err) {
+		if breaker.state != BreakerClosed {
+			slog.Info("redis circuit breaker closed, redis is reachable again")
+		}
+		breaker.state = BreakerClosed
+		breaker.failures = 0
+		return
+	}
+
+	breaker.failures++
+	if breaker.state == BreakerHalfOpen || (breaker.state == BreakerClosed && breaker.failures >= breaker.threshold) {
+		slog.Warn("redis circuit breaker opened", slog.Duration("cooldown", breaker.cooldown), slog.Int("failures", breaker.failures), slog.Any("err", err))
+		breaker.state = BreakerOpen
+		breaker.openedAt = time.Now()
+		breaker.opened.Add(1)
+	}
+}
+
+// isFailure tells connection problems apart from replies of Redis
+func isFailure(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) {
+		return false
+	}
+	var reply redis.Error
+	return !errors.As(err, &reply)
+}
@@ -0,0 +1,123 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := NewMockClient(ctrl)
+	breaker := NewCircuitBreaker(client, 2, 50*time.Millisecond)
+	ctx := context.Background()
+	down := errors.New("connection refused")
+
+	// Missing keys and canceled requests say nothing about the health of Redis
+	client.EXPECT().Get(gomock.Any(), "missing").Times(2).Return("", redis.Nil)
+	for i := 0; i < 2; i++ {
+		_, err := breaker.Get(ctx, "missing")
+		require.ErrorIs(t, err, redis.Nil)
+	}
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	client.EXPECT().Get(gomock.Any(), "key").Times(2).Return("", context.Canceled)
+	for i := 0; i < 2; i++ {
+		_, err := breaker.Get(canceled, "key")
+		require.ErrorIs(t, err, context.Canceled)
+	}
+	require.Equal(t, BreakerClosed, breaker.Stats().State)
+
+	client.EXPECT().Set(gomock.Any(), "key", "value", time.Minute).Times(2).Return(down)
+	for i := 0; i < 2; i++ {
+		require.ErrorIs(t, breaker.Set(ctx, "key", "value", time.Minute), down)
+	}
+	require.Equal(t, BreakerOpen, breaker.Stats().State)
+
+	// Open: Redis is not called at all
+	_, err := breaker.Get(ctx, "key")
+	require.ErrorIs(t, err, ErrUnavailable)
+	require.ErrorIs(t, breaker.Del(ctx, "key"), ErrUnavailable)
+	// The readiness probe fails fast as well
+	require.ErrorIs(t, breaker.Ping(ctx), ErrUnavailable)
+
+	// A failed probe opens the breaker again right away
+	time.Sleep(60 * time.Millisecond)
+	client.EXPECT().Get(gomock.Any(), "key").Times(1).Return("", down)
+	_, err = breaker.Get(ctx, "key")
+	require.ErrorIs(t, err, down)
+	require.Equal(t, BreakerOpen, breaker.Stats().State)
+
+	time.Sleep(60 * time.Millisecond)
+	client.EXPECT().Get(gomock.Any(), "key").Times(1).Return("value", nil)
+	value, err := breaker.Get(ctx, "key")
+	require.NoError(t, err)
+	require.Equal(t, "value", value)
+
+	require.Equal(t, BreakerStats{State: BreakerClosed, Opened: 2, Rejected: 3}, breaker.Stats())
+}
+
+func TestCircuitBreakerHalfOpenAdmitsOneProbe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := NewMockClient(ctrl)
+	breaker := NewCircuitBreaker(client, 1, time.Millisecond)
+	ctx := context.Background()
+
+	client.EXPECT().Incr(gomock.Any(), "counter").Times(1).Return(int64(0), errors.New("timeout"))
+	_, err := breaker.Incr(ctx, "counter")
+	require.Error(t, err)
+	time.Sleep(5 * time.Millisecond)
+
+	probing := make(chan struct{})
+	release := make(chan struct{})
+	client.EXPECT().Incr(gomock.Any(), "counter").Times(1).DoAndReturn(func(_ context.Context, _ string) (int64, error) {
+		close(probing)
+		<-release
+		return 1, nil
+	})
+	done := make(chan error)
+	go func() {
+		_, err := breaker.Incr(ctx, "counter")
+		done <- err
+	}()
+
+	<-probing
+	_, err = breaker.Incr(ctx, "counter")
+	require.ErrorIs(t, err, ErrUnavailable)
+
+	close(release)
+	require.NoError(t, <-done)
+	require.Equal(t, BreakerClosed, breaker.Stats().State)
+}
+
+func TestCircuitBreakerCanceledProbe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := NewMockClient(ctrl)
+	breaker := NewCircuitBreaker(client, 1, 50*time.Millisecond)
+	ctx := context.Background()
+
+	client.EXPECT().Get(gomock.Any(), "key").Times(1).Return("", errors.New("connection refused"))
+	_, err := breaker.Get(ctx, "key")
+	require.Error(t, err)
+	time.Sleep(60 * time.Millisecond)
+
+	// The caller of the probe gave up before Redis answered, which proves nothing
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	client.EXPECT().Get(gomock.Any(), "key").Times(1).Return("", context.Canceled)
+	_, err = breaker.Get(canceled, "key")
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, BreakerOpen, breaker.Stats().State)
+
+	// The next command probes again without waiting for another cooldown
+	client.EXPECT().Get(gomock.Any(), "key").Times(1).Return("value", nil)
+	_, err = breaker.Get(ctx, "key")
+	require.NoError(t, err)
+
+	require.Equal(t, BreakerStats{State: BreakerClosed, Opened: 1}, breaker.Stats())
+}
@@ -2,38 +2,136 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// Client is the subset of Redis the application relies on. Every call takes the context of the request it serves, so
+// cancellations and deadlines reach Redis.
 type Client interface {
-	Get(key string) (string, error)
-	Set(key string, value interface{}, ttl time.Duration) error
-	Del(key string) error
+	Get(ctx context.Context, key string) (string, error)
+	// GetDel reads and deletes the key in one command, so a single-use value is handed out at most once
+	GetDel(ctx context.Context, key string) (string, error)
+	// MGet returns one value per key, nil for the keys that do not exist
+	MGet(ctx context.Context, keys ...string) ([]interface{}, error)
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	// SetNX sets the key only when it does not exist yet and reports whether it did
+	SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error)
+	Del(ctx context.Context, keys ...string) error
+	// Incr increments the counter at key, starting from 0 when it does not exist
+	Incr(ctx context.Context, key string) (int64, error)
+	// Expire sets the ttl of the key and reports whether it exists
+	Expire(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Eval runs a Lua script atomically, it is sent by its SHA1 first so the script body is transferred only once
+	Eval(ctx context.Context, script *redis.Script, keys []string, args ...interface{}) (interface{}, error)
+	// Pipeline sends the commands queued by fn in one round trip
+	Pipeline(ctx context.Context, fn func(pipe redis.Pipeliner) error) ([]redis.Cmder, error)
+	Publish(ctx context.Context, channel string, message interface{}) error
+	// Ping checks that Redis answers, e.g. for the readiness probe
+	Ping(ctx context.Context) error
+	// Subscribe listens on the channels until the returned subscription is closed; it reconnects on its own
+	Subscribe(ctx context.Context, channels ...string) *redis.PubSub
 	Close() error
 }
 
 type Redis struct {
-	Rdb *redis.Client
+	Rdb redis.UniversalClient
+	// cluster is set when the keys of one command may live on different nodes, see NewUniversalClient
+	cluster bool
 }
 
-func NewRedisClient(redis *redis.Client) *Redis {
+func NewRedisClient(client redis.UniversalClient) *Redis {
+	_, cluster := client.(*redis.ClusterClient)
 	return &Redis{
-		Rdb: redis,
+		Rdb:     client,
+		cluster: cluster,
 	}
 }
 
-func (r *Redis) Get(key string) (string, error) {
-	return r.Rdb.Get(context.Background(), key).Result()
+func (r *Redis) Get(ctx context.Context, key string) (string, error) {
+	return r.Rdb.Get(ctx, key).Result()
 }
 
-func (r *Redis) Set(key string, value interface{}, ttl time.Duration) error {
-	return r.Rdb.Set(context.Background(), key, value, ttl).Err()
+func (r *Redis) GetDel(ctx context.Context, key string) (string, error) {
+	return r.Rdb.GetDel(ctx, key).Result()
 }
 
-func (r *Redis) Del(key string) error {
-	return r.Rdb.Del(context.Background(), key).Err()
+func (r *Redis) MGet(ctx context.Context, keys ...string) ([]interface{}, error) {
+	if !r.cluster || len(keys) < 2 {
+		return r.Rdb.MGet(ctx, keys...).Result()
+	}
+
+	// A cluster rejects multi-key commands spanning several hash slots, so every key is read on its own node
+	cmds := make([]*redis.StringCmd, len(keys))
+	_, err := r.Rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.Get(ctx, key)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	values := make([]interface{}, len(keys))
+	for i, cmd := range cmds {
+		if value, err := cmd.Result(); err == nil {
+			values[i] = value
+		}
+	}
+	return values, nil
+}
+
+func (r *Redis) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return r.Rdb.Set(ctx, key, value, ttl).Err()
+}
+
+func (r *Redis) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	return r.Rdb.SetNX(ctx, key, value, ttl).Result()
+}
+
+func (r *Redis) Del(ctx context.Context, keys ...string) error {
+	if !r.cluster || len(keys) < 2 {
+		return r.Rdb.Del(ctx, keys...).Err()
+	}
+
+	_, err := r.Rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Del(ctx, key)
+		}
+		return nil
+	})
+	return err
+}
+
+func (r *Redis) Incr(ctx context.Context, key string) (int64, error) {
+	return r.Rdb.Incr(ctx, key).Result()
+}
+
+func (r *Redis) Expire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return r.Rdb.Expire(ctx, key, ttl).Result()
+}
+
+func (r *Redis) Eval(ctx context.Context, script *redis.Script, keys []string, args ...interface{}) (interface{}, error) {
+	return script.Run(ctx, r.Rdb, keys, args...).Result()
+}
+
+func (r *Redis) Pipeline(ctx context.Context, fn func(pipe redis.Pipeliner) error) ([]redis.Cmder, error) {
+	return r.Rdb.Pipelined(ctx, fn)
+}
+
+func (r *Redis) Publish(ctx context.Context, channel string, message interface{}) error {
+	return r.Rdb.Publish(ctx, channel, message).Err()
+}
+
+func (r *Redis) Ping(ctx context.Context) error {
+	return r.Rdb.Ping(ctx).Err()
+}
+
+func (r *Redis) Subscribe(ctx context.Context, channels ...string) *redis.PubSub {
+	return r.Rdb.Subscribe(ctx, channels...)
 }
 
 func (r *Redis) Close() error {
@@ -0,0 +1,82 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T) (*Redis, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := NewRedisClient(redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1}))
+	t.Cleanup(func() { client.Close() })
+	return client, server
+}
+
+func TestClient(t *testing.T) {
+	client, server := newTestClient(t)
+	ctx := context.Background()
+
+	require.NoError(t, client.Set(ctx, "a", "1", time.Minute))
+	value, err := client.Get(ctx, "a")
+	require.NoError(t, err)
+	require.Equal(t, "1", value)
+
+	_, err = client.Get(ctx, "missing")
+	require.ErrorIs(t, err, redis.Nil)
+
+	require.NoError(t, client.Set(ctx, "once", "1", time.Minute))
+	value, err = client.GetDel(ctx, "once")
+	require.NoError(t, err)
+	require.Equal(t, "1", value)
+	_, err = client.GetDel(ctx, "once")
+	require.ErrorIs(t, err, redis.Nil)
+
+	values, err := client.MGet(ctx, "a", "missing")
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{"1", nil}, values)
+
+	set, err := client.SetNX(ctx, "a", "2", time.Minute)
+	require.NoError(t, err)
+	require.False(t, set)
+
+	count, err := client.Incr(ctx, "counter")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+	exists, err := client.Expire(ctx, "counter", time.Second)
+	require.NoError(t, err)
+	require.True(t, exists)
+	server.FastForward(2 * time.Second)
+	_, err = client.Get(ctx, "counter")
+	require.ErrorIs(t, err, redis.Nil)
+
+	result, err := client.Eval(ctx, redis.NewScript(`return redis.call('INCRBY', KEYS[1], ARGV[1])`), []string{"counter"}, 5)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), result)
+
+	cmds, err := client.Pipeline(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Incr(ctx, "counter")
+		pipe.Set(ctx, "b", "2", 0)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, cmds, 2)
+	require.Equal(t, int64(6), cmds[0].(*redis.IntCmd).Val())
+
+	require.NoError(t, client.Del(ctx, "a", "b"))
+	require.False(t, server.Exists("a"))
+	require.False(t, server.Exists("b"))
+}
+
+func TestClientHonorsContext(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := client.Get(ctx, "a")
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,84 @@
+package redis
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/config"
+	"github.com/redis/go-redis/v9"
+)
+
+// NewUniversalClient connects to Redis in the topology selected by REDIS_MODE. The returned client is a single node,
+// sentinel backed or cluster client, all of which can be wrapped by NewRedisClient. The latency of its commands is
+// recorded in metrics.RedisCommandDuration.
+func NewUniversalClient(config config.Config) (redis.UniversalClient, error) {
+	options, err := universalOptions(config)
+	if err != nil {
+		return nil, err
+	}
+	client := redis.NewUniversalClient(options)
+	client.AddHook(metricsHook{})
+	return client, nil
+}
+
+func universalOptions(config config.Config) (*redis.UniversalOptions, error) {
+	options := &redis.UniversalOptions{
+		Addrs:        splitAddresses(config.RedisAddress),
+		Username:     config.RedisUsername,
+		Password:     config.RedisPassword,
+		DB:           config.RedisDB,
+		PoolSize:     config.RedisPoolSize,
+		DialTimeout:  config.RedisDialTimeout,
+		ReadTimeout:  config.RedisReadTimeout,
+		WriteTimeout: config.RedisWriteTimeout,
+	}
+
+	switch config.RedisMode {
+	case "sentinel":
+		options.MasterName = config.RedisSentinelMaster
+		options.SentinelPassword = config.RedisSentinelPassword
+	case "cluster":
+		// A single seed address would otherwise be taken for a standalone server
+		options.IsClusterMode = true
+	}
+
+	if config.RedisTLS {
+		tlsConfig, err := newTLSConfig(config.RedisTLSCAFile, config.RedisTLSServerName)
+		if err != nil {
+			return nil, err
+		}
+		options.TLSConfig = tlsConfig
+	}
+	return options, nil
+}
+
+// newTLSConfig verifies the server against the CA in caFile, or the system roots when it is empty
+func newTLSConfig(caFile, serverName string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12, ServerName: serverName}
+	if caFile == "" {
+		return tlsConfig, nil
+	}
+
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Redis CA file: %w", err)
+	}
+	tlsConfig.RootCAs = x509.NewCertPool()
+	if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate found in Redis CA file %s", caFile)
+	}
+	return tlsConfig, nil
+}
+
+func splitAddresses(value string) []string {
+	var addresses []string
+	for _, address := range strings.Split(value, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
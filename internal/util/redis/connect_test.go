@@ -0,0 +1,111 @@
+package redis
+
+import (
+	"context"
+	"crypto/tls"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/hanifsyahsn/go_boilerplate/internal/config"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/mtls/mtlstest"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func redisConfig(mode, address string) config.Config {
+	return config.Config{
+		RedisMode:         mode,
+		RedisAddress:      address,
+		RedisPassword:     "secret",
+		RedisDialTimeout:  time.Second,
+		RedisReadTimeout:  time.Second,
+		RedisWriteTimeout: time.Second,
+	}
+}
+
+func TestUniversalOptions(t *testing.T) {
+	standalone := redisConfig("standalone", "localhost:6379")
+	standalone.RedisDB = 2
+	standalone.RedisPoolSize = 20
+	options, err := universalOptions(standalone)
+	require.NoError(t, err)
+	require.Equal(t, []string{"localhost:6379"}, options.Addrs)
+	require.Equal(t, 2, options.DB)
+	require.Equal(t, 20, options.PoolSize)
+	require.Equal(t, time.Second, options.ReadTimeout)
+	require.IsType(t, &redis.Client{}, redis.NewUniversalClient(options))
+
+	sentinel := redisConfig("sentinel", "sentinel-1:26379, sentinel-2:26379")
+	sentinel.RedisSentinelMaster = "primary"
+	sentinel.RedisSentinelPassword = "sentinel-secret"
+	options, err = universalOptions(sentinel)
+	require.NoError(t, err)
+	require.Equal(t, []string{"sentinel-1:26379", "sentinel-2:26379"}, options.Addrs)
+	require.Equal(t, "primary", options.MasterName)
+	require.Equal(t, "sentinel-secret", options.SentinelPassword)
+	require.Equal(t, "secret", options.Password)
+
+	options, err = universalOptions(redisConfig("cluster", "node-1:6379"))
+	require.NoError(t, err)
+	require.IsType(t, &redis.ClusterClient{}, redis.NewUniversalClient(options))
+}
+
+func TestNewUniversalClientTLS(t *testing.T) {
+	ca := mtlstest.NewCA(t, "Redis CA")
+	leaf := mtlstest.NewLeaf(t, ca, "redis", "redis.internal")
+	caFile, _ := ca.WriteFiles(t, t.TempDir(), "ca")
+	certFile, keyFile := leaf.WriteFiles(t, t.TempDir(), "redis")
+
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	require.NoError(t, err)
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.StartTLS(&tls.Config{Certificates: []tls.Certificate{certificate}}))
+	t.Cleanup(server.Close)
+
+	conf := redisConfig("standalone", server.Addr())
+	conf.RedisPassword = ""
+	conf.RedisTLS = true
+	conf.RedisTLSCAFile = caFile
+	conf.RedisTLSServerName = "redis.internal"
+
+	client, err := NewUniversalClient(conf)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	require.NoError(t, client.Ping(context.Background()).Err())
+
+	// The server name has to match the certificate
+	conf.RedisTLSServerName = "other.internal"
+	client, err = NewUniversalClient(conf)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	require.Error(t, client.Ping(context.Background()).Err())
+
+	conf.RedisTLSCAFile = certFile + ".missing"
+	_, err = NewUniversalClient(conf)
+	require.Error(t, err)
+}
+
+func TestClusterMultiKeyCommands(t *testing.T) {
+	server := miniredis.RunT(t)
+	conf := redisConfig("cluster", server.Addr())
+	conf.RedisPassword = ""
+
+	universal, err := NewUniversalClient(conf)
+	require.NoError(t, err)
+	client := NewRedisClient(universal)
+	t.Cleanup(func() { client.Close() })
+	require.True(t, client.cluster)
+
+	ctx := context.Background()
+	require.NoError(t, client.Set(ctx, "user:access:1:a", "jti-a", time.Minute))
+	require.NoError(t, client.Set(ctx, "user:access:1:b", "jti-b", time.Minute))
+
+	values, err := client.MGet(ctx, "user:access:1:a", "user:access:1:missing", "user:access:1:b")
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{"jti-a", nil, "jti-b"}, values)
+
+	require.NoError(t, client.Del(ctx, "user:access:1:a", "user:access:1:b"))
+	require.False(t, server.Exists("user:access:1:a"))
+	require.False(t, server.Exists("user:access:1:b"))
+}
@@ -0,0 +1,13 @@
+package redis
+
+import "strconv"
+
+// UserAccessKey is the key holding the jti of the latest access token issued for a user's session
+func UserAccessKey(userId int64, sessionId string) string {
+	return "user:access:" + strconv.FormatInt(userId, 10) + ":" + sessionId
+}
+
+// DPoPProofKey marks a DPoP proof jti as used so the proof cannot be replayed
+func DPoPProofKey(thumbprint, jti string) string {
+	return "dpop:jti:" + thumbprint + ":" + jti
+}
@@ -0,0 +1,47 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsHook(t *testing.T) {
+	server := miniredis.RunT(t)
+	conf := redisConfig("standalone", server.Addr())
+	conf.RedisPassword = ""
+
+	universal, err := NewUniversalClient(conf)
+	require.NoError(t, err)
+	client := NewRedisClient(universal)
+	t.Cleanup(func() { client.Close() })
+
+	// The handshake of the first connection is a pipeline as well
+	ctx := context.Background()
+	require.NoError(t, universal.Ping(ctx).Err())
+	setCount, pipelineCount := commandCount(t, "set"), commandCount(t, "pipeline")
+
+	require.NoError(t, client.Set(ctx, "user:access:1:a", "jti-a", time.Minute))
+	pipe := universal.Pipeline()
+	pipe.Get(ctx, "user:access:1:a")
+	pipe.Get(ctx, "user:access:1:b")
+	_, _ = pipe.Exec(ctx)
+
+	require.Equal(t, setCount+1, commandCount(t, "set"))
+	require.Equal(t, pipelineCount+1, commandCount(t, "pipeline"))
+}
+
+func commandCount(t *testing.T, command string) uint64 {
+	observer, err := metrics.RedisCommandDuration.GetMetricWithLabelValues(command)
+	require.NoError(t, err)
+
+	var metric dto.Metric
+	require.NoError(t, observer.(prometheus.Metric).Write(&metric))
+	return metric.GetHistogram().GetSampleCount()
+}
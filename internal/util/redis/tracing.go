@@ -0,0 +1,118 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/tracing"
+	"github.com/redis/go-redis/v9"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracedClient is a Client starting a span for every command. Keys are left out of the spans, some of them embed
+// email addresses.
+type TracedClient struct {
+	client Client
+}
+
+func NewTracedClient(client Client) *TracedClient {
+	return &TracedClient{client: client}
+}
+
+func (traced *TracedClient) Get(ctx context.Context, key string) (value string, err error) {
+	ctx, span := startCommand(ctx, "GET")
+	defer func() { endCommand(span, err) }()
+	return traced.client.Get(ctx, key)
+}
+
+func (traced *TracedClient) GetDel(ctx context.Context, key string) (value string, err error) {
+	ctx, span := startCommand(ctx, "GETDEL")
+	defer func() { endCommand(span, err) }()
+	return traced.client.GetDel(ctx, key)
+}
+
+func (traced *TracedClient) MGet(ctx context.Context, keys ...string) (values []interface{}, err error) {
+	ctx, span := startCommand(ctx, "MGET")
+	defer func() { endCommand(span, err) }()
+	return traced.client.MGet(ctx, keys...)
+}
+
+func (traced *TracedClient) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) (err error) {
+	ctx, span := startCommand(ctx, "SET")
+	defer func() { endCommand(span, err) }()
+	return traced.client.Set(ctx, key, value, ttl)
+}
+
+func (traced *TracedClient) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (set bool, err error) {
+	ctx, span := startCommand(ctx, "SETNX")
+	defer func() { endCommand(span, err) }()
+	return traced.client.SetNX(ctx, key, value, ttl)
+}
+
+func (traced *TracedClient) Del(ctx context.Context, keys ...string) (err error) {
+	ctx, span := startCommand(ctx, "DEL")
+	defer func() { endCommand(span, err) }()
+	return traced.client.Del(ctx, keys...)
+}
+
+func (traced *TracedClient) Incr(ctx context.Context, key string) (value int64, err error) {
+	ctx, span := startCommand(ctx, "INCR")
+	defer func() { endCommand(span, err) }()
+	return traced.client.Incr(ctx, key)
+}
+
+func (traced *TracedClient) Expire(ctx context.Context, key string, ttl time.Duration) (exists bool, err error) {
+	ctx, span := startCommand(ctx, "EXPIRE")
+	defer func() { endCommand(span, err) }()
+	return traced.client.Expire(ctx, key, ttl)
+}
+
+func (traced *TracedClient) Eval(ctx context.Context, script *redis.Script, keys []string, args ...interface{}) (result interface{}, err error) {
+	ctx, span := startCommand(ctx, "EVALSHA")
+	defer func() { endCommand(span, err) }()
+	return traced.client.Eval(ctx, script, keys, args...)
+}
+
+func (traced *TracedClient) Pipeline(ctx context.Context, fn func(pipe redis.Pipeliner) error) (cmds []redis.Cmder, err error) {
+	ctx, span := startCommand(ctx, "PIPELINE")
+	defer func() { endCommand(span, err) }()
+	return traced.client.Pipeline(ctx, fn)
+}
+
+func (traced *TracedClient) Publish(ctx context.Context, channel string, message interface{}) (err error) {
+	ctx, span := startCommand(ctx, "PUBLISH")
+	defer func() { endCommand(span, err) }()
+	return traced.client.Publish(ctx, channel, message)
+}
+
+func (traced *TracedClient) Ping(ctx context.Context) (err error) {
+	ctx, span := startCommand(ctx, "PING")
+	defer func() { endCommand(span, err) }()
+	return traced.client.Ping(ctx)
+}
+
+// Subscribe is not traced, the subscription lives as long as the server
+func (traced *TracedClient) Subscribe(ctx context.Context, channels ...string) *redis.PubSub {
+	return traced.client.Subscribe(ctx, channels...)
+}
+
+func (traced *TracedClient) Close() error {
+	return traced.client.Close()
+}
+
+func startCommand(ctx context.Context, command string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, command,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemNameRedis, semconv.DBOperationName(command)),
+	)
+}
+
+// endCommand does not count a missing key as an error
+func endCommand(span trace.Span, err error) {
+	if errors.Is(err, redis.Nil) {
+		err = nil
+	}
+	tracing.End(span, err)
+}
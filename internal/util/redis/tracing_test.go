@@ -0,0 +1,44 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/tracing/tracingtest"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracedClient(t *testing.T) {
+	exporter := tracingtest.NewExporter(t)
+	ctrl := gomock.NewController(t)
+	client := NewMockClient(ctrl)
+	traced := NewTracedClient(client)
+
+	ctx := context.Background()
+	down := errors.New("connection refused")
+
+	client.EXPECT().Get(gomock.Any(), "missing").Times(1).Return("", redis.Nil)
+	client.EXPECT().Set(gomock.Any(), "key", "value", gomock.Any()).Times(1).Return(down)
+
+	_, err := traced.Get(ctx, "missing")
+	require.ErrorIs(t, err, redis.Nil)
+	require.ErrorIs(t, traced.Set(ctx, "key", "value", 0), down)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+
+	require.Equal(t, "GET", spans[0].Name)
+	require.Equal(t, trace.SpanKindClient, spans[0].SpanKind)
+	require.Contains(t, spans[0].Attributes, semconv.DBSystemNameRedis)
+	require.Equal(t, codes.Unset, spans[0].Status.Code)
+
+	require.Equal(t, "SET", spans[1].Name)
+	require.Equal(t, codes.Error, spans[1].Status.Code)
+	require.Equal(t, down.Error(), spans[1].Status.Description)
+}
@@ -0,0 +1,79 @@
+// Package requestid identifies requests across the client, the logs and the audit trail. The request ID comes from
+// the X-Request-ID header and the trace context from the W3C traceparent header, both are generated when the client
+// sent none.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	Header            = "X-Request-ID"
+	TraceparentHeader = "traceparent"
+)
+
+// maxLength bounds the client supplied IDs copied into logs and audit entries
+const maxLength = 128
+
+var (
+	idPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]+$`)
+	// version-trace_id-parent_id-flags, see https://www.w3.org/TR/trace-context/#traceparent-header
+	traceparentPattern = regexp.MustCompile(`^([0-9a-f]{2})-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})$`)
+)
+
+type contextKey struct{}
+
+// IDs identify a request and the trace it is part of
+type IDs struct {
+	RequestID string
+	// TraceID is shared by every service the request passed through
+	TraceID string
+	// SpanID identifies the handling of the request by this server, it is the parent of the calls it makes
+	SpanID string
+	Flags  string
+}
+
+// New keeps the IDs sent by the client when they are well formed and generates the others. The request continues the
+// trace of traceparent as a new span.
+func New(requestId, traceparent string) IDs {
+	ids := IDs{RequestID: requestId, SpanID: randomHex(8), Flags: "01"}
+	if len(requestId) > maxLength || !idPattern.MatchString(requestId) {
+		ids.RequestID = uuid.NewString()
+	}
+
+	matches := traceparentPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(traceparent)))
+	if matches != nil && matches[1] != "ff" && strings.Trim(matches[2], "0") != "" && strings.Trim(matches[3], "0") != "" {
+		ids.TraceID, ids.Flags = matches[2], matches[4]
+	} else {
+		ids.TraceID = randomHex(16)
+	}
+	return ids
+}
+
+// Traceparent formats the trace context to pass on to the next service, and back to the client
+func (ids IDs) Traceparent() string {
+	return "00-" + ids.TraceID + "-" + ids.SpanID + "-" + ids.Flags
+}
+
+// WithIDs returns a copy of ctx carrying the IDs
+func WithIDs(ctx context.Context, ids IDs) context.Context {
+	return context.WithValue(ctx, contextKey{}, ids)
+}
+
+// FromContext returns the IDs stored by WithIDs, or the zero IDs
+func FromContext(ctx context.Context) IDs {
+	ids, _ := ctx.Value(contextKey{}).(IDs)
+	return ids
+}
+
+func randomHex(size int) string {
+	b := make([]byte, size)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,49 @@
+package requestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	ids := New("req-1", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	require.Equal(t, "req-1", ids.RequestID)
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", ids.TraceID)
+	require.Len(t, ids.SpanID, 16)
+	require.NotEqual(t, "00f067aa0ba902b7", ids.SpanID)
+	require.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-"+ids.SpanID+"-00", ids.Traceparent())
+
+	testCases := []struct {
+		name        string
+		requestId   string
+		traceparent string
+	}{
+		{"missing", "", ""},
+		{"too long", strings.Repeat("a", maxLength+1), "00-4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"log injection", "req-1\nlevel=ERROR", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7"},
+		{"zero trace id", "bad id", "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		{"invalid version", "", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ids := New(testCase.requestId, testCase.traceparent)
+			_, err := uuid.Parse(ids.RequestID)
+			require.NoError(t, err)
+			require.Len(t, ids.TraceID, 32)
+			require.NotEqual(t, "4bf92f3577b34da6a3ce929d0e0e4736", ids.TraceID)
+			require.Regexp(t, traceparentPattern, ids.Traceparent())
+		})
+	}
+}
+
+func TestContext(t *testing.T) {
+	require.Equal(t, IDs{}, FromContext(context.Background()))
+
+	ids := New("", "")
+	require.Equal(t, ids, FromContext(WithIDs(context.Background(), ids)))
+}
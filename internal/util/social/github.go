@@ -0,0 +1,113 @@
+package social
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/pkce"
+)
+
+// GitHub does not issue ID tokens for user sign-in, so its identity comes from the REST API instead
+type GitHubConfig struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	// Overridable for tests
+	AuthorizeURL string
+	TokenURL     string
+	APIURL       string
+}
+
+type GitHubProvider struct {
+	config GitHubConfig
+}
+
+func NewGitHubProvider(config GitHubConfig) *GitHubProvider {
+	if config.AuthorizeURL == "" {
+		config.AuthorizeURL = "https://github.com/login/oauth/authorize"
+	}
+	if config.TokenURL == "" {
+		config.TokenURL = "https://github.com/login/oauth/access_token"
+	}
+	if config.APIURL == "" {
+		config.APIURL = "https://api.github.com"
+	}
+	return &GitHubProvider{config: config}
+}
+
+func (p *GitHubProvider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	params := url.Values{
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {"read:user user:email"},
+		"state":                 {state},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {pkce.MethodS256},
+	}
+	return p.config.AuthorizeURL + "?" + params.Encode(), nil
+}
+
+func (p *GitHubProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (Identity, error) {
+	form := url.Values{
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"client_id":     {p.config.ClientID},
+		"client_secret": {p.config.ClientSecret},
+		"code_verifier": {codeVerifier},
+	}
+
+	var tokenResponse struct {
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error"`
+	}
+	if err := postForm(ctx, p.config.TokenURL, form, &tokenResponse); err != nil {
+		return Identity{}, err
+	}
+	if tokenResponse.AccessToken == "" {
+		return Identity{}, errors.New("github token exchange failed: " + tokenResponse.Error)
+	}
+
+	var user struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	if err := p.getAPI(ctx, "/user", tokenResponse.AccessToken, &user); err != nil {
+		return Identity{}, err
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := p.getAPI(ctx, "/user/emails", tokenResponse.AccessToken, &emails); err != nil {
+		return Identity{}, err
+	}
+
+	identity := Identity{Subject: strconv.FormatInt(user.ID, 10), Name: user.Name}
+	if identity.Name == "" {
+		identity.Name = user.Login
+	}
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = email.Email
+			identity.EmailVerified = email.Verified
+		}
+	}
+
+	return identity, nil
+}
+
+func (p *GitHubProvider) getAPI(ctx context.Context, path, accessToken string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.config.APIURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	return doJSON(req, out)
+}
@@ -0,0 +1,148 @@
+package social_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/pkce"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/social"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/social/socialtest"
+	"github.com/stretchr/testify/require"
+)
+
+const codeVerifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+
+func TestOIDCProviderAuthCodeURL(t *testing.T) {
+	fake := socialtest.NewFakeProvider(t, "client")
+	provider := fake.Provider()
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce", pkce.S256CodeChallenge(codeVerifier))
+	require.NoError(t, err)
+
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	require.Equal(t, fake.Issuer()+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	require.Equal(t, "nonce", u.Query().Get("nonce"))
+	require.Equal(t, "S256", u.Query().Get("code_challenge_method"))
+}
+
+func TestOIDCProviderExchange(t *testing.T) {
+	identity := social.Identity{Subject: "1234", Email: "user@example.com", EmailVerified: true, Name: "User"}
+
+	testCases := []struct {
+		name          string
+		grant         socialtest.Grant
+		nonce         string
+		checkResponse func(t *testing.T, got social.Identity, err error)
+	}{
+		{
+			name:  "success",
+			grant: socialtest.Grant{Identity: identity, Nonce: "n1", CodeChallenge: pkce.S256CodeChallenge(codeVerifier)},
+			nonce: "n1",
+			checkResponse: func(t *testing.T, got social.Identity, err error) {
+				require.NoError(t, err)
+				require.Equal(t, identity, got)
+			},
+		},
+		{
+			name:  "nonce mismatch",
+			grant: socialtest.Grant{Identity: identity, Nonce: "n1"},
+			nonce: "n2",
+			checkResponse: func(t *testing.T, got social.Identity, err error) {
+				require.ErrorContains(t, err, "nonce")
+			},
+		},
+		{
+			name: "wrong audience",
+			grant: socialtest.Grant{Identity: identity, Nonce: "n1", Tamper: func(claims jwt.MapClaims) {
+				claims["aud"] = "another-client"
+			}},
+			nonce: "n1",
+			checkResponse: func(t *testing.T, got social.Identity, err error) {
+				require.ErrorIs(t, err, jwt.ErrTokenInvalidAudience)
+			},
+		},
+		{
+			name: "wrong issuer",
+			grant: socialtest.Grant{Identity: identity, Nonce: "n1", Tamper: func(claims jwt.MapClaims) {
+				claims["iss"] = "https://evil.example.com"
+			}},
+			nonce: "n1",
+			checkResponse: func(t *testing.T, got social.Identity, err error) {
+				require.ErrorContains(t, err, "issuer")
+			},
+		},
+		{
+			name: "expired",
+			grant: socialtest.Grant{Identity: identity, Nonce: "n1", Tamper: func(claims jwt.MapClaims) {
+				claims["exp"] = time.Now().Add(-time.Minute).Unix()
+			}},
+			nonce: "n1",
+			checkResponse: func(t *testing.T, got social.Identity, err error) {
+				require.ErrorIs(t, err, jwt.ErrTokenExpired)
+			},
+		},
+		{
+			name:  "wrong code verifier",
+			grant: socialtest.Grant{Identity: identity, Nonce: "n1", CodeChallenge: pkce.S256CodeChallenge("something-else")},
+			nonce: "n1",
+			checkResponse: func(t *testing.T, got social.Identity, err error) {
+				require.ErrorContains(t, err, "400")
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			fake := socialtest.NewFakeProvider(t, "client")
+			code := fake.IssueCode(testCase.grant)
+
+			got, err := fake.Provider().Exchange(context.Background(), code, codeVerifier, testCase.nonce)
+			testCase.checkResponse(t, got, err)
+		})
+	}
+}
+
+func TestOIDCProviderExchangeMicrosoft(t *testing.T) {
+	identity := social.Identity{Subject: "AAAAAAAAAAAAAAAAAAAAAIkzqFVrSaSaFHy782bbtaQ", Email: "user@contoso.com", Name: "User"}
+
+	// Microsoft v2 ID tokens carry the tenant and never email_verified
+	microsoft := func(edov interface{}) func(claims jwt.MapClaims) {
+		return func(claims jwt.MapClaims) {
+			delete(claims, "email_verified")
+			claims["tid"] = "9188040d-6c67-4c5b-b112-36a304b66dad"
+			claims["ver"] = "2.0"
+			if edov != nil {
+				claims[social.MicrosoftEmailVerifiedClaim] = edov
+			}
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		tamper        func(claims jwt.MapClaims)
+		emailVerified bool
+	}{
+		{name: "domain owned by the tenant", tamper: microsoft(true), emailVerified: true},
+		{name: "domain not owned by the tenant", tamper: microsoft(false), emailVerified: false},
+		{name: "optional claim not configured", tamper: microsoft(nil), emailVerified: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			fake := socialtest.NewFakeProvider(t, "client")
+			code := fake.IssueCode(socialtest.Grant{Identity: identity, Nonce: "n1", Tamper: testCase.tamper})
+
+			config := fake.Config()
+			config.EmailVerifiedClaim = social.MicrosoftEmailVerifiedClaim
+			got, err := social.NewOIDCProvider(config).Exchange(context.Background(), code, codeVerifier, "n1")
+			require.NoError(t, err)
+			require.Equal(t, identity.Subject, got.Subject)
+			require.Equal(t, identity.Email, got.Email)
+			require.Equal(t, testCase.emailVerified, got.EmailVerified)
+		})
+	}
+}
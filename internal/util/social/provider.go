@@ -0,0 +1,71 @@
+package social
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/config"
+)
+
+const (
+	ProviderGoogle    = "google"
+	ProviderGitHub    = "github"
+	ProviderMicrosoft = "microsoft"
+)
+
+// Identity is the subset of the external account the relying party cares about
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type Provider interface {
+	// AuthCodeURL builds the URL the browser is sent to. The code challenge is the S256 PKCE challenge.
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	// Exchange redeems the authorization code and returns the verified identity of the user
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (Identity, error)
+}
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// NewProviders builds every provider that has a client ID configured
+func NewProviders(conf config.Config) map[string]Provider {
+	providers := make(map[string]Provider)
+
+	if conf.GoogleClientID != "" {
+		providers[ProviderGoogle] = NewOIDCProvider(OIDCConfig{
+			Issuer:       "https://accounts.google.com",
+			ClientID:     conf.GoogleClientID,
+			ClientSecret: conf.GoogleClientSecret,
+			RedirectURL:  CallbackURL(conf.PublicURL, ProviderGoogle),
+		})
+	}
+
+	if conf.MicrosoftClientID != "" {
+		providers[ProviderMicrosoft] = NewOIDCProvider(OIDCConfig{
+			Issuer:             "https://login.microsoftonline.com/" + conf.MicrosoftTenant + "/v2.0",
+			ClientID:           conf.MicrosoftClientID,
+			ClientSecret:       conf.MicrosoftClientSecret,
+			RedirectURL:        CallbackURL(conf.PublicURL, ProviderMicrosoft),
+			EmailVerifiedClaim: MicrosoftEmailVerifiedClaim,
+		})
+	}
+
+	if conf.GitHubClientID != "" {
+		providers[ProviderGitHub] = NewGitHubProvider(GitHubConfig{
+			ClientID:     conf.GitHubClientID,
+			ClientSecret: conf.GitHubClientSecret,
+			RedirectURL:  CallbackURL(conf.PublicURL, ProviderGitHub),
+		})
+	}
+
+	return providers
+}
+
+func CallbackURL(publicURL, provider string) string {
+	return strings.TrimSuffix(publicURL, "/") + "/auth/social/" + provider + "/callback"
+}
@@ -0,0 +1,161 @@
+// Package socialtest provides an in-process OpenID Connect provider for tests.
+package socialtest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/pkce"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/social"
+)
+
+const keyId = "fake-key"
+
+// Grant describes what the fake provider returns when an authorization code is redeemed
+type Grant struct {
+	Identity      social.Identity
+	Nonce         string
+	CodeChallenge string
+	// Tamper lets a test corrupt the ID token claims before signing
+	Tamper func(claims jwt.MapClaims)
+}
+
+type FakeProvider struct {
+	Server   *httptest.Server
+	ClientID string
+
+	key   *rsa.PrivateKey
+	mu    sync.Mutex
+	codes map[string]Grant
+	count int
+}
+
+func NewFakeProvider(t testing.TB, clientID string) *FakeProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &FakeProvider{ClientID: clientID, key: key, codes: make(map[string]Grant)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", fake.discovery)
+	mux.HandleFunc("/jwks", fake.jwks)
+	mux.HandleFunc("/token", fake.token)
+	fake.Server = httptest.NewServer(mux)
+	t.Cleanup(fake.Server.Close)
+
+	return fake
+}
+
+func (fake *FakeProvider) Issuer() string {
+	return fake.Server.URL
+}
+
+// Provider returns a relying party configured against this fake
+func (fake *FakeProvider) Provider() *social.OIDCProvider {
+	return social.NewOIDCProvider(fake.Config())
+}
+
+// Config is the relying party configuration of Provider, for tests that need to adjust it
+func (fake *FakeProvider) Config() social.OIDCConfig {
+	return social.OIDCConfig{
+		Issuer:       fake.Issuer(),
+		ClientID:     fake.ClientID,
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost/callback",
+	}
+}
+
+// IssueCode registers a grant and returns the authorization code that redeems it
+func (fake *FakeProvider) IssueCode(grant Grant) string {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	fake.count++
+	code := "code-" + big.NewInt(int64(fake.count)).String()
+	fake.codes[code] = grant
+	return code
+}
+
+func (fake *FakeProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]string{
+		"issuer":                 fake.Issuer(),
+		"authorization_endpoint": fake.Issuer() + "/authorize",
+		"token_endpoint":         fake.Issuer() + "/token",
+		"jwks_uri":               fake.Issuer() + "/jwks",
+	})
+}
+
+func (fake *FakeProvider) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": keyId,
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(fake.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(fake.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (fake *FakeProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	fake.mu.Lock()
+	grant, ok := fake.codes[r.PostForm.Get("code")]
+	delete(fake.codes, r.PostForm.Get("code"))
+	fake.mu.Unlock()
+
+	if !ok || r.PostForm.Get("client_id") != fake.ClientID {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+	if grant.CodeChallenge != "" && pkce.S256CodeChallenge(r.PostForm.Get("code_verifier")) != grant.CodeChallenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            fake.Issuer(),
+		"sub":            grant.Identity.Subject,
+		"aud":            fake.ClientID,
+		"exp":            now.Add(time.Hour).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          grant.Nonce,
+		"email":          grant.Identity.Email,
+		"email_verified": grant.Identity.EmailVerified,
+		"name":           grant.Identity.Name,
+	}
+	if grant.Tamper != nil {
+		grant.Tamper(claims)
+	}
+
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	idToken.Header["kid"] = keyId
+	signed, err := idToken.SignedString(fake.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, map[string]string{"access_token": "fake-access-token", "token_type": "Bearer", "id_token": signed})
+}
+
+func writeJSON(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,87 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const KeyIdHeader = "kid"
+
+// ECPublicJWK encodes a P-256 public key as a JSON Web Key (RFC 7517).
+func ECPublicJWK(publicKey *ecdsa.PublicKey) map[string]interface{} {
+	x, y := ecCoordinates(publicKey)
+	return map[string]interface{}{
+		"kty": "EC",
+		"crv": "P-256",
+		"x":   x,
+		"y":   y,
+		"use": "sig",
+		"alg": jwt.SigningMethodES256.Alg(),
+		"kid": ECThumbprint(publicKey),
+	}
+}
+
+// ECThumbprint returns the RFC 7638 SHA-256 thumbprint of a P-256 public key.
+func ECThumbprint(publicKey *ecdsa.PublicKey) string {
+	x, y := ecCoordinates(publicKey)
+	// Members must be in lexicographic order, which encoding/json guarantees for structs declared in that order
+	canonical, _ := json.Marshal(struct {
+		Crv string `json:"crv"`
+		Kty string `json:"kty"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}{Crv: "P-256", Kty: "EC", X: x, Y: y})
+
+	sum := sha256.Sum256(canonical)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// ECPublicKeyFromJWK decodes a P-256 public JSON Web Key. Keys carrying the private "d" member are rejected.
+func ECPublicKeyFromJWK(jwk map[string]interface{}) (*ecdsa.PublicKey, error) {
+	if kty, _ := jwk["kty"].(string); kty != "EC" {
+		return nil, fmt.Errorf("unsupported key type %v", jwk["kty"])
+	}
+	if crv, _ := jwk["crv"].(string); crv != "P-256" {
+		return nil, fmt.Errorf("unsupported curve %v", jwk["crv"])
+	}
+	if _, ok := jwk["d"]; ok {
+		return nil, fmt.Errorf("key must not contain private key material")
+	}
+
+	x, err := jwkCoordinate(jwk, "x")
+	if err != nil {
+		return nil, err
+	}
+	y, err := jwkCoordinate(jwk, "y")
+	if err != nil {
+		return nil, err
+	}
+
+	point := append([]byte{0x04}, x...)
+	return ecdsa.ParseUncompressedPublicKey(elliptic.P256(), append(point, y...))
+}
+
+func jwkCoordinate(jwk map[string]interface{}, name string) ([]byte, error) {
+	encoded, _ := jwk[name].(string)
+	coordinate, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(coordinate) != 32 {
+		return nil, fmt.Errorf("invalid %v coordinate", name)
+	}
+	return coordinate, nil
+}
+
+func ecCoordinates(publicKey *ecdsa.PublicKey) (x, y string) {
+	ecdhKey, err := publicKey.ECDH()
+	if err != nil {
+		return "", ""
+	}
+	// Uncompressed point encoding: 0x04 || X || Y, each coordinate padded to 32 bytes for P-256
+	point := ecdhKey.Bytes()
+	return base64.RawURLEncoding.EncodeToString(point[1:33]), base64.RawURLEncoding.EncodeToString(point[33:])
+}
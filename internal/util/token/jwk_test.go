@@ -0,0 +1,79 @@
+package token
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignClaimsES256(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	maker := NewTokenMakerES256(privateKey, &privateKey.PublicKey, conf.TokenIssuer)
+	require.Equal(t, "ES256", maker.Algorithm())
+
+	signed, err := maker.SignClaims(context.Background(), jwt.MapClaims{constant.SubKey: "1", constant.AudienceKey: "client"})
+	require.NoError(t, err)
+
+	parsed, err := jwt.Parse(signed, func(token *jwt.Token) (interface{}, error) {
+		return &privateKey.PublicKey, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, conf.TokenIssuer, parsed.Claims.(jwt.MapClaims)[constant.IssuerKey])
+
+	keys := maker.JWKS()
+	require.Len(t, keys, 1)
+	require.Equal(t, parsed.Header[KeyIdHeader], keys[0]["kid"])
+	require.Equal(t, "EC", keys[0]["kty"])
+	require.Len(t, keys[0]["x"], 43)
+	require.Len(t, keys[0]["y"], 43)
+}
+
+func TestCheckKeys(t *testing.T) {
+	require.NoError(t, CheckKeys(context.Background(), NewTokenMakerHS256(conf.JWTSecretKey, conf.TokenIssuer)))
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	require.NoError(t, CheckKeys(context.Background(), NewTokenMakerES256(privateKey, &privateKey.PublicKey, conf.TokenIssuer)))
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	require.Error(t, CheckKeys(context.Background(), NewTokenMakerES256(privateKey, &otherKey.PublicKey, conf.TokenIssuer)))
+}
+
+func TestJWKSHS256(t *testing.T) {
+	maker := NewTokenMakerHS256(conf.JWTSecretKey, conf.TokenIssuer)
+	require.Empty(t, maker.JWKS())
+
+	signed, err := maker.SignClaims(context.Background(), jwt.MapClaims{constant.SubKey: "1"})
+	require.NoError(t, err)
+	require.NotEmpty(t, signed)
+}
+
+func TestECPublicKeyFromJWK(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwk := ECPublicJWK(&privateKey.PublicKey)
+	publicKey, err := ECPublicKeyFromJWK(jwk)
+	require.NoError(t, err)
+	require.True(t, publicKey.Equal(&privateKey.PublicKey))
+	require.Equal(t, jwk["kid"], ECThumbprint(publicKey))
+
+	jwk["d"] = "private"
+	_, err = ECPublicKeyFromJWK(jwk)
+	require.Error(t, err)
+
+	_, err = ECPublicKeyFromJWK(map[string]interface{}{"kty": "RSA", "n": "AQAB", "e": "AQAB"})
+	require.Error(t, err)
+
+	_, err = ECPublicKeyFromJWK(map[string]interface{}{"kty": "EC", "crv": "P-256", "x": "AAAA", "y": "AAAA"})
+	require.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package token
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"crypto/x509"
 	"encoding/pem"
@@ -25,7 +26,9 @@ func NewTokenMakerES256(privateKey *ecdsa.PrivateKey, publicKey *ecdsa.PublicKey
 }
 
 func (maker *MakerES256) CreateToken(
+	ctx context.Context,
 	user sqlc.User,
+	session Session,
 	accessTokenDuration,
 	RefreshTokenDuration time.Duration,
 ) (
@@ -35,6 +38,10 @@ func (maker *MakerES256) CreateToken(
 	refreshPayload jwt.MapClaims,
 	err error,
 ) {
+	if session.AuthTime == 0 {
+		session.AuthTime = time.Now().Unix()
+	}
+
 	accessClaims := jwt.MapClaims{
 		constant.SubKey:            user.ID,
 		constant.EmailKey:          user.Email,
@@ -43,9 +50,9 @@ func (maker *MakerES256) CreateToken(
 		constant.IssuedAtKey:       time.Now().Unix(),
 		constant.JsonWebTokenIdKey: uuid.New().String(),
 	}
+	session.apply(accessClaims)
 
-	accessJwt := jwt.NewWithClaims(jwt.SigningMethodES256, accessClaims)
-	accessToken, err = accessJwt.SignedString(maker.privateKey)
+	accessToken, err = sign(ctx, jwt.NewWithClaims(jwt.SigningMethodES256, accessClaims), maker.privateKey)
 	if err != nil {
 		return "", "", jwt.MapClaims{}, jwt.MapClaims{}, err
 	}
@@ -58,9 +65,9 @@ func (maker *MakerES256) CreateToken(
 		constant.IssuedAtKey:       time.Now().Unix(),
 		constant.JsonWebTokenIdKey: uuid.New().String(),
 	}
+	session.apply(refreshClaims)
 
-	refreshJwt := jwt.NewWithClaims(jwt.SigningMethodES256, refreshClaims)
-	refreshToken, err = refreshJwt.SignedString(maker.privateKey)
+	refreshToken, err = sign(ctx, jwt.NewWithClaims(jwt.SigningMethodES256, refreshClaims), maker.privateKey)
 	if err != nil {
 		return "", "", jwt.MapClaims{}, jwt.MapClaims{}, err
 	}
@@ -93,7 +100,7 @@ func (maker *MakerES256) VerifyToken(tokenString string) (*jwt.Token, jwt.MapCla
 	return nil, nil, jwt.ErrSignatureInvalid
 }
 
-func (maker *MakerES256) RefreshToken(email string, userId int64, accessTokenDuration time.Duration, jti string) (accessToken string, err error) {
+func (maker *MakerES256) RefreshToken(ctx context.Context, email string, userId int64, session Session, accessTokenDuration time.Duration, jti string) (accessToken string, err error) {
 
 	accessClaims := jwt.MapClaims{
 		constant.SubKey:            userId,
@@ -103,9 +110,9 @@ func (maker *MakerES256) RefreshToken(email string, userId int64, accessTokenDur
 		constant.IssuedAtKey:       time.Now().Unix(),
 		constant.JsonWebTokenIdKey: jti,
 	}
+	session.apply(accessClaims)
 
-	accessJwt := jwt.NewWithClaims(jwt.SigningMethodES256, accessClaims)
-	accessToken, err = accessJwt.SignedString(maker.privateKey)
+	accessToken, err = sign(ctx, jwt.NewWithClaims(jwt.SigningMethodES256, accessClaims), maker.privateKey)
 	if err != nil {
 		return "", err
 	}
@@ -113,6 +120,22 @@ func (maker *MakerES256) RefreshToken(email string, userId int64, accessTokenDur
 	return
 }
 
+func (maker *MakerES256) SignClaims(ctx context.Context, claims jwt.MapClaims) (signed string, err error) {
+	claims[constant.IssuerKey] = maker.issuer
+
+	signedJwt := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	signedJwt.Header[KeyIdHeader] = ECThumbprint(maker.publicKey)
+	return sign(ctx, signedJwt, maker.privateKey)
+}
+
+func (maker *MakerES256) Algorithm() string {
+	return jwt.SigningMethodES256.Alg()
+}
+
+func (maker *MakerES256) JWKS() []map[string]interface{} {
+	return []map[string]interface{}{ECPublicJWK(maker.publicKey)}
+}
+
 func LoadECPrivateKey(path string) (*ecdsa.PrivateKey, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -1,6 +1,7 @@
 package token
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -21,7 +22,7 @@ func TestJWTES256(t *testing.T) {
 
 	token := NewTokenMakerES256(privateKey, publicKey, conf.TokenIssuer)
 
-	accessToken, refreshToken, accessTokenClaims, refreshTokenClaims, err := token.CreateToken(user, conf.AccessTokenDuration, conf.RefreshTokenDuration)
+	accessToken, refreshToken, accessTokenClaims, refreshTokenClaims, err := token.CreateToken(context.Background(), user, Session{}, conf.AccessTokenDuration, conf.RefreshTokenDuration)
 	require.NoError(t, err)
 	require.NotEmpty(t, accessToken)
 	require.NotEmpty(t, refreshToken)
@@ -64,7 +65,7 @@ func TestRefreshTokenES256(t *testing.T) {
 	userId := int64(1)
 	jti, _ := uuid.NewRandom()
 
-	accessToken, err := token.RefreshToken(email, userId, conf.AccessTokenDuration, jti.String())
+	accessToken, err := token.RefreshToken(context.Background(), email, userId, Session{}, conf.AccessTokenDuration, jti.String())
 
 	accessJwtToken, accessClaims, err := token.VerifyToken(accessToken)
 	require.NoError(t, err)
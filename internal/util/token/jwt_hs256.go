@@ -1,6 +1,7 @@
 package token
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -20,7 +21,9 @@ func NewTokenMakerHS256(secretKey, issuer string) Maker {
 }
 
 func (maker *MakerHS256) CreateToken(
+	ctx context.Context,
 	user sqlc.User,
+	session Session,
 	accessTokenDuration,
 	RefreshTokenDuration time.Duration,
 ) (
@@ -30,6 +33,10 @@ func (maker *MakerHS256) CreateToken(
 	refreshPayload jwt.MapClaims,
 	err error,
 ) {
+	if session.AuthTime == 0 {
+		session.AuthTime = time.Now().Unix()
+	}
+
 	accessClaims := jwt.MapClaims{
 		constant.SubKey:            user.ID,
 		constant.EmailKey:          user.Email,
@@ -38,9 +45,9 @@ func (maker *MakerHS256) CreateToken(
 		constant.IssuedAtKey:       time.Now().Unix(),
 		constant.JsonWebTokenIdKey: uuid.New().String(),
 	}
+	session.apply(accessClaims)
 
-	accessJwt := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	accessToken, err = accessJwt.SignedString([]byte(maker.secretKey))
+	accessToken, err = sign(ctx, jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims), []byte(maker.secretKey))
 	if err != nil {
 		return "", "", jwt.MapClaims{}, jwt.MapClaims{}, err
 	}
@@ -53,9 +60,9 @@ func (maker *MakerHS256) CreateToken(
 		constant.IssuedAtKey:       time.Now().Unix(),
 		constant.JsonWebTokenIdKey: uuid.New().String(),
 	}
+	session.apply(refreshClaims)
 
-	refreshJwt := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	refreshToken, err = refreshJwt.SignedString([]byte(maker.secretKey))
+	refreshToken, err = sign(ctx, jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims), []byte(maker.secretKey))
 	if err != nil {
 		return "", "", jwt.MapClaims{}, jwt.MapClaims{}, err
 	}
@@ -87,7 +94,7 @@ func (maker *MakerHS256) VerifyToken(tokenString string) (*jwt.Token, jwt.MapCla
 	return nil, nil, jwt.ErrSignatureInvalid
 }
 
-func (maker *MakerHS256) RefreshToken(email string, userId int64, accessTokenDuration time.Duration, jti string) (accessToken string, err error) {
+func (maker *MakerHS256) RefreshToken(ctx context.Context, email string, userId int64, session Session, accessTokenDuration time.Duration, jti string) (accessToken string, err error) {
 	accessClaims := jwt.MapClaims{
 		constant.SubKey:            userId,
 		constant.EmailKey:          email,
@@ -96,12 +103,27 @@ func (maker *MakerHS256) RefreshToken(email string, userId int64, accessTokenDur
 		constant.IssuedAtKey:       time.Now().Unix(),
 		constant.JsonWebTokenIdKey: jti,
 	}
+	session.apply(accessClaims)
 
-	accessJwt := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	accessToken, err = accessJwt.SignedString([]byte(maker.secretKey))
+	accessToken, err = sign(ctx, jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims), []byte(maker.secretKey))
 	if err != nil {
 		return "", err
 	}
 
 	return
 }
+
+func (maker *MakerHS256) SignClaims(ctx context.Context, claims jwt.MapClaims) (signed string, err error) {
+	claims[constant.IssuerKey] = maker.issuer
+
+	return sign(ctx, jwt.NewWithClaims(jwt.SigningMethodHS256, claims), []byte(maker.secretKey))
+}
+
+func (maker *MakerHS256) Algorithm() string {
+	return jwt.SigningMethodHS256.Alg()
+}
+
+func (maker *MakerHS256) JWKS() []map[string]interface{} {
+	// The shared secret must never be published
+	return []map[string]interface{}{}
+}
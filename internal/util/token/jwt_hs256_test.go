@@ -1,12 +1,17 @@
 package token
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/hanifsyahsn/go_boilerplate/internal/factory/userfactory"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/tracing"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/tracing/tracingtest"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 func TestJWTHS256(t *testing.T) {
@@ -15,7 +20,7 @@ func TestJWTHS256(t *testing.T) {
 	user := userfactory.NewOptions(nil)
 
 	//noinspection DuplicatedCode
-	accessToken, refreshToken, accessTokenClaims, refreshTokenClaims, err := token.CreateToken(user, conf.AccessTokenDuration, conf.RefreshTokenDuration)
+	accessToken, refreshToken, accessTokenClaims, refreshTokenClaims, err := token.CreateToken(context.Background(), user, Session{}, conf.AccessTokenDuration, conf.RefreshTokenDuration)
 	require.NoError(t, err)
 	require.NotEmpty(t, accessToken)
 	require.NotEmpty(t, refreshToken)
@@ -50,7 +55,7 @@ func TestRefreshTokenHS256(t *testing.T) {
 	jti, _ := uuid.NewRandom()
 
 	//noinspection DuplicatedCode
-	accessToken, err := token.RefreshToken(email, userId, conf.AccessTokenDuration, jti.String())
+	accessToken, err := token.RefreshToken(context.Background(), email, userId, Session{}, conf.AccessTokenDuration, jti.String())
 
 	accessJwtToken, accessClaims, err := token.VerifyToken(accessToken)
 	require.NoError(t, err)
@@ -71,3 +76,108 @@ func TestExpiredTokenHS256(t *testing.T) {
 	_, _, err := token.VerifyToken(expiredToken)
 	require.Error(t, err)
 }
+
+func TestSessionClaimsHS256(t *testing.T) {
+	token := NewTokenMakerHS256(conf.JWTSecretKey, conf.TokenIssuer)
+
+	user := userfactory.NewOptions(nil)
+	session := Session{OrgId: 42, SessionId: uuid.New().String(), AuthTime: time.Now().Add(-time.Hour).Unix()}
+
+	accessToken, refreshToken, _, _, err := token.CreateToken(context.Background(), user, session, conf.AccessTokenDuration, conf.RefreshTokenDuration)
+	require.NoError(t, err)
+
+	_, accessClaims, err := token.VerifyToken(accessToken)
+	require.NoError(t, err)
+	require.Equal(t, session, SessionFromClaims(accessClaims))
+
+	_, refreshClaims, err := token.VerifyToken(refreshToken)
+	require.NoError(t, err)
+	require.Equal(t, session, SessionFromClaims(refreshClaims))
+
+	jti, _ := uuid.NewRandom()
+	refreshedToken, err := token.RefreshToken(context.Background(), user.Email, user.ID, SessionFromClaims(refreshClaims), conf.AccessTokenDuration, jti.String())
+	require.NoError(t, err)
+
+	_, refreshedClaims, err := token.VerifyToken(refreshedToken)
+	require.NoError(t, err)
+	require.Equal(t, session, SessionFromClaims(refreshedClaims))
+}
+
+func TestImpersonationClaimsHS256(t *testing.T) {
+	token := NewTokenMakerHS256(conf.JWTSecretKey, conf.TokenIssuer)
+
+	user := userfactory.NewOptions(nil)
+	session := Session{SessionId: uuid.New().String(), ActorId: 7, AuthTime: time.Now().Unix()}
+
+	accessToken, _, accessClaims, _, err := token.CreateToken(context.Background(), user, session, conf.AccessTokenDuration, conf.RefreshTokenDuration)
+	require.NoError(t, err)
+	require.Equal(t, session, SessionFromClaims(accessClaims))
+
+	_, parsedClaims, err := token.VerifyToken(accessToken)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{constant.SubKey: float64(7)}, parsedClaims[constant.ActorKey])
+	require.Equal(t, session, SessionFromClaims(parsedClaims))
+}
+
+func TestAuthTimeHS256(t *testing.T) {
+	token := NewTokenMakerHS256(conf.JWTSecretKey, conf.TokenIssuer)
+
+	user := userfactory.NewOptions(nil)
+
+	accessToken, refreshToken, _, _, err := token.CreateToken(context.Background(), user, Session{}, conf.AccessTokenDuration, conf.RefreshTokenDuration)
+	require.NoError(t, err)
+
+	_, accessClaims, err := token.VerifyToken(accessToken)
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now(), time.Unix(SessionFromClaims(accessClaims).AuthTime, 0), time.Second)
+
+	_, refreshClaims, err := token.VerifyToken(refreshToken)
+	require.NoError(t, err)
+	authTime := SessionFromClaims(refreshClaims).AuthTime
+	require.Equal(t, SessionFromClaims(accessClaims).AuthTime, authTime)
+
+	jti, _ := uuid.NewRandom()
+	refreshedToken, err := token.RefreshToken(context.Background(), user.Email, user.ID, Session{AuthTime: authTime - 60}, conf.AccessTokenDuration, jti.String())
+	require.NoError(t, err)
+
+	_, refreshedClaims, err := token.VerifyToken(refreshedToken)
+	require.NoError(t, err)
+	require.Equal(t, authTime-60, SessionFromClaims(refreshedClaims).AuthTime)
+}
+
+func TestConfirmationClaimsHS256(t *testing.T) {
+	token := NewTokenMakerHS256(conf.JWTSecretKey, conf.TokenIssuer)
+
+	user := userfactory.NewOptions(nil)
+	session := Session{SessionId: uuid.New().String(), AuthTime: time.Now().Unix(), Jkt: "0ZcOCORZNYy-DWpqq30jZyJGHTN0d2HglBV3uiguA4I", X5t: "bwcK0esc3ACC3DB2Y5_lESsXE8o9ltc05O89jdN-dg2"}
+
+	accessToken, refreshToken, _, _, err := token.CreateToken(context.Background(), user, session, conf.AccessTokenDuration, conf.RefreshTokenDuration)
+	require.NoError(t, err)
+
+	_, accessClaims, err := token.VerifyToken(accessToken)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{constant.JwkThumbprintKey: session.Jkt, constant.CertThumbprintKey: session.X5t}, accessClaims[constant.ConfirmationKey])
+	require.Equal(t, session, SessionFromClaims(accessClaims))
+
+	_, refreshClaims, err := token.VerifyToken(refreshToken)
+	require.NoError(t, err)
+	require.Equal(t, session, SessionFromClaims(refreshClaims))
+}
+
+func TestSignSpanHS256(t *testing.T) {
+	exporter := tracingtest.NewExporter(t)
+	token := NewTokenMakerHS256(conf.JWTSecretKey, conf.TokenIssuer)
+
+	ctx, parent := tracing.Start(context.Background(), "parent")
+	_, _, _, _, err := token.CreateToken(ctx, userfactory.NewOptions(nil), Session{}, conf.AccessTokenDuration, conf.RefreshTokenDuration)
+	require.NoError(t, err)
+	parent.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+	for _, span := range spans[:2] {
+		require.Equal(t, "token.sign", span.Name)
+		require.Equal(t, parent.SpanContext().SpanID(), span.Parent.SpanID())
+		require.Contains(t, span.Attributes, attribute.String("jwt.alg", "HS256"))
+	}
+}
@@ -1,17 +1,24 @@
 package token
 
 import (
+	"context"
 	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
 	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Maker interface {
+	// CreateToken issues a new access / refresh token pair. A zero session.AuthTime is stamped with the current time.
 	CreateToken(
+		ctx context.Context,
 		user sqlc.User,
+		session Session,
 		accessTokenDuration,
 		RefreshTokenDuration time.Duration,
 	) (
@@ -22,7 +29,36 @@ type Maker interface {
 		err error,
 	)
 	VerifyToken(tokenString string) (*jwt.Token, jwt.MapClaims, error)
-	RefreshToken(email string, userId int64, accessTokenDuration time.Duration, jti string) (accessToken string, err error)
+	RefreshToken(ctx context.Context, email string, userId int64, session Session, accessTokenDuration time.Duration, jti string) (accessToken string, err error)
+	// SignClaims signs arbitrary claims (e.g. OIDC ID tokens) with the maker's key, stamping the maker's issuer.
+	SignClaims(ctx context.Context, claims jwt.MapClaims) (signed string, err error)
+	Algorithm() string
+	// JWKS returns the public keys that verify tokens from this maker, empty for symmetric keys.
+	JWKS() []map[string]interface{}
+}
+
+// sign signs the claims in a span, the signature is the costly part of issuing a token
+func sign(ctx context.Context, token *jwt.Token, key interface{}) (string, error) {
+	_, span := tracing.Start(ctx, "token.sign", trace.WithAttributes(attribute.String("jwt.alg", token.Method.Alg())))
+	signed, err := token.SignedString(key)
+	tracing.End(span, err)
+	return signed, err
+}
+
+// CheckKeys signs a short-lived token and verifies it, which fails unless the keys of maker are loaded and match
+func CheckKeys(ctx context.Context, maker Maker) error {
+	now := time.Now()
+	signed, err := maker.SignClaims(ctx, jwt.MapClaims{
+		constant.SubKey:        "readiness",
+		constant.EmailKey:      "",
+		constant.IssuedAtKey:   now.Unix(),
+		constant.ExpirationKey: now.Add(time.Minute).Unix(),
+	})
+	if err != nil {
+		return err
+	}
+	_, _, err = maker.VerifyToken(signed)
+	return err
 }
 
 func payloadChecker(token *jwt.Token, ok bool, iss string) error {
@@ -5,12 +5,14 @@
 package token
 
 import (
+	context "context"
 	reflect "reflect"
 	time "time"
 
 	jwt "github.com/golang-jwt/jwt/v5"
 	gomock "github.com/golang/mock/gomock"
 	sqlc "github.com/hanifsyahsn/go_boilerplate/internal/db/sqlc"
+	token "github.com/hanifsyahsn/go_boilerplate/internal/util/token"
 )
 
 // MockMaker is a mock of Maker interface.
@@ -36,10 +38,24 @@ func (m *MockMaker) EXPECT() *MockMakerMockRecorder {
 	return m.recorder
 }
 
+// Algorithm mocks base method.
+func (m *MockMaker) Algorithm() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Algorithm")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Algorithm indicates an expected call of Algorithm.
+func (mr *MockMakerMockRecorder) Algorithm() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Algorithm", reflect.TypeOf((*MockMaker)(nil).Algorithm))
+}
+
 // CreateToken mocks base method.
-func (m *MockMaker) CreateToken(user sqlc.User, accessTokenDuration, RefreshTokenDuration time.Duration) (string, string, jwt.MapClaims, jwt.MapClaims, error) {
+func (m *MockMaker) CreateToken(ctx context.Context, user sqlc.User, session token.Session, accessTokenDuration, RefreshTokenDuration time.Duration) (string, string, jwt.MapClaims, jwt.MapClaims, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateToken", user, accessTokenDuration, RefreshTokenDuration)
+	ret := m.ctrl.Call(m, "CreateToken", ctx, user, session, accessTokenDuration, RefreshTokenDuration)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(string)
 	ret2, _ := ret[2].(jwt.MapClaims)
@@ -49,24 +65,53 @@ func (m *MockMaker) CreateToken(user sqlc.User, accessTokenDuration, RefreshToke
 }
 
 // CreateToken indicates an expected call of CreateToken.
-func (mr *MockMakerMockRecorder) CreateToken(user, accessTokenDuration, RefreshTokenDuration interface{}) *gomock.Call {
+func (mr *MockMakerMockRecorder) CreateToken(ctx, user, session, accessTokenDuration, RefreshTokenDuration interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateToken", reflect.TypeOf((*MockMaker)(nil).CreateToken), ctx, user, session, accessTokenDuration, RefreshTokenDuration)
+}
+
+// JWKS mocks base method.
+func (m *MockMaker) JWKS() []map[string]interface{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JWKS")
+	ret0, _ := ret[0].([]map[string]interface{})
+	return ret0
+}
+
+// JWKS indicates an expected call of JWKS.
+func (mr *MockMakerMockRecorder) JWKS() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateToken", reflect.TypeOf((*MockMaker)(nil).CreateToken), user, accessTokenDuration, RefreshTokenDuration)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JWKS", reflect.TypeOf((*MockMaker)(nil).JWKS))
 }
 
 // RefreshToken mocks base method.
-func (m *MockMaker) RefreshToken(email string, userId int64, accessTokenDuration time.Duration, jti string) (string, error) {
+func (m *MockMaker) RefreshToken(ctx context.Context, email string, userId int64, session token.Session, accessTokenDuration time.Duration, jti string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RefreshToken", email, userId, accessTokenDuration, jti)
+	ret := m.ctrl.Call(m, "RefreshToken", ctx, email, userId, session, accessTokenDuration, jti)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // RefreshToken indicates an expected call of RefreshToken.
-func (mr *MockMakerMockRecorder) RefreshToken(email, userId, accessTokenDuration, jti interface{}) *gomock.Call {
+func (mr *MockMakerMockRecorder) RefreshToken(ctx, email, userId, session, accessTokenDuration, jti interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshToken", reflect.TypeOf((*MockMaker)(nil).RefreshToken), ctx, email, userId, session, accessTokenDuration, jti)
+}
+
+// SignClaims mocks base method.
+func (m *MockMaker) SignClaims(ctx context.Context, claims jwt.MapClaims) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignClaims", ctx, claims)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SignClaims indicates an expected call of SignClaims.
+func (mr *MockMakerMockRecorder) SignClaims(ctx, claims interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshToken", reflect.TypeOf((*MockMaker)(nil).RefreshToken), email, userId, accessTokenDuration, jti)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignClaims", reflect.TypeOf((*MockMaker)(nil).SignClaims), ctx, claims)
 }
 
 // VerifyToken mocks base method.
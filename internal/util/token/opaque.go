@@ -0,0 +1,16 @@
+package token
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+)
+
+// GenerateOpaqueToken returns a URL-safe random string carrying 256 bits of entropy.
+// Only its HashToken digest should be persisted.
+func GenerateOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
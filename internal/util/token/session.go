@@ -0,0 +1,81 @@
+package token
+
+import (
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/hanifsyahsn/go_boilerplate/internal/util/constant"
+)
+
+// Session holds the optional claims of a login session. CreateToken stamps them on both tokens of the pair and
+// RefreshToken carries them over into every refreshed access token.
+type Session struct {
+	// OrgId is the active organization, 0 while none is selected
+	OrgId int64
+	// SessionId identifies the device session the token pair belongs to, see the refresh_tokens table
+	SessionId string
+	// ActorId is the admin acting on behalf of the subject during impersonation, stamped as the RFC 8693 act claim
+	ActorId int64
+	// AuthTime is the unix time the user last proved their identity. CreateToken stamps the current time when it is
+	// 0, RefreshToken only carries it over, so it does not move forward with refreshed access tokens.
+	AuthTime int64
+	// Jkt is the RFC 7638 thumbprint of the client's DPoP key the tokens are bound to, stamped as the RFC 9449
+	// cnf.jkt claim. Bound tokens are only accepted together with a DPoP proof signed by that key.
+	Jkt string
+	// X5t is the RFC 8705 x5t#S256 thumbprint of the mTLS client certificate the tokens are bound to. Bound tokens
+	// are only accepted over a connection authenticated with that certificate.
+	X5t string
+}
+
+func (session Session) apply(claims jwt.MapClaims) {
+	if session.OrgId != 0 {
+		claims[constant.OrgIdKey] = session.OrgId
+	}
+	if session.SessionId != "" {
+		claims[constant.SessionIdKey] = session.SessionId
+	}
+	if session.AuthTime != 0 {
+		claims[constant.AuthTimeKey] = session.AuthTime
+	}
+	if session.ActorId != 0 {
+		claims[constant.ActorKey] = map[string]interface{}{constant.SubKey: session.ActorId}
+	}
+	if session.Jkt != "" || session.X5t != "" {
+		cnf := map[string]interface{}{}
+		if session.Jkt != "" {
+			cnf[constant.JwkThumbprintKey] = session.Jkt
+		}
+		if session.X5t != "" {
+			cnf[constant.CertThumbprintKey] = session.X5t
+		}
+		claims[constant.ConfirmationKey] = cnf
+	}
+}
+
+// SessionFromClaims reads the session back from token claims, either freshly created or parsed from JSON
+func SessionFromClaims(claims jwt.MapClaims) (session Session) {
+	switch orgId := claims[constant.OrgIdKey].(type) {
+	case int64:
+		session.OrgId = orgId
+	case float64:
+		session.OrgId = int64(orgId)
+	}
+	session.SessionId, _ = claims[constant.SessionIdKey].(string)
+	switch authTime := claims[constant.AuthTimeKey].(type) {
+	case int64:
+		session.AuthTime = authTime
+	case float64:
+		session.AuthTime = int64(authTime)
+	}
+	if actor, ok := claims[constant.ActorKey].(map[string]interface{}); ok {
+		switch actorId := actor[constant.SubKey].(type) {
+		case int64:
+			session.ActorId = actorId
+		case float64:
+			session.ActorId = int64(actorId)
+		}
+	}
+	if cnf, ok := claims[constant.ConfirmationKey].(map[string]interface{}); ok {
+		session.Jkt, _ = cnf[constant.JwkThumbprintKey].(string)
+		session.X5t, _ = cnf[constant.CertThumbprintKey].(string)
+	}
+	return
+}
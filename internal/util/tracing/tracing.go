@@ -0,0 +1,62 @@
+// Package tracing sets up OpenTelemetry and starts the spans of the application. Without an OTLP endpoint the
+// global tracer provider stays the no-op default, so spans cost next to nothing.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hanifsyahsn/go_boilerplate/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName names the tracer of every span started by the application
+const instrumentationName = "github.com/hanifsyahsn/go_boilerplate"
+
+// Setup exports spans to OTEL_EXPORTER_OTLP_ENDPOINT over OTLP/HTTP. The returned function flushes the pending spans
+// and must be called before the process exits; it does nothing when tracing is disabled.
+func Setup(ctx context.Context, config config.Config) (shutdown func(ctx context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	if !config.TracingEnabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	// An http:// URL sends the spans without TLS
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(config.OTelEndpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceName(config.OTelServiceName),
+			semconv.DeploymentEnvironmentName(config.ENV),
+		)),
+		// Callers that already decided to sample keep the whole trace together
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.OTelSampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Start starts a span as a child of the span in ctx, if any
+func Start(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, options...)
+}
+
+// End records err on the span, if any, and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
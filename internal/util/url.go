@@ -0,0 +1,16 @@
+package util
+
+import "net/url"
+
+// WithQueryParam sets key to value in the query of rawURL, keeping the parameters already there, e.g. to append the
+// token to the link of an email. A rawURL that cannot be parsed gets the parameter appended as is.
+func WithQueryParam(rawURL, key, value string) string {
+	link, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL + "?" + url.QueryEscape(key) + "=" + url.QueryEscape(value)
+	}
+	query := link.Query()
+	query.Set(key, value)
+	link.RawQuery = query.Encode()
+	return link.String()
+}
@@ -0,0 +1,27 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithQueryParam(t *testing.T) {
+	testCases := []struct {
+		name   string
+		rawURL string
+		want   string
+	}{
+		{name: "no query", rawURL: "https://app.example.com/magic-link", want: "https://app.example.com/magic-link?token=a+b%2Fc"},
+		{name: "existing query", rawURL: "https://app.example.com/signup?lang=en", want: "https://app.example.com/signup?lang=en&token=a+b%2Fc"},
+		{name: "existing token", rawURL: "https://app.example.com/signup?token=old", want: "https://app.example.com/signup?token=a+b%2Fc"},
+		{name: "fragment", rawURL: "https://app.example.com/#/accept", want: "https://app.example.com/?token=a+b%2Fc#/accept"},
+		{name: "unparsable", rawURL: "https://app.example.com/%zz", want: "https://app.example.com/%zz?token=a+b%2Fc"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			require.Equal(t, testCase.want, WithQueryParam(testCase.rawURL, "token", "a b/c"))
+		})
+	}
+}